@@ -0,0 +1,204 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	AmbientEnrollmentKind = "AmbientEnrollment"
+)
+
+// AmbientEnrollmentSpec defines the desired state of AmbientEnrollment
+type AmbientEnrollmentSpec struct {
+	// The Istio control plane that the enrolled namespaces use. Valid references are Istio and IstioRevision
+	// resources. Istio resources are always resolved to their current active revision.
+	// +kubebuilder:validation:Required
+	TargetRef TargetReference `json:"targetRef"`
+
+	// Selects the namespaces to add to the ambient mesh. The operator sets the istio.io/dataplane-mode=ambient label
+	// on each selected namespace, and removes it when the namespace is no longer selected.
+	// +kubebuilder:validation:Required
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+
+	// Defines the waypoint proxy deployed in each enrolled namespace. If not set, no waypoint is deployed.
+	// +optional
+	Waypoint *WaypointRequirement `json:"waypoint,omitempty"`
+}
+
+// WaypointTrafficType defines the type of traffic that a waypoint proxy handles.
+// +kubebuilder:validation:Enum=service;workload;all;none
+type WaypointTrafficType string
+
+const (
+	WaypointTrafficTypeService  WaypointTrafficType = "service"
+	WaypointTrafficTypeWorkload WaypointTrafficType = "workload"
+	WaypointTrafficTypeAll      WaypointTrafficType = "all"
+	WaypointTrafficTypeNone     WaypointTrafficType = "none"
+)
+
+// WaypointRequirement defines the waypoint proxy deployed in each enrolled namespace.
+type WaypointRequirement struct {
+	// The name of the waypoint Gateway. The operator also sets the istio.io/use-waypoint label on the namespace
+	// to this name, so that the traffic in the namespace is routed through the waypoint.
+	// +kubebuilder:default=waypoint
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// The type of traffic that the waypoint handles. It is set in the istio.io/waypoint-for label of the Gateway.
+	// +kubebuilder:default=service
+	// +optional
+	TrafficType WaypointTrafficType `json:"trafficType,omitempty"`
+}
+
+// AmbientEnrollmentStatus defines the observed state of AmbientEnrollment
+type AmbientEnrollmentStatus struct {
+	// ObservedGeneration is the most recent generation observed for this
+	// AmbientEnrollment object. It corresponds to the object's generation, which is
+	// updated on mutation by the API Server. The information in the status
+	// pertains to this particular generation of the object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Represents the latest available observations of the object's current state.
+	Conditions []StatusCondition `json:"conditions,omitempty"`
+
+	// Reports the current state of the object.
+	State AmbientEnrollmentConditionReason `json:"state,omitempty"`
+
+	// IstioRevision stores the name of the IstioRevision that the enrolled namespaces and their waypoints use.
+	IstioRevision string `json:"istioRevision,omitempty"`
+
+	// Namespaces reports the enrollment of each namespace selected by spec.namespaceSelector.
+	// +optional
+	Namespaces []NamespaceEnrollmentStatus `json:"namespaces,omitempty"`
+
+	// RetryCount is the number of consecutive failed reconciliations. It is reset when the
+	// object is reconciled successfully or when reconciliation fails with an error that retrying can't fix.
+	// +optional
+	RetryCount int32 `json:"retryCount,omitempty"`
+
+	// NextRetryTime is the time at which the operator retries the failed reconciliation.
+	// It is not set when no retry is scheduled.
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+}
+
+// NamespaceEnrollmentState is the enrollment state of a namespace.
+// +kubebuilder:validation:Enum=Enrolled;Conflict
+type NamespaceEnrollmentState string
+
+const (
+	// NamespaceEnrolled indicates that the namespace is labeled for ambient mode and that its waypoint was deployed.
+	NamespaceEnrolled NamespaceEnrollmentState = "Enrolled"
+
+	// NamespaceEnrollmentConflict indicates that the namespace wasn't enrolled, because its labels conflict with the
+	// enrollment, for example because sidecar injection is enabled in the namespace.
+	NamespaceEnrollmentConflict NamespaceEnrollmentState = "Conflict"
+)
+
+// NamespaceEnrollmentStatus reports the enrollment of a namespace.
+type NamespaceEnrollmentStatus struct {
+	// The name of the namespace.
+	Name string `json:"name"`
+
+	// The enrollment state of the namespace.
+	State NamespaceEnrollmentState `json:"state"`
+
+	// A human-readable message describing the conflict.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// GetCondition returns the condition of the specified type
+func (s *AmbientEnrollmentStatus) GetCondition(conditionType AmbientEnrollmentConditionType) StatusCondition {
+	if s == nil {
+		return StatusCondition{Type: conditionType, Status: metav1.ConditionUnknown}
+	}
+	return GetCondition(s.Conditions, conditionType)
+}
+
+// SetCondition sets a specific condition in the list of conditions
+func (s *AmbientEnrollmentStatus) SetCondition(condition StatusCondition) {
+	SetCondition(&s.Conditions, condition)
+}
+
+// AmbientEnrollmentConditionType is an alias for ConditionType.
+type AmbientEnrollmentConditionType = ConditionType
+
+// AmbientEnrollmentConditionReason is an alias for ConditionReason.
+type AmbientEnrollmentConditionReason = ConditionReason
+
+const (
+	// AmbientEnrollmentConditionReconciled signifies whether the controller has
+	// successfully reconciled the resources defined through the CR.
+	AmbientEnrollmentConditionReconciled AmbientEnrollmentConditionType = "Reconciled"
+
+	// AmbientEnrollmentReasonReferenceNotFound indicates that the resource referenced by the enrollment's TargetRef was not found
+	AmbientEnrollmentReasonReferenceNotFound AmbientEnrollmentConditionReason = "RefNotFound"
+
+	// AmbientEnrollmentReasonReconcileError indicates that the reconciliation of the resource has failed, but will be retried.
+	AmbientEnrollmentReasonReconcileError AmbientEnrollmentConditionReason = "ReconcileError"
+
+	// AmbientEnrollmentReasonSuspended indicates that reconciliation of the resource is paused by the sailoperator.io/reconcile annotation.
+	AmbientEnrollmentReasonSuspended AmbientEnrollmentConditionReason = "Suspended"
+)
+
+const (
+	// AmbientEnrollmentConditionNamespacesEnrolled signifies whether all selected namespaces were enrolled.
+	AmbientEnrollmentConditionNamespacesEnrolled AmbientEnrollmentConditionType = "NamespacesEnrolled"
+
+	// AmbientEnrollmentReasonNamespaceConflict indicates that some of the selected namespaces weren't enrolled because their
+	// labels conflict with the enrollment. The conflicts are listed in status.namespaces.
+	AmbientEnrollmentReasonNamespaceConflict AmbientEnrollmentConditionReason = "NamespaceConflict"
+)
+
+const (
+	// AmbientEnrollmentReasonHealthy indicates that all selected namespaces were enrolled.
+	AmbientEnrollmentReasonHealthy AmbientEnrollmentConditionReason = "Healthy"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,categories=istio-io
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.state",description="The current state of this object."
+// +kubebuilder:printcolumn:name="Revision",type="string",JSONPath=".status.istioRevision",description="The IstioRevision the enrolled namespaces use."
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="The age of the object"
+
+// AmbientEnrollment adds the namespaces selected by its namespace selector to the ambient mesh. The operator labels
+// the namespaces with istio.io/dataplane-mode=ambient and optionally deploys a waypoint proxy in each of them, which
+// is controlled by the referenced Istio control plane. Namespaces that have sidecar injection enabled aren't enrolled.
+type AmbientEnrollment struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata"`
+
+	// +optional
+	Spec AmbientEnrollmentSpec `json:"spec"`
+
+	// +optional
+	Status AmbientEnrollmentStatus `json:"status"`
+}
+
+// +kubebuilder:object:root=true
+
+// AmbientEnrollmentList contains a list of AmbientEnrollments
+type AmbientEnrollmentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []AmbientEnrollment `json:"items"`
+}
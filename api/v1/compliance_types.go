@@ -0,0 +1,45 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+// FIPSMode defines whether a component runs in FIPS mode.
+// +kubebuilder:validation:Enum=Auto;Enabled;Disabled
+type FIPSMode string
+
+const (
+	// FIPSModeAuto enables FIPS mode when the node that the operator runs on is in FIPS mode.
+	FIPSModeAuto FIPSMode = "Auto"
+
+	// FIPSModeEnabled always enables FIPS mode.
+	FIPSModeEnabled FIPSMode = "Enabled"
+
+	// FIPSModeDisabled never enables FIPS mode, even when the node that the operator runs on is in FIPS mode.
+	FIPSModeDisabled FIPSMode = "Disabled"
+)
+
+// Compliance defines the compliance policies that the operator applies to a component.
+type Compliance struct {
+	// Defines whether the component runs in FIPS mode. With Auto, FIPS mode is enabled when the node that the
+	// operator runs on is in FIPS mode. FIPS mode is only supported with the default and distroless image variants.
+	// +optional
+	// +kubebuilder:default=Auto
+	FIPS FIPSMode `json:"fips,omitempty"`
+}
+
+// ComplianceStatus reports the compliance policies that are in effect for a component.
+type ComplianceStatus struct {
+	// Whether FIPS mode is in effect. It is either Enabled or Disabled.
+	FIPS FIPSMode `json:"fips"`
+}
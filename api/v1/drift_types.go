@@ -0,0 +1,31 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DriftSummary lists the resources managed by the operator that were modified outside of the operator
+// while reconciliation was paused.
+type DriftSummary struct {
+	// The time at which reconciliation was resumed and the drift was detected.
+	DetectedAt metav1.Time `json:"detectedAt"`
+
+	// The resources that no longer matched the desired state when reconciliation was resumed,
+	// in the format "<Kind> <namespace>/<name>". The operator reverted the changes to these resources.
+	// +optional
+	Resources []string `json:"resources,omitempty"`
+}
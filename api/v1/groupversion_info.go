@@ -42,6 +42,14 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&IstioRevisionList{},
 		&IstioRevisionTag{},
 		&IstioRevisionTagList{},
+		&IstioRevisionBinding{},
+		&IstioRevisionBindingList{},
+		&AmbientEnrollment{},
+		&AmbientEnrollmentList{},
+		&Migration{},
+		&MigrationList{},
+		&Waypoint{},
+		&WaypointList{},
 		&IstioCNI{},
 		&IstioCNIList{},
 		&ZTunnel{},
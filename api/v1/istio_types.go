@@ -15,6 +15,8 @@
 package v1
 
 import (
+	"encoding/json"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -63,6 +65,70 @@ type IstioSpec struct {
 	// Defines the values to be passed to the Helm charts when installing Istio.
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Helm Values"
 	Values *Values `json:"values,omitempty"`
+
+	// Defines which revisions of this control plane namespace administrators may select for their namespaces
+	// by creating an IstioRevisionBinding. If not set, IstioRevisionBindings that reference this Istio or any
+	// of its revisions are rejected.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Tenancy"
+	Tenancy *IstioTenancy `json:"tenancy,omitempty"`
+
+	// Defines the gateways that the operator installs using the gateway Helm chart. The injected revision of
+	// each gateway always tracks the active IstioRevision. Gateways removed from this list are uninstalled.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Gateways"
+	Gateways []IstioGateway `json:"gateways,omitempty"`
+
+	// Defines the minimum TLS version, cipher suites and ECDH curves that the control plane and the mesh use.
+	// The operator applies them to meshConfig.tlsDefaults, meshConfig.meshMTLS and the istiod arguments,
+	// unless they are set explicitly in spec.values. On OpenShift, this overrides the TLS profile of the
+	// cluster's APIServer.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="TLS Profile"
+	TLSProfile *TLSProfile `json:"tlsProfile,omitempty"`
+
+	// Defines the compliance policies, such as FIPS mode, that the operator applies to the control plane.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Compliance"
+	Compliance *Compliance `json:"compliance,omitempty"`
+}
+
+// IstioGateway defines a gateway that is installed using the gateway Helm chart.
+type IstioGateway struct {
+	// Name of the gateway. It is used as the name of the gateway's Deployment and Service. The Helm release is
+	// named <name>-gateway. Gateway names must be unique within the Istio resource.
+	//
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=45
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace to which the gateway should be installed. Defaults to spec.namespace. The namespace must exist.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Defines the values to be passed to the gateway Helm chart. The revision value is always set to the
+	// name of the active IstioRevision.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Values json.RawMessage `json:"values,omitempty"`
+}
+
+// IstioTenancy defines which revisions of an Istio control plane may be selected by namespace administrators
+// through IstioRevisionBinding resources.
+type IstioTenancy struct {
+	// List of IstioRevision names that IstioRevisionBindings may reference directly. An IstioRevisionBinding
+	// that references the Istio resource itself always follows the active revision and is always allowed.
+	// +optional
+	AllowedRevisions []string `json:"allowedRevisions,omitempty"`
+
+	// Restricts which namespaces may bind to the revisions of this Istio. If not set, IstioRevisionBindings
+	// in any namespace are allowed.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
 }
 
 // IstioUpdateStrategy defines how the control plane should be updated when the version in
@@ -96,6 +162,79 @@ type IstioUpdateStrategy struct {
 	// Defaults to false.
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=3,displayName="Update Workloads Automatically",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
 	UpdateWorkloads bool `json:"updateWorkloads,omitempty"`
+
+	// Defines additional rules that determine which non-active revisions are deleted once they are
+	// no longer in use. Revisions referenced by an IstioRevisionTag are never deleted.
+	// +optional
+	PruningPolicy *RevisionPruningPolicy `json:"pruningPolicy,omitempty"`
+}
+
+// RevisionPruningPolicy defines which non-active revisions the operator keeps after they are no longer in use.
+type RevisionPruningPolicy struct {
+	// Number of most recently created non-active revisions that are never deleted, so that
+	// workloads can be moved back to them quickly. Defaults to 0.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepLastRevisions int32 `json:"keepLastRevisions,omitempty"`
+
+	// Names of the IstioRevisions that are never deleted.
+	// +optional
+	RetainedRevisions []string `json:"retainedRevisions,omitempty"`
+
+	// Defines whether non-active revisions are deleted only after they have been approved for deletion
+	// by setting the sailoperator.io/prune-approved annotation on the IstioRevision to "true".
+	// Defaults to false.
+	// +optional
+	RequireApproval bool `json:"requireApproval,omitempty"`
+}
+
+// TLSProfile defines the TLS settings of the control plane and the mesh.
+// +kubebuilder:validation:XValidation:rule="self.type == 'Custom' ? has(self.custom) : !has(self.custom)",message="custom must be set if and only if type is Custom"
+type TLSProfile struct {
+	// The type of the profile. Old, Intermediate and Modern are the predefined profiles based on the
+	// Mozilla Server Side TLS guidelines, which are also used by OpenShift. Custom uses the settings in custom.
+	// +kubebuilder:validation:Enum=Old;Intermediate;Modern;Custom
+	// +kubebuilder:validation:Required
+	Type TLSProfileType `json:"type"`
+
+	// Defines the TLS settings when type is Custom.
+	// +optional
+	Custom *CustomTLSProfile `json:"custom,omitempty"`
+}
+
+// TLSProfileType is the type of a TLSProfile.
+type TLSProfileType string
+
+const (
+	// TLSProfileOld is the profile for clients that don't support TLS 1.2.
+	TLSProfileOld TLSProfileType = "Old"
+
+	// TLSProfileIntermediate is the recommended profile, which requires TLS 1.2 or later.
+	TLSProfileIntermediate TLSProfileType = "Intermediate"
+
+	// TLSProfileModern is the profile that requires TLS 1.3.
+	TLSProfileModern TLSProfileType = "Modern"
+
+	// TLSProfileCustom is a profile with the settings in custom.
+	TLSProfileCustom TLSProfileType = "Custom"
+)
+
+// CustomTLSProfile defines custom TLS settings.
+type CustomTLSProfile struct {
+	// The cipher suites, in OpenSSL or IANA format. Envoy ignores them when minTLSVersion is
+	// VersionTLS13.
+	// +kubebuilder:validation:MinItems=1
+	Ciphers []string `json:"ciphers"`
+
+	// The minimum TLS version.
+	// +kubebuilder:validation:Enum=VersionTLS12;VersionTLS13
+	// +kubebuilder:validation:Required
+	MinTLSVersion string `json:"minTLSVersion"`
+
+	// The ECDH curves, e.g. X25519, secp256r1, secp384r1 or X25519MLKEM768. If not set, the proxies use
+	// their default curves.
+	// +optional
+	Curves []string `json:"curves,omitempty"`
 }
 
 // IstioStatus defines the observed state of Istio
@@ -118,6 +257,156 @@ type IstioStatus struct {
 	// Reports information about the underlying IstioRevisions.
 	// +optional
 	Revisions RevisionSummary `json:"revisions"`
+
+	// Platform is the Kubernetes platform detected by the operator, e.g. openshift, gke or k3s.
+	// It determines the default value of values.global.platform.
+	Platform string `json:"platform,omitempty"`
+
+	// Lists the gateways that are currently installed by the operator.
+	// +optional
+	Gateways []IstioGatewayStatus `json:"gateways,omitempty"`
+
+	// RetryCount is the number of consecutive failed reconciliations. It is reset when the
+	// object is reconciled successfully or when reconciliation fails with an error that retrying can't fix.
+	// +optional
+	RetryCount int32 `json:"retryCount,omitempty"`
+
+	// NextRetryTime is the time at which the operator retries the failed reconciliation.
+	// It is not set when no retry is scheduled.
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+
+	// Reports the pruning decision for each non-active IstioRevision and the reason for it.
+	// +optional
+	RevisionPruning []RevisionPruningStatus `json:"revisionPruning,omitempty"`
+
+	// Reports the TLS settings that the operator applies to the active revision. It is not set when no TLS
+	// settings are applied.
+	// +optional
+	TLSProfile *TLSProfileStatus `json:"tlsProfile,omitempty"`
+
+	// Reports the compliance policies that are in effect for the control plane.
+	// +optional
+	Compliance *ComplianceStatus `json:"compliance,omitempty"`
+}
+
+// TLSProfileSource is the source of the TLS settings that the operator applies.
+type TLSProfileSource string
+
+const (
+	// TLSProfileSourceIstio indicates that the TLS settings are defined in spec.tlsProfile.
+	TLSProfileSourceIstio TLSProfileSource = "Istio"
+
+	// TLSProfileSourceAPIServer indicates that the TLS settings are taken from the TLS profile of the
+	// OpenShift APIServer.
+	TLSProfileSourceAPIServer TLSProfileSource = "APIServer"
+)
+
+// TLSProfileStatus reports the TLS settings that the operator applies.
+type TLSProfileStatus struct {
+	// The source of the TLS settings. It is not set on an IstioRevision, which reports the settings
+	// configured in its values.
+	// +optional
+	Source TLSProfileSource `json:"source,omitempty"`
+
+	// The type of the profile in spec.tlsProfile. It is not set when the settings are taken from
+	// the APIServer.
+	// +optional
+	Type TLSProfileType `json:"type,omitempty"`
+
+	// The minimum TLS version.
+	// +optional
+	MinTLSVersion string `json:"minTLSVersion,omitempty"`
+
+	// The cipher suites, in IANA format.
+	// +optional
+	Ciphers []string `json:"ciphers,omitempty"`
+
+	// The ECDH curves.
+	// +optional
+	Curves []string `json:"curves,omitempty"`
+}
+
+// RevisionPruningDecision is the outcome of evaluating whether a non-active IstioRevision is deleted.
+// +kubebuilder:validation:Enum=Retained;Scheduled;AwaitingApproval;Pruned
+type RevisionPruningDecision string
+
+const (
+	// RevisionPruningDecisionRetained means the revision is kept.
+	RevisionPruningDecisionRetained RevisionPruningDecision = "Retained"
+
+	// RevisionPruningDecisionScheduled means the revision is deleted when its grace period expires.
+	RevisionPruningDecisionScheduled RevisionPruningDecision = "Scheduled"
+
+	// RevisionPruningDecisionAwaitingApproval means the revision is deleted once it is approved for deletion.
+	RevisionPruningDecisionAwaitingApproval RevisionPruningDecision = "AwaitingApproval"
+
+	// RevisionPruningDecisionPruned means the revision was deleted.
+	RevisionPruningDecisionPruned RevisionPruningDecision = "Pruned"
+)
+
+// RevisionPruningReason explains a RevisionPruningDecision.
+// +kubebuilder:validation:Enum=InUse;UsageUnknown;ReferencedByTag;RetainedByName;KeepLast;GracePeriod;ApprovalRequired;Expired
+type RevisionPruningReason string
+
+const (
+	// RevisionPruningReasonInUse indicates that the revision is in use.
+	RevisionPruningReasonInUse RevisionPruningReason = "InUse"
+
+	// RevisionPruningReasonUsageUnknown indicates that the operator couldn't determine whether the revision is in use.
+	RevisionPruningReasonUsageUnknown RevisionPruningReason = "UsageUnknown"
+
+	// RevisionPruningReasonReferencedByTag indicates that the revision is referenced by an IstioRevisionTag.
+	RevisionPruningReasonReferencedByTag RevisionPruningReason = "ReferencedByTag"
+
+	// RevisionPruningReasonRetainedByName indicates that the revision is listed in pruningPolicy.retainedRevisions.
+	RevisionPruningReasonRetainedByName RevisionPruningReason = "RetainedByName"
+
+	// RevisionPruningReasonKeepLast indicates that the revision is one of the revisions kept by pruningPolicy.keepLastRevisions.
+	RevisionPruningReasonKeepLast RevisionPruningReason = "KeepLast"
+
+	// RevisionPruningReasonGracePeriod indicates that the revision is not in use, but its grace period hasn't expired yet.
+	RevisionPruningReasonGracePeriod RevisionPruningReason = "GracePeriod"
+
+	// RevisionPruningReasonApprovalRequired indicates that the grace period of the revision has expired, but
+	// its deletion hasn't been approved.
+	RevisionPruningReasonApprovalRequired RevisionPruningReason = "ApprovalRequired"
+
+	// RevisionPruningReasonExpired indicates that the revision is not in use and its grace period has expired.
+	RevisionPruningReasonExpired RevisionPruningReason = "Expired"
+)
+
+// RevisionPruningStatus reports the pruning decision for a non-active IstioRevision.
+type RevisionPruningStatus struct {
+	// Name of the IstioRevision.
+	Name string `json:"name"`
+
+	// Decision made for the IstioRevision.
+	Decision RevisionPruningDecision `json:"decision"`
+
+	// Reason for the decision.
+	Reason RevisionPruningReason `json:"reason"`
+
+	// Human-readable explanation of the decision.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Time at which the IstioRevision is deleted. Only set when the decision is Scheduled.
+	// +optional
+	PruneTime *metav1.Time `json:"pruneTime,omitempty"`
+}
+
+// IstioGatewayStatus identifies a gateway installed by the operator.
+type IstioGatewayStatus struct {
+	// Name of the gateway.
+	Name string `json:"name"`
+
+	// Namespace in which the gateway is installed.
+	Namespace string `json:"namespace"`
+
+	// Name of the IstioRevision that injects the gateway. When the active revision changes, the gateway is only
+	// moved to the new revision once it is ready.
+	Revision string `json:"revision,omitempty"`
 }
 
 // RevisionSummary contains information on the number of IstioRevisions associated with this Istio.
@@ -158,6 +447,9 @@ const (
 
 	// IstioReasonReconcileError indicates that the reconciliation of the resource has failed, but will be retried.
 	IstioReasonReconcileError IstioConditionReason = "ReconcileError"
+
+	// IstioReasonSuspended indicates that reconciliation of the resource is paused by the sailoperator.io/reconcile annotation.
+	IstioReasonSuspended IstioConditionReason = "Suspended"
 )
 
 const (
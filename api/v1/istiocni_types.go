@@ -41,11 +41,25 @@ type IstioCNISpec struct {
 	Profile string `json:"profile,omitempty"`
 
 	// Namespace to which the Istio CNI component should be installed. Note that this field is immutable.
+	// Each IstioCNI instance must be installed in a different namespace.
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:io.kubernetes:Namespace"}
 	// +kubebuilder:default=istio-cni
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
 	Namespace string `json:"namespace"`
 
+	// Restricts the Istio CNI component to the nodes whose labels match this selector, so that different node pools
+	// can run different IstioCNI instances. The selectors of two instances must not match the same node.
+	// Only the instance named 'default' may omit the selector, in which case it runs on all nodes.
+	// +optional
+	// +kubebuilder:validation:MinProperties=1
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Defines how changes to the Istio CNI DaemonSet are rolled out to the nodes. By default, the update strategy of
+	// the DaemonSet replaces the pods on all nodes. With the Batched type, the operator replaces them in batches
+	// of nodes and waits for the workloads on each batch to be ready before continuing.
+	// +optional
+	Rollout *DaemonSetRolloutStrategy `json:"rollout,omitempty"`
+
 	// Defines the values to be passed to the Helm charts when installing Istio CNI.
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Helm Values"
 	Values *CNIValues `json:"values,omitempty"`
@@ -64,6 +78,35 @@ type IstioCNIStatus struct {
 
 	// Reports the current state of the object.
 	State IstioCNIConditionReason `json:"state,omitempty"`
+
+	// Platform is the Kubernetes platform detected by the operator, e.g. openshift, gke or k3s.
+	// It determines the default value of values.global.platform.
+	Platform string `json:"platform,omitempty"`
+
+	// RetryCount is the number of consecutive failed reconciliations. It is reset when the
+	// object is reconciled successfully or when reconciliation fails with an error that retrying can't fix.
+	// +optional
+	RetryCount int32 `json:"retryCount,omitempty"`
+
+	// NextRetryTime is the time at which the operator retries the failed reconciliation.
+	// It is not set when no retry is scheduled.
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+
+	// Drift lists the resources that were modified outside of the operator while reconciliation was paused.
+	// It is recorded when reconciliation is resumed and cleared when it is paused again.
+	// +optional
+	Drift *DriftSummary `json:"drift,omitempty"`
+
+	// Rollout reports the progress of the batched rollout of the Istio CNI DaemonSet.
+	// It is only set when spec.rollout.type is Batched.
+	// +optional
+	Rollout *DaemonSetRolloutStatus `json:"rollout,omitempty"`
+
+	// Version is the version of the Istio CNI component that was last installed successfully. When the version skew is enforced,
+	// only a change of spec.version to an unsupported version is rejected.
+	// +optional
+	Version string `json:"version,omitempty"`
 }
 
 // GetCondition returns the condition of the specified type
@@ -92,6 +135,9 @@ const (
 
 	// IstioCNIReasonReconcileError indicates that the reconciliation of the resource has failed, but will be retried.
 	IstioCNIReasonReconcileError IstioCNIConditionReason = "ReconcileError"
+
+	// IstioCNIReasonSuspended indicates that reconciliation of the resource is paused by the sailoperator.io/reconcile annotation.
+	IstioCNIReasonSuspended IstioCNIConditionReason = "Suspended"
 )
 
 const (
@@ -105,6 +151,21 @@ const (
 	IstioCNIReasonReadinessCheckFailed IstioCNIConditionReason = "ReadinessCheckFailed"
 )
 
+const (
+	// IstioCNIConditionVersionSkew signifies whether the version of the IstioCNI is outside the version skew supported
+	// by any of the IstioRevisions that depend on it. Unlike the other conditions, True indicates a problem.
+	IstioCNIConditionVersionSkew IstioCNIConditionType = "VersionSkew"
+
+	// IstioCNIReasonUnsupportedVersionSkew indicates that the IstioCNI version isn't supported by at least one IstioRevision.
+	IstioCNIReasonUnsupportedVersionSkew IstioCNIConditionReason = "UnsupportedVersionSkew"
+
+	// IstioCNIReasonSupportedVersionSkew indicates that the IstioCNI version is supported by all IstioRevisions that depend on it.
+	IstioCNIReasonSupportedVersionSkew IstioCNIConditionReason = "SupportedVersionSkew"
+
+	// IstioCNIReasonVersionSkewCheckFailed indicates that the version skew could not be ascertained.
+	IstioCNIReasonVersionSkewCheckFailed IstioCNIConditionReason = "VersionSkewCheckFailed"
+)
+
 const (
 	// IstioCNIReasonHealthy indicates that the control plane is fully reconciled and that all components are ready.
 	IstioCNIReasonHealthy IstioCNIConditionReason = "Healthy"
@@ -119,7 +180,7 @@ const (
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.state",description="The current state of this object."
 // +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".spec.version",description="The version of the Istio CNI installation."
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="The age of the object"
-// +kubebuilder:validation:XValidation:rule="self.metadata.name == 'default'",message="metadata.name must be 'default'"
+// +kubebuilder:validation:XValidation:rule="self.metadata.name == 'default' || has(self.spec.nodeSelector)",message="metadata.name must be 'default' unless spec.nodeSelector is set"
 
 // IstioCNI represents a deployment of the Istio CNI component.
 type IstioCNI struct {
@@ -56,6 +56,40 @@ type IstioRevisionStatus struct {
 
 	// Reports the current state of the object.
 	State IstioRevisionConditionReason `json:"state,omitempty"`
+
+	// Platform is the Kubernetes platform detected by the operator, e.g. openshift, gke or k3s.
+	// It determines the default value of values.global.platform.
+	Platform string `json:"platform,omitempty"`
+
+	// RetryCount is the number of consecutive failed reconciliations. It is reset when the
+	// object is reconciled successfully or when reconciliation fails with an error that retrying can't fix.
+	// +optional
+	RetryCount int32 `json:"retryCount,omitempty"`
+
+	// NextRetryTime is the time at which the operator retries the failed reconciliation.
+	// It is not set when no retry is scheduled.
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+
+	// Drift lists the resources that were modified outside of the operator while reconciliation was paused.
+	// It is recorded when reconciliation is resumed and cleared when it is paused again.
+	// +optional
+	Drift *DriftSummary `json:"drift,omitempty"`
+
+	// RemoteProbes reports the results of the readiness probes of the webhooks that point to the remote
+	// control plane. It is only set when the revision uses a remote control plane.
+	// +optional
+	RemoteProbes []WebhookProbeStatus `json:"remoteProbes,omitempty"`
+
+	// TLSProfile reports the TLS settings in effect for the control plane, as configured in
+	// values.meshConfig.tlsDefaults. It is not set when no TLS settings are configured.
+	// +optional
+	TLSProfile *TLSProfileStatus `json:"tlsProfile,omitempty"`
+
+	// Version is the version of the control plane that was last installed successfully. When the version skew is enforced,
+	// only a change of spec.version to an unsupported version is rejected.
+	// +optional
+	Version string `json:"version,omitempty"`
 }
 
 // GetCondition returns the condition of the specified type
@@ -84,6 +118,9 @@ const (
 
 	// IstioRevisionReasonReconcileError indicates that the reconciliation of the resource has failed, but will be retried.
 	IstioRevisionReasonReconcileError IstioRevisionConditionReason = "ReconcileError"
+
+	// IstioRevisionReasonSuspended indicates that reconciliation of the resource is paused by the sailoperator.io/reconcile annotation.
+	IstioRevisionReasonSuspended IstioRevisionConditionReason = "Suspended"
 )
 
 const (
@@ -116,6 +153,11 @@ const (
 
 	// IstioRevisionReasonUsageCheckFailed indicates that the operator could not check whether any workloads use the revision.
 	IstioRevisionReasonUsageCheckFailed IstioRevisionConditionReason = "UsageCheckFailed"
+
+	// IstioRevisionReasonRemoteKubeconfigNotFound indicates that the revision deploys an external control plane, but the
+	// operator can't check whether any workloads in the remote cluster use the revision, because the kubeconfig Secret
+	// for the remote cluster doesn't exist.
+	IstioRevisionReasonRemoteKubeconfigNotFound IstioRevisionConditionReason = "RemoteKubeconfigNotFound"
 )
 
 const (
@@ -125,18 +167,24 @@ const (
 	// the IstioCNI resource is healthy.
 	IstioRevisionConditionDependenciesHealthy IstioRevisionConditionType = "DependenciesHealthy"
 
-	// IstioRevisionReasonIstioCNINotFound indicates that the IstioCNI resource is not found.
+	// IstioRevisionReasonIstioCNINotFound indicates that no IstioCNI resource exists, or that none runs on a node
+	// hosting workloads of the revision.
 	IstioRevisionReasonIstioCNINotFound IstioRevisionConditionReason = "IstioCNINotFound"
 
-	// IstioRevisionReasonIstioCNINotHealthy indicates that the IstioCNI resource is not healthy.
+	// IstioRevisionReasonIstioCNINotHealthy indicates that an IstioCNI resource the revision depends on is not healthy.
 	IstioRevisionReasonIstioCNINotHealthy IstioRevisionConditionReason = "IstioCNINotHealthy"
 
-	// IstioRevisionReasonZTunnelNotFound indicates that the ZTunnel resource is not found.
+	// IstioRevisionReasonZTunnelNotFound indicates that no ZTunnel resource exists, or that none runs on a node
+	// hosting workloads of the revision.
 	IstioRevisionReasonZTunnelNotFound IstioRevisionConditionReason = "ZTunnelNotFound"
 
-	// IstioRevisionReasonZTunnelNotHealthy indicates that the ZTunnel resource is not healthy.
+	// IstioRevisionReasonZTunnelNotHealthy indicates that a ZTunnel resource the revision depends on is not healthy.
 	IstioRevisionReasonZTunnelNotHealthy IstioRevisionConditionReason = "ZTunnelNotHealthy"
 
+	// IstioRevisionReasonVersionSkew indicates that the version of the IstioCNI or ZTunnel resource is outside the
+	// version skew supported by the revision.
+	IstioRevisionReasonVersionSkew IstioRevisionConditionReason = "VersionSkew"
+
 	// IstioRevisionDependencyCheckFailed indicates that the status of the dependencies could not be ascertained.
 	IstioRevisionDependencyCheckFailed IstioRevisionConditionReason = "DependencyCheckFailed"
 )
@@ -0,0 +1,135 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	IstioRevisionBindingKind = "IstioRevisionBinding"
+	DefaultRevisionBinding   = "default"
+)
+
+// IstioRevisionBindingSpec defines the desired state of IstioRevisionBinding
+type IstioRevisionBindingSpec struct {
+	// +kubebuilder:validation:Required
+	TargetRef TargetReference `json:"targetRef"`
+}
+
+// IstioRevisionBindingStatus defines the observed state of IstioRevisionBinding
+type IstioRevisionBindingStatus struct {
+	// ObservedGeneration is the most recent generation observed for this
+	// IstioRevisionBinding object. It corresponds to the object's generation, which is
+	// updated on mutation by the API Server. The information in the status
+	// pertains to this particular generation of the object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Represents the latest available observations of the object's current state.
+	Conditions []StatusCondition `json:"conditions,omitempty"`
+
+	// Reports the current state of the object.
+	State IstioRevisionBindingConditionReason `json:"state,omitempty"`
+
+	// IstioRevision stores the name of the IstioRevision the namespace is currently bound to.
+	// This is the value the operator has set in the namespace's istio.io/rev label.
+	IstioRevision string `json:"istioRevision,omitempty"`
+
+	// RetryCount is the number of consecutive failed reconciliations. It is reset when the
+	// object is reconciled successfully or when reconciliation fails with an error that retrying can't fix.
+	// +optional
+	RetryCount int32 `json:"retryCount,omitempty"`
+
+	// NextRetryTime is the time at which the operator retries the failed reconciliation.
+	// It is not set when no retry is scheduled.
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+}
+
+// GetCondition returns the condition of the specified type
+func (s *IstioRevisionBindingStatus) GetCondition(conditionType IstioRevisionBindingConditionType) StatusCondition {
+	if s == nil {
+		return StatusCondition{Type: conditionType, Status: metav1.ConditionUnknown}
+	}
+	return GetCondition(s.Conditions, conditionType)
+}
+
+// SetCondition sets a specific condition in the list of conditions
+func (s *IstioRevisionBindingStatus) SetCondition(condition StatusCondition) {
+	SetCondition(&s.Conditions, condition)
+}
+
+// IstioRevisionBindingConditionType is an alias for ConditionType.
+type IstioRevisionBindingConditionType = ConditionType
+
+// IstioRevisionBindingConditionReason is an alias for ConditionReason.
+type IstioRevisionBindingConditionReason = ConditionReason
+
+const (
+	// IstioRevisionBindingConditionReconciled signifies whether the controller has
+	// successfully reconciled the resources defined through the CR.
+	IstioRevisionBindingConditionReconciled IstioRevisionBindingConditionType = "Reconciled"
+
+	// IstioRevisionBindingReasonReferenceNotFound indicates that the resource referenced by the binding's TargetRef was not found
+	IstioRevisionBindingReasonReferenceNotFound IstioRevisionBindingConditionReason = "RefNotFound"
+
+	// IstioRevisionBindingReasonRevisionNotAllowed indicates that the referenced revision is not in the allow-list of
+	// the Istio resource that owns it, or that the namespace is not permitted to use the revisions of that Istio.
+	IstioRevisionBindingReasonRevisionNotAllowed IstioRevisionBindingConditionReason = "RevisionNotAllowed"
+
+	// IstioRevisionBindingReasonReconcileError indicates that the reconciliation of the resource has failed, but will be retried.
+	IstioRevisionBindingReasonReconcileError IstioRevisionBindingConditionReason = "ReconcileError"
+
+	// IstioRevisionBindingReasonSuspended indicates that reconciliation of the resource is paused by the sailoperator.io/reconcile annotation.
+	IstioRevisionBindingReasonSuspended IstioRevisionBindingConditionReason = "Suspended"
+)
+
+const (
+	// IstioRevisionBindingReasonHealthy indicates that the namespace has been successfully bound to the referenced revision.
+	IstioRevisionBindingReasonHealthy IstioRevisionBindingConditionReason = "Healthy"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=istiorevbinding,categories=istio-io
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.state",description="The current state of this object."
+// +kubebuilder:printcolumn:name="Revision",type="string",JSONPath=".status.istioRevision",description="The IstioRevision the namespace is bound to."
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="The age of the object"
+// +kubebuilder:validation:XValidation:rule="self.metadata.name == 'default'",message="metadata.name must be 'default'"
+
+// IstioRevisionBinding is the namespaced counterpart of IstioRevisionTag. It allows namespace administrators to select
+// which Istio control plane revision the workloads in their namespace use, without requiring permission to modify the
+// namespace itself. The operator sets the istio.io/rev label on the namespace, but only if the referenced revision
+// is permitted by the spec.tenancy field of the Istio resource that owns it.
+type IstioRevisionBinding struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata"`
+
+	// +optional
+	Spec IstioRevisionBindingSpec `json:"spec"`
+
+	// +optional
+	Status IstioRevisionBindingStatus `json:"status"`
+}
+
+// +kubebuilder:object:root=true
+
+// IstioRevisionBindingList contains a list of IstioRevisionBindings
+type IstioRevisionBindingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []IstioRevisionBinding `json:"items"`
+}
@@ -64,6 +64,16 @@ type IstioRevisionTagStatus struct {
 
 	// IstioRevision stores the name of the referenced IstioRevision
 	IstioRevision string `json:"istioRevision"`
+
+	// RetryCount is the number of consecutive failed reconciliations. It is reset when the
+	// object is reconciled successfully or when reconciliation fails with an error that retrying can't fix.
+	// +optional
+	RetryCount int32 `json:"retryCount,omitempty"`
+
+	// NextRetryTime is the time at which the operator retries the failed reconciliation.
+	// It is not set when no retry is scheduled.
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
 }
 
 // GetCondition returns the condition of the specified type
@@ -98,6 +108,9 @@ const (
 
 	// IstioRevisionReasonReconcileError indicates that the reconciliation of the resource has failed, but will be retried.
 	IstioRevisionTagReasonReconcileError IstioRevisionTagConditionReason = "ReconcileError"
+
+	// IstioRevisionTagReasonSuspended indicates that reconciliation of the resource is paused by the sailoperator.io/reconcile annotation.
+	IstioRevisionTagReasonSuspended IstioRevisionTagConditionReason = "Suspended"
 )
 
 const (
@@ -0,0 +1,302 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	MigrationKind = "Migration"
+)
+
+// MigrationSpec defines the desired state of Migration
+type MigrationSpec struct {
+	// The Istio control plane that the migrated namespaces use. Valid references are Istio and IstioRevision
+	// resources. Istio resources are always resolved to their current active revision. The operator enables
+	// ambient mode on the referenced resource.
+	// +kubebuilder:validation:Required
+	TargetRef TargetReference `json:"targetRef"`
+
+	// Selects the namespaces to migrate from sidecar mode to ambient mode. The namespaces are migrated one at
+	// a time, in alphabetical order.
+	// +kubebuilder:validation:Required
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+
+	// Defines the waypoint proxy deployed in each migrated namespace. If not set, no waypoint is deployed.
+	// +optional
+	Waypoint *WaypointRequirement `json:"waypoint,omitempty"`
+
+	// Names of the namespaces to roll back to sidecar mode. The operator restores the labels the namespace had
+	// before the migration, removes its waypoint and restarts its workloads, so that the sidecars are injected
+	// again. Remove the namespace from this list to migrate it again.
+	// +listType=set
+	// +optional
+	RollbackNamespaces []string `json:"rollbackNamespaces,omitempty"`
+
+	// Skips the pre-flight check. By default, the migration doesn't start while the namespaces to migrate contain
+	// resources that use features that aren't supported in ambient mode.
+	// +optional
+	SkipPreflightChecks bool `json:"skipPreflightChecks,omitempty"`
+}
+
+// MigrationStatus defines the observed state of Migration
+type MigrationStatus struct {
+	// ObservedGeneration is the most recent generation observed for this
+	// Migration object. It corresponds to the object's generation, which is
+	// updated on mutation by the API Server. The information in the status
+	// pertains to this particular generation of the object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Represents the latest available observations of the object's current state.
+	Conditions []StatusCondition `json:"conditions,omitempty"`
+
+	// Reports the current state of the object.
+	State MigrationConditionReason `json:"state,omitempty"`
+
+	// The current phase of the migration.
+	// +optional
+	Phase MigrationPhase `json:"phase,omitempty"`
+
+	// IstioRevision stores the name of the IstioRevision that the migrated namespaces use.
+	IstioRevision string `json:"istioRevision,omitempty"`
+
+	// Findings lists the resources in the namespaces that haven't been migrated yet that use features that
+	// aren't supported in ambient mode.
+	// +optional
+	Findings []MigrationFinding `json:"findings,omitempty"`
+
+	// Namespaces reports the progress of each namespace.
+	// +optional
+	Namespaces []NamespaceMigrationStatus `json:"namespaces,omitempty"`
+
+	// RetryCount is the number of consecutive failed reconciliations. It is reset when the
+	// object is reconciled successfully or when reconciliation fails with an error that retrying can't fix.
+	// +optional
+	RetryCount int32 `json:"retryCount,omitempty"`
+
+	// NextRetryTime is the time at which the operator retries the failed reconciliation.
+	// It is not set when no retry is scheduled.
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+}
+
+// MigrationPhase is the phase of a Migration. The phases are executed in the order in which they're listed.
+// +kubebuilder:validation:Enum=PreflightCheck;VerifyComponents;EnableAmbient;MigrateNamespaces;Completed
+type MigrationPhase string
+
+const (
+	// MigrationPhasePreflightCheck indicates that the namespaces contain resources that use features that aren't
+	// supported in ambient mode. The findings are listed in status.findings.
+	MigrationPhasePreflightCheck MigrationPhase = "PreflightCheck"
+
+	// MigrationPhaseVerifyComponents indicates that the migration waits for the ZTunnel and IstioCNI resources to be
+	// ready for ambient mode.
+	MigrationPhaseVerifyComponents MigrationPhase = "VerifyComponents"
+
+	// MigrationPhaseEnableAmbient indicates that the migration waits for the control plane to be ready after ambient
+	// mode was enabled.
+	MigrationPhaseEnableAmbient MigrationPhase = "EnableAmbient"
+
+	// MigrationPhaseMigrateNamespaces indicates that namespaces are being migrated or rolled back.
+	MigrationPhaseMigrateNamespaces MigrationPhase = "MigrateNamespaces"
+
+	// MigrationPhaseCompleted indicates that all selected namespaces were migrated or rolled back.
+	MigrationPhaseCompleted MigrationPhase = "Completed"
+)
+
+// NamespaceMigrationPhase is the phase of the migration of a namespace.
+// +kubebuilder:validation:Enum=Pending;RestartingWorkloads;DeployingWaypoint;Migrated;RollingBack;RolledBack
+type NamespaceMigrationPhase string
+
+const (
+	// NamespaceMigrationPending indicates that the migration of the namespace hasn't started.
+	NamespaceMigrationPending NamespaceMigrationPhase = "Pending"
+
+	// NamespaceMigrationRestartingWorkloads indicates that the namespace was labeled for ambient mode and that its
+	// workloads are being restarted to remove their sidecars.
+	NamespaceMigrationRestartingWorkloads NamespaceMigrationPhase = "RestartingWorkloads"
+
+	// NamespaceMigrationDeployingWaypoint indicates that the migration waits for the waypoint of the namespace to be
+	// deployed.
+	NamespaceMigrationDeployingWaypoint NamespaceMigrationPhase = "DeployingWaypoint"
+
+	// NamespaceMigrated indicates that the namespace was migrated to ambient mode.
+	NamespaceMigrated NamespaceMigrationPhase = "Migrated"
+
+	// NamespaceMigrationRollingBack indicates that the labels of the namespace were restored and that its workloads
+	// are being restarted to inject their sidecars.
+	NamespaceMigrationRollingBack NamespaceMigrationPhase = "RollingBack"
+
+	// NamespaceMigrationRolledBack indicates that the namespace was rolled back to sidecar mode.
+	NamespaceMigrationRolledBack NamespaceMigrationPhase = "RolledBack"
+)
+
+// NamespaceMigrationStatus reports the progress of the migration of a namespace.
+type NamespaceMigrationStatus struct {
+	// The name of the namespace.
+	Name string `json:"name"`
+
+	// The migration phase of the namespace.
+	Phase NamespaceMigrationPhase `json:"phase"`
+
+	// The last time the namespace transitioned from one phase to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// A human-readable message describing what the namespace is waiting for.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// MigrationFindingSeverity is the severity of a pre-flight finding.
+// +kubebuilder:validation:Enum=Blocking;Warning
+type MigrationFindingSeverity string
+
+const (
+	// MigrationFindingBlocking indicates that the feature isn't supported in ambient mode. The migration doesn't
+	// start until the resource is removed or spec.skipPreflightChecks is set.
+	MigrationFindingBlocking MigrationFindingSeverity = "Blocking"
+
+	// MigrationFindingWarning indicates that the resource needs to be reviewed, because it behaves differently in
+	// ambient mode. It doesn't block the migration.
+	MigrationFindingWarning MigrationFindingSeverity = "Warning"
+)
+
+// MigrationFinding describes a resource that uses a feature that isn't supported in ambient mode.
+type MigrationFinding struct {
+	// The severity of the finding.
+	Severity MigrationFindingSeverity `json:"severity"`
+
+	// The kind of the resource.
+	Kind string `json:"kind"`
+
+	// The namespace of the resource.
+	Namespace string `json:"namespace"`
+
+	// The name of the resource.
+	Name string `json:"name"`
+
+	// A human-readable message describing the finding.
+	Message string `json:"message"`
+}
+
+// GetCondition returns the condition of the specified type
+func (s *MigrationStatus) GetCondition(conditionType MigrationConditionType) StatusCondition {
+	if s == nil {
+		return StatusCondition{Type: conditionType, Status: metav1.ConditionUnknown}
+	}
+	return GetCondition(s.Conditions, conditionType)
+}
+
+// SetCondition sets a specific condition in the list of conditions
+func (s *MigrationStatus) SetCondition(condition StatusCondition) {
+	SetCondition(&s.Conditions, condition)
+}
+
+// MigrationConditionType is an alias for ConditionType.
+type MigrationConditionType = ConditionType
+
+// MigrationConditionReason is an alias for ConditionReason.
+type MigrationConditionReason = ConditionReason
+
+const (
+	// MigrationConditionReconciled signifies whether the controller has
+	// successfully reconciled the resources defined through the CR.
+	MigrationConditionReconciled MigrationConditionType = "Reconciled"
+
+	// MigrationReasonReferenceNotFound indicates that the resource referenced by the migration's TargetRef was not found
+	MigrationReasonReferenceNotFound MigrationConditionReason = "RefNotFound"
+
+	// MigrationReasonReconcileError indicates that the reconciliation of the resource has failed, but will be retried.
+	MigrationReasonReconcileError MigrationConditionReason = "ReconcileError"
+
+	// MigrationReasonSuspended indicates that reconciliation of the resource is paused by the sailoperator.io/reconcile annotation.
+	MigrationReasonSuspended MigrationConditionReason = "Suspended"
+)
+
+const (
+	// MigrationConditionPreflightChecksPassed signifies whether the namespaces to migrate are free of resources that
+	// use features that aren't supported in ambient mode.
+	MigrationConditionPreflightChecksPassed MigrationConditionType = "PreflightChecksPassed"
+
+	// MigrationReasonUnsupportedFeatures indicates that the namespaces to migrate contain resources that use features
+	// that aren't supported in ambient mode. The resources are listed in status.findings.
+	MigrationReasonUnsupportedFeatures MigrationConditionReason = "UnsupportedFeatures"
+)
+
+const (
+	// MigrationConditionComponentsReady signifies whether the ZTunnel and IstioCNI resources are ready for ambient mode.
+	MigrationConditionComponentsReady MigrationConditionType = "ComponentsReady"
+
+	// MigrationReasonComponentsNotReady indicates that a ZTunnel or IstioCNI resource doesn't exist, isn't ready, or
+	// doesn't have ambient mode enabled.
+	MigrationReasonComponentsNotReady MigrationConditionReason = "ComponentsNotReady"
+)
+
+const (
+	// MigrationConditionAmbientEnabled signifies whether ambient mode is enabled on the control plane and the control
+	// plane is ready.
+	MigrationConditionAmbientEnabled MigrationConditionType = "AmbientEnabled"
+
+	// MigrationReasonAmbientNotEnabled indicates that the control plane hasn't been updated to enable ambient mode yet.
+	MigrationReasonAmbientNotEnabled MigrationConditionReason = "AmbientNotEnabled"
+)
+
+const (
+	// MigrationConditionNamespacesMigrated signifies whether all selected namespaces were migrated or rolled back.
+	MigrationConditionNamespacesMigrated MigrationConditionType = "NamespacesMigrated"
+
+	// MigrationReasonInProgress indicates that some namespaces haven't been migrated or rolled back yet.
+	MigrationReasonInProgress MigrationConditionReason = "InProgress"
+)
+
+const (
+	// MigrationReasonCompleted indicates that all selected namespaces were migrated or rolled back.
+	MigrationReasonCompleted MigrationConditionReason = "Completed"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,categories=istio-io
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="The current phase of the migration."
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.state",description="The current state of this object."
+// +kubebuilder:printcolumn:name="Revision",type="string",JSONPath=".status.istioRevision",description="The IstioRevision the migrated namespaces use."
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="The age of the object"
+
+// Migration migrates the namespaces selected by its namespace selector from sidecar mode to ambient mode. The
+// operator verifies that the ZTunnel and IstioCNI components are ready, enables ambient mode on the referenced
+// control plane, and then migrates the namespaces one at a time: it relabels the namespace, restarts its workloads
+// to remove their sidecars and deploys its waypoint. Deleting a Migration doesn't roll back the migrated namespaces.
+type Migration struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata"`
+
+	// +optional
+	Spec MigrationSpec `json:"spec"`
+
+	// +optional
+	Status MigrationStatus `json:"status"`
+}
+
+// +kubebuilder:object:root=true
+
+// MigrationList contains a list of Migrations
+type MigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []Migration `json:"items"`
+}
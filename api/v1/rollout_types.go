@@ -0,0 +1,111 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DaemonSetRolloutType defines how the pods of a DaemonSet are replaced when the DaemonSet is updated.
+// +kubebuilder:validation:Enum=RollingUpdate;Batched
+type DaemonSetRolloutType string
+
+const (
+	// DaemonSetRolloutRollingUpdate leaves the replacement of the pods to the update strategy of the DaemonSet.
+	DaemonSetRolloutRollingUpdate DaemonSetRolloutType = "RollingUpdate"
+
+	// DaemonSetRolloutBatched sets the update strategy of the DaemonSet to OnDelete and lets the operator replace
+	// the pods in batches of nodes. The next batch is only started when the new pods and the workloads on the
+	// nodes of the previous batch are ready.
+	DaemonSetRolloutBatched DaemonSetRolloutType = "Batched"
+)
+
+// DaemonSetRolloutStrategy defines how changes are rolled out to the nodes.
+type DaemonSetRolloutStrategy struct {
+	// Defines how the pods are replaced when the DaemonSet is updated.
+	// With RollingUpdate, the update strategy of the DaemonSet replaces the pods, as configured in the Helm values.
+	// With Batched, the operator replaces the pods in batches of nodes and pauses the rollout when the pods or
+	// the workloads on a node don't become ready.
+	// +kubebuilder:default=RollingUpdate
+	// +optional
+	Type DaemonSetRolloutType `json:"type,omitempty"`
+
+	// The number of nodes whose pods are replaced at the same time when the type is Batched.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	BatchSize int32 `json:"batchSize,omitempty"`
+}
+
+// DaemonSetRolloutState is the state of a batched rollout.
+// +kubebuilder:validation:Enum=InProgress;Paused;Complete
+type DaemonSetRolloutState string
+
+const (
+	// DaemonSetRolloutInProgress indicates that pods are being replaced.
+	DaemonSetRolloutInProgress DaemonSetRolloutState = "InProgress"
+
+	// DaemonSetRolloutPaused indicates that the rollout is paused, because the pods or the workloads on some of
+	// the updated nodes didn't become ready. It resumes when they do.
+	DaemonSetRolloutPaused DaemonSetRolloutState = "Paused"
+
+	// DaemonSetRolloutComplete indicates that the pods on all nodes are up to date.
+	DaemonSetRolloutComplete DaemonSetRolloutState = "Complete"
+)
+
+// DaemonSetRolloutStatus reports the progress of a batched rollout.
+type DaemonSetRolloutStatus struct {
+	// The state of the rollout.
+	State DaemonSetRolloutState `json:"state"`
+
+	// The number of nodes that run an up-to-date pod.
+	UpdatedNodes int32 `json:"updatedNodes"`
+
+	// The number of nodes that run a pod of the DaemonSet.
+	TotalNodes int32 `json:"totalNodes"`
+
+	// The nodes of the current batch, and the nodes on which the rollout failed.
+	// +optional
+	Nodes []NodeRolloutStatus `json:"nodes,omitempty"`
+}
+
+// NodeRolloutState is the state of the rollout on a node.
+// +kubebuilder:validation:Enum=Updating;Failed
+type NodeRolloutState string
+
+const (
+	// NodeRolloutUpdating indicates that the pod on the node was replaced, and that the operator is waiting for
+	// the new pod and the workloads on the node to be ready.
+	NodeRolloutUpdating NodeRolloutState = "Updating"
+
+	// NodeRolloutFailed indicates that the new pod or the workloads on the node didn't become ready.
+	NodeRolloutFailed NodeRolloutState = "Failed"
+)
+
+// NodeRolloutStatus reports the progress of the rollout on a node.
+type NodeRolloutStatus struct {
+	// The name of the node.
+	Name string `json:"name"`
+
+	// The state of the rollout on the node.
+	State NodeRolloutState `json:"state"`
+
+	// The time at which the operator deleted the outdated pod on the node.
+	StartTime metav1.Time `json:"startTime"`
+
+	// A human-readable message indicating what the rollout is waiting for on the node.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,193 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	WaypointKind = "Waypoint"
+)
+
+// WaypointSpec defines the desired state of Waypoint
+// +kubebuilder:validation:XValidation:rule="self.scope != 'ServiceAccounts' || (has(self.serviceAccounts) && size(self.serviceAccounts) > 0)",message="spec.serviceAccounts must be set when spec.scope is ServiceAccounts"
+type WaypointSpec struct {
+	// The Istio control plane that controls the waypoint. Valid references are Istio and IstioRevision resources.
+	// Istio resources are always resolved to their current active revision, so the waypoint moves to the new
+	// revision when the active revision changes, for example during a RevisionBased update.
+	// +kubebuilder:validation:Required
+	TargetRef TargetReference `json:"targetRef"`
+
+	// The type of traffic that the waypoint handles. It is set in the istio.io/waypoint-for label of the Gateway.
+	// +kubebuilder:default=service
+	// +optional
+	TrafficType WaypointTrafficType `json:"trafficType,omitempty"`
+
+	// Defines which workloads use the waypoint. The operator sets the istio.io/use-waypoint label accordingly.
+	// +kubebuilder:default=Namespace
+	// +optional
+	Scope WaypointScope `json:"scope,omitempty"`
+
+	// The names of the service accounts whose workloads use the waypoint when spec.scope is ServiceAccounts.
+	// +listType=set
+	// +optional
+	ServiceAccounts []string `json:"serviceAccounts,omitempty"`
+
+	// The compute resources of the waypoint proxy container.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// WaypointScope defines which workloads use a waypoint.
+// +kubebuilder:validation:Enum=Namespace;ServiceAccounts;None
+type WaypointScope string
+
+const (
+	// WaypointScopeNamespace indicates that all workloads in the namespace use the waypoint. The operator sets the
+	// istio.io/use-waypoint label on the namespace.
+	WaypointScopeNamespace WaypointScope = "Namespace"
+
+	// WaypointScopeServiceAccounts indicates that the workloads running as one of the service accounts listed in
+	// spec.serviceAccounts use the waypoint. The operator sets the istio.io/use-waypoint label on their pods.
+	WaypointScopeServiceAccounts WaypointScope = "ServiceAccounts"
+
+	// WaypointScopeNone indicates that the operator only deploys the waypoint. The istio.io/use-waypoint label must be
+	// set by the user.
+	WaypointScopeNone WaypointScope = "None"
+)
+
+// WaypointStatus defines the observed state of Waypoint
+type WaypointStatus struct {
+	// ObservedGeneration is the most recent generation observed for this
+	// Waypoint object. It corresponds to the object's generation, which is
+	// updated on mutation by the API Server. The information in the status
+	// pertains to this particular generation of the object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Represents the latest available observations of the object's current state.
+	Conditions []StatusCondition `json:"conditions,omitempty"`
+
+	// Reports the current state of the object.
+	State WaypointConditionReason `json:"state,omitempty"`
+
+	// IstioRevision stores the name of the IstioRevision that controls the waypoint.
+	IstioRevision string `json:"istioRevision,omitempty"`
+
+	// RetryCount is the number of consecutive failed reconciliations. It is reset when the
+	// object is reconciled successfully or when reconciliation fails with an error that retrying can't fix.
+	// +optional
+	RetryCount int32 `json:"retryCount,omitempty"`
+
+	// NextRetryTime is the time at which the operator retries the failed reconciliation.
+	// It is not set when no retry is scheduled.
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+}
+
+// GetCondition returns the condition of the specified type
+func (s *WaypointStatus) GetCondition(conditionType WaypointConditionType) StatusCondition {
+	if s == nil {
+		return StatusCondition{Type: conditionType, Status: metav1.ConditionUnknown}
+	}
+	return GetCondition(s.Conditions, conditionType)
+}
+
+// SetCondition sets a specific condition in the list of conditions
+func (s *WaypointStatus) SetCondition(condition StatusCondition) {
+	SetCondition(&s.Conditions, condition)
+}
+
+// WaypointConditionType is an alias for ConditionType.
+type WaypointConditionType = ConditionType
+
+// WaypointConditionReason is an alias for ConditionReason.
+type WaypointConditionReason = ConditionReason
+
+const (
+	// WaypointConditionReconciled signifies whether the controller has
+	// successfully reconciled the resources defined through the CR.
+	WaypointConditionReconciled WaypointConditionType = "Reconciled"
+
+	// WaypointReasonReferenceNotFound indicates that the resource referenced by the waypoint's TargetRef was not found
+	WaypointReasonReferenceNotFound WaypointConditionReason = "RefNotFound"
+
+	// WaypointReasonNameAlreadyExists indicates that a Gateway with the same name as the Waypoint already exists and
+	// isn't managed by the Waypoint.
+	WaypointReasonNameAlreadyExists WaypointConditionReason = "NameAlreadyExists"
+
+	// WaypointReasonReconcileError indicates that the reconciliation of the resource has failed, but will be retried.
+	WaypointReasonReconcileError WaypointConditionReason = "ReconcileError"
+
+	// WaypointReasonSuspended indicates that reconciliation of the resource is paused by the sailoperator.io/reconcile annotation.
+	WaypointReasonSuspended WaypointConditionReason = "Suspended"
+)
+
+const (
+	// WaypointConditionReady signifies whether the waypoint Gateway has been programmed, which means that the
+	// waypoint proxy is deployed.
+	WaypointConditionReady WaypointConditionType = "Ready"
+
+	// WaypointReasonGatewayNotProgrammed indicates that the waypoint Gateway hasn't been programmed yet.
+	WaypointReasonGatewayNotProgrammed WaypointConditionReason = "GatewayNotProgrammed"
+)
+
+const (
+	// WaypointConditionBound signifies whether all workloads in spec.scope use the waypoint.
+	WaypointConditionBound WaypointConditionType = "Bound"
+
+	// WaypointReasonBindingConflict indicates that the istio.io/use-waypoint label of the namespace or of some pods
+	// is set to a different waypoint by someone else, so the operator left it intact.
+	WaypointReasonBindingConflict WaypointConditionReason = "BindingConflict"
+)
+
+const (
+	// WaypointReasonHealthy indicates that the waypoint is deployed and used by all workloads in spec.scope.
+	WaypointReasonHealthy WaypointConditionReason = "Healthy"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:categories=istio-io
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Scope",type="string",JSONPath=".spec.scope",description="The workloads that use the waypoint."
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether the waypoint proxy is deployed."
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.state",description="The current state of this object."
+// +kubebuilder:printcolumn:name="Revision",type="string",JSONPath=".status.istioRevision",description="The IstioRevision that controls the waypoint."
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="The age of the object"
+
+// Waypoint deploys a waypoint proxy in its namespace. The operator renders a Gateway API Gateway with the
+// istio-waypoint class, which is controlled by the referenced Istio control plane, and routes the traffic of the
+// namespace or of the selected service accounts through it.
+type Waypoint struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata"`
+
+	// +optional
+	Spec WaypointSpec `json:"spec"`
+
+	// +optional
+	Status WaypointStatus `json:"status"`
+}
+
+// +kubebuilder:object:root=true
+
+// WaypointList contains a list of Waypoints
+type WaypointList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []Waypoint `json:"items"`
+}
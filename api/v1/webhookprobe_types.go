@@ -0,0 +1,72 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WebhookProbeFailureReason classifies why the readiness probe of a webhook failed.
+// +kubebuilder:validation:Enum=InvalidConfig;TLSError;Timeout;ConnectionError;HTTPError
+type WebhookProbeFailureReason string
+
+const (
+	// WebhookProbeFailureInvalidConfig indicates that the client configuration of the webhook can't be probed,
+	// e.g. because its caBundle hasn't been set.
+	WebhookProbeFailureInvalidConfig WebhookProbeFailureReason = "InvalidConfig"
+
+	// WebhookProbeFailureTLSError indicates that the TLS handshake with the remote control plane failed,
+	// e.g. because its certificate isn't signed by the caBundle of the webhook.
+	WebhookProbeFailureTLSError WebhookProbeFailureReason = "TLSError"
+
+	// WebhookProbeFailureTimeout indicates that the remote control plane didn't respond within the probe timeout.
+	WebhookProbeFailureTimeout WebhookProbeFailureReason = "Timeout"
+
+	// WebhookProbeFailureConnectionError indicates that the operator couldn't connect to the remote control plane.
+	WebhookProbeFailureConnectionError WebhookProbeFailureReason = "ConnectionError"
+
+	// WebhookProbeFailureHTTPError indicates that the remote control plane responded with an unsuccessful HTTP status.
+	WebhookProbeFailureHTTPError WebhookProbeFailureReason = "HTTPError"
+)
+
+// WebhookProbeStatus reports the result of the readiness probe of a webhook that points to a remote
+// control plane.
+type WebhookProbeStatus struct {
+	// The name of the webhook in the MutatingWebhookConfiguration.
+	Name string `json:"name"`
+
+	// The URL that was probed.
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// Whether the remote control plane responded successfully.
+	Ready bool `json:"ready"`
+
+	// Classifies why the probe failed.
+	// +optional
+	FailureReason WebhookProbeFailureReason `json:"failureReason,omitempty"`
+
+	// The error returned by the last probe.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// The time it took the remote control plane to respond.
+	// +optional
+	Latency *metav1.Duration `json:"latency,omitempty"`
+
+	// The expiry time of the first CA certificate in the caBundle of the webhook to expire.
+	// +optional
+	CAExpiry *metav1.Time `json:"caExpiry,omitempty"`
+}
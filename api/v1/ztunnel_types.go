@@ -33,10 +33,29 @@ type ZTunnelSpec struct {
 	Version string `json:"version"`
 
 	// Namespace to which the Istio ztunnel component should be installed.
+	// Each ZTunnel instance must be installed in a different namespace.
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:io.kubernetes:Namespace"}
 	// +kubebuilder:default=ztunnel
 	Namespace string `json:"namespace"`
 
+	// Restricts the Istio ztunnel component to the nodes whose labels match this selector, so that different node pools
+	// can run different ZTunnel instances. The selectors of two instances must not match the same node.
+	// Only the instance named 'default' may omit the selector, in which case it runs on all nodes.
+	// +optional
+	// +kubebuilder:validation:MinProperties=1
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Defines how changes to the Istio ztunnel DaemonSet are rolled out to the nodes. By default, the update strategy of
+	// the DaemonSet replaces the pods on all nodes. With the Batched type, the operator replaces them in batches
+	// of nodes and waits for the workloads on each batch to be ready before continuing.
+	// +optional
+	Rollout *DaemonSetRolloutStrategy `json:"rollout,omitempty"`
+
+	// Defines the compliance policies, such as FIPS mode, that the operator applies to the Istio ztunnel component.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Compliance"
+	Compliance *Compliance `json:"compliance,omitempty"`
+
 	// Defines the values to be passed to the Helm charts when installing Istio ztunnel.
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Helm Values"
 	Values *ZTunnelValues `json:"values,omitempty"`
@@ -62,6 +81,39 @@ type ZTunnelStatus struct {
 
 	// IstioRevision stores the name of the referenced IstioRevision
 	IstioRevision string `json:"istioRevision,omitempty"`
+
+	// Platform is the Kubernetes platform detected by the operator, e.g. openshift, gke or k3s.
+	// It determines the default value of values.global.platform.
+	Platform string `json:"platform,omitempty"`
+
+	// RetryCount is the number of consecutive failed reconciliations. It is reset when the
+	// object is reconciled successfully or when reconciliation fails with an error that retrying can't fix.
+	// +optional
+	RetryCount int32 `json:"retryCount,omitempty"`
+
+	// NextRetryTime is the time at which the operator retries the failed reconciliation.
+	// It is not set when no retry is scheduled.
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+
+	// Drift lists the resources that were modified outside of the operator while reconciliation was paused.
+	// It is recorded when reconciliation is resumed and cleared when it is paused again.
+	// +optional
+	Drift *DriftSummary `json:"drift,omitempty"`
+
+	// Rollout reports the progress of the batched rollout of the Istio ztunnel DaemonSet.
+	// It is only set when spec.rollout.type is Batched.
+	// +optional
+	Rollout *DaemonSetRolloutStatus `json:"rollout,omitempty"`
+
+	// Reports the compliance policies that are in effect for the Istio ztunnel component.
+	// +optional
+	Compliance *ComplianceStatus `json:"compliance,omitempty"`
+
+	// Version is the version of the Istio ztunnel component that was last installed successfully. When the version skew is enforced,
+	// only a change of spec.version to an unsupported version is rejected.
+	// +optional
+	Version string `json:"version,omitempty"`
 }
 
 // GetCondition returns the condition of the specified type
@@ -90,6 +142,9 @@ const (
 
 	// ZTunnelReasonReconcileError indicates that the reconciliation of the resource has failed, but will be retried.
 	ZTunnelReasonReconcileError ZTunnelConditionReason = "ReconcileError"
+
+	// ZTunnelReasonSuspended indicates that reconciliation of the resource is paused by the sailoperator.io/reconcile annotation.
+	ZTunnelReasonSuspended ZTunnelConditionReason = "Suspended"
 )
 
 const (
@@ -103,6 +158,21 @@ const (
 	ZTunnelReasonReadinessCheckFailed ZTunnelConditionReason = "ReadinessCheckFailed"
 )
 
+const (
+	// ZTunnelConditionVersionSkew signifies whether the version of the ZTunnel is outside the version skew supported
+	// by any of the IstioRevisions that depend on it. Unlike the other conditions, True indicates a problem.
+	ZTunnelConditionVersionSkew ZTunnelConditionType = "VersionSkew"
+
+	// ZTunnelReasonUnsupportedVersionSkew indicates that the ZTunnel version isn't supported by at least one IstioRevision.
+	ZTunnelReasonUnsupportedVersionSkew ZTunnelConditionReason = "UnsupportedVersionSkew"
+
+	// ZTunnelReasonSupportedVersionSkew indicates that the ZTunnel version is supported by all IstioRevisions that depend on it.
+	ZTunnelReasonSupportedVersionSkew ZTunnelConditionReason = "SupportedVersionSkew"
+
+	// ZTunnelReasonVersionSkewCheckFailed indicates that the version skew could not be ascertained.
+	ZTunnelReasonVersionSkewCheckFailed ZTunnelConditionReason = "VersionSkewCheckFailed"
+)
+
 const (
 	// ZTunnelReasonHealthy indicates that the control plane is fully reconciled and that all components are ready.
 	ZTunnelReasonHealthy ZTunnelConditionReason = "Healthy"
@@ -118,7 +188,7 @@ const (
 // +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".spec.version",description="The version of the Istio ztunnel installation."
 // +kubebuilder:printcolumn:name="Revision",type="string",JSONPath=".status.istioRevision",description="The referenced IstioRevision."
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="The age of the object"
-// +kubebuilder:validation:XValidation:rule="self.metadata.name == 'default'",message="metadata.name must be 'default'"
+// +kubebuilder:validation:XValidation:rule="self.metadata.name == 'default' || has(self.spec.nodeSelector)",message="metadata.name must be 'default' unless spec.nodeSelector is set"
 
 // ZTunnel represents a deployment of the Istio ztunnel component.
 type ZTunnel struct {
@@ -48,6 +48,118 @@ func (in *Agentgateway) DeepCopy() *Agentgateway {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AmbientEnrollment) DeepCopyInto(out *AmbientEnrollment) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AmbientEnrollment.
+func (in *AmbientEnrollment) DeepCopy() *AmbientEnrollment {
+	if in == nil {
+		return nil
+	}
+	out := new(AmbientEnrollment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AmbientEnrollment) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AmbientEnrollmentList) DeepCopyInto(out *AmbientEnrollmentList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AmbientEnrollment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AmbientEnrollmentList.
+func (in *AmbientEnrollmentList) DeepCopy() *AmbientEnrollmentList {
+	if in == nil {
+		return nil
+	}
+	out := new(AmbientEnrollmentList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AmbientEnrollmentList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AmbientEnrollmentSpec) DeepCopyInto(out *AmbientEnrollmentSpec) {
+	*out = *in
+	out.TargetRef = in.TargetRef
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+	if in.Waypoint != nil {
+		in, out := &in.Waypoint, &out.Waypoint
+		*out = new(WaypointRequirement)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AmbientEnrollmentSpec.
+func (in *AmbientEnrollmentSpec) DeepCopy() *AmbientEnrollmentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AmbientEnrollmentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AmbientEnrollmentStatus) DeepCopyInto(out *AmbientEnrollmentStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]StatusCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]NamespaceEnrollmentStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AmbientEnrollmentStatus.
+func (in *AmbientEnrollmentStatus) DeepCopy() *AmbientEnrollmentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AmbientEnrollmentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArchConfig) DeepCopyInto(out *ArchConfig) {
 	*out = *in
@@ -628,6 +740,36 @@ func (in *ClientTLSSettings) DeepCopy() *ClientTLSSettings {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Compliance) DeepCopyInto(out *Compliance) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Compliance.
+func (in *Compliance) DeepCopy() *Compliance {
+	if in == nil {
+		return nil
+	}
+	out := new(Compliance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceStatus) DeepCopyInto(out *ComplianceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceStatus.
+func (in *ComplianceStatus) DeepCopy() *ComplianceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigSource) DeepCopyInto(out *ConfigSource) {
 	*out = *in
@@ -833,6 +975,68 @@ func (in *ConnectionPoolSettingsTCPSettingsTcpKeepalive) DeepCopy() *ConnectionP
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomTLSProfile) DeepCopyInto(out *CustomTLSProfile) {
+	*out = *in
+	if in.Ciphers != nil {
+		in, out := &in.Ciphers, &out.Ciphers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Curves != nil {
+		in, out := &in.Curves, &out.Curves
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomTLSProfile.
+func (in *CustomTLSProfile) DeepCopy() *CustomTLSProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomTLSProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonSetRolloutStatus) DeepCopyInto(out *DaemonSetRolloutStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]NodeRolloutStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonSetRolloutStatus.
+func (in *DaemonSetRolloutStatus) DeepCopy() *DaemonSetRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonSetRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonSetRolloutStrategy) DeepCopyInto(out *DaemonSetRolloutStrategy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonSetRolloutStrategy.
+func (in *DaemonSetRolloutStrategy) DeepCopy() *DaemonSetRolloutStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonSetRolloutStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DefaultPodDisruptionBudgetConfig) DeepCopyInto(out *DefaultPodDisruptionBudgetConfig) {
 	*out = *in
@@ -853,6 +1057,27 @@ func (in *DefaultPodDisruptionBudgetConfig) DeepCopy() *DefaultPodDisruptionBudg
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftSummary) DeepCopyInto(out *DriftSummary) {
+	*out = *in
+	in.DetectedAt.DeepCopyInto(&out.DetectedAt)
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftSummary.
+func (in *DriftSummary) DeepCopy() *DriftSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExperimentalConfig) DeepCopyInto(out *ExperimentalConfig) {
 	*out = *in
@@ -1288,6 +1513,18 @@ func (in *IstioCNIList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IstioCNISpec) DeepCopyInto(out *IstioCNISpec) {
 	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(DaemonSetRolloutStrategy)
+		**out = **in
+	}
 	if in.Values != nil {
 		in, out := &in.Values, &out.Values
 		*out = new(CNIValues)
@@ -1315,6 +1552,20 @@ func (in *IstioCNIStatus) DeepCopyInto(out *IstioCNIStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = new(DriftSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(DaemonSetRolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioCNIStatus.
@@ -1327,6 +1578,41 @@ func (in *IstioCNIStatus) DeepCopy() *IstioCNIStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioGateway) DeepCopyInto(out *IstioGateway) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make(json.RawMessage, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioGateway.
+func (in *IstioGateway) DeepCopy() *IstioGateway {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioGateway)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioGatewayStatus) DeepCopyInto(out *IstioGatewayStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioGatewayStatus.
+func (in *IstioGatewayStatus) DeepCopy() *IstioGatewayStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioGatewayStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IstioList) DeepCopyInto(out *IstioList) {
 	*out = *in
@@ -1386,6 +1672,107 @@ func (in *IstioRevision) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioRevisionBinding) DeepCopyInto(out *IstioRevisionBinding) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioRevisionBinding.
+func (in *IstioRevisionBinding) DeepCopy() *IstioRevisionBinding {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioRevisionBinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IstioRevisionBinding) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioRevisionBindingList) DeepCopyInto(out *IstioRevisionBindingList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IstioRevisionBinding, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioRevisionBindingList.
+func (in *IstioRevisionBindingList) DeepCopy() *IstioRevisionBindingList {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioRevisionBindingList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IstioRevisionBindingList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioRevisionBindingSpec) DeepCopyInto(out *IstioRevisionBindingSpec) {
+	*out = *in
+	out.TargetRef = in.TargetRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioRevisionBindingSpec.
+func (in *IstioRevisionBindingSpec) DeepCopy() *IstioRevisionBindingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioRevisionBindingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioRevisionBindingStatus) DeepCopyInto(out *IstioRevisionBindingStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]StatusCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioRevisionBindingStatus.
+func (in *IstioRevisionBindingStatus) DeepCopy() *IstioRevisionBindingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioRevisionBindingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IstioRevisionList) DeepCopyInto(out *IstioRevisionList) {
 	*out = *in
@@ -1448,6 +1835,27 @@ func (in *IstioRevisionStatus) DeepCopyInto(out *IstioRevisionStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = new(DriftSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RemoteProbes != nil {
+		in, out := &in.RemoteProbes, &out.RemoteProbes
+		*out = make([]WebhookProbeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TLSProfile != nil {
+		in, out := &in.TLSProfile, &out.TLSProfile
+		*out = new(TLSProfileStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioRevisionStatus.
@@ -1545,6 +1953,10 @@ func (in *IstioRevisionTagStatus) DeepCopyInto(out *IstioRevisionTagStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioRevisionTagStatus.
@@ -1570,6 +1982,28 @@ func (in *IstioSpec) DeepCopyInto(out *IstioSpec) {
 		*out = new(Values)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Tenancy != nil {
+		in, out := &in.Tenancy, &out.Tenancy
+		*out = new(IstioTenancy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Gateways != nil {
+		in, out := &in.Gateways, &out.Gateways
+		*out = make([]IstioGateway, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TLSProfile != nil {
+		in, out := &in.TLSProfile, &out.TLSProfile
+		*out = new(TLSProfile)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Compliance != nil {
+		in, out := &in.Compliance, &out.Compliance
+		*out = new(Compliance)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioSpec.
@@ -1593,6 +2027,32 @@ func (in *IstioStatus) DeepCopyInto(out *IstioStatus) {
 		}
 	}
 	out.Revisions = in.Revisions
+	if in.Gateways != nil {
+		in, out := &in.Gateways, &out.Gateways
+		*out = make([]IstioGatewayStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.RevisionPruning != nil {
+		in, out := &in.RevisionPruning, &out.RevisionPruning
+		*out = make([]RevisionPruningStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TLSProfile != nil {
+		in, out := &in.TLSProfile, &out.TLSProfile
+		*out = new(TLSProfileStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Compliance != nil {
+		in, out := &in.Compliance, &out.Compliance
+		*out = new(ComplianceStatus)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioStatus.
@@ -1605,6 +2065,31 @@ func (in *IstioStatus) DeepCopy() *IstioStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioTenancy) DeepCopyInto(out *IstioTenancy) {
+	*out = *in
+	if in.AllowedRevisions != nil {
+		in, out := &in.AllowedRevisions, &out.AllowedRevisions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioTenancy.
+func (in *IstioTenancy) DeepCopy() *IstioTenancy {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioTenancy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IstioUpdateStrategy) DeepCopyInto(out *IstioUpdateStrategy) {
 	*out = *in
@@ -1613,6 +2098,11 @@ func (in *IstioUpdateStrategy) DeepCopyInto(out *IstioUpdateStrategy) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.PruningPolicy != nil {
+		in, out := &in.PruningPolicy, &out.PruningPolicy
+		*out = new(RevisionPruningPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioUpdateStrategy.
@@ -3569,50 +4059,189 @@ func (in *MeshConfigTLSConfig) DeepCopyInto(out *MeshConfigTLSConfig) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.CipherSuites != nil {
-		in, out := &in.CipherSuites, &out.CipherSuites
+	if in.CipherSuites != nil {
+		in, out := &in.CipherSuites, &out.CipherSuites
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshConfigTLSConfig.
+func (in *MeshConfigTLSConfig) DeepCopy() *MeshConfigTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshConfigTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshNetworks) DeepCopyInto(out *MeshNetworks) {
+	*out = *in
+	if in.Networks != nil {
+		in, out := &in.Networks, &out.Networks
+		*out = make(map[string]*Network, len(*in))
+		for key, val := range *in {
+			var outVal *Network
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = new(Network)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshNetworks.
+func (in *MeshNetworks) DeepCopy() *MeshNetworks {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshNetworks)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Migration) DeepCopyInto(out *Migration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Migration.
+func (in *Migration) DeepCopy() *Migration {
+	if in == nil {
+		return nil
+	}
+	out := new(Migration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Migration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationFinding) DeepCopyInto(out *MigrationFinding) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationFinding.
+func (in *MigrationFinding) DeepCopy() *MigrationFinding {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationFinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationList) DeepCopyInto(out *MigrationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Migration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationList.
+func (in *MigrationList) DeepCopy() *MigrationList {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigrationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationSpec) DeepCopyInto(out *MigrationSpec) {
+	*out = *in
+	out.TargetRef = in.TargetRef
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+	if in.Waypoint != nil {
+		in, out := &in.Waypoint, &out.Waypoint
+		*out = new(WaypointRequirement)
+		**out = **in
+	}
+	if in.RollbackNamespaces != nil {
+		in, out := &in.RollbackNamespaces, &out.RollbackNamespaces
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshConfigTLSConfig.
-func (in *MeshConfigTLSConfig) DeepCopy() *MeshConfigTLSConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationSpec.
+func (in *MigrationSpec) DeepCopy() *MigrationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MeshConfigTLSConfig)
+	out := new(MigrationSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MeshNetworks) DeepCopyInto(out *MeshNetworks) {
+func (in *MigrationStatus) DeepCopyInto(out *MigrationStatus) {
 	*out = *in
-	if in.Networks != nil {
-		in, out := &in.Networks, &out.Networks
-		*out = make(map[string]*Network, len(*in))
-		for key, val := range *in {
-			var outVal *Network
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				inVal := (*in)[key]
-				in, out := &inVal, &outVal
-				*out = new(Network)
-				(*in).DeepCopyInto(*out)
-			}
-			(*out)[key] = outVal
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]StatusCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Findings != nil {
+		in, out := &in.Findings, &out.Findings
+		*out = make([]MigrationFinding, len(*in))
+		copy(*out, *in)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]NamespaceMigrationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshNetworks.
-func (in *MeshNetworks) DeepCopy() *MeshNetworks {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationStatus.
+func (in *MigrationStatus) DeepCopy() *MigrationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(MeshNetworks)
+	out := new(MigrationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -3652,6 +4281,37 @@ func (in *MultiClusterConfig) DeepCopy() *MultiClusterConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceEnrollmentStatus) DeepCopyInto(out *NamespaceEnrollmentStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceEnrollmentStatus.
+func (in *NamespaceEnrollmentStatus) DeepCopy() *NamespaceEnrollmentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceEnrollmentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceMigrationStatus) DeepCopyInto(out *NamespaceMigrationStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceMigrationStatus.
+func (in *NamespaceMigrationStatus) DeepCopy() *NamespaceMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Network) DeepCopyInto(out *Network) {
 	*out = *in
@@ -3769,6 +4429,22 @@ func (in *NetworkPolicyConfig) DeepCopy() *NetworkPolicyConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeRolloutStatus) DeepCopyInto(out *NodeRolloutStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeRolloutStatus.
+func (in *NodeRolloutStatus) DeepCopy() *NodeRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OutboundTrafficPolicyConfig) DeepCopyInto(out *OutboundTrafficPolicyConfig) {
 	*out = *in
@@ -4973,6 +5649,45 @@ func (in *ResourcesRequestsConfig) DeepCopy() *ResourcesRequestsConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RevisionPruningPolicy) DeepCopyInto(out *RevisionPruningPolicy) {
+	*out = *in
+	if in.RetainedRevisions != nil {
+		in, out := &in.RetainedRevisions, &out.RetainedRevisions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RevisionPruningPolicy.
+func (in *RevisionPruningPolicy) DeepCopy() *RevisionPruningPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RevisionPruningPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RevisionPruningStatus) DeepCopyInto(out *RevisionPruningStatus) {
+	*out = *in
+	if in.PruneTime != nil {
+		in, out := &in.PruneTime, &out.PruneTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RevisionPruningStatus.
+func (in *RevisionPruningStatus) DeepCopy() *RevisionPruningStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RevisionPruningStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RevisionSummary) DeepCopyInto(out *RevisionSummary) {
 	*out = *in
@@ -5284,6 +5999,51 @@ func (in *StatusCondition) DeepCopy() *StatusCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSProfile) DeepCopyInto(out *TLSProfile) {
+	*out = *in
+	if in.Custom != nil {
+		in, out := &in.Custom, &out.Custom
+		*out = new(CustomTLSProfile)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSProfile.
+func (in *TLSProfile) DeepCopy() *TLSProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSProfileStatus) DeepCopyInto(out *TLSProfileStatus) {
+	*out = *in
+	if in.Ciphers != nil {
+		in, out := &in.Ciphers, &out.Ciphers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Curves != nil {
+		in, out := &in.Curves, &out.Curves
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSProfileStatus.
+func (in *TLSProfileStatus) DeepCopy() *TLSProfileStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSProfileStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TargetReference) DeepCopyInto(out *TargetReference) {
 	*out = *in
@@ -5970,6 +6730,33 @@ func (in *Values) DeepCopy() *Values {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Waypoint) DeepCopyInto(out *Waypoint) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Waypoint.
+func (in *Waypoint) DeepCopy() *Waypoint {
+	if in == nil {
+		return nil
+	}
+	out := new(Waypoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Waypoint) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WaypointConfig) DeepCopyInto(out *WaypointConfig) {
 	*out = *in
@@ -6022,6 +6809,129 @@ func (in *WaypointConfig) DeepCopy() *WaypointConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaypointList) DeepCopyInto(out *WaypointList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Waypoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaypointList.
+func (in *WaypointList) DeepCopy() *WaypointList {
+	if in == nil {
+		return nil
+	}
+	out := new(WaypointList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WaypointList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaypointRequirement) DeepCopyInto(out *WaypointRequirement) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaypointRequirement.
+func (in *WaypointRequirement) DeepCopy() *WaypointRequirement {
+	if in == nil {
+		return nil
+	}
+	out := new(WaypointRequirement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaypointSpec) DeepCopyInto(out *WaypointSpec) {
+	*out = *in
+	out.TargetRef = in.TargetRef
+	if in.ServiceAccounts != nil {
+		in, out := &in.ServiceAccounts, &out.ServiceAccounts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaypointSpec.
+func (in *WaypointSpec) DeepCopy() *WaypointSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaypointSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaypointStatus) DeepCopyInto(out *WaypointStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]StatusCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaypointStatus.
+func (in *WaypointStatus) DeepCopy() *WaypointStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WaypointStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookProbeStatus) DeepCopyInto(out *WebhookProbeStatus) {
+	*out = *in
+	if in.Latency != nil {
+		in, out := &in.Latency, &out.Latency
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.CAExpiry != nil {
+		in, out := &in.CAExpiry, &out.CAExpiry
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookProbeStatus.
+func (in *WebhookProbeStatus) DeepCopy() *WebhookProbeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookProbeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadSelector) DeepCopyInto(out *WorkloadSelector) {
 	*out = *in
@@ -6364,6 +7274,23 @@ func (in *ZTunnelList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ZTunnelSpec) DeepCopyInto(out *ZTunnelSpec) {
 	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(DaemonSetRolloutStrategy)
+		**out = **in
+	}
+	if in.Compliance != nil {
+		in, out := &in.Compliance, &out.Compliance
+		*out = new(Compliance)
+		**out = **in
+	}
 	if in.Values != nil {
 		in, out := &in.Values, &out.Values
 		*out = new(ZTunnelValues)
@@ -6396,6 +7323,25 @@ func (in *ZTunnelStatus) DeepCopyInto(out *ZTunnelStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = new(DriftSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(DaemonSetRolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Compliance != nil {
+		in, out := &in.Compliance, &out.Compliance
+		*out = new(ComplianceStatus)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZTunnelStatus.
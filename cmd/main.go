@@ -22,21 +22,30 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/istio-ecosystem/sail-operator/chart"
+	"github.com/istio-ecosystem/sail-operator/controllers/ambientenrollment"
 	"github.com/istio-ecosystem/sail-operator/controllers/istio"
 	"github.com/istio-ecosystem/sail-operator/controllers/istiocni"
+	"github.com/istio-ecosystem/sail-operator/controllers/istiocrds"
 	"github.com/istio-ecosystem/sail-operator/controllers/istiorevision"
+	"github.com/istio-ecosystem/sail-operator/controllers/istiorevisionbinding"
 	"github.com/istio-ecosystem/sail-operator/controllers/istiorevisiontag"
+	"github.com/istio-ecosystem/sail-operator/controllers/migration"
+	"github.com/istio-ecosystem/sail-operator/controllers/waypoint"
 	"github.com/istio-ecosystem/sail-operator/controllers/webhook"
 	"github.com/istio-ecosystem/sail-operator/controllers/ztunnel"
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
 	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
 	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"github.com/istio-ecosystem/sail-operator/pkg/install"
+	sharedreconcile "github.com/istio-ecosystem/sail-operator/pkg/reconcile"
 	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
 	"github.com/istio-ecosystem/sail-operator/pkg/version"
 	"github.com/istio-ecosystem/sail-operator/resources"
 	configv1 "github.com/openshift/api/config/v1"
 	openshifttls "github.com/openshift/controller-runtime-common/pkg/tls"
 	openshiftcrypto "github.com/openshift/library-go/pkg/crypto"
+	corev1 "k8s.io/api/core/v1"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -57,6 +66,7 @@ func main() {
 	var logAPIRequests bool
 	var printVersion bool
 	var leaderElectionEnabled bool
+	var controllerBackoff string
 	var reconcilerCfg config.ReconcilerConfig
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8443", "The address the metric endpoint binds to.")
@@ -69,6 +79,17 @@ func main() {
 	flag.BoolVar(&printVersion, "version", printVersion, "Prints version information and exits")
 	flag.BoolVar(&leaderElectionEnabled, "leader-elect", true,
 		"Enable leader election for this operator. Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&reconcilerCfg.ManageIstioCRDs, "manage-istio-crds", false,
+		"Whether the operator installs and updates the Istio CRDs to match the highest Istio version, and migrates their stored versions.")
+	flag.BoolVar(&reconcilerCfg.StrictVersionSkew, "strict-version-skew", false,
+		"Whether the operator refuses to install or upgrade an IstioRevision, IstioCNI or ZTunnel whose version is outside the supported version skew.")
+
+	flag.DurationVar(&reconcilerCfg.Backoff.InitialDelay, "reconcile-backoff-initial-delay", config.DefaultBackoffPolicy.InitialDelay,
+		"How long controllers wait before retrying a failed reconciliation for the first time. The delay doubles with each consecutive failure.")
+	flag.DurationVar(&reconcilerCfg.Backoff.MaxDelay, "reconcile-backoff-max-delay", config.DefaultBackoffPolicy.MaxDelay,
+		"The maximum delay between retries of a failed reconciliation.")
+	flag.StringVar(&controllerBackoff, "controller-backoff", "",
+		"Per-controller backoff policies that override the reconcile-backoff flags, e.g. istiocni=10s/10m,ztunnel=1s/1m.")
 
 	flag.BoolVar(&enqueuelogger.LogEnqueueEvents, "log-enqueue-events", false, "Whether to log events that cause an object to be enqueued for reconciliation")
 
@@ -92,6 +113,13 @@ func main() {
 		setupLog.Info("using embedded resources")
 		reconcilerCfg.ResourceFS = resources.FS
 	}
+	var err error
+	reconcilerCfg.ControllerBackoff, err = config.ParseBackoffPolicies(controllerBackoff)
+	if err != nil {
+		setupLog.Error(err, "invalid value of the controller-backoff flag")
+		os.Exit(1)
+	}
+
 	reconcilerCfg.OperatorNamespace = os.Getenv("POD_NAMESPACE")
 	if reconcilerCfg.OperatorNamespace == "" {
 		contents, err := os.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace")
@@ -104,11 +132,20 @@ func main() {
 
 	setupLog.Info(version.Info.String())
 	setupLog.Info("reading config")
-	err := config.Read(configFile)
+	err = config.Read(configFile)
 	if err != nil {
 		setupLog.Error(err, "unable to read config file at "+configFile)
 		os.Exit(1)
 	}
+	err = config.Config.ValidateRegistryMirrors()
+	if err != nil {
+		setupLog.Error(err, "invalid registry mirrors in config file at "+configFile)
+		os.Exit(1)
+	}
+	err = config.Config.CheckRegistryMirrorSources(install.OperandImages())
+	if err != nil {
+		setupLog.Info("registry mirrors in config file at "+configFile+" may be misconfigured", "warning", err.Error())
+	}
 	setupLog.Info("config loaded", "config", config.Config)
 
 	cfg := ctrl.GetConfigOrDie()
@@ -147,7 +184,7 @@ func main() {
 		disableHTTP2,
 	}
 
-	ctx, shutdown := context.WithCancel(ctrl.SetupSignalHandler())
+	ctx := ctrl.SetupSignalHandler()
 
 	if reconcilerCfg.Platform == config.PlatformOpenShift {
 		// Create a temporary client to fetch the initial TLS settings.
@@ -158,16 +195,19 @@ func main() {
 			os.Exit(1)
 		}
 
-		reconcilerCfg.TLSConfig, err = config.FetchTLSConfigForOpenShift(ctx, setupLog, cl)
+		tlsConfig, err := config.FetchTLSConfigForOpenShift(ctx, setupLog, cl)
 		if err != nil {
 			setupLog.Error(err, "unable to fetch TLS config")
 			os.Exit(1)
 		}
+		reconcilerCfg.TLSConfig = config.NewTLSConfigSource(tlsConfig)
 
-		if reconcilerCfg.TLSConfig.OpenShift != nil && reconcilerCfg.TLSConfig.OpenShift.TLSConfigFunc != nil {
-			setupLog.Info("Using TLS config from APIServer", "tlsProfileSpec", reconcilerCfg.TLSConfig.OpenShift.TLSProfileSpec)
-			metricsServerTLSOptions = append(metricsServerTLSOptions, reconcilerCfg.TLSConfig.OpenShift.TLSConfigFunc)
+		if tlsConfig.OpenShift != nil && tlsConfig.OpenShift.TLSConfigFunc != nil {
+			setupLog.Info("Using TLS config from APIServer", "tlsProfileSpec", tlsConfig.OpenShift.TLSProfileSpec)
 		}
+		// the metrics server always uses the TLS profile that is currently in effect, so that it doesn't need
+		// to be restarted when the profile changes
+		metricsServerTLSOptions = append(metricsServerTLSOptions, reconcilerCfg.TLSConfig.ApplyToServer)
 	}
 
 	metricsServerOptions := metricsserver.Options{
@@ -184,6 +224,13 @@ func main() {
 		LeaderElection:          leaderElectionEnabled,
 		LeaderElectionID:        "sail-operator-lock",
 		LeaderElectionNamespace: reconcilerCfg.OperatorNamespace,
+		Client: client.Options{
+			Cache: &client.CacheOptions{
+				// Secrets are only read occasionally (e.g. the kubeconfig for the remote cluster of an external
+				// control plane), so they aren't cached to avoid watching all Secrets in the cluster
+				DisableFor: []client.Object{&corev1.Secret{}},
+			},
+		},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -201,9 +248,15 @@ func main() {
 		os.Exit(1)
 	}
 
+	// the batched rollouts of the istio-cni and ztunnel DaemonSets list the workload pods on each node
+	if err := sharedreconcile.IndexPodsByNode(ctx, mgr.GetFieldIndexer()); err != nil {
+		setupLog.Error(err, "unable to register the pod index")
+		os.Exit(1)
+	}
+
 	chartManager := helm.NewChartManager(mgr.GetConfig(), os.Getenv("HELM_DRIVER"))
 
-	err = istio.NewReconciler(reconcilerCfg, mgr.GetClient(), mgr.GetScheme()).
+	err = istio.NewReconciler(reconcilerCfg, mgr.GetClient(), mgr.GetScheme(), chartManager).
 		SetupWithManager(mgr)
 	if err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Istio")
@@ -224,6 +277,34 @@ func main() {
 		os.Exit(1)
 	}
 
+	err = istiorevisionbinding.NewReconciler(reconcilerCfg, mgr.GetClient(), mgr.GetScheme()).
+		SetupWithManager(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "IstioRevisionBinding")
+		os.Exit(1)
+	}
+
+	err = ambientenrollment.NewReconciler(reconcilerCfg, mgr.GetClient(), mgr.GetScheme()).
+		SetupWithManager(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "AmbientEnrollment")
+		os.Exit(1)
+	}
+
+	err = migration.NewReconciler(reconcilerCfg, mgr.GetClient(), mgr.GetScheme()).
+		SetupWithManager(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Migration")
+		os.Exit(1)
+	}
+
+	err = waypoint.NewReconciler(reconcilerCfg, mgr.GetClient(), mgr.GetScheme()).
+		SetupWithManager(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Waypoint")
+		os.Exit(1)
+	}
+
 	err = istiocni.NewReconciler(reconcilerCfg, mgr.GetClient(), mgr.GetScheme(), chartManager).
 		SetupWithManager(mgr)
 	if err != nil {
@@ -245,27 +326,43 @@ func main() {
 		os.Exit(1)
 	}
 
-	if reconcilerCfg.TLSConfig != nil && reconcilerCfg.TLSConfig.OpenShift != nil {
+	if reconcilerCfg.ManageIstioCRDs {
+		err = istiocrds.NewReconciler(reconcilerCfg, mgr.GetClient(), mgr.GetScheme(), chart.CRDsFS).
+			SetupWithManager(mgr)
+		if err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "IstioCRDs")
+			os.Exit(1)
+		}
+	}
+
+	if tlsConfig := reconcilerCfg.TLSConfig.Get(); tlsConfig != nil && tlsConfig.OpenShift != nil {
+		// reloadTLSConfig recomputes the TLS config, which causes all Istio and IstioRevision objects to be
+		// reconciled, so that the running control planes pick up the new settings without restarting the operator
+		reloadTLSConfig := func(profileSpec configv1.TLSProfileSpec, adherencePolicy configv1.TLSAdherencePolicy) {
+			reconcilerCfg.TLSConfig.Set(config.NewTLSConfigForOpenShift(profileSpec, adherencePolicy, setupLog))
+		}
 		tlsWatcher := &openshifttls.SecurityProfileWatcher{
 			Client:                    mgr.GetClient(),
-			InitialTLSProfileSpec:     reconcilerCfg.TLSConfig.OpenShift.TLSProfileSpec,
-			InitialTLSAdherencePolicy: reconcilerCfg.TLSConfig.OpenShift.TLSAdherencePolicy,
+			InitialTLSProfileSpec:     tlsConfig.OpenShift.TLSProfileSpec,
+			InitialTLSAdherencePolicy: tlsConfig.OpenShift.TLSAdherencePolicy,
 			OnProfileChange: func(ctx context.Context, oldProfile, newProfile configv1.TLSProfileSpec) {
-				if openshiftcrypto.ShouldHonorClusterTLSProfile(reconcilerCfg.TLSConfig.OpenShift.TLSAdherencePolicy) {
-					setupLog.Info("TLS profile has changed, initiating shutdown to reload configuration",
+				adherencePolicy := reconcilerCfg.TLSConfig.Get().OpenShift.TLSAdherencePolicy
+				if openshiftcrypto.ShouldHonorClusterTLSProfile(adherencePolicy) {
+					setupLog.Info("TLS profile has changed, reloading configuration",
 						"oldProfile", oldProfile,
 						"newProfile", newProfile)
-					shutdown()
 				} else {
-					setupLog.Info("TLS profile has changed, but TLS adherence policy does not honor cluster TLS profile, skipping reload",
-						"policy", reconcilerCfg.TLSConfig.OpenShift.TLSAdherencePolicy)
+					setupLog.Info("TLS profile has changed, but TLS adherence policy does not honor cluster TLS profile, the profile isn't applied",
+						"policy", adherencePolicy)
 				}
+				// the new profile is stored even when it isn't honored, so that it applies if the policy changes
+				reloadTLSConfig(newProfile, adherencePolicy)
 			},
 			OnAdherencePolicyChange: func(ctx context.Context, oldPolicy, newPolicy configv1.TLSAdherencePolicy) {
-				setupLog.Info("TLS adherence policy has changed, initiating shutdown to reload configuration",
+				setupLog.Info("TLS adherence policy has changed, reloading configuration",
 					"oldPolicy", oldPolicy,
 					"newPolicy", newPolicy)
-				shutdown()
+				reloadTLSConfig(reconcilerCfg.TLSConfig.Get().OpenShift.TLSProfileSpec, newPolicy)
 			},
 		}
 		err = tlsWatcher.SetupWithManager(mgr)
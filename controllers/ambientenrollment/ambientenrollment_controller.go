@@ -0,0 +1,457 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ambientenrollment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/istio-ecosystem/sail-operator/pkg/revision"
+	"github.com/istio-ecosystem/sail-operator/pkg/watches"
+	"github.com/istio-ecosystem/sail-operator/pkg/waypoint"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const controllerName = "ambientenrollment"
+
+// Reconciler reconciles an AmbientEnrollment object
+type Reconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Config config.ReconcilerConfig
+}
+
+func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *runtime.Scheme) *Reconciler {
+	return &Reconciler{
+		Client: client,
+		Scheme: scheme,
+		Config: cfg,
+	}
+}
+
+// +kubebuilder:rbac:groups=sailoperator.io,resources=ambientenrollments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=sailoperator.io,resources=ambientenrollments/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=sailoperator.io,resources=ambientenrollments/finalizers,verbs=update
+// +kubebuilder:rbac:groups="gateway.networking.k8s.io",resources=gateways,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.14.1/pkg/reconcile
+func (r *Reconciler) Reconcile(ctx context.Context, enrollment *v1.AmbientEnrollment) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	rev, namespaces, reconcileErr := r.doReconcile(ctx, enrollment)
+
+	log.Info("Reconciliation done. Updating status.")
+	statusErr := r.updateStatus(ctx, enrollment, rev, namespaces, reconcileErr)
+
+	return ctrl.Result{}, errors.Join(reconcileErr, statusErr)
+}
+
+// Suspend updates the status of an AmbientEnrollment whose reconciliation is paused.
+func (r *Reconciler) Suspend(ctx context.Context, enrollment *v1.AmbientEnrollment) error {
+	status := *enrollment.Status.DeepCopy()
+	status.SetCondition(reconciler.SuspendedCondition(v1.AmbientEnrollmentConditionReconciled, v1.AmbientEnrollmentReasonSuspended))
+	status.State = v1.AmbientEnrollmentReasonSuspended
+	status.RetryCount, status.NextRetryTime = 0, nil
+	return reconciler.UpdateStatus(ctx, r.Client, enrollment, enrollment.Status, status, nil)
+}
+
+// doReconcile enrolls the selected namespaces and unenrolls the namespaces that are no longer selected. It returns
+// the IstioRevision used by the enrolled namespaces and the enrollment status of each selected namespace.
+func (r *Reconciler) doReconcile(
+	ctx context.Context, enrollment *v1.AmbientEnrollment,
+) (*v1.IstioRevision, []v1.NamespaceEnrollmentStatus, error) {
+	log := logf.FromContext(ctx)
+	if enrollment.Spec.TargetRef.Kind == "" || enrollment.Spec.TargetRef.Name == "" {
+		return nil, nil, reconciler.NewValidationError("spec.targetRef not set")
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&enrollment.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, nil, reconciler.NewValidationError(fmt.Sprintf("invalid spec.namespaceSelector: %v", err))
+	}
+
+	log.Info("Retrieving referenced IstioRevision")
+	rev, err := revision.GetIstioRevisionFromTargetReference(ctx, r.Client, enrollment.Spec.TargetRef)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, reconciler.NewReferenceNotFoundError("referenced resource does not exist", err)
+		}
+		return nil, nil, err
+	}
+
+	nsList := &corev1.NamespaceList{}
+	if err := r.Client.List(ctx, nsList); err != nil {
+		return nil, nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	var errs []error
+	var namespaces []v1.NamespaceEnrollmentStatus
+	for i := range nsList.Items {
+		ns := &nsList.Items[i]
+		if ns.DeletionTimestamp != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(ns.Labels)) {
+			status, err := r.enrollNamespace(ctx, enrollment, rev, ns)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			namespaces = append(namespaces, status)
+		} else if isEnrolledBy(ns, enrollment) {
+			log.Info("Removing namespace from ambient mode", "Namespace", ns.Name)
+			errs = append(errs, r.unenrollNamespace(ctx, enrollment, ns))
+		}
+	}
+	slices.SortFunc(namespaces, func(a, b v1.NamespaceEnrollmentStatus) int { return strings.Compare(a.Name, b.Name) })
+	return rev, namespaces, errors.Join(errs...)
+}
+
+func (r *Reconciler) Finalize(ctx context.Context, enrollment *v1.AmbientEnrollment) error {
+	nsList := &corev1.NamespaceList{}
+	if err := r.Client.List(ctx, nsList); err != nil {
+		return fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	var errs []error
+	for i := range nsList.Items {
+		if isEnrolledBy(&nsList.Items[i], enrollment) {
+			errs = append(errs, r.unenrollNamespace(ctx, enrollment, &nsList.Items[i]))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func isEnrolledBy(ns *corev1.Namespace, enrollment *v1.AmbientEnrollment) bool {
+	return ns.Annotations[constants.AmbientEnrollmentAnnotation] == enrollment.Name
+}
+
+// enrollNamespace labels the namespace for ambient mode and deploys its waypoint, unless the labels of the
+// namespace conflict with the enrollment.
+func (r *Reconciler) enrollNamespace(
+	ctx context.Context, enrollment *v1.AmbientEnrollment, rev *v1.IstioRevision, ns *corev1.Namespace,
+) (v1.NamespaceEnrollmentStatus, error) {
+	status := v1.NamespaceEnrollmentStatus{Name: ns.Name, State: v1.NamespaceEnrolled}
+	waypointName := ""
+	if enrollment.Spec.Waypoint != nil {
+		waypointName = enrollment.Spec.Waypoint.Name
+	}
+	if conflict := findConflict(enrollment, ns, waypointName); conflict != "" {
+		status.State, status.Message = v1.NamespaceEnrollmentConflict, conflict
+		return status, nil
+	}
+
+	previousWaypoint := ns.Annotations[constants.AmbientWaypointAnnotation]
+	if previousWaypoint != "" && previousWaypoint != waypointName {
+		if err := r.deleteWaypoint(ctx, enrollment, ns.Name, previousWaypoint); err != nil {
+			return status, err
+		}
+	}
+	if waypointName != "" {
+		if conflict, err := r.applyWaypoint(ctx, enrollment, rev, ns.Name); err != nil {
+			return status, err
+		} else if conflict != "" {
+			status.State, status.Message = v1.NamespaceEnrollmentConflict, conflict
+			return status, nil
+		}
+	}
+
+	patch := client.MergeFrom(ns.DeepCopy())
+	if ns.Labels == nil {
+		ns.Labels = map[string]string{}
+	}
+	if ns.Annotations == nil {
+		ns.Annotations = map[string]string{}
+	}
+	ns.Labels[constants.DataplaneModeLabel] = constants.DataplaneModeAmbient
+	ns.Annotations[constants.AmbientEnrollmentAnnotation] = enrollment.Name
+	if previousWaypoint != "" && ns.Labels[constants.UseWaypointLabel] == previousWaypoint {
+		delete(ns.Labels, constants.UseWaypointLabel)
+	}
+	delete(ns.Annotations, constants.AmbientWaypointAnnotation)
+	if waypointName != "" {
+		ns.Labels[constants.UseWaypointLabel] = waypointName
+		ns.Annotations[constants.AmbientWaypointAnnotation] = waypointName
+	}
+	if err := r.Client.Patch(ctx, ns, patch); err != nil {
+		return status, fmt.Errorf("failed to enroll namespace %q: %w", ns.Name, err)
+	}
+	return status, nil
+}
+
+// findConflict returns a message describing why the namespace can't be enrolled, or an empty string if it can.
+func findConflict(enrollment *v1.AmbientEnrollment, ns *corev1.Namespace, waypointName string) string {
+	if owner := ns.Annotations[constants.AmbientEnrollmentAnnotation]; owner != "" && owner != enrollment.Name {
+		return fmt.Sprintf("namespace is already enrolled by AmbientEnrollment %q", owner)
+	}
+	if rev := revision.GetReferencedRevisionFromNamespace(ns.Labels); rev != "" {
+		label := constants.IstioRevLabel
+		if ns.Labels[constants.IstioInjectionLabel] == constants.IstioInjectionEnabledValue {
+			label = constants.IstioInjectionLabel
+		}
+		return fmt.Sprintf("sidecar injection for revision %q is enabled by the %s label; remove it to enroll the namespace in ambient mode",
+			rev, label)
+	}
+	if mode := ns.Labels[constants.DataplaneModeLabel]; mode != "" && mode != constants.DataplaneModeAmbient {
+		return fmt.Sprintf("namespace has the %s=%s label", constants.DataplaneModeLabel, mode)
+	}
+	if waypoint := ns.Labels[constants.UseWaypointLabel]; waypointName != "" && waypoint != "" && waypoint != waypointName &&
+		waypoint != ns.Annotations[constants.AmbientWaypointAnnotation] {
+		return fmt.Sprintf("namespace already uses waypoint %q", waypoint)
+	}
+	return ""
+}
+
+// unenrollNamespace removes the labels set by the operator from the namespace and deletes its waypoint. Labels
+// that were modified by someone else are left intact.
+func (r *Reconciler) unenrollNamespace(ctx context.Context, enrollment *v1.AmbientEnrollment, ns *corev1.Namespace) error {
+	waypointName := ns.Annotations[constants.AmbientWaypointAnnotation]
+	if waypointName != "" {
+		if err := r.deleteWaypoint(ctx, enrollment, ns.Name, waypointName); err != nil {
+			return err
+		}
+	}
+
+	patch := client.MergeFrom(ns.DeepCopy())
+	if ns.Labels[constants.DataplaneModeLabel] == constants.DataplaneModeAmbient {
+		delete(ns.Labels, constants.DataplaneModeLabel)
+	}
+	if waypointName != "" && ns.Labels[constants.UseWaypointLabel] == waypointName {
+		delete(ns.Labels, constants.UseWaypointLabel)
+	}
+	delete(ns.Annotations, constants.AmbientEnrollmentAnnotation)
+	delete(ns.Annotations, constants.AmbientWaypointAnnotation)
+	if err := r.Client.Patch(ctx, ns, patch); err != nil {
+		return fmt.Errorf("failed to remove namespace %q from ambient mode: %w", ns.Name, err)
+	}
+	return nil
+}
+
+// applyWaypoint creates or updates the waypoint Gateway in the namespace. If a Gateway with the same name exists
+// and isn't managed by the enrollment, it's left intact and a message describing the conflict is returned.
+func (r *Reconciler) applyWaypoint(
+	ctx context.Context, enrollment *v1.AmbientEnrollment, rev *v1.IstioRevision, namespace string,
+) (string, error) {
+	waypointName := enrollment.Spec.Waypoint.Name
+	gw := waypoint.NewGateway(namespace, waypointName)
+
+	conflict := ""
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, gw, func() error {
+		if gw.GetResourceVersion() != "" && !metav1.IsControlledBy(gw, enrollment) {
+			conflict = fmt.Sprintf("Gateway %q already exists and isn't managed by this AmbientEnrollment", waypointName)
+			return nil
+		}
+		if err := waypoint.Configure(gw, *enrollment.Spec.Waypoint, waypoint.RevisionName(rev)); err != nil {
+			return err
+		}
+		return controllerutil.SetControllerReference(enrollment, gw, r.Scheme)
+	})
+	if meta.IsNoMatchError(err) {
+		return "", reconciler.NewTransientError("the Gateway API CRDs, which are required to deploy waypoints, are not installed")
+	} else if err != nil {
+		return "", fmt.Errorf("failed to apply waypoint Gateway %s/%s: %w", namespace, waypointName, err)
+	}
+	return conflict, nil
+}
+
+// deleteWaypoint deletes the waypoint Gateway, if it's managed by the enrollment.
+func (r *Reconciler) deleteWaypoint(ctx context.Context, enrollment *v1.AmbientEnrollment, namespace, name string) error {
+	gw := waypoint.NewGateway(namespace, name)
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(gw), gw); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get waypoint Gateway %s/%s: %w", namespace, name, err)
+	}
+	if !metav1.IsControlledBy(gw, enrollment) {
+		return nil
+	}
+	if err := r.Client.Delete(ctx, gw); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete waypoint Gateway %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	logger := mgr.GetLogger().WithName("ctrlr").WithName("ambientenrollment")
+
+	// mainObjectHandler handles the AmbientEnrollment watch events
+	mainObjectHandler := wrapEventHandler(logger, &handler.EnqueueRequestForObject{})
+
+	// operatorResourcesHandler handles watch events from operator CRDs Istio and IstioRevision
+	operatorResourcesHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapOperatorResourceToReconcileRequest))
+
+	// nsHandler triggers reconciliation of all enrollments whenever the labels of a namespace change, since the
+	// namespace may be selected by a different enrollment or its labels may conflict with the enrollment
+	nsHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapToAllEnrollments))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			LogConstructor: func(req *reconcile.Request) logr.Logger {
+				log := logger
+				if req != nil {
+					log = log.WithValues("AmbientEnrollment", req.Name)
+				}
+				return log
+			},
+			MaxConcurrentReconciles: r.Config.MaxConcurrentReconciles,
+			RateLimiter:             reconciler.RateLimiter(r.Config.BackoffPolicyFor(controllerName)),
+		}).
+		// we use the Watches function instead of For(), so that we can wrap the handler so that events that cause the object to be enqueued are logged
+		Watches(&v1.AmbientEnrollment{}, mainObjectHandler, builder.WithPredicates(watches.AsPredicate(watches.IgnoreStatusChanges()))).
+		Named(controllerName).
+		Watches(&corev1.Namespace{}, nsHandler, builder.WithPredicates(watches.AsPredicate(watches.IgnoreStatusChanges()))).
+
+		// cluster-scoped resources
+		Watches(&v1.Istio{}, operatorResourcesHandler).
+		Watches(&v1.IstioRevision{}, operatorResourcesHandler).
+		Complete(reconciler.NewStandardReconcilerWithFinalizer[*v1.AmbientEnrollment](r.Client, r.Reconcile, r.Finalize, constants.FinalizerName).
+			WithSuspendFunc(r.Suspend))
+}
+
+func (r *Reconciler) determineStatus(
+	enrollment *v1.AmbientEnrollment, rev *v1.IstioRevision, namespaces []v1.NamespaceEnrollmentStatus, reconcileErr error,
+) v1.AmbientEnrollmentStatus {
+	reconciledCondition := r.determineReconciledCondition(reconcileErr)
+
+	status := *enrollment.Status.DeepCopy()
+	status.ObservedGeneration = enrollment.Generation
+	retry := reconciler.NextRetry(r.Config.BackoffPolicyFor(controllerName), enrollment.Status.RetryCount, reconcileErr)
+	status.RetryCount, status.NextRetryTime = retry.Count, retry.Time
+	if rev != nil {
+		status.IstioRevision = rev.Name
+	}
+	if reconcileErr == nil {
+		// when reconciliation fails, some namespaces may not have been processed, so the previous results are kept
+		status.Namespaces = namespaces
+		status.SetCondition(determineNamespacesEnrolledCondition(namespaces))
+	}
+	status.SetCondition(reconciledCondition)
+	status.State = reconciler.DeriveState(v1.AmbientEnrollmentReasonHealthy,
+		reconciledCondition, status.GetCondition(v1.AmbientEnrollmentConditionNamespacesEnrolled))
+	return status
+}
+
+func (r *Reconciler) updateStatus(
+	ctx context.Context, enrollment *v1.AmbientEnrollment, rev *v1.IstioRevision, namespaces []v1.NamespaceEnrollmentStatus, reconcileErr error,
+) error {
+	status := r.determineStatus(enrollment, rev, namespaces, reconcileErr)
+	return reconciler.UpdateStatus(ctx, r.Client, enrollment, enrollment.Status, status, nil)
+}
+
+func (r *Reconciler) determineReconciledCondition(err error) v1.StatusCondition {
+	c := v1.StatusCondition{Type: v1.AmbientEnrollmentConditionReconciled}
+	if err == nil {
+		c.Status = metav1.ConditionTrue
+		c.Reason = v1.ConditionReason(v1.AmbientEnrollmentConditionReconciled)
+	} else {
+		c.Status = metav1.ConditionFalse
+		c.Message = err.Error()
+		if reconciler.IsReferenceNotFoundError(err) {
+			c.Reason = v1.AmbientEnrollmentReasonReferenceNotFound
+		} else {
+			c.Reason, c.Message = reconciler.DescribeReconcileError(err, v1.AmbientEnrollmentReasonReconcileError)
+		}
+	}
+	return c
+}
+
+func determineNamespacesEnrolledCondition(namespaces []v1.NamespaceEnrollmentStatus) v1.StatusCondition {
+	c := v1.StatusCondition{Type: v1.AmbientEnrollmentConditionNamespacesEnrolled}
+	var conflicts []string
+	for _, ns := range namespaces {
+		if ns.State == v1.NamespaceEnrollmentConflict {
+			conflicts = append(conflicts, ns.Name)
+		}
+	}
+	if len(conflicts) == 0 {
+		c.Status = metav1.ConditionTrue
+		c.Reason = v1.ConditionReason(v1.AmbientEnrollmentConditionNamespacesEnrolled)
+		c.Message = fmt.Sprintf("%d namespaces enrolled", len(namespaces))
+	} else {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1.AmbientEnrollmentReasonNamespaceConflict
+		c.Message = fmt.Sprintf("%d of %d selected namespaces could not be enrolled because of conflicts: %s",
+			len(conflicts), len(namespaces), strings.Join(conflicts, ", "))
+	}
+	return c
+}
+
+func (r *Reconciler) mapToAllEnrollments(ctx context.Context, _ client.Object) []reconcile.Request {
+	list := v1.AmbientEnrollmentList{}
+	if err := r.Client.List(ctx, &list); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list AmbientEnrollments")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, enrollment := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: enrollment.Name}})
+	}
+	return requests
+}
+
+func (r *Reconciler) mapOperatorResourceToReconcileRequest(ctx context.Context, obj client.Object) []reconcile.Request {
+	var kind string
+	if _, ok := obj.(*v1.Istio); ok {
+		kind = v1.IstioKind
+	} else if _, ok := obj.(*v1.IstioRevision); ok {
+		kind = v1.IstioRevisionKind
+	} else {
+		return nil
+	}
+
+	list := v1.AmbientEnrollmentList{}
+	if err := r.Client.List(ctx, &list); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, enrollment := range list.Items {
+		ref := enrollment.Spec.TargetRef
+		if ref.Kind == kind && ref.Name == obj.GetName() || kind == v1.IstioRevisionKind && enrollment.Status.IstioRevision == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: enrollment.Name}})
+		}
+	}
+	return requests
+}
+
+func wrapEventHandler(logger logr.Logger, handler handler.EventHandler) handler.EventHandler {
+	return enqueuelogger.WrapIfNecessary(v1.AmbientEnrollmentKind, logger, handler)
+}
@@ -0,0 +1,327 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ambientenrollment
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	"github.com/istio-ecosystem/sail-operator/pkg/waypoint"
+	"github.com/istio-ecosystem/sail-operator/pkg/waypoint/waypointtest"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	enrollmentName = "default"
+	istioName      = "default"
+	activeRev      = "default-v1-30-0"
+)
+
+func TestDoReconcile(t *testing.T) {
+	testCases := []struct {
+		name          string
+		nsLabels      map[string]string
+		nsAnnotations map[string]string
+
+		expectState  v1.NamespaceEnrollmentState
+		expectLabels map[string]string
+	}{
+		{
+			name:         "enrolls selected namespace",
+			nsLabels:     map[string]string{"team": "a"},
+			expectState:  v1.NamespaceEnrolled,
+			expectLabels: map[string]string{"team": "a", constants.DataplaneModeLabel: constants.DataplaneModeAmbient},
+		},
+		{
+			name:         "namespace already in ambient mode",
+			nsLabels:     map[string]string{"team": "a", constants.DataplaneModeLabel: constants.DataplaneModeAmbient},
+			expectState:  v1.NamespaceEnrolled,
+			expectLabels: map[string]string{"team": "a", constants.DataplaneModeLabel: constants.DataplaneModeAmbient},
+		},
+		{
+			name:         "conflict with istio-injection label",
+			nsLabels:     map[string]string{"team": "a", constants.IstioInjectionLabel: constants.IstioInjectionEnabledValue},
+			expectState:  v1.NamespaceEnrollmentConflict,
+			expectLabels: map[string]string{"team": "a", constants.IstioInjectionLabel: constants.IstioInjectionEnabledValue},
+		},
+		{
+			name:         "conflict with istio.io/rev label",
+			nsLabels:     map[string]string{"team": "a", constants.IstioRevLabel: activeRev},
+			expectState:  v1.NamespaceEnrollmentConflict,
+			expectLabels: map[string]string{"team": "a", constants.IstioRevLabel: activeRev},
+		},
+		{
+			name:         "conflict with dataplane-mode set to another value",
+			nsLabels:     map[string]string{"team": "a", constants.DataplaneModeLabel: "none"},
+			expectState:  v1.NamespaceEnrollmentConflict,
+			expectLabels: map[string]string{"team": "a", constants.DataplaneModeLabel: "none"},
+		},
+		{
+			name:          "conflict with another AmbientEnrollment",
+			nsLabels:      map[string]string{"team": "a"},
+			nsAnnotations: map[string]string{constants.AmbientEnrollmentAnnotation: "other"},
+			expectState:   v1.NamespaceEnrollmentConflict,
+			expectLabels:  map[string]string{"team": "a"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			ctx := context.TODO()
+
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "selected", Labels: tc.nsLabels, Annotations: tc.nsAnnotations},
+			}
+			other := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other", Labels: map[string]string{"team": "b"}}}
+			enrollment := newEnrollment(nil)
+
+			cl := waypointtest.NewFakeClient(
+				false, waypointtest.NewIstio(istioName, activeRev), waypointtest.NewOwnedRevision(istioName, activeRev, "", false),
+				ns, other, enrollment,
+			)
+			r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+			rev, namespaces, err := r.doReconcile(ctx, enrollment)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(rev.Name).To(Equal(activeRev))
+			g.Expect(namespaces).To(HaveLen(1))
+			g.Expect(namespaces[0].Name).To(Equal(ns.Name))
+			g.Expect(namespaces[0].State).To(Equal(tc.expectState))
+			if tc.expectState == v1.NamespaceEnrollmentConflict {
+				g.Expect(namespaces[0].Message).NotTo(BeEmpty())
+			}
+
+			g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(ns), ns)).To(Succeed())
+			g.Expect(ns.Labels).To(Equal(tc.expectLabels))
+			g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(other), other)).To(Succeed())
+			g.Expect(other.Labels).NotTo(HaveKey(constants.DataplaneModeLabel))
+		})
+	}
+}
+
+func TestDoReconcileUnenrollsNamespaceNoLongerSelected(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "previously-selected",
+			Labels:      map[string]string{"team": "b", constants.DataplaneModeLabel: constants.DataplaneModeAmbient},
+			Annotations: map[string]string{constants.AmbientEnrollmentAnnotation: enrollmentName},
+		},
+	}
+	enrollment := newEnrollment(nil)
+
+	cl := waypointtest.NewFakeClient(
+		false, waypointtest.NewIstio(istioName, activeRev), waypointtest.NewOwnedRevision(istioName, activeRev, "", false),
+		ns, enrollment,
+	)
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	_, namespaces, err := r.doReconcile(ctx, enrollment)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(namespaces).To(BeEmpty())
+
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(ns), ns)).To(Succeed())
+	g.Expect(ns.Labels).To(Equal(map[string]string{"team": "b"}))
+	g.Expect(ns.Annotations).NotTo(HaveKey(constants.AmbientEnrollmentAnnotation))
+}
+
+func TestDoReconcileDeploysWaypoint(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "selected", Labels: map[string]string{"team": "a"}}}
+	enrollment := newEnrollment(&v1.WaypointRequirement{Name: "waypoint", TrafficType: v1.WaypointTrafficTypeAll})
+
+	cl := waypointtest.NewFakeClient(
+		true, waypointtest.NewIstio(istioName, activeRev), waypointtest.NewOwnedRevision(istioName, activeRev, activeRev, false),
+		ns, enrollment,
+	)
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	_, namespaces, err := r.doReconcile(ctx, enrollment)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(namespaces).To(ConsistOf(HaveField("State", v1.NamespaceEnrolled)))
+
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(ns), ns)).To(Succeed())
+	g.Expect(ns.Labels).To(HaveKeyWithValue(constants.UseWaypointLabel, "waypoint"))
+	g.Expect(ns.Annotations).To(HaveKeyWithValue(constants.AmbientWaypointAnnotation, "waypoint"))
+
+	gw := waypointtest.GetGateway(g, cl, ns.Name, "waypoint")
+	g.Expect(metav1.IsControlledBy(gw, enrollment)).To(BeTrue())
+	g.Expect(gw.GetLabels()).To(HaveKeyWithValue(constants.WaypointForLabel, "all"))
+	g.Expect(gw.GetLabels()).To(HaveKeyWithValue(constants.IstioRevLabel, activeRev))
+	className, _, _ := unstructured.NestedString(gw.Object, "spec", "gatewayClassName")
+	g.Expect(className).To(Equal(waypoint.GatewayClassName))
+
+	// renaming the waypoint replaces the Gateway
+	enrollment.Spec.Waypoint.Name = "renamed"
+	_, _, err = r.doReconcile(ctx, enrollment)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cl.Get(ctx, types.NamespacedName{Namespace: ns.Name, Name: "waypoint"}, waypoint.NewGateway("", ""))).NotTo(Succeed())
+	waypointtest.GetGateway(g, cl, ns.Name, "renamed")
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(ns), ns)).To(Succeed())
+	g.Expect(ns.Labels).To(HaveKeyWithValue(constants.UseWaypointLabel, "renamed"))
+
+	// finalizing the enrollment removes the Gateway and the labels
+	g.Expect(r.Finalize(ctx, enrollment)).To(Succeed())
+	g.Expect(cl.Get(ctx, types.NamespacedName{Namespace: ns.Name, Name: "renamed"}, waypoint.NewGateway("", ""))).NotTo(Succeed())
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(ns), ns)).To(Succeed())
+	g.Expect(ns.Labels).To(Equal(map[string]string{"team": "a"}))
+	g.Expect(ns.Annotations).To(BeEmpty())
+}
+
+func TestDoReconcileKeepsUnmanagedGateway(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "selected", Labels: map[string]string{"team": "a"}}}
+	existing := waypoint.NewGateway("", "")
+	existing.SetNamespace(ns.Name)
+	existing.SetName("waypoint")
+	enrollment := newEnrollment(&v1.WaypointRequirement{Name: "waypoint", TrafficType: v1.WaypointTrafficTypeService})
+
+	cl := waypointtest.NewFakeClient(
+		true, waypointtest.NewIstio(istioName, activeRev), waypointtest.NewOwnedRevision(istioName, activeRev, "", false),
+		ns, existing, enrollment,
+	)
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	_, namespaces, err := r.doReconcile(ctx, enrollment)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(namespaces).To(ConsistOf(HaveField("State", v1.NamespaceEnrollmentConflict)))
+
+	gw := waypointtest.GetGateway(g, cl, ns.Name, "waypoint")
+	g.Expect(gw.GetOwnerReferences()).To(BeEmpty())
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(ns), ns)).To(Succeed())
+	g.Expect(ns.Labels).NotTo(HaveKey(constants.DataplaneModeLabel))
+}
+
+func TestDoReconcileWithoutGatewayAPI(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "selected", Labels: map[string]string{"team": "a"}}}
+	enrollment := newEnrollment(&v1.WaypointRequirement{Name: "waypoint", TrafficType: v1.WaypointTrafficTypeService})
+
+	cl := waypointtest.NewFakeClient(
+		false, waypointtest.NewIstio(istioName, activeRev), waypointtest.NewOwnedRevision(istioName, activeRev, "", false),
+		ns, enrollment,
+	)
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	_, _, err := r.doReconcile(ctx, enrollment)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(reconciler.IsTransientError(err)).To(BeTrue())
+}
+
+func TestDoReconcileReferenceNotFound(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	enrollment := newEnrollment(nil)
+	cl := waypointtest.NewFakeClient(false, enrollment)
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	rev, _, err := r.doReconcile(ctx, enrollment)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(reconciler.IsReferenceNotFoundError(err)).To(BeTrue())
+	g.Expect(rev).To(BeNil())
+}
+
+func TestDetermineStatus(t *testing.T) {
+	g := NewWithT(t)
+	r := NewReconciler(newReconcilerTestConfig(t), nil, scheme.Scheme)
+	rev := waypointtest.NewOwnedRevision(istioName, activeRev, "", false)
+
+	status := r.determineStatus(newEnrollment(nil), rev, []v1.NamespaceEnrollmentStatus{
+		{Name: "a", State: v1.NamespaceEnrolled},
+		{Name: "b", State: v1.NamespaceEnrollmentConflict, Message: "conflict"},
+	}, nil)
+	g.Expect(status.IstioRevision).To(Equal(activeRev))
+	g.Expect(status.Namespaces).To(HaveLen(2))
+	g.Expect(status.GetCondition(v1.AmbientEnrollmentConditionReconciled).Status).To(Equal(metav1.ConditionTrue))
+	enrolled := status.GetCondition(v1.AmbientEnrollmentConditionNamespacesEnrolled)
+	g.Expect(enrolled.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(enrolled.Reason).To(Equal(v1.AmbientEnrollmentReasonNamespaceConflict))
+	g.Expect(enrolled.Message).To(ContainSubstring("b"))
+	g.Expect(status.State).To(Equal(v1.AmbientEnrollmentReasonNamespaceConflict))
+
+	status = r.determineStatus(newEnrollment(nil), rev, []v1.NamespaceEnrollmentStatus{{Name: "a", State: v1.NamespaceEnrolled}}, nil)
+	g.Expect(status.GetCondition(v1.AmbientEnrollmentConditionNamespacesEnrolled).Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(status.State).To(Equal(v1.AmbientEnrollmentReasonHealthy))
+}
+
+func TestMapOperatorResourceToReconcileRequest(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	followsIstio := &v1.AmbientEnrollment{
+		ObjectMeta: metav1.ObjectMeta{Name: "follows-istio"},
+		Spec:       v1.AmbientEnrollmentSpec{TargetRef: v1.TargetReference{Kind: v1.IstioKind, Name: istioName}},
+		Status:     v1.AmbientEnrollmentStatus{IstioRevision: activeRev},
+	}
+	pinsRevision := &v1.AmbientEnrollment{
+		ObjectMeta: metav1.ObjectMeta{Name: "pins-revision"},
+		Spec:       v1.AmbientEnrollmentSpec{TargetRef: v1.TargetReference{Kind: v1.IstioRevisionKind, Name: "canary"}},
+	}
+
+	cl := waypointtest.NewFakeClient(false, followsIstio, pinsRevision)
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	g.Expect(r.mapOperatorResourceToReconcileRequest(ctx, &v1.Istio{ObjectMeta: metav1.ObjectMeta{Name: istioName}})).To(ConsistOf(
+		HaveField("NamespacedName", client.ObjectKeyFromObject(followsIstio)),
+	))
+	g.Expect(r.mapOperatorResourceToReconcileRequest(ctx, &v1.IstioRevision{ObjectMeta: metav1.ObjectMeta{Name: activeRev}})).To(ConsistOf(
+		HaveField("NamespacedName", client.ObjectKeyFromObject(followsIstio)),
+	))
+	g.Expect(r.mapOperatorResourceToReconcileRequest(ctx, &v1.IstioRevision{ObjectMeta: metav1.ObjectMeta{Name: "canary"}})).To(ConsistOf(
+		HaveField("NamespacedName", client.ObjectKeyFromObject(pinsRevision)),
+	))
+	g.Expect(r.mapToAllEnrollments(ctx, &corev1.Namespace{})).To(HaveLen(2))
+}
+
+func newEnrollment(waypoint *v1.WaypointRequirement) *v1.AmbientEnrollment {
+	return &v1.AmbientEnrollment{
+		ObjectMeta: metav1.ObjectMeta{Name: enrollmentName, UID: "enrollment-uid"},
+		Spec: v1.AmbientEnrollmentSpec{
+			TargetRef:         v1.TargetReference{Kind: v1.IstioKind, Name: istioName},
+			NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			Waypoint:          waypoint,
+		},
+	}
+}
+
+func newReconcilerTestConfig(t *testing.T) config.ReconcilerConfig {
+	return config.ReconcilerConfig{
+		ResourceFS:              os.DirFS(t.TempDir()),
+		Platform:                config.PlatformKubernetes,
+		DefaultProfile:          "",
+		MaxConcurrentReconciles: 1,
+	}
+}
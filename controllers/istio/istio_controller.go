@@ -18,49 +18,74 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
 	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
 	"github.com/istio-ecosystem/sail-operator/pkg/errlist"
+	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"github.com/istio-ecosystem/sail-operator/pkg/istiovalues"
 	"github.com/istio-ecosystem/sail-operator/pkg/istioversion"
+	sharedreconcile "github.com/istio-ecosystem/sail-operator/pkg/reconcile"
 	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
 	"github.com/istio-ecosystem/sail-operator/pkg/revision"
+	"github.com/istio-ecosystem/sail-operator/pkg/watches"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"istio.io/istio/pkg/ptr"
 )
 
+const controllerName = "istio"
+
 // Reconciler reconciles an Istio object
 type Reconciler struct {
 	Config config.ReconcilerConfig
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme       *runtime.Scheme
+	ChartManager *helm.ChartManager
+}
+
+// result holds the outcome of a reconciliation that is reported in the status of the Istio.
+type result struct {
+	ctrl.Result
+	pruning  []v1.RevisionPruningStatus
+	gateways []v1.IstioGatewayStatus // the gateways installed in this reconciliation
 }
 
-func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *runtime.Scheme) *Reconciler {
+func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *runtime.Scheme, chartManager *helm.ChartManager) *Reconciler {
 	return &Reconciler{
-		Config: cfg,
-		Client: client,
-		Scheme: scheme,
+		Config:       cfg,
+		Client:       client,
+		Scheme:       scheme,
+		ChartManager: chartManager,
 	}
 }
 
 // +kubebuilder:rbac:groups=sailoperator.io,resources=istios,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=sailoperator.io,resources=istios/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=sailoperator.io,resources=istios/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources="*",verbs="*"
+// +kubebuilder:rbac:groups="networking.k8s.io",resources="networkpolicies",verbs="*"
+// +kubebuilder:rbac:groups="policy",resources="poddisruptionbudgets",verbs="*"
+// +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=roles;rolebindings,verbs="*"
+// +kubebuilder:rbac:groups="apps",resources=deployments,verbs="*"
+// +kubebuilder:rbac:groups="autoscaling",resources=horizontalpodautoscalers,verbs="*"
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -71,41 +96,54 @@ func (r *Reconciler) Reconcile(ctx context.Context, istio *v1.Istio) (ctrl.Resul
 	log := logf.FromContext(ctx)
 
 	log.Info("Reconciling")
-	result, reconcileErr := r.doReconcile(ctx, istio)
+	res, reconcileErr := r.doReconcile(ctx, istio)
 
 	log.Info("Reconciliation done. Updating status.")
-	statusErr := r.updateStatus(ctx, istio, reconcileErr)
+	statusErr := r.updateStatus(ctx, istio, res, reconcileErr)
+
+	return res.Result, errors.Join(reconcileErr, statusErr)
+}
+
+// Finalize uninstalls all gateways that were, or may have been, installed for the Istio object.
+func (r *Reconciler) Finalize(ctx context.Context, istio *v1.Istio) error {
+	gatewayReconciler := r.newGatewayReconciler()
+	var errs errlist.Builder
+	for _, gw := range installedGateways(istio, true) {
+		errs.Add(gatewayReconciler.Uninstall(ctx, gw.Name, gw.Namespace))
+	}
+	return errs.Error()
+}
 
-	return result, errors.Join(reconcileErr, statusErr)
+// Suspend updates the status of an Istio whose reconciliation is paused.
+func (r *Reconciler) Suspend(ctx context.Context, istio *v1.Istio) error {
+	status := *istio.Status.DeepCopy()
+	status.SetCondition(reconciler.SuspendedCondition(v1.IstioConditionReconciled, v1.IstioReasonSuspended))
+	status.State = v1.IstioReasonSuspended
+	status.RetryCount, status.NextRetryTime = 0, nil
+	return reconciler.UpdateStatus(ctx, r.Client, istio, istio.Status, status, nil)
 }
 
 // doReconcile is the function that actually reconciles the Istio object. Any error reported by this
-// function should get reported in the status of the Istio object by the caller.
-func (r *Reconciler) doReconcile(ctx context.Context, istio *v1.Istio) (result ctrl.Result, err error) {
+// function should get reported in the status of the Istio object by the caller. It also returns the
+// gateways it installed and the pruning decisions for the non-active revisions.
+func (r *Reconciler) doReconcile(ctx context.Context, istio *v1.Istio) (result, error) {
 	if err := validate(istio); err != nil {
-		return ctrl.Result{}, err
+		return result{}, err
 	}
 
-	if err = r.reconcileActiveRevision(ctx, istio); err != nil {
-		return ctrl.Result{}, err
+	if err := r.reconcileActiveRevision(ctx, istio); err != nil {
+		return result{}, err
 	}
 
-	// We cannot prune revisions that manage an external cluster because the operator currently
-	// has no way of knowing if the revision is still in use on the external cluster.
-	if !managesExternalRevision(istio) {
-		return revision.PruneInactive(ctx, r.Client, istio.UID, getActiveRevisionName(istio), getPruningGracePeriod(istio))
+	gateways, err := r.reconcileGateways(ctx, istio)
+	if err != nil {
+		return result{gateways: gateways}, err
 	}
 
-	return result, err
-}
-
-func managesExternalRevision(istio *v1.Istio) bool {
-	if values := istio.Spec.Values; values != nil {
-		if pilot := values.Pilot; pilot != nil {
-			return pilot.Env["EXTERNAL_ISTIOD"] == "true"
-		}
-	}
-	return false
+	// Revisions of external control planes are pruned too; the IstioRevision controller checks whether they are in use
+	// on the remote cluster. If it can't, their InUse condition is Unknown and PruneInactive skips them.
+	pruning, res, err := revision.PruneInactive(ctx, r.Client, istio.UID, getActiveRevisionName(istio), getPrunePolicy(istio))
+	return result{Result: res, pruning: pruning, gateways: gateways}, err
 }
 
 func validate(istio *v1.Istio) error {
@@ -127,13 +165,24 @@ func (r *Reconciler) reconcileActiveRevision(ctx context.Context, istio *v1.Isti
 		return fmt.Errorf("failed to resolve Istio version for %q: %w", istio.Name, err)
 	}
 
+	tlsConfig, _, err := r.tlsConfig(ctx, istio)
+	if err != nil {
+		return err
+	}
+
+	fipsEnabled := istiovalues.ResolveFipsMode(istio.Spec.Compliance)
 	values, err := revision.ComputeValues(
 		istio.Spec.Values, istio.Spec.Namespace, version,
 		r.Config.Platform, r.Config.DefaultProfile, istio.Spec.Profile,
-		r.Config.ResourceFS, getActiveRevisionName(istio), r.Config.TLSConfig)
+		r.Config.ResourceFS, getActiveRevisionName(istio), tlsConfig, fipsEnabled)
 	if err != nil {
 		return err
 	}
+	if values.Global != nil {
+		if err := istiovalues.ValidateFipsImageVariant(fipsEnabled, values.Global.Variant); err != nil {
+			return reconciler.NewValidationError(err.Error())
+		}
+	}
 
 	return revision.CreateOrUpdate(ctx, r.Client,
 		getActiveRevisionName(istio),
@@ -148,6 +197,150 @@ func (r *Reconciler) reconcileActiveRevision(ctx context.Context, istio *v1.Isti
 		})
 }
 
+// tlsConfig returns the TLS settings that are applied to the active revision and the status that reports them.
+// The profile in spec.tlsProfile takes precedence over the TLS profile of the OpenShift APIServer.
+func (r *Reconciler) tlsConfig(ctx context.Context, istio *v1.Istio) (*config.TLSConfig, *v1.TLSProfileStatus, error) {
+	return istiovalues.ResolveTLSConfig(istio.Spec.TLSProfile, r.Config.TLSConfig.Get(), logf.FromContext(ctx))
+}
+
+// reconcileGateways installs or upgrades the gateways defined in spec.gateways, so that they are injected by the
+// active revision, and uninstalls the gateways that were previously installed but are no longer defined. It returns
+// the gateways it installed, along with the revision that injects them.
+func (r *Reconciler) reconcileGateways(ctx context.Context, istio *v1.Istio) ([]v1.IstioGatewayStatus, error) {
+	log := logf.FromContext(ctx)
+	gatewayReconciler := r.newGatewayReconciler()
+
+	desired := desiredGateways(istio)
+	for _, gw := range installedGateways(istio, false) {
+		if !containsGateway(desired, gw) {
+			log.Info("Uninstalling gateway", "gateway", gw.Namespace+"/"+gw.Name)
+			if err := gatewayReconciler.Uninstall(ctx, gw.Name, gw.Namespace); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(istio.Spec.Gateways) == 0 {
+		return nil, nil
+	}
+
+	version, err := istioversion.Resolve(istio.Spec.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve Istio version for %q: %w", istio.Name, err)
+	}
+
+	ownerReference := metav1.OwnerReference{
+		APIVersion:         v1.GroupVersion.String(),
+		Kind:               v1.IstioKind,
+		Name:               istio.Name,
+		UID:                istio.UID,
+		Controller:         ptr.Of(true),
+		BlockOwnerDeletion: ptr.Of(true),
+	}
+	installed := make([]v1.IstioGatewayStatus, 0, len(istio.Spec.Gateways))
+	for _, gw := range istio.Spec.Gateways {
+		namespace := gatewayNamespace(istio, gw)
+		if err := gatewayReconciler.Validate(ctx, gw.Name, namespace); err != nil {
+			return installed, err
+		}
+		status := v1.IstioGatewayStatus{Name: gw.Name, Namespace: namespace}
+		if i := gatewayIndex(istio.Status.Gateways, status); i >= 0 {
+			status.Revision = istio.Status.Gateways[i].Revision
+		}
+		status.Revision, err = r.resolveGatewayRevision(ctx, istio, status)
+		if err != nil {
+			return installed, err
+		}
+		log.Info("Installing gateway", "gateway", namespace+"/"+gw.Name, "IstioRevision", status.Revision)
+		if err := gatewayReconciler.Install(ctx, version, gw.Name, namespace, gw.Values, status.Revision, &ownerReference); err != nil {
+			return installed, err
+		}
+		installed = append(installed, status)
+	}
+	return installed, nil
+}
+
+// resolveGatewayRevision returns the name of the IstioRevision that should inject the gateway. Like waypoints, a
+// gateway is only moved to a new active revision once the revision is ready, so that it isn't restarted onto a
+// control plane that can't serve it yet.
+func (r *Reconciler) resolveGatewayRevision(ctx context.Context, istio *v1.Istio, gw v1.IstioGatewayStatus) (string, error) {
+	active := getActiveRevisionName(istio)
+	if gw.Revision == "" || gw.Revision == active {
+		return active, nil
+	}
+
+	rev, err := r.getActiveRevision(ctx, istio)
+	if err != nil {
+		return "", fmt.Errorf("failed to get active IstioRevision: %w", err)
+	}
+	if rev.Status.GetCondition(v1.IstioRevisionConditionReady).Status == metav1.ConditionTrue {
+		return active, nil
+	}
+
+	previousRev := &v1.IstioRevision{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: gw.Revision}, previousRev); err != nil {
+		if apierrors.IsNotFound(err) {
+			return active, nil
+		}
+		return "", err
+	}
+	logf.FromContext(ctx).Info("Waiting for the new active revision to be ready before moving the gateway",
+		"gateway", gw.Namespace+"/"+gw.Name, "IstioRevision", active)
+	return gw.Revision, nil
+}
+
+func (r *Reconciler) newGatewayReconciler() *sharedreconcile.GatewayReconciler {
+	return sharedreconcile.NewGatewayReconciler(sharedreconcile.Config{
+		ResourceFS:        r.Config.ResourceFS,
+		Platform:          r.Config.Platform,
+		DefaultProfile:    r.Config.DefaultProfile,
+		OperatorNamespace: r.Config.OperatorNamespace,
+		ChartManager:      r.ChartManager,
+	}, r.Client)
+}
+
+// gatewayNamespace returns the namespace of the gateway, which defaults to spec.namespace.
+func gatewayNamespace(istio *v1.Istio, gw v1.IstioGateway) string {
+	if gw.Namespace != "" {
+		return gw.Namespace
+	}
+	return istio.Spec.Namespace
+}
+
+// desiredGateways returns the gateways defined in spec.gateways.
+func desiredGateways(istio *v1.Istio) []v1.IstioGatewayStatus {
+	gateways := make([]v1.IstioGatewayStatus, 0, len(istio.Spec.Gateways))
+	for _, gw := range istio.Spec.Gateways {
+		gateways = append(gateways, v1.IstioGatewayStatus{Name: gw.Name, Namespace: gatewayNamespace(istio, gw)})
+	}
+	return gateways
+}
+
+// installedGateways returns the gateways recorded in status.gateways and, if includeDesired is true, also the
+// gateways defined in spec.gateways that aren't recorded yet (e.g. because the last reconciliation failed).
+func installedGateways(istio *v1.Istio, includeDesired bool) []v1.IstioGatewayStatus {
+	gateways := append([]v1.IstioGatewayStatus{}, istio.Status.Gateways...)
+	if includeDesired {
+		for _, gw := range desiredGateways(istio) {
+			if !containsGateway(gateways, gw) {
+				gateways = append(gateways, gw)
+			}
+		}
+	}
+	return gateways
+}
+
+func containsGateway(gateways []v1.IstioGatewayStatus, gw v1.IstioGatewayStatus) bool {
+	return gatewayIndex(gateways, gw) >= 0
+}
+
+// gatewayIndex returns the index of the gateway with the same name and namespace as gw, or -1 if there is none.
+func gatewayIndex(gateways []v1.IstioGatewayStatus, gw v1.IstioGatewayStatus) int {
+	return slices.IndexFunc(gateways, func(g v1.IstioGatewayStatus) bool {
+		return g.Name == gw.Name && g.Namespace == gw.Namespace
+	})
+}
+
 func getPruningGracePeriod(istio *v1.Istio) time.Duration {
 	strategy := istio.Spec.UpdateStrategy
 	period := int64(v1.DefaultRevisionDeletionGracePeriodSeconds)
@@ -160,6 +353,16 @@ func getPruningGracePeriod(istio *v1.Istio) time.Duration {
 	return time.Duration(period) * time.Second
 }
 
+func getPrunePolicy(istio *v1.Istio) revision.PrunePolicy {
+	policy := revision.PrunePolicy{GracePeriod: getPruningGracePeriod(istio)}
+	if strategy := istio.Spec.UpdateStrategy; strategy != nil && strategy.PruningPolicy != nil {
+		policy.KeepLast = int(strategy.PruningPolicy.KeepLastRevisions)
+		policy.Retained = strategy.PruningPolicy.RetainedRevisions
+		policy.RequireApproval = strategy.PruningPolicy.RequireApproval
+	}
+	return policy
+}
+
 func (r *Reconciler) getActiveRevision(ctx context.Context, istio *v1.Istio) (v1.IstioRevision, error) {
 	rev := v1.IstioRevision{}
 	err := r.Client.Get(ctx, GetActiveRevisionKey(istio), &rev)
@@ -202,7 +405,7 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	ownedResourceHandler := wrapEventHandler(logger,
 		handler.EnqueueRequestForOwner(r.Scheme, r.RESTMapper(), &v1.Istio{}, handler.OnlyControllerOwner()))
 
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{
 			LogConstructor: func(req *reconcile.Request) logr.Logger {
 				log := logger
@@ -212,26 +415,46 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 				return log
 			},
 			MaxConcurrentReconciles: r.Config.MaxConcurrentReconciles,
+			RateLimiter:             reconciler.RateLimiter(r.Config.BackoffPolicyFor(controllerName)),
 		}).
 		// we use the Watches function instead of For(), so that we can wrap the handler so that events that cause the object to be enqueued are logged
-		Watches(&v1.Istio{}, mainObjectHandler).
-		Named("istio").
-		Watches(&v1.IstioRevision{}, ownedResourceHandler).
-		Complete(reconciler.NewStandardReconciler(r.Client, r.Reconcile))
+		Watches(&v1.Istio{}, mainObjectHandler, builder.WithPredicates(watches.AsPredicate(watches.IgnoreRetryStatusChanges()))).
+		Named(controllerName).
+		Watches(&v1.IstioRevision{}, ownedResourceHandler)
+
+	// watch the resources created by the gateway chart
+	watches.RegisterOwnedWatches(b, watches.GatewayWatches, ownedResourceHandler, nil)
+
+	// reconcile all Istios when the platform TLS config changes, so that the new settings are applied to the active revisions
+	if r.Config.TLSConfig != nil {
+		b.WatchesRawSource(source.Channel(r.Config.TLSConfig.Subscribe(),
+			wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapToAllIstios))))
+	}
+
+	return b.Complete(reconciler.NewStandardReconcilerWithFinalizer[*v1.Istio](r.Client, r.Reconcile, r.Finalize, constants.FinalizerName).
+		WithSuspendFunc(r.Suspend))
 }
 
-func (r *Reconciler) determineStatus(ctx context.Context, istio *v1.Istio, reconcileErr error) (v1.IstioStatus, error) {
+func (r *Reconciler) determineStatus(ctx context.Context, istio *v1.Istio, res result, reconcileErr error,
+) (v1.IstioStatus, error) {
 	var errs errlist.Builder
 	status := *istio.Status.DeepCopy()
 	status.ObservedGeneration = istio.Generation
+	retry := reconciler.NextRetry(r.Config.BackoffPolicyFor(controllerName), istio.Status.RetryCount, reconcileErr)
+	status.RetryCount, status.NextRetryTime = retry.Count, retry.Time
+	status.Platform = string(r.Config.Platform)
 
 	// set Reconciled and Ready conditions
 	if reconcileErr != nil {
+		reason, message := v1.IstioReasonReconcileError, reconcileErr.Error()
+		if classifiedReason, hint, ok := reconciler.ClassifyError(reconcileErr); ok {
+			reason, message = classifiedReason, message+". "+hint
+		}
 		status.SetCondition(v1.StatusCondition{
 			Type:    v1.IstioConditionReconciled,
 			Status:  metav1.ConditionFalse,
-			Reason:  v1.IstioReasonReconcileError,
-			Message: reconcileErr.Error(),
+			Reason:  reason,
+			Message: message,
 		})
 		status.SetCondition(v1.StatusCondition{
 			Type:    v1.IstioConditionReady,
@@ -239,9 +462,12 @@ func (r *Reconciler) determineStatus(ctx context.Context, istio *v1.Istio, recon
 			Reason:  v1.IstioReasonReconcileError,
 			Message: "cannot determine readiness due to reconciliation error",
 		})
-		status.State = v1.IstioReasonReconcileError
+		status.State = reason
 	} else {
 		status.ActiveRevisionName = getActiveRevisionName(istio)
+		status.RevisionPruning = res.pruning
+		_, status.TLSProfile, _ = r.tlsConfig(ctx, istio)
+		status.Compliance = istiovalues.FipsComplianceStatus(istiovalues.ResolveFipsMode(istio.Spec.Compliance))
 		rev, err := r.getActiveRevision(ctx, istio)
 		if apierrors.IsNotFound(err) {
 			revisionNotFound := func(conditionType v1.IstioConditionType) v1.StatusCondition {
@@ -277,6 +503,22 @@ func (r *Reconciler) determineStatus(ctx context.Context, istio *v1.Istio, recon
 		}
 	}
 
+	// when reconciliation fails, some gateways may have been installed and others not, so we keep track of all of them
+	// to ensure they are uninstalled when they are removed from spec.gateways
+	if reconcileErr == nil {
+		status.Gateways = res.gateways
+	} else {
+		status.Gateways = installedGateways(istio, true)
+		for i, gw := range status.Gateways {
+			if j := gatewayIndex(res.gateways, gw); j >= 0 {
+				status.Gateways[i] = res.gateways[j]
+			}
+		}
+	}
+	if len(status.Gateways) == 0 {
+		status.Gateways = nil
+	}
+
 	// count the ready, in-use, and total revisions
 	if revs, err := revision.ListOwned(ctx, r.Client, istio.UID); err == nil {
 		status.Revisions.Total = int32(len(revs))
@@ -299,11 +541,26 @@ func (r *Reconciler) determineStatus(ctx context.Context, istio *v1.Istio, recon
 	return status, errs.Error()
 }
 
-func (r *Reconciler) updateStatus(ctx context.Context, istio *v1.Istio, reconcileErr error) error {
-	status, err := r.determineStatus(ctx, istio, reconcileErr)
+func (r *Reconciler) updateStatus(ctx context.Context, istio *v1.Istio, res result, reconcileErr error) error {
+	status, err := r.determineStatus(ctx, istio, res, reconcileErr)
 	return reconciler.UpdateStatus(ctx, r.Client, istio, istio.Status, status, err)
 }
 
+// mapToAllIstios enqueues all Istios. It's used when the platform TLS config changes, since it applies to all of them.
+func (r *Reconciler) mapToAllIstios(ctx context.Context, _ client.Object) []reconcile.Request {
+	istioList := v1.IstioList{}
+	if err := r.Client.List(ctx, &istioList); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list Istios")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(istioList.Items))
+	for _, istio := range istioList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: istio.Name}})
+	}
+	return requests
+}
+
 func wrapEventHandler(logger logr.Logger, handler handler.EventHandler) handler.EventHandler {
 	return enqueuelogger.WrapIfNecessary(v1.IstioKind, logger, handler)
 }
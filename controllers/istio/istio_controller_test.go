@@ -16,6 +16,7 @@ package istio
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"os"
 	"runtime/debug"
@@ -27,9 +28,12 @@ import (
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
 	"github.com/istio-ecosystem/sail-operator/pkg/istioversion"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/istio-ecosystem/sail-operator/pkg/revision"
 	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
 	"github.com/istio-ecosystem/sail-operator/pkg/test/testtime"
 	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -63,7 +67,7 @@ func TestReconcile(t *testing.T) {
 		cl := newFakeClientBuilder().
 			WithObjects(istio).
 			Build()
-		reconciler := NewReconciler(cfg, cl, scheme.Scheme)
+		reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
 
 		_, err := reconciler.Reconcile(ctx, istio)
 		if err == nil {
@@ -101,7 +105,7 @@ func TestReconcile(t *testing.T) {
 			Build()
 		cfg := newReconcilerTestConfig(t)
 		cfg.DefaultProfile = "invalid-profile"
-		reconciler := NewReconciler(cfg, cl, scheme.Scheme)
+		reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
 
 		_, err := reconciler.Reconcile(ctx, istio)
 		if err == nil {
@@ -142,7 +146,7 @@ func TestReconcile(t *testing.T) {
 				},
 			}).
 			Build()
-		reconciler := NewReconciler(cfg, cl, scheme.Scheme)
+		reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
 
 		_, err := reconciler.Reconcile(ctx, istio)
 		if err == nil {
@@ -289,8 +293,10 @@ func TestDetermineStatus(t *testing.T) {
 			reconciliationErr: fmt.Errorf("reconciliation error"),
 			wantErr:           false,
 			expectedStatus: v1.IstioStatus{
+				Platform:           string(config.PlatformKubernetes),
 				State:              v1.IstioReasonReconcileError,
 				ObservedGeneration: generation,
+				RetryCount:         1,
 				Conditions: []v1.StatusCondition{
 					{
 						Type:    v1.IstioConditionReconciled,
@@ -307,6 +313,50 @@ func TestDetermineStatus(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:              "keeps track of gateways on reconciliation error",
+			reconciliationErr: fmt.Errorf("reconciliation error"),
+			istio: &v1.Istio{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       istioKey.Name,
+					UID:        istioUID,
+					Generation: 100,
+				},
+				Spec: v1.IstioSpec{
+					Version:   "my-version",
+					Namespace: istioNamespace,
+					Gateways:  []v1.IstioGateway{{Name: "new-gateway"}},
+				},
+				Status: v1.IstioStatus{
+					Gateways: []v1.IstioGatewayStatus{{Name: "old-gateway", Namespace: istioNamespace}},
+				},
+			},
+			wantErr: false,
+			expectedStatus: v1.IstioStatus{
+				Platform:           string(config.PlatformKubernetes),
+				State:              v1.IstioReasonReconcileError,
+				ObservedGeneration: generation,
+				RetryCount:         1,
+				Conditions: []v1.StatusCondition{
+					{
+						Type:    v1.IstioConditionReconciled,
+						Status:  metav1.ConditionFalse,
+						Reason:  v1.IstioReasonReconcileError,
+						Message: "reconciliation error",
+					},
+					{
+						Type:    v1.IstioConditionReady,
+						Status:  metav1.ConditionUnknown,
+						Reason:  v1.IstioReasonReconcileError,
+						Message: "cannot determine readiness due to reconciliation error",
+					},
+				},
+				Gateways: []v1.IstioGatewayStatus{
+					{Name: "old-gateway", Namespace: istioNamespace},
+					{Name: "new-gateway", Namespace: istioNamespace},
+				},
+			},
+		},
 		{
 			name:    "mirrors status of active revision",
 			wantErr: false,
@@ -371,6 +421,7 @@ func TestDetermineStatus(t *testing.T) {
 				},
 			},
 			expectedStatus: v1.IstioStatus{
+				Platform:           string(config.PlatformKubernetes),
 				State:              v1.IstioReasonHealthy,
 				ObservedGeneration: generation,
 				Conditions: []v1.StatusCondition{
@@ -394,6 +445,7 @@ func TestDetermineStatus(t *testing.T) {
 					},
 				},
 				ActiveRevisionName: istioKey.Name,
+				Compliance:         &v1.ComplianceStatus{FIPS: v1.FIPSModeDisabled},
 				Revisions: v1.RevisionSummary{
 					Total: 2,
 					Ready: 1,
@@ -413,6 +465,7 @@ func TestDetermineStatus(t *testing.T) {
 				revision("some-other-istio", ownedByAnotherIstio, true, true, true),
 			},
 			expectedStatus: v1.IstioStatus{
+				Platform:           string(config.PlatformKubernetes),
 				State:              v1.IstioReasonHealthy,
 				ObservedGeneration: generation,
 				Conditions: []v1.StatusCondition{
@@ -433,6 +486,7 @@ func TestDetermineStatus(t *testing.T) {
 					},
 				},
 				ActiveRevisionName: istioKey.Name,
+				Compliance:         &v1.ComplianceStatus{FIPS: v1.FIPSModeDisabled},
 				Revisions: v1.RevisionSummary{
 					Total: 3,
 					Ready: 2,
@@ -444,6 +498,7 @@ func TestDetermineStatus(t *testing.T) {
 			name:    "active revision not found",
 			wantErr: false,
 			expectedStatus: v1.IstioStatus{
+				Platform:           string(config.PlatformKubernetes),
 				State:              v1.IstioReasonRevisionNotFound,
 				ObservedGeneration: generation,
 				Conditions: []v1.StatusCondition{
@@ -461,6 +516,7 @@ func TestDetermineStatus(t *testing.T) {
 					},
 				},
 				ActiveRevisionName: istioKey.Name,
+				Compliance:         &v1.ComplianceStatus{FIPS: v1.FIPSModeDisabled},
 			},
 		},
 		{
@@ -475,6 +531,7 @@ func TestDetermineStatus(t *testing.T) {
 			},
 			wantErr: true,
 			expectedStatus: v1.IstioStatus{
+				Platform:           string(config.PlatformKubernetes),
 				State:              v1.IstioReasonFailedToGetActiveRevision,
 				ObservedGeneration: generation,
 				Conditions: []v1.StatusCondition{
@@ -492,6 +549,7 @@ func TestDetermineStatus(t *testing.T) {
 					},
 				},
 				ActiveRevisionName: istioKey.Name,
+				Compliance:         &v1.ComplianceStatus{FIPS: v1.FIPSModeDisabled},
 				Revisions:          v1.RevisionSummary{},
 			},
 		},
@@ -507,6 +565,7 @@ func TestDetermineStatus(t *testing.T) {
 			},
 			wantErr: true,
 			expectedStatus: v1.IstioStatus{
+				Platform:           string(config.PlatformKubernetes),
 				State:              v1.IstioReasonRevisionNotFound,
 				ObservedGeneration: generation,
 				Conditions: []v1.StatusCondition{
@@ -524,6 +583,7 @@ func TestDetermineStatus(t *testing.T) {
 					},
 				},
 				ActiveRevisionName: istioKey.Name,
+				Compliance:         &v1.ComplianceStatus{FIPS: v1.FIPSModeDisabled},
 				Revisions: v1.RevisionSummary{
 					Total: -1,
 					Ready: -1,
@@ -564,12 +624,15 @@ func TestDetermineStatus(t *testing.T) {
 				WithObjects(initObjs...).
 				WithInterceptorFuncs(interceptorFuncs).
 				Build()
-			reconciler := NewReconciler(cfg, cl, scheme.Scheme)
+			reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
 
-			status, err := reconciler.determineStatus(ctx, istio, tc.reconciliationErr)
+			status, err := reconciler.determineStatus(ctx, istio, result{}, tc.reconciliationErr)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("determineStatus() error = %v, wantErr %v", err, tc.wantErr)
 			}
+			if retryScheduled := status.NextRetryTime != nil; retryScheduled != (tc.expectedStatus.RetryCount > 0) {
+				t.Errorf("determineStatus() scheduled retry = %v, expected retry count %d", retryScheduled, tc.expectedStatus.RetryCount)
+			}
 
 			if diff := cmp.Diff(tc.expectedStatus, clearTimestamps(status)); diff != "" {
 				t.Errorf("returned status wasn't as expected; diff (-expected, +actual):\n%v", diff)
@@ -578,6 +641,172 @@ func TestDetermineStatus(t *testing.T) {
 	}
 }
 
+func TestDetermineStatusReportsRevisionPruning(t *testing.T) {
+	cfg := newReconcilerTestConfig(t)
+
+	previous := []v1.RevisionPruningStatus{
+		{Name: "my-istio-old", Decision: v1.RevisionPruningDecisionRetained, Reason: v1.RevisionPruningReasonInUse},
+	}
+	current := []v1.RevisionPruningStatus{
+		{Name: "my-istio-old", Decision: v1.RevisionPruningDecisionAwaitingApproval, Reason: v1.RevisionPruningReasonApprovalRequired},
+	}
+
+	istio := &v1.Istio{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: istioKey.Name,
+			UID:  istioUID,
+		},
+		Spec: v1.IstioSpec{
+			Version:   "my-version",
+			Namespace: istioNamespace,
+		},
+		Status: v1.IstioStatus{
+			RevisionPruning: previous,
+		},
+	}
+	cl := newFakeClientBuilder().WithObjects(istio).Build()
+	reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+	status, _ := reconciler.determineStatus(ctx, istio, result{pruning: current}, nil)
+	if diff := cmp.Diff(current, status.RevisionPruning); diff != "" {
+		t.Errorf("unexpected revision pruning status; diff (-expected, +actual):\n%v", diff)
+	}
+
+	// when reconciliation fails, the revisions weren't evaluated, so the previous decisions are kept
+	status, _ = reconciler.determineStatus(ctx, istio, result{}, fmt.Errorf("reconcile error"))
+	if diff := cmp.Diff(previous, status.RevisionPruning); diff != "" {
+		t.Errorf("unexpected revision pruning status; diff (-expected, +actual):\n%v", diff)
+	}
+}
+
+func TestDetermineStatusReportsCompliance(t *testing.T) {
+	cfg := newReconcilerTestConfig(t)
+
+	istio := &v1.Istio{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: istioKey.Name,
+			UID:  istioUID,
+		},
+		Spec: v1.IstioSpec{
+			Version:    "my-version",
+			Namespace:  istioNamespace,
+			Compliance: &v1.Compliance{FIPS: v1.FIPSModeEnabled},
+		},
+	}
+	cl := newFakeClientBuilder().WithObjects(istio).Build()
+	reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+	status, _ := reconciler.determineStatus(ctx, istio, result{}, nil)
+	if diff := cmp.Diff(&v1.ComplianceStatus{FIPS: v1.FIPSModeEnabled}, status.Compliance); diff != "" {
+		t.Errorf("unexpected compliance status; diff (-expected, +actual):\n%v", diff)
+	}
+
+	istio.Spec.Compliance.FIPS = v1.FIPSModeDisabled
+	status, _ = reconciler.determineStatus(ctx, istio, result{}, nil)
+	if diff := cmp.Diff(&v1.ComplianceStatus{FIPS: v1.FIPSModeDisabled}, status.Compliance); diff != "" {
+		t.Errorf("unexpected compliance status; diff (-expected, +actual):\n%v", diff)
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	apiServerConfig := &config.TLSConfig{
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+		MinVersion:   tls.VersionTLS12,
+		OpenShift: &config.OpenShiftTLS{
+			TLSProfileSpec: configv1.TLSProfileSpec{MinTLSVersion: configv1.VersionTLS12},
+		},
+	}
+
+	testCases := []struct {
+		name             string
+		platformConfig   *config.TLSConfig
+		profile          *v1.TLSProfile
+		expectMinVersion uint16
+		expectStatus     *v1.TLSProfileStatus
+		expectErr        bool
+	}{
+		{
+			name: "no profile",
+		},
+		{
+			name:             "APIServer profile",
+			platformConfig:   apiServerConfig,
+			expectMinVersion: tls.VersionTLS12,
+			expectStatus: &v1.TLSProfileStatus{
+				Source:        v1.TLSProfileSourceAPIServer,
+				MinTLSVersion: "VersionTLS12",
+				Ciphers:       []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+			},
+		},
+		{
+			name:             "predefined profile overrides APIServer profile",
+			platformConfig:   apiServerConfig,
+			profile:          &v1.TLSProfile{Type: v1.TLSProfileModern},
+			expectMinVersion: tls.VersionTLS13,
+			expectStatus: &v1.TLSProfileStatus{
+				Source:        v1.TLSProfileSourceIstio,
+				Type:          v1.TLSProfileModern,
+				MinTLSVersion: "VersionTLS13",
+				Ciphers:       []string{"TLS_AES_128_GCM_SHA256", "TLS_AES_256_GCM_SHA384", "TLS_CHACHA20_POLY1305_SHA256"},
+			},
+		},
+		{
+			name: "custom profile",
+			profile: &v1.TLSProfile{
+				Type: v1.TLSProfileCustom,
+				Custom: &v1.CustomTLSProfile{
+					Ciphers:       []string{"ECDHE-RSA-AES128-GCM-SHA256"},
+					MinTLSVersion: "VersionTLS12",
+					Curves:        []string{"X25519"},
+				},
+			},
+			expectMinVersion: tls.VersionTLS12,
+			expectStatus: &v1.TLSProfileStatus{
+				Source:        v1.TLSProfileSourceIstio,
+				Type:          v1.TLSProfileCustom,
+				MinTLSVersion: "VersionTLS12",
+				Ciphers:       []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+				Curves:        []string{"X25519"},
+			},
+		},
+		{
+			name:      "custom profile without settings",
+			profile:   &v1.TLSProfile{Type: v1.TLSProfileCustom},
+			expectErr: true,
+		},
+		{
+			name: "custom profile without supported ciphers",
+			profile: &v1.TLSProfile{
+				Type:   v1.TLSProfileCustom,
+				Custom: &v1.CustomTLSProfile{Ciphers: []string{"unsupported"}, MinTLSVersion: "VersionTLS12"},
+			},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			cfg := newReconcilerTestConfig(t)
+			cfg.TLSConfig = config.NewTLSConfigSource(tc.platformConfig)
+			r := NewReconciler(cfg, nil, scheme.Scheme, nil)
+
+			istio := &v1.Istio{Spec: v1.IstioSpec{TLSProfile: tc.profile}}
+			tlsConfig, status, err := r.tlsConfig(ctx, istio)
+			if tc.expectErr {
+				g.Expect(reconciler.IsValidationError(err)).To(BeTrue())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(status).To(Equal(tc.expectStatus))
+			if tc.expectMinVersion == 0 {
+				g.Expect(tlsConfig).To(BeNil())
+			} else {
+				g.Expect(tlsConfig.MinVersion).To(Equal(tc.expectMinVersion))
+			}
+		})
+	}
+}
+
 func TestUpdateStatus(t *testing.T) {
 	cfg := newReconcilerTestConfig(t)
 
@@ -608,6 +837,7 @@ func TestUpdateStatus(t *testing.T) {
 			},
 			wantErr: true,
 			expectedStatus: v1.IstioStatus{
+				Platform:           string(config.PlatformKubernetes),
 				State:              v1.IstioReasonRevisionNotFound,
 				ObservedGeneration: generation,
 				Conditions: []v1.StatusCondition{
@@ -625,6 +855,7 @@ func TestUpdateStatus(t *testing.T) {
 					},
 				},
 				ActiveRevisionName: istioKey.Name,
+				Compliance:         &v1.ComplianceStatus{FIPS: v1.FIPSModeDisabled},
 				Revisions: v1.RevisionSummary{
 					Total: -1,
 					Ready: -1,
@@ -645,6 +876,7 @@ func TestUpdateStatus(t *testing.T) {
 					Namespace: istioNamespace,
 				},
 				Status: v1.IstioStatus{
+					Platform:           string(config.PlatformKubernetes),
 					ObservedGeneration: 100,
 					State:              v1.IstioReasonHealthy,
 					Conditions: []v1.StatusCondition{
@@ -670,6 +902,7 @@ func TestUpdateStatus(t *testing.T) {
 						},
 					},
 					ActiveRevisionName: istioKey.Name,
+					Compliance:         &v1.ComplianceStatus{FIPS: v1.FIPSModeDisabled},
 				},
 			},
 			revisions: []v1.IstioRevision{
@@ -708,6 +941,7 @@ func TestUpdateStatus(t *testing.T) {
 				},
 			},
 			expectedStatus: v1.IstioStatus{
+				Platform:           string(config.PlatformKubernetes),
 				State:              v1.IstioReasonHealthy,
 				ObservedGeneration: generation,
 				Conditions: []v1.StatusCondition{
@@ -730,6 +964,7 @@ func TestUpdateStatus(t *testing.T) {
 					},
 				},
 				ActiveRevisionName: istioKey.Name,
+				Compliance:         &v1.ComplianceStatus{FIPS: v1.FIPSModeDisabled},
 			},
 			disallowWrites: true,
 			wantErr:        false,
@@ -781,9 +1016,9 @@ func TestUpdateStatus(t *testing.T) {
 				WithObjects(initObjs...).
 				WithInterceptorFuncs(interceptorFuncs).
 				Build()
-			reconciler := NewReconciler(cfg, cl, scheme.Scheme)
+			reconciler := NewReconciler(cfg, cl, scheme.Scheme, nil)
 
-			err := reconciler.updateStatus(ctx, istio, tc.reconciliationErr)
+			err := reconciler.updateStatus(ctx, istio, result{}, tc.reconciliationErr)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("updateStatus() error = %v, wantErr %v", err, tc.wantErr)
 			}
@@ -800,6 +1035,7 @@ func clearTimestamps(status v1.IstioStatus) v1.IstioStatus {
 	for i := range status.Conditions {
 		status.Conditions[i].LastTransitionTime = metav1.Time{}
 	}
+	status.NextRetryTime = nil
 	return status
 }
 
@@ -919,47 +1155,189 @@ func TestGetPruningGracePeriod(t *testing.T) {
 	}
 }
 
-func TestManagesExternalRevision(t *testing.T) {
+func TestGetPrunePolicy(t *testing.T) {
+	istio := &v1.Istio{
+		Spec: v1.IstioSpec{
+			UpdateStrategy: &v1.IstioUpdateStrategy{
+				InactiveRevisionDeletionGracePeriodSeconds: ptr.Of(int64(60)),
+				PruningPolicy: &v1.RevisionPruningPolicy{
+					KeepLastRevisions: 2,
+					RetainedRevisions: []string{"my-istio-v1-24-0"},
+					RequireApproval:   true,
+				},
+			},
+		},
+	}
+	expected := revision.PrunePolicy{
+		GracePeriod:     time.Minute,
+		KeepLast:        2,
+		Retained:        []string{"my-istio-v1-24-0"},
+		RequireApproval: true,
+	}
+	if diff := cmp.Diff(expected, getPrunePolicy(istio)); diff != "" {
+		t.Errorf("getPrunePolicy() returned unexpected policy; diff (-expected, +actual):\n%v", diff)
+	}
+
+	expected = revision.PrunePolicy{GracePeriod: v1.DefaultRevisionDeletionGracePeriodSeconds * time.Second}
+	if diff := cmp.Diff(expected, getPrunePolicy(&v1.Istio{})); diff != "" {
+		t.Errorf("getPrunePolicy() returned unexpected policy; diff (-expected, +actual):\n%v", diff)
+	}
+}
+
+func TestInstalledGateways(t *testing.T) {
+	istio := &v1.Istio{
+		Spec: v1.IstioSpec{
+			Namespace: istioNamespace,
+			Gateways: []v1.IstioGateway{
+				{Name: "ingress", Namespace: "istio-ingress"},
+				{Name: "egress"},
+			},
+		},
+		Status: v1.IstioStatus{
+			Gateways: []v1.IstioGatewayStatus{
+				{Name: "ingress", Namespace: "istio-ingress"},
+				{Name: "old", Namespace: "istio-ingress"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		includeDesired bool
+		expected       []v1.IstioGatewayStatus
+	}{
+		{
+			name:           "only gateways in status",
+			includeDesired: false,
+			expected: []v1.IstioGatewayStatus{
+				{Name: "ingress", Namespace: "istio-ingress"},
+				{Name: "old", Namespace: "istio-ingress"},
+			},
+		},
+		{
+			name:           "gateways in status and spec",
+			includeDesired: true,
+			expected: []v1.IstioGatewayStatus{
+				{Name: "ingress", Namespace: "istio-ingress"},
+				{Name: "old", Namespace: "istio-ingress"},
+				{Name: "egress", Namespace: istioNamespace},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := installedGateways(istio, tt.includeDesired)
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("installedGateways() returned unexpected result; diff (-expected, +actual):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestResolveGatewayRevision(t *testing.T) {
+	istio := &v1.Istio{
+		ObjectMeta: objectMeta,
+		Spec: v1.IstioSpec{
+			Version:        "2.0.0",
+			Namespace:      istioNamespace,
+			UpdateStrategy: &v1.IstioUpdateStrategy{Type: v1.UpdateStrategyTypeRevisionBased},
+		},
+	}
+	activeName := istioName + "-2-0-0"
+	previousName := istioName + "-1-0-0"
+	newRevision := func(name string, ready metav1.ConditionStatus) *v1.IstioRevision {
+		return &v1.IstioRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1.IstioRevisionStatus{
+				Conditions: []v1.StatusCondition{{Type: v1.IstioRevisionConditionReady, Status: ready}},
+			},
+		}
+	}
+
 	tests := []struct {
 		name     string
-		spec     v1.IstioSpec
-		expected bool
+		previous string
+		objects  []client.Object
+		expected string
 	}{
 		{
-			name:     "Empty spec.values does not manage",
-			spec:     v1.IstioSpec{},
-			expected: false,
+			name:     "new gateway",
+			previous: "",
+			expected: activeName,
+		},
+		{
+			name:     "gateway already uses the active revision",
+			previous: activeName,
+			objects:  []client.Object{newRevision(activeName, metav1.ConditionFalse)},
+			expected: activeName,
 		},
 		{
-			name:     "Empty spec.values.pilot does not manage",
-			spec:     v1.IstioSpec{Values: &v1.Values{}},
-			expected: false,
+			name:     "active revision is ready",
+			previous: previousName,
+			objects:  []client.Object{newRevision(activeName, metav1.ConditionTrue), newRevision(previousName, metav1.ConditionTrue)},
+			expected: activeName,
 		},
 		{
-			name:     "Empty spec.values.pilot.env does not manage",
-			spec:     v1.IstioSpec{Values: &v1.Values{Pilot: &v1.PilotConfig{}}},
-			expected: false,
+			name:     "active revision is not ready",
+			previous: previousName,
+			objects:  []client.Object{newRevision(activeName, metav1.ConditionFalse), newRevision(previousName, metav1.ConditionTrue)},
+			expected: previousName,
 		},
 		{
-			name:     "env with EXTERNAL_ISTIOD=true does manage",
-			spec:     v1.IstioSpec{Values: &v1.Values{Pilot: &v1.PilotConfig{Env: map[string]string{"EXTERNAL_ISTIOD": "true"}}}},
-			expected: true,
+			name:     "previous revision no longer exists",
+			previous: previousName,
+			objects:  []client.Object{newRevision(activeName, metav1.ConditionFalse)},
+			expected: activeName,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			istio := &v1.Istio{
-				Spec: tt.spec,
-			}
-			got := managesExternalRevision(istio)
-			if got != tt.expected {
-				t.Errorf("managesExternalRevision() = %v, want %v", got, tt.expected)
-			}
+			g := NewWithT(t)
+			cl := newFakeClientBuilder().WithObjects(tt.objects...).Build()
+			reconciler := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme, nil)
+
+			gw := v1.IstioGatewayStatus{Name: "ingress", Namespace: istioNamespace, Revision: tt.previous}
+			revisionName, err := reconciler.resolveGatewayRevision(ctx, istio, gw)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(revisionName).To(Equal(tt.expected))
 		})
 	}
 }
 
+func TestDetermineStatusRecordsGatewayRevisions(t *testing.T) {
+	g := NewWithT(t)
+	cl := newFakeClientBuilder().Build()
+	reconciler := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme, nil)
+
+	istio := &v1.Istio{
+		ObjectMeta: objectMeta,
+		Spec: v1.IstioSpec{
+			Namespace: istioNamespace,
+			Gateways:  []v1.IstioGateway{{Name: "ingress"}, {Name: "egress"}},
+		},
+		Status: v1.IstioStatus{
+			Gateways: []v1.IstioGatewayStatus{
+				{Name: "ingress", Namespace: istioNamespace, Revision: "old"},
+				{Name: "egress", Namespace: istioNamespace, Revision: "old"},
+			},
+		},
+	}
+
+	// when the reconciliation fails part-way, the gateways that were moved keep their new revision
+	res := result{gateways: []v1.IstioGatewayStatus{{Name: "ingress", Namespace: istioNamespace, Revision: "new"}}}
+	status, _ := reconciler.determineStatus(ctx, istio, res, fmt.Errorf("reconcile error"))
+	g.Expect(status.Gateways).To(Equal([]v1.IstioGatewayStatus{
+		{Name: "ingress", Namespace: istioNamespace, Revision: "new"},
+		{Name: "egress", Namespace: istioNamespace, Revision: "old"},
+	}))
+
+	res.gateways = append(res.gateways, v1.IstioGatewayStatus{Name: "egress", Namespace: istioNamespace, Revision: "new"})
+	status, _ = reconciler.determineStatus(ctx, istio, res, nil)
+	g.Expect(status.Gateways).To(Equal(res.gateways))
+}
+
 func Must(t *testing.T, err error) {
 	t.Helper()
 	if err != nil {
@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/go-logr/logr"
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
@@ -26,14 +27,19 @@ import (
 	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
 	"github.com/istio-ecosystem/sail-operator/pkg/errlist"
 	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"github.com/istio-ecosystem/sail-operator/pkg/istiovalues"
+	"github.com/istio-ecosystem/sail-operator/pkg/istioversion"
 	sharedreconcile "github.com/istio-ecosystem/sail-operator/pkg/reconcile"
 	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/istio-ecosystem/sail-operator/pkg/revision"
+	"github.com/istio-ecosystem/sail-operator/pkg/validation"
 	"github.com/istio-ecosystem/sail-operator/pkg/watches"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -43,6 +49,8 @@ import (
 	"istio.io/istio/pkg/ptr"
 )
 
+const controllerName = "istiocni"
+
 // Reconciler reconciles an IstioCNI object
 type Reconciler struct {
 	client.Client
@@ -83,12 +91,19 @@ func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *ru
 func (r *Reconciler) Reconcile(ctx context.Context, cni *v1.IstioCNI) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
 
-	reconcileErr := r.doReconcile(ctx, cni)
+	if cni.Status.State == v1.IstioCNIReasonSuspended {
+		// reconciliation was resumed; record the changes made while it was paused before they're reverted. The
+		// summary is stored in the current status, so that determineStatus carries it over to the new one.
+		log.Info("Reconciliation resumed. Checking for drift.")
+		cni.Status.Drift = r.detectDrift(ctx, cni)
+	}
+
+	rollout, reconcileErr := r.doReconcile(ctx, cni)
 
 	log.Info("Reconciliation done. Updating status.")
-	statusErr := r.updateStatus(ctx, cni, reconcileErr)
+	result, statusErr := r.updateStatus(ctx, cni, rollout, reconcileErr)
 
-	return ctrl.Result{}, errors.Join(reconcileErr, statusErr)
+	return result, errors.Join(reconcileErr, statusErr)
 }
 
 func (r *Reconciler) Finalize(ctx context.Context, cni *v1.IstioCNI) error {
@@ -96,12 +111,45 @@ func (r *Reconciler) Finalize(ctx context.Context, cni *v1.IstioCNI) error {
 	return cniReconciler.Uninstall(ctx, cni.Spec.Namespace)
 }
 
-func (r *Reconciler) doReconcile(ctx context.Context, cni *v1.IstioCNI) error {
+// Suspend updates the status of an IstioCNI whose reconciliation is paused.
+func (r *Reconciler) Suspend(ctx context.Context, cni *v1.IstioCNI) error {
+	status := *cni.Status.DeepCopy()
+	status.SetCondition(reconciler.SuspendedCondition(v1.IstioCNIConditionReconciled, v1.IstioCNIReasonSuspended))
+	status.State = v1.IstioCNIReasonSuspended
+	status.RetryCount, status.NextRetryTime = 0, nil
+	status.Drift = nil
+	return reconciler.UpdateStatus(ctx, r.Client, cni, cni.Status, status, nil)
+}
+
+// detectDrift returns a summary of the resources that were modified while reconciliation was paused.
+func (r *Reconciler) detectDrift(ctx context.Context, cni *v1.IstioCNI) *v1.DriftSummary {
+	drift, err := r.newCNIReconciler().DetectDrift(ctx, cni.Spec.Namespace)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to detect drift")
+		return nil
+	}
+	return reconciler.NewDriftSummary(drift)
+}
+
+// doReconcile installs the chart and advances the batched rollout of the DaemonSet. It returns the status of the
+// rollout, which is nil unless the rollout strategy is batched.
+func (r *Reconciler) doReconcile(ctx context.Context, cni *v1.IstioCNI) (*v1.DaemonSetRolloutStatus, error) {
 	log := logf.FromContext(ctx)
 	cniReconciler := r.newCNIReconciler()
 
 	if err := cniReconciler.Validate(ctx, cni.Spec.Version, cni.Spec.Namespace); err != nil {
-		return err
+		return nil, err
+	}
+
+	if err := r.validateNodePool(ctx, cni); err != nil {
+		return nil, err
+	}
+
+	if r.Config.StrictVersionSkew {
+		err := revision.ValidateComponentVersion(ctx, r.Client, r.Config, istioversion.ComponentCNI, cni.Spec.Version, cni.Status.Version)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	log.Info("Installing Helm chart")
@@ -113,7 +161,29 @@ func (r *Reconciler) doReconcile(ctx context.Context, cni *v1.IstioCNI) error {
 		Controller:         ptr.Of(true),
 		BlockOwnerDeletion: ptr.Of(true),
 	}
-	return cniReconciler.Install(ctx, cni.Spec.Version, cni.Spec.Namespace, cni.Spec.Values, cni.Spec.Profile, &ownerReference)
+	values := istiovalues.ApplyCNINodeSelector(cni.Spec.Values, cni.Spec.NodeSelector)
+	if err := cniReconciler.Install(ctx, cni.Spec.Version, cni.Spec.Namespace, values, cni.Spec.Profile, cni.Spec.Rollout, &ownerReference); err != nil {
+		return nil, err
+	}
+	return cniReconciler.Rollout(ctx, cni.Spec.Namespace, cni.Spec.Rollout, cni.Status.Rollout)
+}
+
+// validateNodePool checks that the IstioCNI doesn't share its namespace or any of its nodes with another IstioCNI.
+func (r *Reconciler) validateNodePool(ctx context.Context, cni *v1.IstioCNI) error {
+	cniList := v1.IstioCNIList{}
+	if err := r.Client.List(ctx, &cniList); err != nil {
+		return fmt.Errorf("failed to list IstioCNIs: %w", err)
+	}
+
+	others := make([]validation.NodePool, 0, len(cniList.Items))
+	for i := range cniList.Items {
+		others = append(others, nodePool(&cniList.Items[i]))
+	}
+	return validation.ValidateNodePool(v1.IstioCNIKind, nodePool(cni), others)
+}
+
+func nodePool(cni *v1.IstioCNI) validation.NodePool {
+	return validation.NodePool{Meta: &cni.ObjectMeta, Namespace: cni.Spec.Namespace, NodeSelector: cni.Spec.NodeSelector}
 }
 
 func (r *Reconciler) newCNIReconciler() *sharedreconcile.CNIReconciler {
@@ -139,6 +209,12 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	namespaceHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToReconcileRequest))
 
+	// revisionHandler handles IstioRevisions, whose versions determine the VersionSkew condition
+	revisionHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapToAllIstioCNIs))
+
+	// nodePoolHandler handles changes to any IstioCNI, since they can resolve node pool conflicts with other IstioCNIs
+	nodePoolHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapToAllIstioCNIs))
+
 	b := ctrl.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{
 			LogConstructor: func(req *reconcile.Request) logr.Logger {
@@ -149,36 +225,58 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 				return log
 			},
 			MaxConcurrentReconciles: r.Config.MaxConcurrentReconciles,
+			RateLimiter:             reconciler.RateLimiter(r.Config.BackoffPolicyFor(controllerName)),
 		}).
 		// we use the Watches function instead of For(), so that we can wrap the handler so that events that cause the object to be enqueued are logged
-		Watches(&v1.IstioCNI{}, mainObjectHandler).
-		Named("istiocni")
+		Watches(&v1.IstioCNI{}, mainObjectHandler, builder.WithPredicates(watches.AsPredicate(watches.IgnoreRetryStatusChanges()))).
+		Watches(&v1.IstioCNI{}, nodePoolHandler, builder.WithPredicates(watches.AsPredicate(watches.IgnoreStatusChanges()))).
+		Named(controllerName)
 
 	watches.RegisterOwnedWatches(b, watches.CNIWatches, ownedResourceHandler, nil)
 
 	return b.
 		// +lint-watches:ignore: Namespace (not present in charts, but must be watched to reconcile IstioCni when its namespace is created)
 		Watches(&corev1.Namespace{}, namespaceHandler).
-		Complete(reconciler.NewStandardReconcilerWithFinalizer[*v1.IstioCNI](r.Client, r.Reconcile, r.Finalize, constants.FinalizerName))
+		Watches(&v1.IstioRevision{}, revisionHandler, builder.WithPredicates(watches.AsPredicate(watches.IgnoreStatusChanges()))).
+		Complete(reconciler.NewStandardReconcilerWithFinalizer[*v1.IstioCNI](r.Client, r.Reconcile, r.Finalize, constants.FinalizerName).
+			WithSuspendFunc(r.Suspend))
 }
 
-func (r *Reconciler) determineStatus(ctx context.Context, cni *v1.IstioCNI, reconcileErr error) (v1.IstioCNIStatus, error) {
+func (r *Reconciler) determineStatus(
+	ctx context.Context, cni *v1.IstioCNI, rollout *v1.DaemonSetRolloutStatus, reconcileErr error,
+) (v1.IstioCNIStatus, error) {
 	var errs errlist.Builder
 	reconciledCondition := r.determineReconciledCondition(reconcileErr)
 	readyCondition, err := r.determineReadyCondition(ctx, cni)
 	errs.Add(err)
+	versionSkewCondition, err := r.determineVersionSkewCondition(ctx, cni)
+	errs.Add(err)
 
 	status := *cni.Status.DeepCopy()
 	status.ObservedGeneration = cni.Generation
+	retry := reconciler.NextRetry(r.Config.BackoffPolicyFor(controllerName), cni.Status.RetryCount, reconcileErr)
+	status.RetryCount, status.NextRetryTime = retry.Count, retry.Time
+	status.Platform = string(r.Config.Platform)
 	status.SetCondition(reconciledCondition)
 	status.SetCondition(readyCondition)
+	status.SetCondition(versionSkewCondition)
 	status.State = reconciler.DeriveState(v1.IstioCNIReasonHealthy, reconciledCondition, readyCondition)
+	if reconcileErr == nil {
+		status.Version = cni.Spec.Version
+	}
+	if reconcileErr == nil || !sharedreconcile.IsBatchedRollout(cni.Spec.Rollout) {
+		// when reconciliation of a batched rollout fails, the rollout didn't advance, so its previous status is kept
+		status.Rollout = rollout
+	}
 	return status, errs.Error()
 }
 
-func (r *Reconciler) updateStatus(ctx context.Context, cni *v1.IstioCNI, reconcileErr error) error {
-	status, err := r.determineStatus(ctx, cni, reconcileErr)
-	return reconciler.UpdateStatus(ctx, r.Client, cni, cni.Status, status, err)
+func (r *Reconciler) updateStatus(
+	ctx context.Context, cni *v1.IstioCNI, rollout *v1.DaemonSetRolloutStatus, reconcileErr error,
+) (ctrl.Result, error) {
+	status, err := r.determineStatus(ctx, cni, rollout, reconcileErr)
+	result := ctrl.Result{RequeueAfter: sharedreconcile.RolloutRequeueAfter(status.Rollout)}
+	return result, reconciler.UpdateStatus(ctx, r.Client, cni, cni.Status, status, err)
 }
 
 func (r *Reconciler) determineReconciledCondition(err error) v1.StatusCondition {
@@ -188,8 +286,7 @@ func (r *Reconciler) determineReconciledCondition(err error) v1.StatusCondition
 		c.Reason = v1.ConditionReason(v1.IstioCNIConditionReconciled)
 	} else {
 		c.Status = metav1.ConditionFalse
-		c.Reason = v1.IstioCNIReasonReconcileError
-		c.Message = fmt.Sprintf("error reconciling resource: %v", err)
+		c.Reason, c.Message = reconciler.DescribeReconcileError(err, v1.IstioCNIReasonReconcileError)
 	}
 	return c
 }
@@ -199,6 +296,27 @@ func (r *Reconciler) determineReadyCondition(ctx context.Context, cni *v1.IstioC
 		"istio-cni-node", v1.IstioCNIConditionReady, v1.IstioCNIDaemonSetNotReady, v1.IstioCNIReasonReadinessCheckFailed)
 }
 
+func (r *Reconciler) determineVersionSkewCondition(ctx context.Context, cni *v1.IstioCNI) (v1.StatusCondition, error) {
+	c := v1.StatusCondition{Type: v1.IstioCNIConditionVersionSkew}
+	skewed, err := revision.CheckComponentSkew(ctx, r.Client, r.Config, istioversion.ComponentCNI, cni.Spec.Version)
+	if err != nil {
+		c.Status = metav1.ConditionUnknown
+		c.Reason = v1.IstioCNIReasonVersionSkewCheckFailed
+		c.Message = fmt.Sprintf("failed to check version skew: %v", err)
+		return c, err
+	}
+	if len(skewed) > 0 {
+		c.Status = metav1.ConditionTrue
+		c.Reason = v1.IstioCNIReasonUnsupportedVersionSkew
+		c.Message = strings.Join(skewed, "; ")
+	} else {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1.IstioCNIReasonSupportedVersionSkew
+		c.Message = "IstioCNI version is supported by all IstioRevisions that use it"
+	}
+	return c, nil
+}
+
 func (r *Reconciler) cniDaemonSetKey(cni *v1.IstioCNI) client.ObjectKey {
 	return client.ObjectKey{
 		Namespace: cni.Spec.Namespace,
@@ -225,6 +343,22 @@ func (r *Reconciler) mapNamespaceToReconcileRequest(ctx context.Context, ns clie
 	return requests
 }
 
+// mapToAllIstioCNIs enqueues all IstioCNIs. It's used for IstioRevisions, since any IstioRevision can depend on
+// them, and for IstioCNIs, since any IstioCNI can conflict with the node pool of another.
+func (r *Reconciler) mapToAllIstioCNIs(ctx context.Context, _ client.Object) []reconcile.Request {
+	cniList := v1.IstioCNIList{}
+	if err := r.Client.List(ctx, &cniList); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list IstioCNIs")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(cniList.Items))
+	for _, cni := range cniList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: cni.Name}})
+	}
+	return requests
+}
+
 func wrapEventHandler(logger logr.Logger, handler handler.EventHandler) handler.EventHandler {
 	return enqueuelogger.WrapIfNecessary(v1.IstioCNIKind, logger, handler)
 }
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
@@ -31,7 +32,9 @@ import (
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
@@ -663,16 +666,36 @@ func TestDetermineStatus(t *testing.T) {
 	cfg := newReconcilerTestConfig(t)
 
 	tests := []struct {
-		name         string
-		reconcileErr error
+		name               string
+		reconcileErr       error
+		previousRetryCount int32
+		expectedReason     v1.ConditionReason
+		expectedRetryCount int32
 	}{
 		{
-			name:         "no error",
-			reconcileErr: nil,
+			name:               "no error",
+			reconcileErr:       nil,
+			previousRetryCount: 2,
+			expectedRetryCount: 0,
+		},
+		{
+			name:               "reconcile error",
+			reconcileErr:       fmt.Errorf("some reconcile error"),
+			previousRetryCount: 2,
+			expectedReason:     v1.IstioCNIReasonReconcileError,
+			expectedRetryCount: 3,
 		},
 		{
-			name:         "reconcile error",
-			reconcileErr: fmt.Errorf("some reconcile error"),
+			name:               "resource conflict",
+			reconcileErr:       apierrors.NewAlreadyExists(appsv1.Resource("daemonsets"), "istio-cni-node"),
+			expectedReason:     reconciler.ReasonResourceConflict,
+			expectedRetryCount: 1,
+		},
+		{
+			name:               "validation error",
+			reconcileErr:       reconciler.NewValidationError("some validation error"),
+			previousRetryCount: 2,
+			expectedRetryCount: 0,
 		},
 	}
 
@@ -689,12 +712,25 @@ func TestDetermineStatus(t *testing.T) {
 					Name:       "my-cni",
 					Generation: 123,
 				},
+				Spec: v1.IstioCNISpec{
+					Version: "v1.30.3",
+				},
+				Status: v1.IstioCNIStatus{
+					RetryCount: tt.previousRetryCount,
+					Version:    "v1.30.2",
+				},
 			}
 
-			status, err := r.determineStatus(ctx, cni, tt.reconcileErr)
+			status, err := r.determineStatus(ctx, cni, nil, tt.reconcileErr)
 			g.Expect(err).ToNot(HaveOccurred())
 
 			g.Expect(status.ObservedGeneration).To(Equal(cni.Generation))
+			if tt.reconcileErr == nil {
+				g.Expect(status.Version).To(Equal("v1.30.3"))
+			} else {
+				g.Expect(status.Version).To(Equal("v1.30.2"), "the last installed version must be kept")
+			}
+			g.Expect(status.Platform).To(Equal(string(cfg.Platform)))
 
 			reconciledCondition := r.determineReconciledCondition(tt.reconcileErr)
 			readyCondition, err := r.determineReadyCondition(ctx, cni)
@@ -703,10 +739,56 @@ func TestDetermineStatus(t *testing.T) {
 			g.Expect(status.State).To(Equal(reconciler.DeriveState(v1.IstioCNIReasonHealthy, reconciledCondition, readyCondition)))
 			g.Expect(normalize(status.GetCondition(v1.IstioCNIConditionReconciled))).To(Equal(normalize(reconciledCondition)))
 			g.Expect(normalize(status.GetCondition(v1.IstioCNIConditionReady))).To(Equal(normalize(readyCondition)))
+			if tt.expectedReason != "" {
+				g.Expect(status.GetCondition(v1.IstioCNIConditionReconciled).Reason).To(Equal(tt.expectedReason))
+			}
+
+			g.Expect(status.RetryCount).To(Equal(tt.expectedRetryCount))
+			if tt.expectedRetryCount > 0 {
+				delay := cfg.BackoffPolicyFor(controllerName).Delay(tt.expectedRetryCount)
+				g.Expect(status.NextRetryTime).ToNot(BeNil())
+				g.Expect(status.NextRetryTime.Time).To(BeTemporally("~", time.Now().Add(delay), 2*time.Second))
+			} else {
+				g.Expect(status.NextRetryTime).To(BeNil())
+			}
 		})
 	}
 }
 
+func TestSuspend(t *testing.T) {
+	g := NewWithT(t)
+	cfg := newReconcilerTestConfig(t)
+
+	cni := &v1.IstioCNI{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Annotations: map[string]string{reconciler.ReconcileAnnotation: reconciler.ReconcilePaused},
+		},
+		Status: v1.IstioCNIStatus{
+			State:      v1.IstioCNIReasonReconcileError,
+			RetryCount: 2,
+			Drift:      &v1.DriftSummary{Resources: []string{"DaemonSet istio-cni/istio-cni-node"}},
+		},
+	}
+
+	ctx := context.TODO()
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cni).WithStatusSubresource(&v1.IstioCNI{}).Build()
+	r := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+	g.Expect(r.Suspend(ctx, cni)).To(Succeed())
+
+	updated := &v1.IstioCNI{}
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(cni), updated)).To(Succeed())
+	g.Expect(updated.Status.State).To(Equal(v1.IstioCNIReasonSuspended))
+	g.Expect(updated.Status.RetryCount).To(BeZero())
+	g.Expect(updated.Status.NextRetryTime).To(BeNil())
+	g.Expect(updated.Status.Drift).To(BeNil())
+
+	condition := updated.Status.GetCondition(v1.IstioCNIConditionReconciled)
+	g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(condition.Reason).To(Equal(v1.IstioCNIReasonSuspended))
+}
+
 func normalize(condition v1.StatusCondition) v1.StatusCondition {
 	condition.LastTransitionTime = metav1.Time{}
 	return condition
@@ -720,3 +802,86 @@ func newReconcilerTestConfig(t *testing.T) config.ReconcilerConfig {
 		MaxConcurrentReconciles: 1,
 	}
 }
+
+func TestDetermineVersionSkewCondition(t *testing.T) {
+	obj := &v1.IstioCNI{ObjectMeta: metav1.ObjectMeta{Name: "default"}, Spec: v1.IstioCNISpec{Version: istioversion.Default, Namespace: "istio-cni"}}
+
+	t.Run("no skewed revisions", func(t *testing.T) {
+		g := NewWithT(t)
+		cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme, nil)
+
+		c, err := r.determineVersionSkewCondition(context.TODO(), obj)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(c.Type).To(Equal(v1.IstioCNIConditionVersionSkew))
+		g.Expect(c.Status).To(Equal(metav1.ConditionFalse))
+		g.Expect(c.Reason).To(Equal(v1.IstioCNIReasonSupportedVersionSkew))
+	})
+
+	t.Run("check failed", func(t *testing.T) {
+		g := NewWithT(t)
+		cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithInterceptorFuncs(interceptor.Funcs{
+			List: func(_ context.Context, _ client.WithWatch, _ client.ObjectList, _ ...client.ListOption) error {
+				return fmt.Errorf("simulated error")
+			},
+		}).Build()
+		r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme, nil)
+
+		c, err := r.determineVersionSkewCondition(context.TODO(), obj)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(c.Status).To(Equal(metav1.ConditionUnknown))
+		g.Expect(c.Reason).To(Equal(v1.IstioCNIReasonVersionSkewCheckFailed))
+	})
+}
+
+func TestValidateNodePool(t *testing.T) {
+	newCNI := func(name, namespace string, created metav1.Time, selector map[string]string) *v1.IstioCNI {
+		return &v1.IstioCNI{
+			ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name), CreationTimestamp: created},
+			Spec: v1.IstioCNISpec{
+				Version:      istioversion.Default,
+				Namespace:    namespace,
+				NodeSelector: selector,
+			},
+		}
+	}
+	earlier := metav1.NewTime(metav1.Now().Add(-time.Hour))
+	general := newCNI("general", "istio-cni", earlier, map[string]string{"pool": "general"})
+
+	testCases := []struct {
+		name      string
+		cni       *v1.IstioCNI
+		expectErr string
+	}{
+		{
+			name: "disjoint node pools",
+			cni:  newCNI("gpu", "istio-cni-gpu", metav1.Now(), map[string]string{"pool": "gpu"}),
+		},
+		{
+			name:      "overlapping node pools",
+			cni:       newCNI("default", "istio-cni-all", metav1.Now(), nil),
+			expectErr: `nodeSelector overlaps with the nodeSelector of IstioCNI "general"`,
+		},
+		{
+			name:      "shared namespace",
+			cni:       newCNI("gpu", "istio-cni", metav1.Now(), map[string]string{"pool": "gpu"}),
+			expectErr: `namespace "istio-cni" is already used by IstioCNI "general"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(general, tc.cni).Build()
+			r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme, nil)
+
+			err := r.validateNodePool(context.TODO(), tc.cni)
+			if tc.expectErr == "" {
+				g.Expect(err).ToNot(HaveOccurred())
+			} else {
+				g.Expect(reconciler.IsValidationError(err)).To(BeTrue())
+				g.Expect(err.Error()).To(ContainSubstring(tc.expectErr))
+			}
+			g.Expect(r.validateNodePool(context.TODO(), general)).To(Succeed())
+		})
+	}
+}
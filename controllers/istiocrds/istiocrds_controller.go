@@ -0,0 +1,171 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istiocrds
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-logr/logr"
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
+	"github.com/istio-ecosystem/sail-operator/pkg/install"
+	"github.com/istio-ecosystem/sail-operator/pkg/istioversion"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// singletonRequest is the only request this controller reconciles, since all
+// Istio CRDs are reconciled together.
+var singletonRequest = reconcile.Request{NamespacedName: types.NamespacedName{Name: "istio-crds"}}
+
+// Reconciler installs and updates the Istio CRDs so that they match the highest
+// Istio version used by any Istio resource in the cluster. CRDs managed by OLM
+// are left alone; CRDs without an ownership label (e.g. those installed by the
+// operator's Helm chart) are adopted. When a stored version of a CRD becomes
+// deprecated or is no longer served, the stored objects are migrated to the
+// current storage version and status.storedVersions is pruned.
+type Reconciler struct {
+	Config config.ReconcilerConfig
+	client.Client
+	Scheme     *runtime.Scheme
+	crdManager *install.CRDManager
+}
+
+func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *runtime.Scheme, crdFS fs.FS) *Reconciler {
+	return &Reconciler{
+		Config:     cfg,
+		Client:     client,
+		Scheme:     scheme,
+		crdManager: install.NewCRDManager(client, crdFS, constants.KubernetesAppManagedByKey, constants.ManagedByLabelValue, true),
+	}
+}
+
+// +kubebuilder:rbac:groups="apiextensions.k8s.io",resources=customresourcedefinitions,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="apiextensions.k8s.io",resources=customresourcedefinitions/status,verbs=update;patch
+// +kubebuilder:rbac:groups=extensions.istio.io,resources=trafficextensions;wasmplugins,verbs=get;list;update
+// +kubebuilder:rbac:groups=networking.istio.io,resources=destinationrules;envoyfilters;gateways;proxyconfigs;serviceentries;sidecars;virtualservices;workloadentries;workloadgroups,verbs=get;list;update
+// +kubebuilder:rbac:groups=security.istio.io,resources=authorizationpolicies;peerauthentications;requestauthentications,verbs=get;list;update
+// +kubebuilder:rbac:groups=telemetry.istio.io,resources=telemetries,verbs=get;list;update
+
+// Reconcile installs or updates the Istio CRDs and migrates their stored versions.
+func (r *Reconciler) Reconcile(ctx context.Context, _ reconcile.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	version, err := r.highestIstioVersion(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if version == "" {
+		log.Info("No Istio resources found; skipping reconciliation of Istio CRDs")
+		return ctrl.Result{}, nil
+	}
+
+	log.Info("Reconciling Istio CRDs", "version", version)
+	infos, err := r.crdManager.Reconcile(ctx, install.Options{
+		ManageCRDs:            true,
+		IncludeAllCRDs:        true,
+		MigrateStoredVersions: true,
+	}, version)
+	for _, info := range infos {
+		if len(info.MigratedVersions) > 0 {
+			log.Info("Migrated stored versions of CRD", "crd", info.Name, "versions", info.MigratedVersions)
+		}
+	}
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to reconcile Istio CRDs: %w", err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// highestIstioVersion returns the highest version referenced by any Istio
+// resource, or an empty string if there are none.
+func (r *Reconciler) highestIstioVersion(ctx context.Context) (string, error) {
+	log := logf.FromContext(ctx)
+
+	istioList := &v1.IstioList{}
+	if err := r.Client.List(ctx, istioList); err != nil {
+		return "", fmt.Errorf("failed to list Istio resources: %w", err)
+	}
+
+	var highest *semver.Version
+	var highestName string
+	for _, istio := range istioList.Items {
+		name, err := istioversion.Resolve(istio.Spec.Version)
+		if err != nil {
+			log.Info("Ignoring Istio resource with unknown version", "Istio", istio.Name, "version", istio.Spec.Version)
+			continue
+		}
+		v, err := semver.NewVersion(name)
+		if err != nil {
+			log.Info("Ignoring Istio resource with invalid version", "Istio", istio.Name, "version", name)
+			continue
+		}
+		if highest == nil || v.GreaterThan(highest) {
+			highest, highestName = v, name
+		}
+	}
+	return highestName, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	logger := mgr.GetLogger().WithName("ctrlr").WithName("istiocrds")
+
+	// all watch events enqueue the same request, since the CRDs are always reconciled together
+	singletonHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(
+		func(context.Context, client.Object) []reconcile.Request {
+			return []reconcile.Request{singletonRequest}
+		}))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			LogConstructor: func(*reconcile.Request) logr.Logger {
+				return logger
+			},
+			// the single request can't be reconciled concurrently anyway
+			MaxConcurrentReconciles: 1,
+		}).
+		Named("istiocrds").
+		// +lint-watches:ignore: CustomResourceDefinition (not found in charts, but this is the main resource managed by this controller)
+		Watches(&apiextensionsv1.CustomResourceDefinition{}, singletonHandler, builder.WithPredicates(istioCRDPredicate())).
+		Watches(&v1.Istio{}, singletonHandler, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
+
+func istioCRDPredicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
+		return ok && strings.HasSuffix(crd.Spec.Group, ".istio.io")
+	})
+}
+
+func wrapEventHandler(logger logr.Logger, handler handler.EventHandler) handler.EventHandler {
+	return enqueuelogger.WrapIfNecessary("IstioCRDs", logger, handler)
+}
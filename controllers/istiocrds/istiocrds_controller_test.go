@@ -0,0 +1,168 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istiocrds
+
+import (
+	"testing"
+	"testing/fstest"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/istioversion"
+	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var crdFS = fstest.MapFS{
+	"networking.istio.io_sidecars.yaml": &fstest.MapFile{Data: []byte(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: sidecars.networking.istio.io
+spec:
+  group: networking.istio.io
+  names:
+    kind: Sidecar
+    listKind: SidecarList
+    plural: sidecars
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+`)},
+	"sailoperator.io_istios.yaml": &fstest.MapFile{Data: []byte(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: istios.sailoperator.io
+spec:
+  group: sailoperator.io
+  names:
+    kind: Istio
+    plural: istios
+  scope: Cluster
+  versions:
+  - name: v1
+    served: true
+    storage: true
+`)},
+}
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name          string
+		objects       []client.Object
+		expectVersion string
+	}{
+		{
+			name: "no Istio resources",
+		},
+		{
+			name: "single Istio",
+			objects: []client.Object{
+				newIstio("default", istioversion.Base),
+			},
+			expectVersion: istioversion.Base,
+		},
+		{
+			name: "highest version wins",
+			objects: []client.Object{
+				newIstio("old", istioversion.Base),
+				newIstio("new", istioversion.New),
+			},
+			expectVersion: istioversion.New,
+		},
+		{
+			name: "unknown version is ignored",
+			objects: []client.Object{
+				newIstio("default", istioversion.Base),
+				newIstio("broken", "v0.0.1"),
+			},
+			expectVersion: istioversion.Base,
+		},
+		{
+			name: "unlabeled CRD is adopted",
+			objects: []client.Object{
+				newIstio("default", istioversion.Base),
+				&apiextensionsv1.CustomResourceDefinition{
+					ObjectMeta: metav1.ObjectMeta{Name: "sidecars.networking.istio.io"},
+				},
+			},
+			expectVersion: istioversion.Base,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			cl := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(tc.objects...).
+				WithStatusSubresource(&apiextensionsv1.CustomResourceDefinition{}).
+				Build()
+			r := NewReconciler(config.ReconcilerConfig{}, cl, scheme.Scheme, crdFS)
+
+			_, err := r.Reconcile(t.Context(), singletonRequest)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			crd := &apiextensionsv1.CustomResourceDefinition{}
+			err = cl.Get(t.Context(), client.ObjectKey{Name: "sidecars.networking.istio.io"}, crd)
+			if tc.expectVersion == "" {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(crd.Labels).To(HaveKeyWithValue(constants.KubernetesAppManagedByKey, constants.ManagedByLabelValue))
+			g.Expect(crd.Annotations).To(HaveKeyWithValue(constants.KubernetesAppVersionKey, tc.expectVersion))
+
+			g.Expect(cl.Get(t.Context(), client.ObjectKey{Name: "istios.sailoperator.io"}, crd)).NotTo(Succeed(),
+				"sailoperator.io CRDs must not be installed by this controller")
+		})
+	}
+}
+
+func TestReconcileSkipsCRDOwnedByOthers(t *testing.T) {
+	g := NewWithT(t)
+	existing := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "sidecars.networking.istio.io",
+			Labels: map[string]string{constants.KubernetesAppManagedByKey: "Helm"},
+		},
+	}
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(newIstio("default", istioversion.Base), existing).
+		Build()
+	r := NewReconciler(config.ReconcilerConfig{}, cl, scheme.Scheme, crdFS)
+
+	_, err := r.Reconcile(t.Context(), singletonRequest)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	g.Expect(cl.Get(t.Context(), client.ObjectKey{Name: existing.Name}, crd)).To(Succeed())
+	g.Expect(crd.Labels).To(HaveKeyWithValue(constants.KubernetesAppManagedByKey, "Helm"))
+	g.Expect(crd.Annotations).NotTo(HaveKey(constants.KubernetesAppVersionKey))
+}
+
+func newIstio(name, version string) *v1.Istio {
+	return &v1.Istio{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1.IstioSpec{Version: version},
+	}
+}
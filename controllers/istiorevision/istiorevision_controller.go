@@ -19,6 +19,10 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
@@ -27,18 +31,22 @@ import (
 	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
 	"github.com/istio-ecosystem/sail-operator/pkg/errlist"
 	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"github.com/istio-ecosystem/sail-operator/pkg/istioversion"
+	"github.com/istio-ecosystem/sail-operator/pkg/kube"
 	predicate2 "github.com/istio-ecosystem/sail-operator/pkg/predicate"
 	sharedreconcile "github.com/istio-ecosystem/sail-operator/pkg/reconcile"
 	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
 	"github.com/istio-ecosystem/sail-operator/pkg/revision"
 	"github.com/istio-ecosystem/sail-operator/pkg/validation"
 	"github.com/istio-ecosystem/sail-operator/pkg/watches"
+	configv1 "github.com/openshift/api/config/v1"
 	admissionv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -48,29 +56,73 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"istio.io/istio/pkg/ptr"
+	"istio.io/istio/pkg/util/sets"
 )
 
 const (
-	istioCniName = "default"
-	ztunnelName  = "default"
+	// remoteUsageCheckInterval is how often the operator checks whether the revision of an external control plane
+	// is used by workloads in the remote cluster, since it can't watch the pods and namespaces in that cluster.
+	remoteUsageCheckInterval = 5 * time.Minute
 )
 
+// errRemoteKubeconfigNotFound is returned when the kubeconfig Secret for the remote cluster of an external
+// control plane doesn't exist.
+var errRemoteKubeconfigNotFound = errors.New("kubeconfig Secret for the remote cluster not found")
+
+const controllerName = "istiorevision"
+
 // Reconciler reconciles an IstioRevision object
 type Reconciler struct {
 	client.Client
-	Config       config.ReconcilerConfig
-	Scheme       *runtime.Scheme
-	ChartManager *helm.ChartManager
+	Config          config.ReconcilerConfig
+	Scheme          *runtime.Scheme
+	ChartManager    *helm.ChartManager
+	newRemoteClient kube.RemoteClientFactory
+	remote          *remoteClusterCache
+}
+
+// remoteClusterCache holds the clients for the remote clusters of external control planes and the results of the
+// last checks whether their revisions are used in those clusters. Each check lists all namespaces and pods in the
+// remote cluster, so it's only repeated every remoteUsageCheckInterval rather than on every reconciliation.
+type remoteClusterCache struct {
+	mu      sync.Mutex
+	clients map[types.NamespacedName]remoteClient // keyed by the kubeconfig Secret
+	usage   map[types.UID]remoteUsage             // keyed by the IstioRevision
+}
+
+// remoteClient is a client created from a specific version of a kubeconfig Secret.
+type remoteClient struct {
+	secretUID             types.UID
+	secretResourceVersion string
+	client.Reader
+}
+
+// remoteUsage is the result of checking whether a revision is used in its remote cluster.
+type remoteUsage struct {
+	secretUID             types.UID
+	secretResourceVersion string
+	referenced            bool
+	checkTime             time.Time
+}
+
+func newRemoteClusterCache() *remoteClusterCache {
+	return &remoteClusterCache{
+		clients: map[types.NamespacedName]remoteClient{},
+		usage:   map[types.UID]remoteUsage{},
+	}
 }
 
 func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *runtime.Scheme, chartManager *helm.ChartManager) *Reconciler {
 	return &Reconciler{
-		Config:       cfg,
-		Client:       client,
-		Scheme:       scheme,
-		ChartManager: chartManager,
+		Config:          cfg,
+		Client:          client,
+		Scheme:          scheme,
+		ChartManager:    chartManager,
+		newRemoteClient: kube.NewRemoteClient,
+		remote:          newRemoteClusterCache(),
 	}
 }
 
@@ -98,12 +150,43 @@ func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *ru
 func (r *Reconciler) Reconcile(ctx context.Context, rev *v1.IstioRevision) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
 
+	if rev.Status.State == v1.IstioRevisionReasonSuspended {
+		// reconciliation was resumed; record the changes made while it was paused before they're reverted. The
+		// summary is stored in the current status, so that determineStatus carries it over to the new one.
+		log.Info("Reconciliation resumed. Checking for drift.")
+		rev.Status.Drift = r.detectDrift(ctx, rev)
+	}
+
 	reconcileErr := r.doReconcile(ctx, rev)
 
 	log.Info("Reconciliation done. Updating status.")
 	statusErr := r.updateStatus(ctx, rev, reconcileErr)
+	var result ctrl.Result
+	if revision.IsExternalControlPlane(rev.Spec.Values) {
+		result.RequeueAfter = remoteUsageCheckInterval
+	}
+
+	return result, errors.Join(reconcileErr, statusErr)
+}
+
+// Suspend updates the status of an IstioRevision whose reconciliation is paused.
+func (r *Reconciler) Suspend(ctx context.Context, rev *v1.IstioRevision) error {
+	status := *rev.Status.DeepCopy()
+	status.SetCondition(reconciler.SuspendedCondition(v1.IstioRevisionConditionReconciled, v1.IstioRevisionReasonSuspended))
+	status.State = v1.IstioRevisionReasonSuspended
+	status.RetryCount, status.NextRetryTime = 0, nil
+	status.Drift = nil
+	return reconciler.UpdateStatus(ctx, r.Client, rev, rev.Status, status, nil)
+}
 
-	return ctrl.Result{}, errors.Join(reconcileErr, statusErr)
+// detectDrift returns a summary of the resources that were modified while reconciliation was paused.
+func (r *Reconciler) detectDrift(ctx context.Context, rev *v1.IstioRevision) *v1.DriftSummary {
+	drift, err := r.newIstiodReconciler().DetectDrift(ctx, rev.Spec.Namespace, rev.Name)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to detect drift")
+		return nil
+	}
+	return reconciler.NewDriftSummary(drift)
 }
 
 func (r *Reconciler) doReconcile(ctx context.Context, rev *v1.IstioRevision) error {
@@ -117,6 +200,11 @@ func (r *Reconciler) doReconcile(ctx context.Context, rev *v1.IstioRevision) err
 	if err := r.validateNoTagConflict(ctx, rev); err != nil {
 		return err
 	}
+	if r.Config.StrictVersionSkew && rev.Spec.Version != rev.Status.Version {
+		if err := r.validateVersionSkew(ctx, rev); err != nil {
+			return err
+		}
+	}
 
 	// General validations
 	if err := istiodReconciler.Validate(ctx, rev.Spec.Version, rev.Spec.Namespace, rev.Spec.Values); err != nil {
@@ -135,7 +223,45 @@ func (r *Reconciler) doReconcile(ctx context.Context, rev *v1.IstioRevision) err
 	return istiodReconciler.Install(ctx, rev.Spec.Version, rev.Spec.Namespace, rev.Spec.Values, rev.Name, &ownerReference)
 }
 
+// validateVersionSkew checks that the revision supports the versions of the IstioCNI and ZTunnel instances it
+// depends on. Components that don't exist yet aren't checked. It's only called when the version of the revision
+// changed, so that an installed revision keeps being reconciled, e.g. when the version of an IstioCNI changes.
+func (r *Reconciler) validateVersionSkew(ctx context.Context, rev *v1.IstioRevision) error {
+	dependsOnCNI, dependsOnZTunnel := revision.DependsOnIstioCNI(rev, r.Config), revision.DependsOnZTunnel(rev, r.Config)
+	if !dependsOnCNI && !dependsOnZTunnel {
+		return nil
+	}
+
+	nodes, err := r.getWorkloadNodes(ctx, rev)
+	if err != nil {
+		return err
+	}
+
+	var skewed []string
+	if dependsOnCNI {
+		instances, err := r.listIstioCNIs(ctx)
+		if err != nil {
+			return err
+		}
+		covering, _ := selectDependencyInstances(instances, nodes)
+		skewed = append(skewed, checkVersionSkew(istioversion.ComponentCNI, rev, covering)...)
+	}
+	if dependsOnZTunnel {
+		instances, err := r.listZTunnels(ctx)
+		if err != nil {
+			return err
+		}
+		covering, _ := selectDependencyInstances(instances, nodes)
+		skewed = append(skewed, checkVersionSkew(istioversion.ComponentZTunnel, rev, covering)...)
+	}
+	if len(skewed) > 0 {
+		return reconciler.NewValidationError("unsupported version skew: " + strings.Join(skewed, "; "))
+	}
+	return nil
+}
+
 func (r *Reconciler) Finalize(ctx context.Context, rev *v1.IstioRevision) error {
+	r.remote.forget(rev)
 	istiodReconciler := r.newIstiodReconciler()
 	return istiodReconciler.Uninstall(ctx, rev.Spec.Namespace, rev.Name)
 }
@@ -262,10 +388,11 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 				return log
 			},
 			MaxConcurrentReconciles: r.Config.MaxConcurrentReconciles,
+			RateLimiter:             reconciler.RateLimiter(r.Config.BackoffPolicyFor(controllerName)),
 		}).
 		// we use the Watches function instead of For(), so that we can wrap the handler so that events that cause the object to be enqueued are logged
-		Watches(&v1.IstioRevision{}, mainObjectHandler).
-		Named("istiorevision")
+		Watches(&v1.IstioRevision{}, mainObjectHandler, builder.WithPredicates(watches.AsPredicate(watches.IgnoreRetryStatusChanges()))).
+		Named(controllerName)
 
 	// +lint-watches:ignore: Endpoints (older versions of istiod chart create Endpoints for remote installs, but this controller watches EndpointSlices)
 	// +lint-watches:ignore: EndpointSlice (istiod chart creates Endpoints for remote installs, but this controller watches EndpointSlices)
@@ -274,6 +401,12 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 	watches.RegisterOwnedWatches(b, watches.IstiodWatches, ownedResourceHandler, handlerOverrides, predicate2.IgnoreUpdateWhenAnnotation())
 
+	// reconcile all IstioRevisions when the platform TLS config changes, so that their status reports the new settings
+	if r.Config.TLSConfig != nil {
+		b.WatchesRawSource(source.Channel(r.Config.TLSConfig.Subscribe(),
+			wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapToAllRevisions))))
+	}
+
 	return b.
 		// +lint-watches:ignore: Namespace (not found in charts, but must be watched to reconcile IstioRevision when its namespace is created)
 		Watches(&corev1.Namespace{}, nsHandler,
@@ -285,7 +418,8 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(&v1.IstioCNI{}, istioCniHandler).
 		Watches(&v1.ZTunnel{}, ztunnelHandler).
 		// +lint-watches:ignore: CustomResourceDefinition (prevents `make lint-watches` from bugging us about CRDs)
-		Complete(reconciler.NewStandardReconcilerWithFinalizer[*v1.IstioRevision](r.Client, r.Reconcile, r.Finalize, constants.FinalizerName))
+		Complete(reconciler.NewStandardReconcilerWithFinalizer[*v1.IstioRevision](r.Client, r.Reconcile, r.Finalize, constants.FinalizerName).
+			WithSuspendFunc(r.Suspend))
 }
 
 func (r *Reconciler) determineStatus(ctx context.Context, rev *v1.IstioRevision, reconcileErr error) (v1.IstioRevisionStatus, error) {
@@ -301,14 +435,45 @@ func (r *Reconciler) determineStatus(ctx context.Context, rev *v1.IstioRevision,
 
 	status := *rev.Status.DeepCopy()
 	status.ObservedGeneration = rev.Generation
+	retry := reconciler.NextRetry(r.Config.BackoffPolicyFor(controllerName), rev.Status.RetryCount, reconcileErr)
+	status.RetryCount, status.NextRetryTime = retry.Count, retry.Time
+	status.Platform = string(r.Config.Platform)
 	status.SetCondition(reconciledCondition)
 	status.SetCondition(readyCondition)
 	status.SetCondition(dependenciesHealthyCondition)
 	status.SetCondition(inUseCondition)
+	status.TLSProfile = tlsProfileStatus(rev.Spec.Values)
 	status.State = reconciler.DeriveState(v1.IstioRevisionReasonHealthy, reconciledCondition, readyCondition, dependenciesHealthyCondition)
+	if reconcileErr == nil {
+		status.Version = rev.Spec.Version
+	}
 	return status, errs.Error()
 }
 
+// tlsProfileStatus returns the TLS settings that are in effect for the revision, which are the ones in
+// values.meshConfig.tlsDefaults. When the revision is created by an Istio, these include the settings of the
+// TLS profile applied by the operator.
+func tlsProfileStatus(values *v1.Values) *v1.TLSProfileStatus {
+	if values == nil || values.MeshConfig == nil || values.MeshConfig.TlsDefaults == nil {
+		return nil
+	}
+	tlsDefaults := values.MeshConfig.TlsDefaults
+	status := &v1.TLSProfileStatus{
+		Ciphers: tlsDefaults.CipherSuites,
+		Curves:  tlsDefaults.EcdhCurves,
+	}
+	switch tlsDefaults.MinProtocolVersion {
+	case v1.MeshConfigTLSConfigTLSProtocolTlsv12:
+		status.MinTLSVersion = string(configv1.VersionTLS12)
+	case v1.MeshConfigTLSConfigTLSProtocolTlsv13:
+		status.MinTLSVersion = string(configv1.VersionTLS13)
+	}
+	if status.MinTLSVersion == "" && len(status.Ciphers) == 0 && len(status.Curves) == 0 {
+		return nil
+	}
+	return status
+}
+
 func (r *Reconciler) updateStatus(ctx context.Context, rev *v1.IstioRevision, reconcileErr error) error {
 	status, err := r.determineStatus(ctx, rev, reconcileErr)
 	return reconciler.UpdateStatus(ctx, r.Client, rev, rev.Status, status, err)
@@ -326,8 +491,7 @@ func (r *Reconciler) determineReconciledCondition(err error) v1.StatusCondition
 			c.Reason = v1.IstioRevisionReasonNameAlreadyExists
 			c.Message = err.Error()
 		default:
-			c.Reason = v1.IstioRevisionReasonReconcileError
-			c.Message = fmt.Sprintf("error reconciling resource: %v", err)
+			c.Reason, c.Message = reconciler.DescribeReconcileError(err, v1.IstioRevisionReasonReconcileError)
 		}
 	}
 	return c
@@ -372,6 +536,9 @@ func (r *Reconciler) determineReadyCondition(ctx context.Context, rev *v1.IstioR
 			case "false":
 				c.Reason = v1.IstioRevisionReasonRemoteIstiodNotReady
 				c.Message = "readiness probe on remote istiod failed"
+				if reason := webhook.Annotations[constants.WebhookReadinessProbeStatusReasonAnnotationKey]; reason != "" {
+					c.Message += ": " + reason + "; see status.remoteProbes for details"
+				}
 			default:
 				c.Reason = v1.IstioRevisionReasonRemoteIstiodNotReady
 				c.Message = fmt.Sprintf("invalid or missing annotation %s on MutatingWebhookConfiguration %s",
@@ -391,71 +558,230 @@ func (r *Reconciler) determineReadyCondition(ctx context.Context, rev *v1.IstioR
 }
 
 func (r *Reconciler) determineDependenciesHealthyCondition(ctx context.Context, rev *v1.IstioRevision) (v1.StatusCondition, error) {
-	if revision.DependsOnIstioCNI(rev, r.Config) {
-		cni := v1.IstioCNI{}
-		if err := r.Client.Get(ctx, client.ObjectKey{Name: istioCniName}, &cni); err != nil {
-			if apierrors.IsNotFound(err) {
-				return v1.StatusCondition{
-					Type:    v1.IstioRevisionConditionDependenciesHealthy,
-					Status:  metav1.ConditionFalse,
-					Reason:  v1.IstioRevisionReasonIstioCNINotFound,
-					Message: "IstioCNI resource does not exist",
-				}, nil
-			}
+	healthy := v1.StatusCondition{
+		Type:   v1.IstioRevisionConditionDependenciesHealthy,
+		Status: metav1.ConditionTrue,
+		Reason: v1.ConditionReason(v1.IstioRevisionConditionDependenciesHealthy),
+	}
+
+	dependsOnCNI, dependsOnZTunnel := revision.DependsOnIstioCNI(rev, r.Config), revision.DependsOnZTunnel(rev, r.Config)
+	if !dependsOnCNI && !dependsOnZTunnel {
+		return healthy, nil
+	}
+
+	nodes, err := r.getWorkloadNodes(ctx, rev)
+	if err != nil {
+		return v1.StatusCondition{
+			Type:    v1.IstioRevisionConditionDependenciesHealthy,
+			Status:  metav1.ConditionUnknown,
+			Reason:  v1.IstioRevisionDependencyCheckFailed,
+			Message: fmt.Sprintf("failed to determine the nodes running the revision's workloads: %v", err),
+		}, err
+	}
 
+	if dependsOnCNI {
+		instances, err := r.listIstioCNIs(ctx)
+		if err != nil {
 			return v1.StatusCondition{
 				Type:    v1.IstioRevisionConditionDependenciesHealthy,
 				Status:  metav1.ConditionUnknown,
 				Reason:  v1.IstioRevisionDependencyCheckFailed,
 				Message: fmt.Sprintf("failed to get IstioCNI status: %v", err),
-			}, fmt.Errorf("get failed: %w", err)
+			}, err
 		}
+		if c := checkDependency(istioversion.ComponentCNI, rev, instances, nodes,
+			v1.IstioRevisionReasonIstioCNINotFound, v1.IstioRevisionReasonIstioCNINotHealthy); c != nil {
+			return *c, nil
+		}
+	}
 
-		if cni.Status.State != v1.IstioCNIReasonHealthy {
-			return v1.StatusCondition{
-				Type:    v1.IstioRevisionConditionDependenciesHealthy,
-				Status:  metav1.ConditionFalse,
-				Reason:  v1.IstioRevisionReasonIstioCNINotHealthy,
-				Message: "IstioCNI resource status indicates that the component is not healthy",
-			}, nil
-		}
-	}
-
-	if revision.DependsOnZTunnel(rev, r.Config) {
-		ztunnel := v1.ZTunnel{}
-		if err := r.Client.Get(ctx, client.ObjectKey{Name: ztunnelName}, &ztunnel); err != nil {
-			if apierrors.IsNotFound(err) {
-				return v1.StatusCondition{
-					Type:    v1.IstioRevisionConditionDependenciesHealthy,
-					Status:  metav1.ConditionFalse,
-					Reason:  v1.IstioRevisionReasonZTunnelNotFound,
-					Message: "ZTunnel resource does not exist",
-				}, nil
-			}
-
+	if dependsOnZTunnel {
+		instances, err := r.listZTunnels(ctx)
+		if err != nil {
 			return v1.StatusCondition{
 				Type:    v1.IstioRevisionConditionDependenciesHealthy,
 				Status:  metav1.ConditionUnknown,
 				Reason:  v1.IstioRevisionDependencyCheckFailed,
 				Message: fmt.Sprintf("failed to get ZTunnel status: %v", err),
-			}, fmt.Errorf("get failed: %w", err)
+			}, err
 		}
-
-		if ztunnel.Status.State != v1.ZTunnelReasonHealthy {
-			return v1.StatusCondition{
-				Type:    v1.IstioRevisionConditionDependenciesHealthy,
-				Status:  metav1.ConditionFalse,
-				Reason:  v1.IstioRevisionReasonZTunnelNotHealthy,
-				Message: "ZTunnel resource status indicates that the component is not healthy",
-			}, nil
+		if c := checkDependency(istioversion.ComponentZTunnel, rev, instances, nodes,
+			v1.IstioRevisionReasonZTunnelNotFound, v1.IstioRevisionReasonZTunnelNotHealthy); c != nil {
+			return *c, nil
 		}
 	}
 
-	return v1.StatusCondition{
+	return healthy, nil
+}
+
+// dependencyInstance is an IstioCNI or ZTunnel instance that a revision can depend on.
+type dependencyInstance struct {
+	name         string
+	nodeSelector map[string]string
+	version      string
+	healthy      bool
+}
+
+func (r *Reconciler) listIstioCNIs(ctx context.Context) ([]dependencyInstance, error) {
+	cniList := v1.IstioCNIList{}
+	if err := r.Client.List(ctx, &cniList); err != nil {
+		return nil, fmt.Errorf("failed to list IstioCNIs: %w", err)
+	}
+	instances := make([]dependencyInstance, 0, len(cniList.Items))
+	for _, cni := range cniList.Items {
+		instances = append(instances, dependencyInstance{
+			name:         cni.Name,
+			nodeSelector: cni.Spec.NodeSelector,
+			version:      cni.Spec.Version,
+			healthy:      cni.Status.State == v1.IstioCNIReasonHealthy,
+		})
+	}
+	return instances, nil
+}
+
+func (r *Reconciler) listZTunnels(ctx context.Context) ([]dependencyInstance, error) {
+	ztunnelList := v1.ZTunnelList{}
+	if err := r.Client.List(ctx, &ztunnelList); err != nil {
+		return nil, fmt.Errorf("failed to list ZTunnels: %w", err)
+	}
+	instances := make([]dependencyInstance, 0, len(ztunnelList.Items))
+	for _, ztunnel := range ztunnelList.Items {
+		instances = append(instances, dependencyInstance{
+			name:         ztunnel.Name,
+			nodeSelector: ztunnel.Spec.NodeSelector,
+			version:      ztunnel.Spec.Version,
+			healthy:      ztunnel.Status.State == v1.ZTunnelReasonHealthy,
+		})
+	}
+	return instances, nil
+}
+
+// checkDependency returns a DependenciesHealthy condition that describes why the IstioCNI or ZTunnel instances
+// running on the given nodes don't meet the needs of the revision, or nil if they do.
+func checkDependency(component istioversion.Component, rev *v1.IstioRevision, instances []dependencyInstance, nodes []corev1.Node,
+	notFoundReason, notHealthyReason v1.IstioRevisionConditionReason,
+) *v1.StatusCondition {
+	c := &v1.StatusCondition{
 		Type:   v1.IstioRevisionConditionDependenciesHealthy,
-		Status: metav1.ConditionTrue,
-		Reason: v1.ConditionReason(v1.IstioRevisionConditionDependenciesHealthy),
-	}, nil
+		Status: metav1.ConditionFalse,
+	}
+	if len(instances) == 0 {
+		c.Reason = notFoundReason
+		c.Message = fmt.Sprintf("%s resource does not exist", component)
+		return c
+	}
+
+	covering, uncovered := selectDependencyInstances(instances, nodes)
+	if len(uncovered) > 0 {
+		c.Reason = notFoundReason
+		c.Message = fmt.Sprintf("no %s resource runs on the nodes %s, which host workloads of this revision",
+			component, strings.Join(uncovered, ", "))
+		return c
+	}
+
+	var unhealthy []string
+	for _, instance := range covering {
+		if !instance.healthy {
+			unhealthy = append(unhealthy, instance.name)
+		}
+	}
+	if len(unhealthy) > 0 {
+		c.Reason = notHealthyReason
+		c.Message = fmt.Sprintf("status of %s %s indicates that the component is not healthy", component, strings.Join(unhealthy, ", "))
+		return c
+	}
+
+	if skewed := checkVersionSkew(component, rev, covering); len(skewed) > 0 {
+		c.Reason = v1.IstioRevisionReasonVersionSkew
+		c.Message = strings.Join(skewed, "; ")
+		return c
+	}
+	return nil
+}
+
+// checkVersionSkew returns a message for each instance whose version isn't supported by the revision.
+func checkVersionSkew(component istioversion.Component, rev *v1.IstioRevision, instances []dependencyInstance) []string {
+	var skewed []string
+	for _, instance := range instances {
+		if err := istioversion.CheckSkew(component, rev.Spec.Version, instance.version); err != nil {
+			skewed = append(skewed, fmt.Sprintf("%s %s: %v", component, instance.name, err))
+		}
+	}
+	return skewed
+}
+
+// selectDependencyInstances returns the instances that run on at least one of the nodes, along with the names of
+// the nodes that no instance runs on. When there are no nodes, i.e. the revision has no workloads yet, all
+// instances are returned.
+func selectDependencyInstances(instances []dependencyInstance, nodes []corev1.Node) ([]dependencyInstance, []string) {
+	if len(nodes) == 0 {
+		return instances, nil
+	}
+
+	var covering []dependencyInstance
+	var uncovered []string
+	coveredNodes := sets.New[string]()
+	for _, instance := range instances {
+		selector := labels.SelectorFromSet(instance.nodeSelector)
+		runsOnNodes := false
+		for _, node := range nodes {
+			if selector.Matches(labels.Set(node.Labels)) {
+				coveredNodes.Insert(node.Name)
+				runsOnNodes = true
+			}
+		}
+		if runsOnNodes {
+			covering = append(covering, instance)
+		}
+	}
+	for _, node := range nodes {
+		if !coveredNodes.Contains(node.Name) {
+			uncovered = append(uncovered, node.Name)
+		}
+	}
+	return covering, uncovered
+}
+
+// getWorkloadNodes returns the nodes that run pods which use the revision, sorted by name.
+func (r *Reconciler) getWorkloadNodes(ctx context.Context, rev *v1.IstioRevision) ([]corev1.Node, error) {
+	nsList := corev1.NamespaceList{}
+	if err := r.Client.List(ctx, &nsList); err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	nsMap := map[string]corev1.Namespace{}
+	for _, ns := range nsList.Items {
+		nsMap[ns.Name] = ns
+	}
+
+	podList := corev1.PodList{}
+	if err := r.Client.List(ctx, &podList); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	nodeNames := sets.New[string]()
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if ns, found := nsMap[pod.Namespace]; found && (namespaceReferencesRevision(ns, rev) || podReferencesRevision(pod, ns, rev)) {
+			nodeNames.Insert(pod.Spec.NodeName)
+		}
+	}
+	if nodeNames.Len() == 0 {
+		return nil, nil
+	}
+
+	nodeList := corev1.NodeList{}
+	if err := r.Client.List(ctx, &nodeList); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	var nodes []corev1.Node
+	for _, node := range nodeList.Items {
+		if nodeNames.Contains(node.Name) {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes, nil
 }
 
 func (r *Reconciler) determineInUseCondition(ctx context.Context, rev *v1.IstioRevision) (v1.StatusCondition, error) {
@@ -473,6 +799,14 @@ func (r *Reconciler) determineInUseCondition(ctx context.Context, rev *v1.IstioR
 			c.Message = "Not referenced by any pod or namespace"
 		}
 		return c, nil
+	} else if errors.Is(err, errRemoteKubeconfigNotFound) {
+		// the revision must not be pruned, but this isn't an error; the usage is checked again when the
+		// revision is requeued
+		secret := revision.ExternalKubeconfigSecret(rev)
+		c.Status = metav1.ConditionUnknown
+		c.Reason = v1.IstioRevisionReasonRemoteKubeconfigNotFound
+		c.Message = fmt.Sprintf("cannot determine if revision is used in the remote cluster: Secret %s not found", secret)
+		return c, nil
 	}
 	c.Status = metav1.ConditionUnknown
 	c.Reason = v1.IstioRevisionReasonUsageCheckFailed
@@ -482,8 +816,6 @@ func (r *Reconciler) determineInUseCondition(ctx context.Context, rev *v1.IstioR
 
 func (r *Reconciler) isRevisionReferenced(ctx context.Context, rev *v1.IstioRevision) (bool, error) {
 	log := logf.FromContext(ctx)
-	nsList := corev1.NamespaceList{}
-	nsMap := map[string]corev1.Namespace{}
 	// if an IstioRevision is referenced by a revisionTag, it's considered as InUse
 	revisionTagList := v1.IstioRevisionTagList{}
 	if err := r.Client.List(ctx, &revisionTagList); err != nil {
@@ -496,7 +828,36 @@ func (r *Reconciler) isRevisionReferenced(ctx context.Context, rev *v1.IstioRevi
 		}
 	}
 
-	if err := r.Client.List(ctx, &nsList); err != nil { // TODO: can we optimize this by specifying a label selector
+	isReferenced, err := isReferencedByWorkloads(ctx, r.Client, rev)
+	if err != nil || isReferenced {
+		return isReferenced, err
+	}
+
+	if rev.Name == v1.DefaultRevision && rev.Spec.Values != nil &&
+		rev.Spec.Values.SidecarInjectorWebhook != nil &&
+		rev.Spec.Values.SidecarInjectorWebhook.EnableNamespacesByDefault != nil &&
+		*rev.Spec.Values.SidecarInjectorWebhook.EnableNamespacesByDefault {
+		return true, nil
+	}
+
+	// the workloads of an external control plane run in the remote cluster
+	if revision.IsExternalControlPlane(rev.Spec.Values) {
+		isReferencedRemotely, err := r.isReferencedRemotely(ctx, rev)
+		if err != nil || isReferencedRemotely {
+			return isReferencedRemotely, err
+		}
+	}
+
+	log.V(2).Info("Revision is not referenced by any Pod or Namespace")
+	return false, nil
+}
+
+// isReferencedByWorkloads returns true if the revision is referenced by a namespace or pod in the cluster.
+func isReferencedByWorkloads(ctx context.Context, cl client.Reader, rev *v1.IstioRevision) (bool, error) {
+	log := logf.FromContext(ctx)
+	nsList := corev1.NamespaceList{}
+	nsMap := map[string]corev1.Namespace{}
+	if err := cl.List(ctx, &nsList); err != nil { // TODO: can we optimize this by specifying a label selector
 		return false, fmt.Errorf("failed to list namespaces: %w", err)
 	}
 	for _, ns := range nsList.Items {
@@ -508,7 +869,7 @@ func (r *Reconciler) isRevisionReferenced(ctx context.Context, rev *v1.IstioRevi
 	}
 
 	podList := corev1.PodList{}
-	if err := r.Client.List(ctx, &podList); err != nil { // TODO: can we optimize this by specifying a label selector
+	if err := cl.List(ctx, &podList); err != nil { // TODO: can we optimize this by specifying a label selector
 		return false, fmt.Errorf("failed to list pods: %w", err)
 	}
 	for _, pod := range podList.Items {
@@ -521,15 +882,106 @@ func (r *Reconciler) isRevisionReferenced(ctx context.Context, rev *v1.IstioRevi
 		}
 	}
 
-	if rev.Name == v1.DefaultRevision && rev.Spec.Values != nil &&
-		rev.Spec.Values.SidecarInjectorWebhook != nil &&
-		rev.Spec.Values.SidecarInjectorWebhook.EnableNamespacesByDefault != nil &&
-		*rev.Spec.Values.SidecarInjectorWebhook.EnableNamespacesByDefault {
-		return true, nil
+	return false, nil
+}
+
+// isReferencedRemotely returns true if the revision of an external control plane is referenced by a namespace or
+// pod in the remote cluster. The result of the last check is reused until remoteUsageCheckInterval has passed or
+// the kubeconfig Secret changes.
+func (r *Reconciler) isReferencedRemotely(ctx context.Context, rev *v1.IstioRevision) (bool, error) {
+	secretKey := revision.ExternalKubeconfigSecret(rev)
+	secret := corev1.Secret{}
+	if err := r.Client.Get(ctx, secretKey, &secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, errRemoteKubeconfigNotFound
+		}
+		return false, fmt.Errorf("failed to get Secret %s: %w", secretKey, err)
 	}
 
-	log.V(2).Info("Revision is not referenced by any Pod or Namespace")
-	return false, nil
+	if usage, found := r.remote.getUsage(rev, &secret); found {
+		return usage.referenced, nil
+	}
+
+	remoteClient, err := r.getRemoteClient(&secret)
+	if err != nil {
+		return false, err
+	}
+	referenced, err := isReferencedByWorkloads(ctx, remoteClient, rev)
+	if err != nil {
+		return false, fmt.Errorf("remote cluster: %w", err)
+	}
+	r.remote.setUsage(rev, &secret, referenced)
+	return referenced, nil
+}
+
+// getRemoteClient returns a client for the remote cluster of an external control plane, created from the
+// kubeconfig Secret that istiod uses to access that cluster. The client is reused until the Secret changes.
+func (r *Reconciler) getRemoteClient(secret *corev1.Secret) (client.Reader, error) {
+	secretKey := client.ObjectKeyFromObject(secret)
+	if cl, found := r.remote.getClient(secret); found {
+		return cl, nil
+	}
+	kubeconfig, found := secret.Data[revision.ExternalKubeconfigSecretKey]
+	if !found {
+		return nil, fmt.Errorf("secret %s doesn't contain the key %q", secretKey, revision.ExternalKubeconfigSecretKey)
+	}
+	cl, err := r.newRemoteClient(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	r.remote.setClient(secret, cl)
+	return cl, nil
+}
+
+func (c *remoteClusterCache) getClient(secret *corev1.Secret) (client.Reader, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cl, found := c.clients[client.ObjectKeyFromObject(secret)]
+	if !found || cl.secretUID != secret.UID || cl.secretResourceVersion != secret.ResourceVersion {
+		return nil, false
+	}
+	return cl.Reader, true
+}
+
+func (c *remoteClusterCache) setClient(secret *corev1.Secret, cl client.Reader) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clients[client.ObjectKeyFromObject(secret)] = remoteClient{
+		secretUID:             secret.UID,
+		secretResourceVersion: secret.ResourceVersion,
+		Reader:                cl,
+	}
+}
+
+// getUsage returns the result of the last usage check of the revision, unless it is older than
+// remoteUsageCheckInterval or was made with a different version of the kubeconfig Secret.
+func (c *remoteClusterCache) getUsage(rev *v1.IstioRevision, secret *corev1.Secret) (remoteUsage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	usage, found := c.usage[rev.UID]
+	if !found || usage.secretUID != secret.UID || usage.secretResourceVersion != secret.ResourceVersion ||
+		time.Since(usage.checkTime) >= remoteUsageCheckInterval {
+		return remoteUsage{}, false
+	}
+	return usage, true
+}
+
+func (c *remoteClusterCache) setUsage(rev *v1.IstioRevision, secret *corev1.Secret, referenced bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.usage[rev.UID] = remoteUsage{
+		secretUID:             secret.UID,
+		secretResourceVersion: secret.ResourceVersion,
+		referenced:            referenced,
+		checkTime:             time.Now(),
+	}
+}
+
+// forget removes the result of the last usage check of a revision that is being deleted.
+func (c *remoteClusterCache) forget(rev *v1.IstioRevision) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.usage, rev.UID)
 }
 
 func namespaceReferencesRevision(ns corev1.Namespace, rev *v1.IstioRevision) bool {
@@ -657,6 +1109,21 @@ func (r *Reconciler) mapZTunnelToReconcileRequests(ctx context.Context, _ client
 	return reqs
 }
 
+// mapToAllRevisions enqueues all IstioRevisions. It's used when the platform TLS config changes.
+func (r *Reconciler) mapToAllRevisions(ctx context.Context, _ client.Object) []reconcile.Request {
+	list := v1.IstioRevisionList{}
+	if err := r.Client.List(ctx, &list); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list IstioRevisions")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, rev := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: rev.Name}})
+	}
+	return requests
+}
+
 func wrapEventHandler(logger logr.Logger, handler handler.EventHandler) handler.EventHandler {
 	return enqueuelogger.WrapIfNecessary(v1.IstioRevisionKind, logger, handler)
 }
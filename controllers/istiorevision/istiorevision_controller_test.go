@@ -16,10 +16,12 @@ package istiorevision
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"testing"
+	"testing/fstest"
 
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
@@ -27,6 +29,7 @@ import (
 	"github.com/istio-ecosystem/sail-operator/pkg/istioversion"
 	sharedreconcile "github.com/istio-ecosystem/sail-operator/pkg/reconcile"
 	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/istio-ecosystem/sail-operator/pkg/revision"
 	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
 	. "github.com/onsi/gomega"
 	admissionv1 "k8s.io/api/admissionregistration/v1"
@@ -386,6 +389,56 @@ func TestMapEndpointSliceToReconcileRequests(t *testing.T) {
 	}
 }
 
+func TestTLSProfileStatus(t *testing.T) {
+	testCases := []struct {
+		name     string
+		values   *v1.Values
+		expected *v1.TLSProfileStatus
+	}{
+		{
+			name:     "no values",
+			values:   nil,
+			expected: nil,
+		},
+		{
+			name:     "no tlsDefaults",
+			values:   &v1.Values{MeshConfig: &v1.MeshConfig{}},
+			expected: nil,
+		},
+		{
+			name:     "empty tlsDefaults",
+			values:   &v1.Values{MeshConfig: &v1.MeshConfig{TlsDefaults: &v1.MeshConfigTLSConfig{}}},
+			expected: nil,
+		},
+		{
+			name: "TLS 1.2 with ciphers and curves",
+			values: &v1.Values{MeshConfig: &v1.MeshConfig{TlsDefaults: &v1.MeshConfigTLSConfig{
+				MinProtocolVersion: v1.MeshConfigTLSConfigTLSProtocolTlsv12,
+				CipherSuites:       []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+				EcdhCurves:         []string{"X25519"},
+			}}},
+			expected: &v1.TLSProfileStatus{
+				MinTLSVersion: "VersionTLS12",
+				Ciphers:       []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+				Curves:        []string{"X25519"},
+			},
+		},
+		{
+			name: "TLS 1.3",
+			values: &v1.Values{MeshConfig: &v1.MeshConfig{TlsDefaults: &v1.MeshConfigTLSConfig{
+				MinProtocolVersion: v1.MeshConfigTLSConfigTLSProtocolTlsv13,
+			}}},
+			expected: &v1.TLSProfileStatus{MinTLSVersion: "VersionTLS13"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(tlsProfileStatus(tc.values)).To(Equal(tc.expected))
+		})
+	}
+}
+
 func TestDeriveState(t *testing.T) {
 	testCases := []struct {
 		name          string
@@ -636,6 +689,27 @@ func TestDetermineReadyCondition(t *testing.T) {
 				Message: "readiness probe on remote istiod failed",
 			},
 		},
+		{
+			name:   "Istiod-remote not ready with reason",
+			values: &v1.Values{Profile: ptr.Of("remote")},
+			clientObjects: []client.Object{
+				&admissionv1.MutatingWebhookConfiguration{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "istio-sidecar-injector",
+						Annotations: map[string]string{
+							constants.WebhookReadinessProbeStatusAnnotationKey:       "false",
+							constants.WebhookReadinessProbeStatusReasonAnnotationKey: "webhook sidecar-injector.istio.io: unexpected HTTP status 503",
+						},
+					},
+				},
+			},
+			expected: v1.StatusCondition{
+				Type:    v1.IstioRevisionConditionReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  v1.IstioRevisionReasonRemoteIstiodNotReady,
+				Message: "readiness probe on remote istiod failed: webhook sidecar-injector.istio.io: unexpected HTTP status 503; see status.remoteProbes for details",
+			},
+		},
 		{
 			name:   "Istiod-remote no readiness probe status annotation",
 			values: &v1.Values{Profile: ptr.Of("remote")},
@@ -957,6 +1031,221 @@ func TestDetermineInUseCondition(t *testing.T) {
 	}
 }
 
+func TestDetermineInUseConditionForExternalControlPlane(t *testing.T) {
+	cfg := newReconcilerTestConfig(t)
+
+	kubeconfigSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      revision.ExternalKubeconfigSecretName,
+			Namespace: "external-istiod",
+		},
+		Data: map[string][]byte{
+			revision.ExternalKubeconfigSecretKey: []byte("kubeconfig"),
+		},
+	}
+
+	tests := []struct {
+		name            string
+		localObjects    []client.Object
+		remoteObjects   []client.Object
+		remoteClientErr error
+		expectedStatus  metav1.ConditionStatus
+		expectedReason  v1.ConditionReason
+		expectErr       bool
+	}{
+		{
+			name:           "kubeconfig Secret not found",
+			expectedStatus: metav1.ConditionUnknown,
+			expectedReason: v1.IstioRevisionReasonRemoteKubeconfigNotFound,
+		},
+		{
+			name:         "referenced by namespace in remote cluster",
+			localObjects: []client.Object{kubeconfigSecret},
+			remoteObjects: []client.Object{
+				&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "sample",
+						Labels: map[string]string{"istio.io/rev": "external-istiod"},
+					},
+				},
+			},
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: v1.IstioRevisionReasonReferencedByWorkloads,
+		},
+		{
+			name:         "referenced by pod in remote cluster",
+			localObjects: []client.Object{kubeconfigSecret},
+			remoteObjects: []client.Object{
+				&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{Name: "sample"},
+				},
+				&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "sleep",
+						Namespace:   "sample",
+						Annotations: map[string]string{"istio.io/rev": "external-istiod"},
+					},
+				},
+			},
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: v1.IstioRevisionReasonReferencedByWorkloads,
+		},
+		{
+			name:         "not referenced in remote cluster",
+			localObjects: []client.Object{kubeconfigSecret},
+			remoteObjects: []client.Object{
+				&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "sample",
+						Labels: map[string]string{"istio.io/rev": "other"},
+					},
+				},
+			},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: v1.IstioRevisionReasonNotReferenced,
+		},
+		{
+			name:            "remote cluster client error",
+			localObjects:    []client.Object{kubeconfigSecret},
+			remoteClientErr: errors.New("invalid kubeconfig"),
+			expectedStatus:  metav1.ConditionUnknown,
+			expectedReason:  v1.IstioRevisionReasonUsageCheckFailed,
+			expectErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			rev := &v1.IstioRevision{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "external-istiod",
+				},
+				Spec: v1.IstioRevisionSpec{
+					Namespace: "external-istiod",
+					Version:   "my-version",
+					Values: &v1.Values{
+						Pilot: &v1.PilotConfig{
+							Env: map[string]string{"EXTERNAL_ISTIOD": "true"},
+						},
+					},
+				},
+			}
+
+			cl := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(append(tt.localObjects, rev)...).
+				Build()
+			remoteClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(tt.remoteObjects...).
+				Build()
+
+			r := NewReconciler(cfg, cl, scheme.Scheme, nil)
+			r.newRemoteClient = func(kubeconfig []byte) (client.Reader, error) {
+				g.Expect(kubeconfig).To(Equal([]byte("kubeconfig")))
+				return remoteClient, tt.remoteClientErr
+			}
+
+			result, err := r.determineInUseCondition(context.TODO(), rev)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+			g.Expect(result.Status).To(Equal(tt.expectedStatus))
+			g.Expect(result.Reason).To(Equal(tt.expectedReason))
+		})
+	}
+}
+
+func TestRemoteUsageCheckIsCached(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	rev := &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "external-istiod",
+			UID:  "external-istiod-uid",
+		},
+		Spec: v1.IstioRevisionSpec{
+			Namespace: "external-istiod",
+			Version:   "my-version",
+			Values: &v1.Values{
+				Pilot: &v1.PilotConfig{
+					Env: map[string]string{"EXTERNAL_ISTIOD": "true"},
+				},
+			},
+		},
+	}
+	kubeconfigSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      revision.ExternalKubeconfigSecretName,
+			Namespace: "external-istiod",
+		},
+		Data: map[string][]byte{
+			revision.ExternalKubeconfigSecretKey: []byte("kubeconfig"),
+		},
+	}
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "sample",
+			Labels: map[string]string{"istio.io/rev": "external-istiod"},
+		},
+	}
+
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(rev, kubeconfigSecret).
+		Build()
+	remoteClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(ns).
+		Build()
+
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme, nil)
+	clientsCreated := 0
+	r.newRemoteClient = func(_ []byte) (client.Reader, error) {
+		clientsCreated++
+		return remoteClient, nil
+	}
+
+	condition, err := r.determineInUseCondition(ctx, rev)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+
+	// the remote cluster isn't checked again until remoteUsageCheckInterval has passed
+	g.Expect(remoteClient.Delete(ctx, ns)).To(Succeed())
+	condition, err = r.determineInUseCondition(ctx, rev)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+
+	usage := r.remote.usage[rev.UID]
+	usage.checkTime = usage.checkTime.Add(-remoteUsageCheckInterval)
+	r.remote.usage[rev.UID] = usage
+	condition, err = r.determineInUseCondition(ctx, rev)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(clientsCreated).To(Equal(1))
+
+	// a new client is created and the usage is checked again when the kubeconfig Secret changes
+	g.Expect(remoteClient.Create(ctx, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "sample2",
+			Labels: map[string]string{"istio.io/rev": "external-istiod"},
+		},
+	})).To(Succeed())
+	secret := &corev1.Secret{}
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(kubeconfigSecret), secret)).To(Succeed())
+	secret.Data[revision.ExternalKubeconfigSecretKey] = []byte("new-kubeconfig")
+	g.Expect(cl.Update(ctx, secret)).To(Succeed())
+
+	condition, err = r.determineInUseCondition(ctx, rev)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(clientsCreated).To(Equal(2))
+}
+
 func newReconcilerTestConfig(t *testing.T) config.ReconcilerConfig {
 	return config.ReconcilerConfig{
 		ResourceFS:              os.DirFS(t.TempDir()),
@@ -965,3 +1254,199 @@ func newReconcilerTestConfig(t *testing.T) config.ReconcilerConfig {
 		MaxConcurrentReconciles: 1,
 	}
 }
+
+func TestVersionSkew(t *testing.T) {
+	versions := istioversion.GetLatestPatchVersions()
+	if len(versions) < 3 {
+		t.Skip("test requires at least three minor versions")
+	}
+	newest, previous, oldest := versions[0].Name, versions[1].Name, versions[2].Name
+
+	profile := &fstest.MapFile{Data: []byte("apiVersion: sailoperator.io/v1\nkind: IstioRevision\nspec:\n  values: {}\n")}
+	cfg := newReconcilerTestConfig(t)
+	cfg.ResourceFS = fstest.MapFS{
+		newest + "/profiles/default.yaml":   profile,
+		previous + "/profiles/default.yaml": profile,
+	}
+
+	newRevision := func(version string) *v1.IstioRevision {
+		return &v1.IstioRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec: v1.IstioRevisionSpec{
+				Version:   version,
+				Namespace: "istio-system",
+				Values: &v1.Values{
+					Pilot: &v1.PilotConfig{Cni: &v1.CNIUsageConfig{Enabled: ptr.Of(true)}},
+				},
+			},
+		}
+	}
+	cni := &v1.IstioCNI{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec:       v1.IstioCNISpec{Version: oldest, Namespace: "istio-cni"},
+		Status:     v1.IstioCNIStatus{State: v1.IstioCNIReasonHealthy},
+	}
+
+	t.Run("reports unsupported skew in DependenciesHealthy condition", func(t *testing.T) {
+		g := NewWithT(t)
+		cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cni).Build()
+		r := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+		c, err := r.determineDependenciesHealthyCondition(context.Background(), newRevision(newest))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(c.Status).To(Equal(metav1.ConditionFalse))
+		g.Expect(c.Reason).To(Equal(v1.IstioRevisionReasonVersionSkew))
+		g.Expect(c.Message).To(ContainSubstring("IstioCNI version " + oldest))
+
+		c, err = r.determineDependenciesHealthyCondition(context.Background(), newRevision(previous))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(c.Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	t.Run("strict mode rejects unsupported skew", func(t *testing.T) {
+		g := NewWithT(t)
+		cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cni).Build()
+		strictCfg := cfg
+		strictCfg.StrictVersionSkew = true
+		r := NewReconciler(strictCfg, cl, scheme.Scheme, nil)
+
+		err := r.validateVersionSkew(context.Background(), newRevision(newest))
+		g.Expect(reconciler.IsValidationError(err)).To(BeTrue())
+		g.Expect(err.Error()).To(ContainSubstring("unsupported version skew"))
+
+		g.Expect(r.validateVersionSkew(context.Background(), newRevision(previous))).To(Succeed())
+	})
+
+	t.Run("strict mode ignores missing components", func(t *testing.T) {
+		g := NewWithT(t)
+		cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		strictCfg := cfg
+		strictCfg.StrictVersionSkew = true
+		r := NewReconciler(strictCfg, cl, scheme.Scheme, nil)
+
+		g.Expect(r.validateVersionSkew(context.Background(), newRevision(newest))).To(Succeed())
+	})
+}
+
+func TestDetermineDependenciesHealthyConditionWithNodePools(t *testing.T) {
+	version := istioversion.Default
+	cfg := newReconcilerTestConfig(t)
+	cfg.ResourceFS = fstest.MapFS{
+		version + "/profiles/default.yaml": &fstest.MapFile{
+			Data: []byte("apiVersion: sailoperator.io/v1\nkind: IstioRevision\nspec:\n  values: {}\n"),
+		},
+	}
+
+	rev := &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v1.IstioRevisionSpec{
+			Version:   version,
+			Namespace: "istio-system",
+			Values: &v1.Values{
+				Pilot: &v1.PilotConfig{Cni: &v1.CNIUsageConfig{Enabled: ptr.Of(true)}},
+			},
+		},
+	}
+	newNode := func(name, pool string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"pool": pool}}}
+	}
+	newPod := func(name, node string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+			Spec:       corev1.PodSpec{NodeName: node},
+		}
+	}
+	newCNI := func(name, pool string, state v1.IstioCNIConditionReason) *v1.IstioCNI {
+		return &v1.IstioCNI{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1.IstioCNISpec{
+				Version:      version,
+				Namespace:    "istio-cni-" + name,
+				NodeSelector: map[string]string{"pool": pool},
+			},
+			Status: v1.IstioCNIStatus{State: state},
+		}
+	}
+
+	infra := []client.Object{
+		newNode("node-general", "general"),
+		newNode("node-gpu", "gpu"),
+		newNode("node-canary", "canary"),
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app", Labels: map[string]string{"istio-injection": "enabled"}}},
+	}
+	workloads := []client.Object{newPod("app-1", "node-general"), newPod("app-2", "node-gpu")}
+
+	testCases := []struct {
+		name            string
+		objects         []client.Object
+		expectStatus    metav1.ConditionStatus
+		expectReason    v1.IstioRevisionConditionReason
+		expectMessage   string
+		unexpectMessage string
+	}{
+		{
+			name: "instances covering workload nodes are healthy",
+			objects: append(workloads,
+				newCNI("general", "general", v1.IstioCNIReasonHealthy),
+				newCNI("gpu", "gpu", v1.IstioCNIReasonHealthy),
+				newCNI("canary", "canary", v1.IstioCNIReasonReconcileError)),
+			expectStatus: metav1.ConditionTrue,
+			expectReason: v1.ConditionReason(v1.IstioRevisionConditionDependenciesHealthy),
+		},
+		{
+			name: "instance covering workload nodes is unhealthy",
+			objects: append(workloads,
+				newCNI("general", "general", v1.IstioCNIReasonHealthy),
+				newCNI("gpu", "gpu", v1.IstioCNIReasonReconcileError),
+				newCNI("canary", "canary", v1.IstioCNIReasonReconcileError)),
+			expectStatus:    metav1.ConditionFalse,
+			expectReason:    v1.IstioRevisionReasonIstioCNINotHealthy,
+			expectMessage:   "IstioCNI gpu",
+			unexpectMessage: "canary",
+		},
+		{
+			name: "workload node not covered by any instance",
+			objects: append(workloads,
+				newCNI("general", "general", v1.IstioCNIReasonHealthy),
+				newCNI("canary", "canary", v1.IstioCNIReasonHealthy)),
+			expectStatus:  metav1.ConditionFalse,
+			expectReason:  v1.IstioRevisionReasonIstioCNINotFound,
+			expectMessage: "node-gpu",
+		},
+		{
+			name: "all instances are checked when there are no workloads",
+			objects: []client.Object{
+				newCNI("general", "general", v1.IstioCNIReasonHealthy),
+				newCNI("canary", "canary", v1.IstioCNIReasonReconcileError),
+			},
+			expectStatus:  metav1.ConditionFalse,
+			expectReason:  v1.IstioRevisionReasonIstioCNINotHealthy,
+			expectMessage: "IstioCNI canary",
+		},
+		{
+			name:          "no instances",
+			objects:       workloads,
+			expectStatus:  metav1.ConditionFalse,
+			expectReason:  v1.IstioRevisionReasonIstioCNINotFound,
+			expectMessage: "IstioCNI resource does not exist",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			objects := append(append([]client.Object{}, infra...), tc.objects...)
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build()
+			r := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+			c, err := r.determineDependenciesHealthyCondition(context.Background(), rev)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(c.Status).To(Equal(tc.expectStatus))
+			g.Expect(c.Reason).To(Equal(tc.expectReason))
+			g.Expect(c.Message).To(ContainSubstring(tc.expectMessage))
+			if tc.unexpectMessage != "" {
+				g.Expect(c.Message).ToNot(ContainSubstring(tc.unexpectMessage))
+			}
+		})
+	}
+}
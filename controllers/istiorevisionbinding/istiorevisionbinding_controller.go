@@ -0,0 +1,379 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istiorevisionbinding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/go-logr/logr"
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/istio-ecosystem/sail-operator/pkg/watches"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const controllerName = "istiorevisionbinding"
+
+// Reconciler reconciles an IstioRevisionBinding object
+type Reconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Config config.ReconcilerConfig
+}
+
+func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *runtime.Scheme) *Reconciler {
+	return &Reconciler{
+		Client: client,
+		Scheme: scheme,
+		Config: cfg,
+	}
+}
+
+// +kubebuilder:rbac:groups=sailoperator.io,resources=istiorevisionbindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=sailoperator.io,resources=istiorevisionbindings/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=sailoperator.io,resources=istiorevisionbindings/finalizers,verbs=update
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.14.1/pkg/reconcile
+func (r *Reconciler) Reconcile(ctx context.Context, binding *v1.IstioRevisionBinding) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	rev, reconcileErr := r.doReconcile(ctx, binding)
+
+	log.Info("Reconciliation done. Updating status.")
+	statusErr := r.updateStatus(ctx, binding, rev, reconcileErr)
+
+	return ctrl.Result{}, errors.Join(reconcileErr, statusErr)
+}
+
+// Suspend updates the status of an IstioRevisionBinding whose reconciliation is paused.
+func (r *Reconciler) Suspend(ctx context.Context, binding *v1.IstioRevisionBinding) error {
+	status := *binding.Status.DeepCopy()
+	status.SetCondition(reconciler.SuspendedCondition(v1.IstioRevisionBindingConditionReconciled, v1.IstioRevisionBindingReasonSuspended))
+	status.State = v1.IstioRevisionBindingReasonSuspended
+	status.RetryCount, status.NextRetryTime = 0, nil
+	return reconciler.UpdateStatus(ctx, r.Client, binding, binding.Status, status, nil)
+}
+
+func (r *Reconciler) doReconcile(ctx context.Context, binding *v1.IstioRevisionBinding) (*v1.IstioRevision, error) {
+	log := logf.FromContext(ctx)
+	if binding.Spec.TargetRef.Kind == "" || binding.Spec.TargetRef.Name == "" {
+		return nil, reconciler.NewValidationError("spec.targetRef not set")
+	}
+
+	ns := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: binding.Namespace}, ns); err != nil {
+		return nil, fmt.Errorf("failed to get namespace %q: %w", binding.Namespace, err)
+	}
+
+	log.Info("Retrieving referenced IstioRevision and its owning Istio")
+	istio, rev, err := r.resolveTargetRef(ctx, binding.Spec.TargetRef)
+	if err == nil {
+		err = checkAllowed(binding, ns, istio, rev)
+	}
+	if isUnbindingError(err) {
+		// the namespace must not keep using a revision it is no longer allowed to use
+		if unbindErr := r.unbindNamespace(ctx, ns, binding.Status.IstioRevision); unbindErr != nil {
+			return nil, errors.Join(err, unbindErr)
+		}
+		return nil, err
+	} else if err != nil {
+		return nil, err
+	}
+
+	if ns.Labels[constants.IstioInjectionLabel] != "" {
+		return nil, reconciler.NewValidationError(fmt.Sprintf("namespace %q has the %s label, which takes precedence over the %s label; "+
+			"a cluster administrator must remove it before the namespace can be bound to a revision",
+			ns.Name, constants.IstioInjectionLabel, constants.IstioRevLabel))
+	}
+
+	log.Info("Binding namespace to revision", "IstioRevision", rev.Name)
+	return rev, r.bindNamespace(ctx, ns, rev.Name)
+}
+
+func (r *Reconciler) Finalize(ctx context.Context, binding *v1.IstioRevisionBinding) error {
+	ns := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: binding.Namespace}, ns); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	return r.unbindNamespace(ctx, ns, binding.Status.IstioRevision)
+}
+
+// resolveTargetRef returns the IstioRevision referenced by the given TargetReference, along with the Istio
+// resource that owns it. Only revisions owned by an Istio resource can be selected through a binding, since
+// the allow-list is defined in the Istio resource.
+func (r *Reconciler) resolveTargetRef(ctx context.Context, ref v1.TargetReference) (*v1.Istio, *v1.IstioRevision, error) {
+	istio := &v1.Istio{}
+	rev := &v1.IstioRevision{}
+	switch ref.Kind {
+	case v1.IstioKind:
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: ref.Name}, istio); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil, reconciler.NewReferenceNotFoundError("referenced Istio resource does not exist", err)
+			}
+			return nil, nil, fmt.Errorf("failed to get referenced Istio resource: %w", err)
+		}
+		if istio.Status.ActiveRevisionName == "" {
+			return nil, nil, reconciler.NewTransientError("referenced Istio has no active revision")
+		}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: istio.Status.ActiveRevisionName}, rev); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil, reconciler.NewTransientError("active revision of the referenced Istio does not exist yet")
+			}
+			return nil, nil, fmt.Errorf("failed to get active IstioRevision: %w", err)
+		}
+	case v1.IstioRevisionKind:
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: ref.Name}, rev); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil, reconciler.NewReferenceNotFoundError("referenced IstioRevision resource does not exist", err)
+			}
+			return nil, nil, fmt.Errorf("failed to get referenced IstioRevision resource: %w", err)
+		}
+		owner := getOwningIstioName(rev)
+		if owner == "" {
+			return nil, nil, reconciler.NewNotAllowedError(
+				fmt.Sprintf("IstioRevision %q is not managed by an Istio resource and can't be selected through an IstioRevisionBinding", rev.Name), nil)
+		}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: owner}, istio); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil, reconciler.NewReferenceNotFoundError("Istio resource owning the referenced IstioRevision does not exist", err)
+			}
+			return nil, nil, fmt.Errorf("failed to get Istio resource owning the referenced IstioRevision: %w", err)
+		}
+	default:
+		return nil, nil, reconciler.NewValidationError("unknown targetRef.kind")
+	}
+	return istio, rev, nil
+}
+
+// checkAllowed verifies that the tenancy settings of the Istio resource permit the binding's namespace
+// to use the given revision.
+func checkAllowed(binding *v1.IstioRevisionBinding, ns *corev1.Namespace, istio *v1.Istio, rev *v1.IstioRevision) error {
+	tenancy := istio.Spec.Tenancy
+	if tenancy == nil {
+		return reconciler.NewNotAllowedError(fmt.Sprintf("Istio %q does not allow namespaces to select its revisions; spec.tenancy is not set", istio.Name), nil)
+	}
+
+	if tenancy.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(tenancy.NamespaceSelector)
+		if err != nil {
+			return reconciler.NewNotAllowedError(fmt.Sprintf("Istio %q has an invalid spec.tenancy.namespaceSelector", istio.Name), err)
+		}
+		if !selector.Matches(labels.Set(ns.Labels)) {
+			return reconciler.NewNotAllowedError(
+				fmt.Sprintf("namespace %q does not match spec.tenancy.namespaceSelector of Istio %q", ns.Name, istio.Name), nil)
+		}
+	}
+
+	if binding.Spec.TargetRef.Kind == v1.IstioRevisionKind && !slices.Contains(tenancy.AllowedRevisions, rev.Name) {
+		return reconciler.NewNotAllowedError(
+			fmt.Sprintf("IstioRevision %q is not listed in spec.tenancy.allowedRevisions of Istio %q", rev.Name, istio.Name), nil)
+	}
+	return nil
+}
+
+// isUnbindingError returns true if the error means that the namespace must no longer use the previously bound revision.
+func isUnbindingError(err error) bool {
+	return reconciler.IsNotAllowedError(err) || reconciler.IsReferenceNotFoundError(err)
+}
+
+func getOwningIstioName(rev *v1.IstioRevision) string {
+	for _, ownerRef := range rev.OwnerReferences {
+		if ownerRef.APIVersion == v1.GroupVersion.String() && ownerRef.Kind == v1.IstioKind {
+			return ownerRef.Name
+		}
+	}
+	return ""
+}
+
+func (r *Reconciler) bindNamespace(ctx context.Context, ns *corev1.Namespace, revisionName string) error {
+	if ns.Labels[constants.IstioRevLabel] == revisionName {
+		return nil
+	}
+	patch := client.MergeFrom(ns.DeepCopy())
+	if ns.Labels == nil {
+		ns.Labels = map[string]string{}
+	}
+	ns.Labels[constants.IstioRevLabel] = revisionName
+	if err := r.Client.Patch(ctx, ns, patch); err != nil {
+		return fmt.Errorf("failed to set %s label on namespace %q: %w", constants.IstioRevLabel, ns.Name, err)
+	}
+	return nil
+}
+
+// unbindNamespace removes the istio.io/rev label from the namespace, but only if it still points to the
+// revision that was previously set by the operator. A label that was modified by someone else is left intact.
+func (r *Reconciler) unbindNamespace(ctx context.Context, ns *corev1.Namespace, boundRevision string) error {
+	if boundRevision == "" || ns.Labels[constants.IstioRevLabel] != boundRevision {
+		return nil
+	}
+	patch := client.MergeFrom(ns.DeepCopy())
+	delete(ns.Labels, constants.IstioRevLabel)
+	if err := r.Client.Patch(ctx, ns, patch); err != nil {
+		return fmt.Errorf("failed to remove %s label from namespace %q: %w", constants.IstioRevLabel, ns.Name, err)
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	logger := mgr.GetLogger().WithName("ctrlr").WithName("revbinding")
+
+	// mainObjectHandler handles the IstioRevisionBinding watch events
+	mainObjectHandler := wrapEventHandler(logger, &handler.EnqueueRequestForObject{})
+
+	// operatorResourcesHandler handles watch events from operator CRDs Istio and IstioRevision
+	operatorResourcesHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapOperatorResourceToReconcileRequest))
+
+	// nsHandler triggers reconciliation of the binding in the namespace whenever the namespace's labels change,
+	// so that the istio.io/rev label is restored and the tenancy namespaceSelector is re-evaluated.
+	nsHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToReconcileRequest))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			LogConstructor: func(req *reconcile.Request) logr.Logger {
+				log := logger
+				if req != nil {
+					log = log.WithValues("IstioRevisionBinding", req.NamespacedName)
+				}
+				return log
+			},
+			MaxConcurrentReconciles: r.Config.MaxConcurrentReconciles,
+			RateLimiter:             reconciler.RateLimiter(r.Config.BackoffPolicyFor(controllerName)),
+		}).
+		// we use the Watches function instead of For(), so that we can wrap the handler so that events that cause the object to be enqueued are logged
+		Watches(&v1.IstioRevisionBinding{}, mainObjectHandler, builder.WithPredicates(watches.AsPredicate(watches.IgnoreRetryStatusChanges()))).
+		Named(controllerName).
+		// watches related to the istio.io/rev label and the tenancy namespaceSelector
+		Watches(&corev1.Namespace{}, nsHandler, builder.WithPredicates(watches.AsPredicate(watches.IgnoreStatusChanges()))).
+
+		// cluster-scoped resources
+		Watches(&v1.Istio{}, operatorResourcesHandler).
+		Watches(&v1.IstioRevision{}, operatorResourcesHandler).
+		Complete(reconciler.NewStandardReconcilerWithFinalizer[*v1.IstioRevisionBinding](r.Client, r.Reconcile, r.Finalize, constants.FinalizerName).
+			WithSuspendFunc(r.Suspend))
+}
+
+func (r *Reconciler) determineStatus(binding *v1.IstioRevisionBinding, rev *v1.IstioRevision, reconcileErr error) v1.IstioRevisionBindingStatus {
+	reconciledCondition := r.determineReconciledCondition(reconcileErr)
+
+	status := *binding.Status.DeepCopy()
+	status.ObservedGeneration = binding.Generation
+	retry := reconciler.NextRetry(r.Config.BackoffPolicyFor(controllerName), binding.Status.RetryCount, reconcileErr)
+	status.RetryCount, status.NextRetryTime = retry.Count, retry.Time
+	if reconciledCondition.Status == metav1.ConditionTrue && rev != nil {
+		status.IstioRevision = rev.Name
+	} else if isUnbindingError(reconcileErr) {
+		status.IstioRevision = ""
+	}
+	status.SetCondition(reconciledCondition)
+	status.State = reconciler.DeriveState(v1.IstioRevisionBindingReasonHealthy, reconciledCondition)
+	return status
+}
+
+func (r *Reconciler) updateStatus(ctx context.Context, binding *v1.IstioRevisionBinding, rev *v1.IstioRevision, reconcileErr error) error {
+	status := r.determineStatus(binding, rev, reconcileErr)
+	return reconciler.UpdateStatus(ctx, r.Client, binding, binding.Status, status, nil)
+}
+
+func (r *Reconciler) determineReconciledCondition(err error) v1.StatusCondition {
+	c := v1.StatusCondition{Type: v1.IstioRevisionBindingConditionReconciled}
+	if err == nil {
+		c.Status = metav1.ConditionTrue
+		c.Reason = v1.ConditionReason(v1.IstioRevisionBindingConditionReconciled)
+	} else {
+		c.Status = metav1.ConditionFalse
+		c.Message = err.Error()
+		switch {
+		case reconciler.IsReferenceNotFoundError(err):
+			c.Reason = v1.IstioRevisionBindingReasonReferenceNotFound
+		case reconciler.IsNotAllowedError(err):
+			c.Reason = v1.IstioRevisionBindingReasonRevisionNotAllowed
+		default:
+			c.Reason, c.Message = reconciler.DescribeReconcileError(err, v1.IstioRevisionBindingReasonReconcileError)
+		}
+	}
+	return c
+}
+
+func (r *Reconciler) mapNamespaceToReconcileRequest(ctx context.Context, ns client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: ns.GetName(), Name: v1.DefaultRevisionBinding}}}
+}
+
+func (r *Reconciler) mapOperatorResourceToReconcileRequest(ctx context.Context, obj client.Object) []reconcile.Request {
+	var kind string
+	var revisionName string
+	if i, ok := obj.(*v1.Istio); ok {
+		kind = v1.IstioKind
+		revisionName = i.Status.ActiveRevisionName
+	} else if rev, ok := obj.(*v1.IstioRevision); ok {
+		kind = v1.IstioRevisionKind
+		revisionName = rev.Name
+	} else {
+		return nil
+	}
+
+	bindings := v1.IstioRevisionBindingList{}
+	if err := r.Client.List(ctx, &bindings); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, binding := range bindings.Items {
+		if bindingDependsOn(binding, kind, obj.GetName(), revisionName) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&binding)})
+		}
+	}
+	return requests
+}
+
+// bindingDependsOn returns true if the outcome of reconciling the binding may depend on the given Istio or IstioRevision.
+func bindingDependsOn(binding v1.IstioRevisionBinding, kind, name, revisionName string) bool {
+	ref := binding.Spec.TargetRef
+	switch {
+	case ref.Kind == kind && ref.Name == name:
+		return true
+	case revisionName != "" && binding.Status.IstioRevision == revisionName:
+		return true
+	default:
+		// bindings that reference an IstioRevision directly are checked against the allow-list
+		// in the owning Istio, so any change to an Istio might affect them
+		return kind == v1.IstioKind && ref.Kind == v1.IstioRevisionKind
+	}
+}
+
+func wrapEventHandler(logger logr.Logger, handler handler.EventHandler) handler.EventHandler {
+	return enqueuelogger.WrapIfNecessary(v1.IstioRevisionBindingKind, logger, handler)
+}
@@ -0,0 +1,332 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istiorevisionbinding
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"istio.io/istio/pkg/ptr"
+)
+
+const (
+	istioName    = "default"
+	activeRev    = "default-v1-30-0"
+	canaryRev    = "default-v1-31-0"
+	tenantNsName = "tenant"
+)
+
+func TestDoReconcile(t *testing.T) {
+	testCases := []struct {
+		name          string
+		targetRef     v1.TargetReference
+		tenancy       *v1.IstioTenancy
+		nsLabels      map[string]string
+		boundRevision string
+
+		expectErr      func(error) bool
+		expectRevLabel string
+	}{
+		{
+			name:           "binds namespace to active revision of Istio",
+			targetRef:      v1.TargetReference{Kind: v1.IstioKind, Name: istioName},
+			tenancy:        &v1.IstioTenancy{},
+			expectRevLabel: activeRev,
+		},
+		{
+			name:           "binds namespace to allowed revision",
+			targetRef:      v1.TargetReference{Kind: v1.IstioRevisionKind, Name: canaryRev},
+			tenancy:        &v1.IstioTenancy{AllowedRevisions: []string{canaryRev}},
+			expectRevLabel: canaryRev,
+		},
+		{
+			name:           "rejects revision not in allow-list",
+			targetRef:      v1.TargetReference{Kind: v1.IstioRevisionKind, Name: canaryRev},
+			tenancy:        &v1.IstioTenancy{AllowedRevisions: []string{activeRev}},
+			expectErr:      reconciler.IsNotAllowedError,
+			expectRevLabel: "",
+		},
+		{
+			name:           "rejects binding when Istio has no tenancy settings",
+			targetRef:      v1.TargetReference{Kind: v1.IstioKind, Name: istioName},
+			expectErr:      reconciler.IsNotAllowedError,
+			expectRevLabel: "",
+		},
+		{
+			name:      "rejects namespace not matching namespaceSelector",
+			targetRef: v1.TargetReference{Kind: v1.IstioKind, Name: istioName},
+			tenancy: &v1.IstioTenancy{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}},
+			},
+			expectErr:      reconciler.IsNotAllowedError,
+			expectRevLabel: "",
+		},
+		{
+			name:      "binds namespace matching namespaceSelector",
+			targetRef: v1.TargetReference{Kind: v1.IstioKind, Name: istioName},
+			tenancy: &v1.IstioTenancy{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}},
+			},
+			nsLabels:       map[string]string{"tenant": "true"},
+			expectRevLabel: activeRev,
+		},
+		{
+			name:           "removes previously set label when revision is no longer allowed",
+			targetRef:      v1.TargetReference{Kind: v1.IstioRevisionKind, Name: canaryRev},
+			tenancy:        &v1.IstioTenancy{},
+			nsLabels:       map[string]string{"istio.io/rev": canaryRev},
+			boundRevision:  canaryRev,
+			expectErr:      reconciler.IsNotAllowedError,
+			expectRevLabel: "",
+		},
+		{
+			name:           "keeps label that wasn't set by the operator",
+			targetRef:      v1.TargetReference{Kind: v1.IstioRevisionKind, Name: canaryRev},
+			tenancy:        &v1.IstioTenancy{},
+			nsLabels:       map[string]string{"istio.io/rev": "something-else"},
+			boundRevision:  canaryRev,
+			expectErr:      reconciler.IsNotAllowedError,
+			expectRevLabel: "something-else",
+		},
+		{
+			name:           "referenced IstioRevision not found",
+			targetRef:      v1.TargetReference{Kind: v1.IstioRevisionKind, Name: "missing"},
+			tenancy:        &v1.IstioTenancy{},
+			expectErr:      reconciler.IsReferenceNotFoundError,
+			expectRevLabel: "",
+		},
+		{
+			name:           "istio-injection label takes precedence",
+			targetRef:      v1.TargetReference{Kind: v1.IstioKind, Name: istioName},
+			tenancy:        &v1.IstioTenancy{},
+			nsLabels:       map[string]string{"istio-injection": "enabled"},
+			expectErr:      reconciler.IsValidationError,
+			expectRevLabel: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			ctx := context.TODO()
+
+			istio := &v1.Istio{
+				ObjectMeta: metav1.ObjectMeta{Name: istioName, UID: "istio-uid"},
+				Spec:       v1.IstioSpec{Tenancy: tc.tenancy},
+				Status:     v1.IstioStatus{ActiveRevisionName: activeRev},
+			}
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: tenantNsName, Labels: tc.nsLabels},
+			}
+			binding := &v1.IstioRevisionBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: v1.DefaultRevisionBinding, Namespace: tenantNsName},
+				Spec:       v1.IstioRevisionBindingSpec{TargetRef: tc.targetRef},
+				Status:     v1.IstioRevisionBindingStatus{IstioRevision: tc.boundRevision},
+			}
+
+			cl := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(istio, newOwnedRevision(istio, activeRev), newOwnedRevision(istio, canaryRev), ns, binding).
+				Build()
+
+			r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+			rev, err := r.doReconcile(ctx, binding)
+			if tc.expectErr != nil {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(tc.expectErr(err)).To(BeTrue(), "unexpected error type: %v", err)
+				g.Expect(rev).To(BeNil())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(rev.Name).To(Equal(tc.expectRevLabel))
+			}
+
+			g.Expect(cl.Get(ctx, types.NamespacedName{Name: tenantNsName}, ns)).To(Succeed())
+			g.Expect(ns.Labels["istio.io/rev"]).To(Equal(tc.expectRevLabel))
+		})
+	}
+}
+
+func TestRejectsRevisionNotOwnedByIstio(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	rev := &v1.IstioRevision{ObjectMeta: metav1.ObjectMeta{Name: "standalone"}}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: tenantNsName}}
+	binding := &v1.IstioRevisionBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: v1.DefaultRevisionBinding, Namespace: tenantNsName},
+		Spec: v1.IstioRevisionBindingSpec{
+			TargetRef: v1.TargetReference{Kind: v1.IstioRevisionKind, Name: rev.Name},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(rev, ns, binding).Build()
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	_, err := r.doReconcile(ctx, binding)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(reconciler.IsNotAllowedError(err)).To(BeTrue())
+}
+
+func TestOwningIstioNotFound(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	// the IstioRevision is owned by an Istio that has been deleted
+	istio := &v1.Istio{ObjectMeta: metav1.ObjectMeta{Name: istioName, UID: "istio-uid"}}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: tenantNsName}}
+	binding := &v1.IstioRevisionBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: v1.DefaultRevisionBinding, Namespace: tenantNsName},
+		Spec: v1.IstioRevisionBindingSpec{
+			TargetRef: v1.TargetReference{Kind: v1.IstioRevisionKind, Name: canaryRev},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(newOwnedRevision(istio, canaryRev), ns, binding).Build()
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	_, err := r.doReconcile(ctx, binding)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(reconciler.IsReferenceNotFoundError(err)).To(BeTrue(), "unexpected error type: %v", err)
+}
+
+func TestFinalize(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: tenantNsName, Labels: map[string]string{"istio.io/rev": activeRev}},
+	}
+	binding := &v1.IstioRevisionBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: v1.DefaultRevisionBinding, Namespace: tenantNsName},
+		Status:     v1.IstioRevisionBindingStatus{IstioRevision: activeRev},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(ns, binding).Build()
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	g.Expect(r.Finalize(ctx, binding)).To(Succeed())
+	g.Expect(cl.Get(ctx, types.NamespacedName{Name: tenantNsName}, ns)).To(Succeed())
+	g.Expect(ns.Labels).NotTo(HaveKey("istio.io/rev"))
+}
+
+func TestDetermineReconciledCondition(t *testing.T) {
+	r := &Reconciler{}
+
+	testCases := []struct {
+		err            error
+		expectedStatus metav1.ConditionStatus
+		expectedReason v1.IstioRevisionBindingConditionReason
+	}{
+		{
+			err:            nil,
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: v1.ConditionReason(v1.IstioRevisionBindingConditionReconciled),
+		},
+		{
+			err:            reconciler.NewNotAllowedError("not allowed", nil),
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: v1.IstioRevisionBindingReasonRevisionNotAllowed,
+		},
+		{
+			err:            reconciler.NewReferenceNotFoundError("not found", nil),
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: v1.IstioRevisionBindingReasonReferenceNotFound,
+		},
+		{
+			err:            reconciler.NewValidationError("invalid"),
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: v1.IstioRevisionBindingReasonReconcileError,
+		},
+	}
+
+	for _, tc := range testCases {
+		g := NewWithT(t)
+		c := r.determineReconciledCondition(tc.err)
+		g.Expect(c.Type).To(Equal(v1.IstioRevisionBindingConditionReconciled))
+		g.Expect(c.Status).To(Equal(tc.expectedStatus))
+		g.Expect(c.Reason).To(Equal(tc.expectedReason))
+	}
+}
+
+func TestMapOperatorResourceToReconcileRequest(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	followsIstio := &v1.IstioRevisionBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: v1.DefaultRevisionBinding, Namespace: "ns1"},
+		Spec:       v1.IstioRevisionBindingSpec{TargetRef: v1.TargetReference{Kind: v1.IstioKind, Name: istioName}},
+	}
+	pinsRevision := &v1.IstioRevisionBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: v1.DefaultRevisionBinding, Namespace: "ns2"},
+		Spec:       v1.IstioRevisionBindingSpec{TargetRef: v1.TargetReference{Kind: v1.IstioRevisionKind, Name: canaryRev}},
+	}
+	unrelated := &v1.IstioRevisionBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: v1.DefaultRevisionBinding, Namespace: "ns3"},
+		Spec:       v1.IstioRevisionBindingSpec{TargetRef: v1.TargetReference{Kind: v1.IstioKind, Name: "other"}},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(followsIstio, pinsRevision, unrelated).Build()
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	istio := &v1.Istio{ObjectMeta: metav1.ObjectMeta{Name: istioName}}
+	g.Expect(r.mapOperatorResourceToReconcileRequest(ctx, istio)).To(ConsistOf(
+		HaveField("NamespacedName", client.ObjectKeyFromObject(followsIstio)),
+		HaveField("NamespacedName", client.ObjectKeyFromObject(pinsRevision)),
+	))
+
+	rev := &v1.IstioRevision{ObjectMeta: metav1.ObjectMeta{Name: canaryRev}}
+	g.Expect(r.mapOperatorResourceToReconcileRequest(ctx, rev)).To(ConsistOf(
+		HaveField("NamespacedName", client.ObjectKeyFromObject(pinsRevision)),
+	))
+}
+
+func newOwnedRevision(istio *v1.Istio, name string) *v1.IstioRevision {
+	return &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: v1.GroupVersion.String(),
+					Kind:       v1.IstioKind,
+					Name:       istio.Name,
+					UID:        istio.UID,
+					Controller: ptr.Of(true),
+				},
+			},
+		},
+	}
+}
+
+func newReconcilerTestConfig(t *testing.T) config.ReconcilerConfig {
+	return config.ReconcilerConfig{
+		ResourceFS:              os.DirFS(t.TempDir()),
+		Platform:                config.PlatformKubernetes,
+		DefaultProfile:          "",
+		MaxConcurrentReconciles: 1,
+	}
+}
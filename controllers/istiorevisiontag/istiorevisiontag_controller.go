@@ -52,6 +52,8 @@ const (
 	revisionTagsChartName = "revisiontags"
 )
 
+const controllerName = "istiorevisiontag"
+
 // Reconciler reconciles an IstioRevisionTag object
 type Reconciler struct {
 	client.Client
@@ -90,6 +92,15 @@ func (r *Reconciler) Reconcile(ctx context.Context, tag *v1.IstioRevisionTag) (c
 	return ctrl.Result{}, errors.Join(reconcileErr, statusErr)
 }
 
+// Suspend updates the status of an IstioRevisionTag whose reconciliation is paused.
+func (r *Reconciler) Suspend(ctx context.Context, tag *v1.IstioRevisionTag) error {
+	status := *tag.Status.DeepCopy()
+	status.SetCondition(reconciler.SuspendedCondition(v1.IstioRevisionTagConditionReconciled, v1.IstioRevisionTagReasonSuspended))
+	status.State = v1.IstioRevisionTagReasonSuspended
+	status.RetryCount, status.NextRetryTime = 0, nil
+	return reconciler.UpdateStatus(ctx, r.Client, tag, tag.Status, status, nil)
+}
+
 func (r *Reconciler) doReconcile(ctx context.Context, tag *v1.IstioRevisionTag) (*v1.IstioRevision, error) {
 	log := logf.FromContext(ctx).WithValues("IstioRevisionTag", tag.Name)
 	if err := r.validate(ctx, tag); err != nil {
@@ -238,10 +249,11 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 				return log
 			},
 			MaxConcurrentReconciles: r.Config.MaxConcurrentReconciles,
+			RateLimiter:             reconciler.RateLimiter(r.Config.BackoffPolicyFor(controllerName)),
 		}).
 		// we use the Watches function instead of For(), so that we can wrap the handler so that events that cause the object to be enqueued are logged
-		Watches(&v1.IstioRevisionTag{}, mainObjectHandler).
-		Named("istiorevisiontag").
+		Watches(&v1.IstioRevisionTag{}, mainObjectHandler, builder.WithPredicates(watches.AsPredicate(watches.IgnoreRetryStatusChanges()))).
+		Named(controllerName).
 		// watches related to in-use detection
 		Watches(&corev1.Namespace{}, nsHandler, builder.WithPredicates(watches.AsPredicate(watches.IgnoreStatusChanges()))).
 		Watches(&corev1.Pod{}, podHandler, builder.WithPredicates(watches.AsPredicate(watches.IgnoreStatusChanges()))).
@@ -253,7 +265,8 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 			builder.WithPredicates(watches.AsPredicate(watches.WebhookFilter()))).
 		Watches(&admissionv1.ValidatingWebhookConfiguration{}, ownedResourceHandler,
 			builder.WithPredicates(watches.AsPredicate(watches.WebhookFilter()))).
-		Complete(reconciler.NewStandardReconcilerWithFinalizer[*v1.IstioRevisionTag](r.Client, r.Reconcile, r.Finalize, constants.FinalizerName))
+		Complete(reconciler.NewStandardReconcilerWithFinalizer[*v1.IstioRevisionTag](r.Client, r.Reconcile, r.Finalize, constants.FinalizerName).
+			WithSuspendFunc(r.Suspend))
 }
 
 func (r *Reconciler) determineStatus(ctx context.Context, tag *v1.IstioRevisionTag,
@@ -267,6 +280,8 @@ func (r *Reconciler) determineStatus(ctx context.Context, tag *v1.IstioRevisionT
 
 	status := *tag.Status.DeepCopy()
 	status.ObservedGeneration = tag.Generation
+	retry := reconciler.NextRetry(r.Config.BackoffPolicyFor(controllerName), tag.Status.RetryCount, reconcileErr)
+	status.RetryCount, status.NextRetryTime = retry.Count, retry.Time
 	if reconciledCondition.Status == metav1.ConditionTrue && rev != nil {
 		status.IstiodNamespace = rev.Spec.Namespace
 		status.IstioRevision = rev.Name
@@ -296,8 +311,7 @@ func (r *Reconciler) determineReconciledCondition(err error) v1.StatusCondition
 		case reconciler.IsReferenceNotFoundError(err):
 			c.Reason = v1.IstioRevisionTagReasonReferenceNotFound
 		default:
-			c.Reason = v1.IstioRevisionTagReasonReconcileError
-			c.Message = fmt.Sprintf("error reconciling resource: %v", err)
+			c.Reason, c.Message = reconciler.DescribeReconcileError(err, v1.IstioRevisionTagReasonReconcileError)
 		}
 	}
 	return c
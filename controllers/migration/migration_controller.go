@@ -0,0 +1,874 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
+	"github.com/istio-ecosystem/sail-operator/pkg/migration"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/istio-ecosystem/sail-operator/pkg/revision"
+	"github.com/istio-ecosystem/sail-operator/pkg/watches"
+	"github.com/istio-ecosystem/sail-operator/pkg/waypoint"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	controllerName = "migration"
+
+	// checkInterval is the interval at which an incomplete migration is checked. The pods, the waypoints and the
+	// resources inspected by the pre-flight check aren't watched, so their state is polled.
+	checkInterval = 15 * time.Second
+
+	// pilotEnableAmbientEnv is the istiod environment variable that enables ambient mode
+	pilotEnableAmbientEnv = "PILOT_ENABLE_AMBIENT"
+
+	// restartedAtAnnotation is the pod template annotation that `kubectl rollout restart` sets to restart a workload
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+)
+
+// sidecarInjectionLabels are the namespace labels that the migration removes and restores on rollback.
+var sidecarInjectionLabels = []string{constants.IstioInjectionLabel, constants.IstioRevLabel}
+
+// Reconciler reconciles a Migration object
+type Reconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Config config.ReconcilerConfig
+}
+
+func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *runtime.Scheme) *Reconciler {
+	return &Reconciler{
+		Client: client,
+		Scheme: scheme,
+		Config: cfg,
+	}
+}
+
+// progress holds the outcome of the phases of a migration.
+type progress struct {
+	rev        *v1.IstioRevision
+	phase      v1.MigrationPhase
+	findings   []v1.MigrationFinding
+	blocked    bool
+	components string // describes why the components aren't ready; empty if they are
+	ambient    string // describes why ambient mode isn't enabled yet; empty if it is
+	namespaces []v1.NamespaceMigrationStatus
+}
+
+// +kubebuilder:rbac:groups=sailoperator.io,resources=migrations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=sailoperator.io,resources=migrations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=sailoperator.io,resources=migrations/finalizers,verbs=update
+// +kubebuilder:rbac:groups="apps",resources=statefulsets,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups="networking.istio.io",resources=envoyfilters;sidecars;virtualservices,verbs=list
+// +kubebuilder:rbac:groups="security.istio.io",resources=authorizationpolicies;peerauthentications,verbs=list
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.14.1/pkg/reconcile
+func (r *Reconciler) Reconcile(ctx context.Context, m *v1.Migration) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	p, reconcileErr := r.doReconcile(ctx, m)
+
+	log.Info("Reconciliation done. Updating status.")
+	result, statusErr := r.updateStatus(ctx, m, p, reconcileErr)
+
+	return result, errors.Join(reconcileErr, statusErr)
+}
+
+// Suspend updates the status of a Migration whose reconciliation is paused.
+func (r *Reconciler) Suspend(ctx context.Context, m *v1.Migration) error {
+	status := *m.Status.DeepCopy()
+	status.SetCondition(reconciler.SuspendedCondition(v1.MigrationConditionReconciled, v1.MigrationReasonSuspended))
+	status.State = v1.MigrationReasonSuspended
+	status.RetryCount, status.NextRetryTime = 0, nil
+	return reconciler.UpdateStatus(ctx, r.Client, m, m.Status, status, nil)
+}
+
+// doReconcile advances the migration. Namespaces listed in spec.rollbackNamespaces are always rolled back. The
+// other namespaces are migrated one at a time, but only once the pre-flight check has passed, the ZTunnel and
+// IstioCNI components are ready and ambient mode is enabled on the control plane.
+func (r *Reconciler) doReconcile(ctx context.Context, m *v1.Migration) (*progress, error) {
+	log := logf.FromContext(ctx)
+	if m.Spec.TargetRef.Kind == "" || m.Spec.TargetRef.Name == "" {
+		return nil, reconciler.NewValidationError("spec.targetRef not set")
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&m.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, reconciler.NewValidationError(fmt.Sprintf("invalid spec.namespaceSelector: %v", err))
+	}
+
+	log.Info("Retrieving referenced IstioRevision")
+	rev, err := revision.GetIstioRevisionFromTargetReference(ctx, r.Client, m.Spec.TargetRef)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, reconciler.NewReferenceNotFoundError("referenced resource does not exist", err)
+		}
+		return nil, err
+	}
+	p := &progress{rev: rev}
+
+	namespaces, err := r.listNamespaces(ctx, m, selector)
+	if err != nil {
+		return nil, err
+	}
+	previous := map[string]v1.NamespaceMigrationStatus{}
+	for _, ns := range m.Status.Namespaces {
+		previous[ns.Name] = ns
+	}
+
+	var errs []error
+	var forward, pending []*corev1.Namespace
+	for _, ns := range namespaces {
+		status, found := previous[ns.Name]
+		if !found {
+			status = v1.NamespaceMigrationStatus{Name: ns.Name, Phase: v1.NamespaceMigrationPending}
+		}
+		if slices.Contains(m.Spec.RollbackNamespaces, ns.Name) {
+			status, err = r.rollbackNamespace(ctx, m, ns, status)
+			errs = append(errs, err)
+			p.namespaces = append(p.namespaces, status)
+			continue
+		}
+		if status.Phase == v1.NamespaceMigrationRollingBack || status.Phase == v1.NamespaceMigrationRolledBack {
+			// the namespace was removed from spec.rollbackNamespaces, so it's migrated again
+			status = transition(status, v1.NamespaceMigrationPending, "")
+		}
+		if status.Phase == v1.NamespaceMigrationPending {
+			pending = append(pending, ns)
+		}
+		forward = append(forward, ns)
+		p.namespaces = append(p.namespaces, status)
+	}
+
+	p.findings, err = migration.Preflight(ctx, r.Client, namespaceNames(pending), m.Spec.Waypoint != nil)
+	if err != nil {
+		return nil, errors.Join(append(errs, err)...)
+	}
+	p.blocked = !m.Spec.SkipPreflightChecks && migration.HasBlockingFindings(p.findings)
+
+	if p.components, err = r.checkComponents(ctx); err != nil {
+		return nil, errors.Join(append(errs, err)...)
+	}
+
+	migrating := slices.ContainsFunc(p.namespaces, func(s v1.NamespaceMigrationStatus) bool { return !isDone(s.Phase) })
+	switch {
+	case p.blocked:
+		p.phase = v1.MigrationPhasePreflightCheck
+	case p.components != "":
+		p.phase = v1.MigrationPhaseVerifyComponents
+	default:
+		// ambient mode is only enabled once there are namespaces to migrate
+		if p.ambient, err = r.enableAmbient(ctx, m, rev, migrating); err != nil {
+			return nil, errors.Join(append(errs, err)...)
+		}
+		if p.ambient != "" {
+			p.phase = v1.MigrationPhaseEnableAmbient
+		} else {
+			errs = append(errs, r.migrateNamespaces(ctx, m, rev, forward, p.namespaces))
+			p.phase = v1.MigrationPhaseMigrateNamespaces
+		}
+	}
+	if p.phase == v1.MigrationPhasePreflightCheck || p.phase == v1.MigrationPhaseVerifyComponents {
+		p.ambient = ambientStatus(rev)
+	}
+	if !slices.ContainsFunc(p.namespaces, func(s v1.NamespaceMigrationStatus) bool { return !isDone(s.Phase) }) {
+		p.phase = v1.MigrationPhaseCompleted
+	}
+	return p, errors.Join(errs...)
+}
+
+// listNamespaces returns the namespaces that the migration applies to, sorted by name. These are the namespaces
+// selected by the namespace selector and the namespaces that were already migrated by this migration.
+func (r *Reconciler) listNamespaces(ctx context.Context, m *v1.Migration, selector labels.Selector) ([]*corev1.Namespace, error) {
+	nsList := &corev1.NamespaceList{}
+	if err := r.Client.List(ctx, nsList); err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	reported := map[string]bool{}
+	for _, ns := range m.Status.Namespaces {
+		reported[ns.Name] = true
+	}
+	var namespaces []*corev1.Namespace
+	for i := range nsList.Items {
+		ns := &nsList.Items[i]
+		if ns.DeletionTimestamp != nil {
+			continue
+		}
+		rollback := reported[ns.Name] && slices.Contains(m.Spec.RollbackNamespaces, ns.Name)
+		if selector.Matches(labels.Set(ns.Labels)) || isMigratedBy(ns, m) || rollback {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	slices.SortFunc(namespaces, func(a, b *corev1.Namespace) int { return strings.Compare(a.Name, b.Name) })
+	return namespaces, nil
+}
+
+func isMigratedBy(ns *corev1.Namespace, m *v1.Migration) bool {
+	return ns.Annotations[constants.MigrationAnnotation] == m.Name
+}
+
+// isDone returns true if the namespace doesn't need any further action.
+func isDone(phase v1.NamespaceMigrationPhase) bool {
+	return phase == v1.NamespaceMigrated || phase == v1.NamespaceMigrationRolledBack
+}
+
+func transition(status v1.NamespaceMigrationStatus, phase v1.NamespaceMigrationPhase, message string) v1.NamespaceMigrationStatus {
+	if status.Phase != phase {
+		status.Phase = phase
+		status.LastTransitionTime = metav1.Now().Rfc3339Copy()
+	}
+	status.Message = message
+	return status
+}
+
+func namespaceNames(namespaces []*corev1.Namespace) []string {
+	names := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		names = append(names, ns.Name)
+	}
+	return names
+}
+
+// checkComponents returns a message describing why the ZTunnel and IstioCNI resources aren't ready for ambient mode,
+// or an empty string if they are.
+func (r *Reconciler) checkComponents(ctx context.Context) (string, error) {
+	var problems []string
+
+	ztunnels := &v1.ZTunnelList{}
+	if err := r.Client.List(ctx, ztunnels); err != nil {
+		return "", fmt.Errorf("failed to list ZTunnels: %w", err)
+	}
+	if len(ztunnels.Items) == 0 {
+		problems = append(problems, "no ZTunnel resource exists")
+	}
+	for _, z := range ztunnels.Items {
+		if z.Status.GetCondition(v1.ZTunnelConditionReady).Status != metav1.ConditionTrue {
+			problems = append(problems, fmt.Sprintf("ZTunnel %q is not ready", z.Name))
+		}
+	}
+
+	cnis := &v1.IstioCNIList{}
+	if err := r.Client.List(ctx, cnis); err != nil {
+		return "", fmt.Errorf("failed to list IstioCNIs: %w", err)
+	}
+	if len(cnis.Items) == 0 {
+		problems = append(problems, "no IstioCNI resource exists")
+	}
+	for _, cni := range cnis.Items {
+		if !cniAmbientEnabled(&cni) {
+			problems = append(problems, fmt.Sprintf("IstioCNI %q doesn't have ambient mode enabled; set spec.profile to ambient", cni.Name))
+		} else if cni.Status.GetCondition(v1.IstioCNIConditionReady).Status != metav1.ConditionTrue {
+			problems = append(problems, fmt.Sprintf("IstioCNI %q is not ready", cni.Name))
+		}
+	}
+	return strings.Join(problems, "; "), nil
+}
+
+func cniAmbientEnabled(cni *v1.IstioCNI) bool {
+	if cni.Spec.Profile == "ambient" {
+		return true
+	}
+	values := cni.Spec.Values
+	return values != nil && values.Cni != nil && values.Cni.Ambient != nil && values.Cni.Ambient.Enabled != nil && *values.Cni.Ambient.Enabled
+}
+
+func ambientEnabled(values *v1.Values) bool {
+	if values == nil {
+		return false
+	}
+	if values.Profile != nil && *values.Profile == "ambient" {
+		return true
+	}
+	return values.Pilot != nil && values.Pilot.Env[pilotEnableAmbientEnv] == "true"
+}
+
+// ambientStatus returns a message describing why ambient mode isn't enabled on the revision, or an empty string if
+// it's enabled and the revision is ready.
+func ambientStatus(rev *v1.IstioRevision) string {
+	if !ambientEnabled(rev.Spec.Values) {
+		return fmt.Sprintf("ambient mode is not enabled on IstioRevision %q", rev.Name)
+	}
+	if rev.Status.ObservedGeneration != rev.Generation || rev.Status.GetCondition(v1.IstioRevisionConditionReady).Status != metav1.ConditionTrue {
+		return fmt.Sprintf("waiting for IstioRevision %q to be ready", rev.Name)
+	}
+	return ""
+}
+
+// enableAmbient sets PILOT_ENABLE_AMBIENT on the control plane, if required, and returns a message describing why
+// ambient mode isn't enabled yet, or an empty string if it is. If the revision is owned by an Istio resource, the
+// variable is set on the Istio resource, since the operator would otherwise revert the change to the revision.
+func (r *Reconciler) enableAmbient(ctx context.Context, m *v1.Migration, rev *v1.IstioRevision, required bool) (string, error) {
+	if ambientEnabled(rev.Spec.Values) || !required {
+		return ambientStatus(rev), nil
+	}
+
+	var obj client.Object = rev
+	valuesOf := func() **v1.Values { return &rev.Spec.Values }
+	istioName := m.Spec.TargetRef.Name
+	if m.Spec.TargetRef.Kind != v1.IstioKind {
+		istioName = ""
+		if owner := metav1.GetControllerOf(rev); owner != nil && owner.Kind == v1.IstioKind {
+			istioName = owner.Name
+		}
+	}
+	if istioName != "" {
+		istio := &v1.Istio{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: istioName}, istio); err != nil {
+			return "", fmt.Errorf("failed to get Istio %q: %w", istioName, err)
+		}
+		obj = istio
+		valuesOf = func() **v1.Values { return &istio.Spec.Values }
+	}
+
+	logf.FromContext(ctx).Info("Enabling ambient mode", "Kind", obj.GetObjectKind().GroupVersionKind().Kind, "Name", obj.GetName())
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	values := valuesOf()
+	if *values == nil {
+		*values = &v1.Values{}
+	}
+	if (*values).Pilot == nil {
+		(*values).Pilot = &v1.PilotConfig{}
+	}
+	if (*values).Pilot.Env == nil {
+		(*values).Pilot.Env = map[string]string{}
+	}
+	(*values).Pilot.Env[pilotEnableAmbientEnv] = "true"
+	if err := r.Client.Patch(ctx, obj, patch); err != nil {
+		return "", fmt.Errorf("failed to enable ambient mode on %s: %w", obj.GetName(), err)
+	}
+	return fmt.Sprintf("waiting for ambient mode to be enabled on IstioRevision %q", rev.Name), nil
+}
+
+// migrateNamespaces advances the migration of the namespaces that aren't rolled back. Only one namespace is migrated
+// at a time. The statuses are updated in place.
+func (r *Reconciler) migrateNamespaces(
+	ctx context.Context, m *v1.Migration, rev *v1.IstioRevision, namespaces []*corev1.Namespace, statuses []v1.NamespaceMigrationStatus,
+) error {
+	status := func(name string) *v1.NamespaceMigrationStatus {
+		i := slices.IndexFunc(statuses, func(s v1.NamespaceMigrationStatus) bool { return s.Name == name })
+		return &statuses[i]
+	}
+
+	// the namespace that is currently being migrated, if any, is finished first
+	active := slices.IndexFunc(namespaces, func(ns *corev1.Namespace) bool {
+		phase := status(ns.Name).Phase
+		return phase == v1.NamespaceMigrationRestartingWorkloads || phase == v1.NamespaceMigrationDeployingWaypoint
+	})
+	var errs []error
+	for i, ns := range namespaces {
+		s := status(ns.Name)
+		if isDone(s.Phase) {
+			continue
+		}
+		if conflict := findConflict(m, ns); conflict != "" {
+			*s = transition(*s, v1.NamespaceMigrationPending, conflict)
+			continue
+		}
+		if active != -1 && active != i {
+			*s = transition(*s, v1.NamespaceMigrationPending, fmt.Sprintf("waiting for the migration of namespace %s to complete", namespaces[active].Name))
+			continue
+		}
+		next, err := r.migrateNamespace(ctx, m, rev, ns, *s)
+		*s = next
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if !isDone(next.Phase) {
+			active = i
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// findConflict returns a message describing why the namespace can't be migrated, or an empty string if it can.
+func findConflict(m *v1.Migration, ns *corev1.Namespace) string {
+	if owner := ns.Annotations[constants.MigrationAnnotation]; owner != "" && owner != m.Name {
+		return fmt.Sprintf("namespace is being migrated by Migration %q", owner)
+	}
+	if owner := ns.Annotations[constants.AmbientEnrollmentAnnotation]; owner != "" {
+		return fmt.Sprintf("namespace is enrolled in ambient mode by AmbientEnrollment %q", owner)
+	}
+	return ""
+}
+
+// migrateNamespace advances the migration of a single namespace by one phase and returns its new status.
+func (r *Reconciler) migrateNamespace(
+	ctx context.Context, m *v1.Migration, rev *v1.IstioRevision, ns *corev1.Namespace, status v1.NamespaceMigrationStatus,
+) (v1.NamespaceMigrationStatus, error) {
+	log := logf.FromContext(ctx).WithValues("Namespace", ns.Name)
+	switch status.Phase {
+	case v1.NamespaceMigrationPending:
+		log.Info("Labeling namespace for ambient mode")
+		if err := r.relabelNamespace(ctx, m, ns); err != nil {
+			return status, err
+		}
+		log.Info("Restarting workloads to remove their sidecars")
+		if err := r.restartWorkloads(ctx, ns.Name); err != nil {
+			return status, err
+		}
+		status = transition(status, v1.NamespaceMigrationRestartingWorkloads, "")
+		fallthrough
+
+	case v1.NamespaceMigrationRestartingWorkloads:
+		remaining, err := r.listPods(ctx, ns.Name, func(pod *corev1.Pod) bool {
+			return revision.GetInjectedRevisionFromPod(pod.Annotations) != ""
+		})
+		if err != nil {
+			return status, err
+		}
+		if len(remaining) > 0 {
+			return transition(status, v1.NamespaceMigrationRestartingWorkloads, describePods(remaining, "still have a sidecar")), nil
+		}
+		if m.Spec.Waypoint == nil {
+			return transition(status, v1.NamespaceMigrated, ""), nil
+		}
+		status = transition(status, v1.NamespaceMigrationDeployingWaypoint, "")
+		fallthrough
+
+	case v1.NamespaceMigrationDeployingWaypoint:
+		if m.Spec.Waypoint == nil {
+			return transition(status, v1.NamespaceMigrated, ""), nil
+		}
+		programmed, err := r.applyWaypoint(ctx, m, rev, ns.Name)
+		if err != nil {
+			return status, err
+		}
+		if !programmed {
+			return transition(status, v1.NamespaceMigrationDeployingWaypoint,
+				fmt.Sprintf("waiting for waypoint Gateway %q to be programmed", m.Spec.Waypoint.Name)), nil
+		}
+		log.Info("Routing namespace traffic through waypoint")
+		patch := client.MergeFrom(ns.DeepCopy())
+		ns.Labels[constants.UseWaypointLabel] = m.Spec.Waypoint.Name
+		ns.Annotations[constants.AmbientWaypointAnnotation] = m.Spec.Waypoint.Name
+		if err := r.Client.Patch(ctx, ns, patch); err != nil {
+			return status, fmt.Errorf("failed to label namespace %q: %w", ns.Name, err)
+		}
+		return transition(status, v1.NamespaceMigrated, ""), nil
+	}
+	return status, nil
+}
+
+// relabelNamespace replaces the sidecar injection labels of the namespace with the istio.io/dataplane-mode=ambient
+// label. The removed labels are stored in an annotation, so that they can be restored on rollback.
+func (r *Reconciler) relabelNamespace(ctx context.Context, m *v1.Migration, ns *corev1.Namespace) error {
+	patch := client.MergeFrom(ns.DeepCopy())
+	if ns.Labels == nil {
+		ns.Labels = map[string]string{}
+	}
+	if ns.Annotations == nil {
+		ns.Annotations = map[string]string{}
+	}
+	if _, found := ns.Annotations[constants.MigrationPreviousLabelsAnnotation]; !found {
+		previous := map[string]string{}
+		for _, label := range sidecarInjectionLabels {
+			if value, found := ns.Labels[label]; found {
+				previous[label] = value
+			}
+		}
+		data, err := json.Marshal(previous)
+		if err != nil {
+			return err
+		}
+		ns.Annotations[constants.MigrationPreviousLabelsAnnotation] = string(data)
+	}
+	for _, label := range sidecarInjectionLabels {
+		delete(ns.Labels, label)
+	}
+	ns.Labels[constants.DataplaneModeLabel] = constants.DataplaneModeAmbient
+	ns.Annotations[constants.MigrationAnnotation] = m.Name
+	if err := r.Client.Patch(ctx, ns, patch); err != nil {
+		return fmt.Errorf("failed to label namespace %q: %w", ns.Name, err)
+	}
+	return nil
+}
+
+// rollbackNamespace advances the rollback of a namespace to sidecar mode and returns its new status.
+func (r *Reconciler) rollbackNamespace(
+	ctx context.Context, m *v1.Migration, ns *corev1.Namespace, status v1.NamespaceMigrationStatus,
+) (v1.NamespaceMigrationStatus, error) {
+	switch {
+	case status.Phase == v1.NamespaceMigrationRolledBack:
+		return status, nil
+
+	case status.Phase == v1.NamespaceMigrationRollingBack:
+		remaining, err := r.listPods(ctx, ns.Name, func(pod *corev1.Pod) bool {
+			return pod.Annotations[constants.AmbientRedirectionAnnotation] == "enabled"
+		})
+		if err != nil {
+			return status, err
+		}
+		if len(remaining) > 0 {
+			return transition(status, v1.NamespaceMigrationRollingBack, describePods(remaining, "are still in ambient mode")), nil
+		}
+		return transition(status, v1.NamespaceMigrationRolledBack, ""), nil
+
+	case !isMigratedBy(ns, m):
+		return transition(status, v1.NamespaceMigrationRolledBack, "the namespace was not migrated"), nil
+	}
+
+	log := logf.FromContext(ctx).WithValues("Namespace", ns.Name)
+	log.Info("Rolling back namespace to sidecar mode")
+	if name := ns.Annotations[constants.AmbientWaypointAnnotation]; name != "" {
+		if err := r.deleteWaypoint(ctx, m, ns.Name, name); err != nil {
+			return status, err
+		}
+	}
+	if err := r.restoreNamespace(ctx, ns); err != nil {
+		return status, err
+	}
+	log.Info("Restarting workloads to inject their sidecars")
+	if err := r.restartWorkloads(ctx, ns.Name); err != nil {
+		return status, err
+	}
+	return transition(status, v1.NamespaceMigrationRollingBack, ""), nil
+}
+
+// restoreNamespace removes the labels set by the migration from the namespace and restores its sidecar injection
+// labels.
+func (r *Reconciler) restoreNamespace(ctx context.Context, ns *corev1.Namespace) error {
+	patch := client.MergeFrom(ns.DeepCopy())
+	if ns.Labels == nil {
+		ns.Labels = map[string]string{}
+	}
+	if ns.Labels[constants.DataplaneModeLabel] == constants.DataplaneModeAmbient {
+		delete(ns.Labels, constants.DataplaneModeLabel)
+	}
+	if waypointName := ns.Annotations[constants.AmbientWaypointAnnotation]; waypointName != "" && ns.Labels[constants.UseWaypointLabel] == waypointName {
+		delete(ns.Labels, constants.UseWaypointLabel)
+	}
+	if data := ns.Annotations[constants.MigrationPreviousLabelsAnnotation]; data != "" {
+		previous := map[string]string{}
+		if err := json.Unmarshal([]byte(data), &previous); err != nil {
+			return reconciler.NewValidationError(fmt.Sprintf("namespace %s has an invalid %s annotation: %v", ns.Name,
+				constants.MigrationPreviousLabelsAnnotation, err))
+		}
+		for label, value := range previous {
+			ns.Labels[label] = value
+		}
+	}
+	delete(ns.Annotations, constants.MigrationAnnotation)
+	delete(ns.Annotations, constants.MigrationPreviousLabelsAnnotation)
+	delete(ns.Annotations, constants.AmbientWaypointAnnotation)
+	if err := r.Client.Patch(ctx, ns, patch); err != nil {
+		return fmt.Errorf("failed to restore labels of namespace %q: %w", ns.Name, err)
+	}
+	return nil
+}
+
+// restartWorkloads restarts the Deployments, StatefulSets and DaemonSets in the namespace the same way as
+// `kubectl rollout restart`, so that their pods are recreated.
+func (r *Reconciler) restartWorkloads(ctx context.Context, namespace string) error {
+	restartedAt := time.Now().Format(time.RFC3339)
+	restart := func(obj client.Object, template *corev1.PodTemplateSpec) error {
+		patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations[restartedAtAnnotation] = restartedAt
+		if err := r.Client.Patch(ctx, obj, patch); err != nil {
+			return fmt.Errorf("failed to restart %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+		}
+		return nil
+	}
+
+	var errs []error
+	deployments := &appsv1.DeploymentList{}
+	if err := r.Client.List(ctx, deployments, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list Deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		errs = append(errs, restart(&deployments.Items[i], &deployments.Items[i].Spec.Template))
+	}
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := r.Client.List(ctx, statefulSets, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list StatefulSets: %w", err)
+	}
+	for i := range statefulSets.Items {
+		errs = append(errs, restart(&statefulSets.Items[i], &statefulSets.Items[i].Spec.Template))
+	}
+	daemonSets := &appsv1.DaemonSetList{}
+	if err := r.Client.List(ctx, daemonSets, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list DaemonSets: %w", err)
+	}
+	for i := range daemonSets.Items {
+		errs = append(errs, restart(&daemonSets.Items[i], &daemonSets.Items[i].Spec.Template))
+	}
+	return errors.Join(errs...)
+}
+
+// listPods returns the names of the pods in the namespace that aren't being deleted and that match the filter.
+func (r *Reconciler) listPods(ctx context.Context, namespace string, filter func(*corev1.Pod) bool) ([]string, error) {
+	pods := &corev1.PodList{}
+	if err := r.Client.List(ctx, pods, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
+	}
+	var names []string
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp == nil && pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed && filter(pod) {
+			names = append(names, pod.Name)
+		}
+	}
+	return names, nil
+}
+
+// describePods returns a message listing the pods. Pods that aren't managed by a Deployment, StatefulSet or
+// DaemonSet aren't restarted by the migration and must be recreated by the user.
+func describePods(names []string, state string) string {
+	const maxNames = 5
+	listed := names
+	if len(listed) > maxNames {
+		listed = listed[:maxNames]
+	}
+	message := fmt.Sprintf("%d pods %s: %s", len(names), state, strings.Join(listed, ", "))
+	if len(names) > maxNames {
+		message += ", ..."
+	}
+	return message + "; pods that aren't managed by a Deployment, StatefulSet or DaemonSet must be recreated manually"
+}
+
+// applyWaypoint creates or updates the waypoint Gateway in the namespace and returns whether it has been programmed.
+// A Gateway with the same name that wasn't created by the migration is used as is.
+func (r *Reconciler) applyWaypoint(ctx context.Context, m *v1.Migration, rev *v1.IstioRevision, namespace string) (bool, error) {
+	requirement := *m.Spec.Waypoint
+	gw := waypoint.NewGateway(namespace, requirement.Name)
+	err := r.Client.Get(ctx, client.ObjectKeyFromObject(gw), gw)
+	switch {
+	case apierrors.IsNotFound(err):
+		if err := waypoint.Configure(gw, requirement, waypoint.RevisionName(rev)); err != nil {
+			return false, err
+		}
+		gw.SetAnnotations(map[string]string{constants.MigrationAnnotation: m.Name})
+		err = r.Client.Create(ctx, gw)
+	case err == nil && gw.GetAnnotations()[constants.MigrationAnnotation] == m.Name:
+		patch := client.MergeFrom(gw.DeepCopy())
+		if err := waypoint.Configure(gw, requirement, waypoint.RevisionName(rev)); err != nil {
+			return false, err
+		}
+		err = r.Client.Patch(ctx, gw, patch)
+	}
+	if meta.IsNoMatchError(err) {
+		return false, reconciler.NewTransientError("the Gateway API CRDs, which are required to deploy waypoints, are not installed")
+	} else if err != nil {
+		return false, fmt.Errorf("failed to apply waypoint Gateway %s/%s: %w", namespace, requirement.Name, err)
+	}
+	return waypoint.IsProgrammed(gw), nil
+}
+
+// deleteWaypoint deletes the waypoint Gateway, if it was created by the migration.
+func (r *Reconciler) deleteWaypoint(ctx context.Context, m *v1.Migration, namespace, name string) error {
+	gw := waypoint.NewGateway(namespace, name)
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(gw), gw); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get waypoint Gateway %s/%s: %w", namespace, name, err)
+	}
+	if gw.GetAnnotations()[constants.MigrationAnnotation] != m.Name {
+		return nil
+	}
+	if err := r.Client.Delete(ctx, gw); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete waypoint Gateway %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	logger := mgr.GetLogger().WithName("ctrlr").WithName("migration")
+
+	// mainObjectHandler handles the Migration watch events
+	mainObjectHandler := wrapEventHandler(logger, &handler.EnqueueRequestForObject{})
+
+	// allMigrationsHandler triggers reconciliation of all migrations whenever a namespace or one of the components
+	// that the migration depends on changes
+	allMigrationsHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapToAllMigrations))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			LogConstructor: func(req *reconcile.Request) logr.Logger {
+				log := logger
+				if req != nil {
+					log = log.WithValues("Migration", req.Name)
+				}
+				return log
+			},
+			MaxConcurrentReconciles: r.Config.MaxConcurrentReconciles,
+			RateLimiter:             reconciler.RateLimiter(r.Config.BackoffPolicyFor(controllerName)),
+		}).
+		// we use the Watches function instead of For(), so that we can wrap the handler so that events that cause the object to be enqueued are logged
+		Watches(&v1.Migration{}, mainObjectHandler, builder.WithPredicates(watches.AsPredicate(watches.IgnoreStatusChanges()))).
+		Named(controllerName).
+		Watches(&corev1.Namespace{}, allMigrationsHandler, builder.WithPredicates(watches.AsPredicate(watches.IgnoreStatusChanges()))).
+
+		// cluster-scoped resources
+		Watches(&v1.Istio{}, allMigrationsHandler).
+		Watches(&v1.IstioRevision{}, allMigrationsHandler).
+		Watches(&v1.IstioCNI{}, allMigrationsHandler).
+		Watches(&v1.ZTunnel{}, allMigrationsHandler).
+		Complete(reconciler.NewStandardReconciler[*v1.Migration](r.Client, r.Reconcile).WithSuspendFunc(r.Suspend))
+}
+
+func (r *Reconciler) determineStatus(m *v1.Migration, p *progress, reconcileErr error) v1.MigrationStatus {
+	reconciledCondition := r.determineReconciledCondition(reconcileErr)
+
+	status := *m.Status.DeepCopy()
+	status.ObservedGeneration = m.Generation
+	retry := reconciler.NextRetry(r.Config.BackoffPolicyFor(controllerName), m.Status.RetryCount, reconcileErr)
+	status.RetryCount, status.NextRetryTime = retry.Count, retry.Time
+	if p != nil {
+		// the progress is reported even if reconciliation failed, because the phase of each namespace must be
+		// recorded once its labels have been changed
+		status.IstioRevision = p.rev.Name
+		status.Phase = p.phase
+		status.Findings = p.findings
+		status.Namespaces = p.namespaces
+		status.SetCondition(determinePreflightCondition(p))
+		status.SetCondition(determineMessageCondition(v1.MigrationConditionComponentsReady, v1.MigrationReasonComponentsNotReady, p.components))
+		status.SetCondition(determineMessageCondition(v1.MigrationConditionAmbientEnabled, v1.MigrationReasonAmbientNotEnabled, p.ambient))
+		status.SetCondition(determineNamespacesMigratedCondition(p.namespaces))
+	}
+	status.SetCondition(reconciledCondition)
+	status.State = reconciler.DeriveState(v1.MigrationReasonCompleted,
+		reconciledCondition,
+		status.GetCondition(v1.MigrationConditionPreflightChecksPassed),
+		status.GetCondition(v1.MigrationConditionComponentsReady),
+		status.GetCondition(v1.MigrationConditionAmbientEnabled),
+		status.GetCondition(v1.MigrationConditionNamespacesMigrated))
+	return status
+}
+
+func (r *Reconciler) updateStatus(ctx context.Context, m *v1.Migration, p *progress, reconcileErr error) (ctrl.Result, error) {
+	status := r.determineStatus(m, p, reconcileErr)
+	var result ctrl.Result
+	if reconcileErr == nil && status.Phase != v1.MigrationPhaseCompleted {
+		result.RequeueAfter = checkInterval
+	}
+	return result, reconciler.UpdateStatus(ctx, r.Client, m, m.Status, status, nil)
+}
+
+func (r *Reconciler) determineReconciledCondition(err error) v1.StatusCondition {
+	c := v1.StatusCondition{Type: v1.MigrationConditionReconciled}
+	if err == nil {
+		c.Status = metav1.ConditionTrue
+		c.Reason = v1.ConditionReason(v1.MigrationConditionReconciled)
+	} else {
+		c.Status = metav1.ConditionFalse
+		c.Message = err.Error()
+		if reconciler.IsReferenceNotFoundError(err) {
+			c.Reason = v1.MigrationReasonReferenceNotFound
+		} else {
+			c.Reason, c.Message = reconciler.DescribeReconcileError(err, v1.MigrationReasonReconcileError)
+		}
+	}
+	return c
+}
+
+func determinePreflightCondition(p *progress) v1.StatusCondition {
+	c := v1.StatusCondition{Type: v1.MigrationConditionPreflightChecksPassed}
+	blocking := 0
+	for _, f := range p.findings {
+		if f.Severity == v1.MigrationFindingBlocking {
+			blocking++
+		}
+	}
+	if p.blocked {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1.MigrationReasonUnsupportedFeatures
+		c.Message = fmt.Sprintf("%d resources use features that aren't supported in ambient mode; see status.findings", blocking)
+	} else {
+		c.Status = metav1.ConditionTrue
+		c.Reason = v1.ConditionReason(v1.MigrationConditionPreflightChecksPassed)
+		if blocking > 0 {
+			c.Message = fmt.Sprintf("%d resources use features that aren't supported in ambient mode, but spec.skipPreflightChecks is set", blocking)
+		}
+	}
+	return c
+}
+
+// determineMessageCondition returns a condition that is True if the message is empty, and False with the given
+// reason and the message otherwise.
+func determineMessageCondition(conditionType v1.MigrationConditionType, reason v1.MigrationConditionReason, message string) v1.StatusCondition {
+	if message == "" {
+		return v1.StatusCondition{Type: conditionType, Status: metav1.ConditionTrue, Reason: v1.ConditionReason(conditionType)}
+	}
+	return v1.StatusCondition{Type: conditionType, Status: metav1.ConditionFalse, Reason: reason, Message: message}
+}
+
+func determineNamespacesMigratedCondition(namespaces []v1.NamespaceMigrationStatus) v1.StatusCondition {
+	c := v1.StatusCondition{Type: v1.MigrationConditionNamespacesMigrated}
+	done := 0
+	for _, ns := range namespaces {
+		if isDone(ns.Phase) {
+			done++
+		}
+	}
+	if done == len(namespaces) {
+		c.Status = metav1.ConditionTrue
+		c.Reason = v1.ConditionReason(v1.MigrationConditionNamespacesMigrated)
+	} else {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1.MigrationReasonInProgress
+	}
+	c.Message = fmt.Sprintf("%d of %d namespaces migrated or rolled back", done, len(namespaces))
+	return c
+}
+
+func (r *Reconciler) mapToAllMigrations(ctx context.Context, _ client.Object) []reconcile.Request {
+	list := v1.MigrationList{}
+	if err := r.Client.List(ctx, &list); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list Migrations")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, m := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: m.Name}})
+	}
+	return requests
+}
+
+func wrapEventHandler(logger logr.Logger, handler handler.EventHandler) handler.EventHandler {
+	return enqueuelogger.WrapIfNecessary(v1.MigrationKind, logger, handler)
+}
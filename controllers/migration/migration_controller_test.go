@@ -0,0 +1,366 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	"github.com/istio-ecosystem/sail-operator/pkg/waypoint"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"istio.io/istio/pkg/ptr"
+)
+
+const (
+	migrationName = "default"
+	istioName     = "default"
+	activeRev     = "default-v1-30-0"
+)
+
+func TestDoReconcileMigratesAndRollsBackNamespace(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "bookinfo",
+		Labels: map[string]string{"team": "a", constants.IstioInjectionLabel: constants.IstioInjectionEnabledValue},
+	}}
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: ns.Name, Name: "productpage"}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   ns.Name,
+		Name:        "productpage-1",
+		Annotations: map[string]string{constants.IstioRevLabel: activeRev},
+		OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "productpage-1", UID: "productpage-1", Controller: ptr.Of(true)},
+		},
+	}}
+	m := newMigration(&v1.WaypointRequirement{Name: "waypoint", TrafficType: v1.WaypointTrafficTypeService})
+
+	cl := newFakeClient(newIstio(), newOwnedRevision(false), newZTunnel(), newIstioCNI(), ns, deployment, pod, m)
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	// ambient mode is enabled on the Istio resource that owns the revision
+	p, err := r.doReconcile(ctx, m)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(p.phase).To(Equal(v1.MigrationPhaseEnableAmbient))
+	g.Expect(p.namespaces).To(ConsistOf(HaveField("Phase", v1.NamespaceMigrationPending)))
+	istio := &v1.Istio{}
+	g.Expect(cl.Get(ctx, types.NamespacedName{Name: istioName}, istio)).To(Succeed())
+	g.Expect(istio.Spec.Values.Pilot.Env).To(HaveKeyWithValue(pilotEnableAmbientEnv, "true"))
+	m.Status.Namespaces = p.namespaces
+
+	// once the revision has ambient mode enabled, the namespace is relabeled and its workloads are restarted
+	rev := &v1.IstioRevision{}
+	g.Expect(cl.Get(ctx, types.NamespacedName{Name: activeRev}, rev)).To(Succeed())
+	rev.Spec.Values.Pilot = &v1.PilotConfig{Env: map[string]string{pilotEnableAmbientEnv: "true"}}
+	g.Expect(cl.Update(ctx, rev)).To(Succeed())
+
+	p, err = r.doReconcile(ctx, m)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(p.phase).To(Equal(v1.MigrationPhaseMigrateNamespaces))
+	g.Expect(p.namespaces).To(ConsistOf(HaveField("Phase", v1.NamespaceMigrationRestartingWorkloads)))
+	g.Expect(p.namespaces[0].Message).To(ContainSubstring(pod.Name))
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(ns), ns)).To(Succeed())
+	g.Expect(ns.Labels).To(Equal(map[string]string{"team": "a", constants.DataplaneModeLabel: constants.DataplaneModeAmbient}))
+	g.Expect(ns.Annotations).To(HaveKeyWithValue(constants.MigrationAnnotation, migrationName))
+	g.Expect(ns.Annotations).To(HaveKeyWithValue(constants.MigrationPreviousLabelsAnnotation, `{"istio-injection":"enabled"}`))
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+	g.Expect(deployment.Spec.Template.Annotations).To(HaveKey(restartedAtAnnotation))
+	m.Status.Namespaces = p.namespaces
+
+	// once the pods no longer have a sidecar, the waypoint is deployed
+	g.Expect(cl.Delete(ctx, pod)).To(Succeed())
+	p, err = r.doReconcile(ctx, m)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(p.namespaces).To(ConsistOf(HaveField("Phase", v1.NamespaceMigrationDeployingWaypoint)))
+	gw := getGateway(g, cl, ns.Name, "waypoint")
+	g.Expect(gw.GetAnnotations()).To(HaveKeyWithValue(constants.MigrationAnnotation, migrationName))
+	g.Expect(gw.GetLabels()).To(HaveKeyWithValue(constants.WaypointForLabel, "service"))
+	m.Status.Namespaces = p.namespaces
+
+	// once the waypoint is programmed, the namespace uses it and the migration completes
+	g.Expect(unstructured.SetNestedSlice(gw.Object, []any{map[string]any{"type": "Programmed", "status": "True"}},
+		"status", "conditions")).To(Succeed())
+	g.Expect(cl.Update(ctx, gw)).To(Succeed())
+	p, err = r.doReconcile(ctx, m)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(p.phase).To(Equal(v1.MigrationPhaseCompleted))
+	g.Expect(p.namespaces).To(ConsistOf(HaveField("Phase", v1.NamespaceMigrated)))
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(ns), ns)).To(Succeed())
+	g.Expect(ns.Labels).To(HaveKeyWithValue(constants.UseWaypointLabel, "waypoint"))
+	m.Status.Namespaces = p.namespaces
+
+	// rolling back the namespace removes the waypoint and restores the sidecar injection label
+	m.Spec.RollbackNamespaces = []string{ns.Name}
+	ambientPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   ns.Name,
+		Name:        "productpage-2",
+		Annotations: map[string]string{constants.AmbientRedirectionAnnotation: "enabled"},
+	}}
+	g.Expect(cl.Create(ctx, ambientPod)).To(Succeed())
+	p, err = r.doReconcile(ctx, m)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(p.phase).To(Equal(v1.MigrationPhaseMigrateNamespaces))
+	g.Expect(p.namespaces).To(ConsistOf(HaveField("Phase", v1.NamespaceMigrationRollingBack)))
+	g.Expect(cl.Get(ctx, types.NamespacedName{Namespace: ns.Name, Name: "waypoint"}, waypoint.NewGateway("", ""))).NotTo(Succeed())
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(ns), ns)).To(Succeed())
+	g.Expect(ns.Labels).To(Equal(map[string]string{"team": "a", constants.IstioInjectionLabel: constants.IstioInjectionEnabledValue}))
+	g.Expect(ns.Annotations).To(BeEmpty())
+	m.Status.Namespaces = p.namespaces
+
+	// the rollback completes once no pod is in ambient mode
+	g.Expect(cl.Delete(ctx, ambientPod)).To(Succeed())
+	p, err = r.doReconcile(ctx, m)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(p.phase).To(Equal(v1.MigrationPhaseCompleted))
+	g.Expect(p.namespaces).To(ConsistOf(HaveField("Phase", v1.NamespaceMigrationRolledBack)))
+}
+
+func TestDoReconcileBlockedByPreflightCheck(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "bookinfo",
+		Labels: map[string]string{"team": "a", constants.IstioInjectionLabel: constants.IstioInjectionEnabledValue},
+	}}
+	envoyFilter := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "networking.istio.io/v1alpha3",
+		"kind":       "EnvoyFilter",
+		"metadata":   map[string]any{"namespace": ns.Name, "name": "lua"},
+		"spec":       map[string]any{},
+	}}
+	m := newMigration(nil)
+
+	cl := newFakeClient(newIstio(), newOwnedRevision(true), newZTunnel(), newIstioCNI(), ns, envoyFilter, m)
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	p, err := r.doReconcile(ctx, m)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(p.phase).To(Equal(v1.MigrationPhasePreflightCheck))
+	g.Expect(p.blocked).To(BeTrue())
+	g.Expect(p.findings).To(ConsistOf(HaveField("Name", "lua")))
+	g.Expect(p.namespaces).To(ConsistOf(HaveField("Phase", v1.NamespaceMigrationPending)))
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(ns), ns)).To(Succeed())
+	g.Expect(ns.Labels).To(HaveKey(constants.IstioInjectionLabel))
+
+	m.Spec.SkipPreflightChecks = true
+	p, err = r.doReconcile(ctx, m)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(p.phase).To(Equal(v1.MigrationPhaseCompleted))
+	g.Expect(p.blocked).To(BeFalse())
+	g.Expect(p.findings).To(HaveLen(1))
+	g.Expect(p.namespaces).To(ConsistOf(HaveField("Phase", v1.NamespaceMigrated)))
+}
+
+func TestDoReconcileComponentsNotReady(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "bookinfo", Labels: map[string]string{"team": "a"}}}
+	cni := newIstioCNI()
+	cni.Spec.Profile = ""
+	m := newMigration(nil)
+
+	cl := newFakeClient(newIstio(), newOwnedRevision(false), cni, ns, m)
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	p, err := r.doReconcile(ctx, m)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(p.phase).To(Equal(v1.MigrationPhaseVerifyComponents))
+	g.Expect(p.components).To(ContainSubstring("no ZTunnel resource exists"))
+	g.Expect(p.components).To(ContainSubstring("doesn't have ambient mode enabled"))
+	g.Expect(p.ambient).NotTo(BeEmpty())
+
+	// ambient mode isn't enabled until the components are ready
+	istio := &v1.Istio{}
+	g.Expect(cl.Get(ctx, types.NamespacedName{Name: istioName}, istio)).To(Succeed())
+	g.Expect(istio.Spec.Values).To(BeNil())
+}
+
+func TestDoReconcileSkipsNamespaceOwnedByAmbientEnrollment(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	enrolled := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        "enrolled",
+		Labels:      map[string]string{"team": "a", constants.DataplaneModeLabel: constants.DataplaneModeAmbient},
+		Annotations: map[string]string{constants.AmbientEnrollmentAnnotation: "other"},
+	}}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "bookinfo", Labels: map[string]string{"team": "a"}}}
+	m := newMigration(nil)
+
+	cl := newFakeClient(newIstio(), newOwnedRevision(true), newZTunnel(), newIstioCNI(), enrolled, ns, m)
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	p, err := r.doReconcile(ctx, m)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(p.phase).To(Equal(v1.MigrationPhaseMigrateNamespaces))
+	g.Expect(p.namespaces).To(HaveLen(2))
+	g.Expect(p.namespaces[0].Name).To(Equal("bookinfo"))
+	g.Expect(p.namespaces[0].Phase).To(Equal(v1.NamespaceMigrated))
+	g.Expect(p.namespaces[1].Name).To(Equal("enrolled"))
+	g.Expect(p.namespaces[1].Phase).To(Equal(v1.NamespaceMigrationPending))
+	g.Expect(p.namespaces[1].Message).To(ContainSubstring("AmbientEnrollment"))
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(enrolled), enrolled)).To(Succeed())
+	g.Expect(enrolled.Annotations).NotTo(HaveKey(constants.MigrationAnnotation))
+}
+
+func TestDoReconcileReferenceNotFound(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	m := newMigration(nil)
+	cl := newFakeClient(m)
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	p, err := r.doReconcile(ctx, m)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(reconciler.IsReferenceNotFoundError(err)).To(BeTrue())
+	g.Expect(p).To(BeNil())
+}
+
+func TestDetermineStatus(t *testing.T) {
+	g := NewWithT(t)
+	r := NewReconciler(newReconcilerTestConfig(t), nil, scheme.Scheme)
+	rev := newOwnedRevision(true)
+
+	status := r.determineStatus(newMigration(nil), &progress{
+		rev:      rev,
+		phase:    v1.MigrationPhasePreflightCheck,
+		blocked:  true,
+		findings: []v1.MigrationFinding{{Severity: v1.MigrationFindingBlocking, Kind: "EnvoyFilter", Namespace: "a", Name: "lua"}},
+		namespaces: []v1.NamespaceMigrationStatus{
+			{Name: "a", Phase: v1.NamespaceMigrationPending},
+		},
+	}, nil)
+	g.Expect(status.IstioRevision).To(Equal(activeRev))
+	g.Expect(status.Phase).To(Equal(v1.MigrationPhasePreflightCheck))
+	g.Expect(status.GetCondition(v1.MigrationConditionReconciled).Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(status.GetCondition(v1.MigrationConditionPreflightChecksPassed).Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(status.GetCondition(v1.MigrationConditionNamespacesMigrated).Message).To(Equal("0 of 1 namespaces migrated or rolled back"))
+	g.Expect(status.State).To(Equal(v1.MigrationReasonUnsupportedFeatures))
+
+	status = r.determineStatus(newMigration(nil), &progress{
+		rev:        rev,
+		phase:      v1.MigrationPhaseCompleted,
+		namespaces: []v1.NamespaceMigrationStatus{{Name: "a", Phase: v1.NamespaceMigrated}},
+	}, nil)
+	g.Expect(status.GetCondition(v1.MigrationConditionNamespacesMigrated).Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(status.State).To(Equal(v1.MigrationReasonCompleted))
+
+	status = r.determineStatus(newMigration(nil), nil, reconciler.NewReferenceNotFoundError("not found", nil))
+	g.Expect(status.GetCondition(v1.MigrationConditionReconciled).Reason).To(Equal(v1.MigrationReasonReferenceNotFound))
+	g.Expect(status.State).To(Equal(v1.MigrationReasonReferenceNotFound))
+}
+
+func newMigration(waypoint *v1.WaypointRequirement) *v1.Migration {
+	return &v1.Migration{
+		ObjectMeta: metav1.ObjectMeta{Name: migrationName},
+		Spec: v1.MigrationSpec{
+			TargetRef:         v1.TargetReference{Kind: v1.IstioKind, Name: istioName},
+			NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			Waypoint:          waypoint,
+		},
+	}
+}
+
+func newIstio() *v1.Istio {
+	return &v1.Istio{
+		ObjectMeta: metav1.ObjectMeta{Name: istioName, UID: "istio-uid"},
+		Status:     v1.IstioStatus{ActiveRevisionName: activeRev},
+	}
+}
+
+func newOwnedRevision(ambient bool) *v1.IstioRevision {
+	rev := &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: activeRev,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: v1.GroupVersion.String(),
+					Kind:       v1.IstioKind,
+					Name:       istioName,
+					UID:        "istio-uid",
+					Controller: ptr.Of(true),
+				},
+			},
+		},
+		Spec: v1.IstioRevisionSpec{Values: &v1.Values{}},
+		Status: v1.IstioRevisionStatus{
+			Conditions: []v1.StatusCondition{{Type: v1.IstioRevisionConditionReady, Status: metav1.ConditionTrue}},
+		},
+	}
+	if ambient {
+		rev.Spec.Values.Profile = ptr.Of("ambient")
+	}
+	return rev
+}
+
+func newZTunnel() *v1.ZTunnel {
+	return &v1.ZTunnel{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Status: v1.ZTunnelStatus{
+			Conditions: []v1.StatusCondition{{Type: v1.ZTunnelConditionReady, Status: metav1.ConditionTrue}},
+		},
+	}
+}
+
+func newIstioCNI() *v1.IstioCNI {
+	return &v1.IstioCNI{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec:       v1.IstioCNISpec{Profile: "ambient"},
+		Status: v1.IstioCNIStatus{
+			Conditions: []v1.StatusCondition{{Type: v1.IstioCNIConditionReady, Status: metav1.ConditionTrue}},
+		},
+	}
+}
+
+func getGateway(g *WithT, cl client.Client, namespace, name string) *unstructured.Unstructured {
+	gw := waypoint.NewGateway("", "")
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, gw)).To(Succeed())
+	return gw
+}
+
+func newFakeClient(objs ...client.Object) client.Client {
+	return fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&v1.Migration{}).
+		Build()
+}
+
+func newReconcilerTestConfig(t *testing.T) config.ReconcilerConfig {
+	return config.ReconcilerConfig{
+		ResourceFS:              os.DirFS(t.TempDir()),
+		Platform:                config.PlatformKubernetes,
+		DefaultProfile:          "",
+		MaxConcurrentReconciles: 1,
+	}
+}
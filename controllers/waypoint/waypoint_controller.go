@@ -0,0 +1,543 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package waypoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/istio-ecosystem/sail-operator/pkg/revision"
+	"github.com/istio-ecosystem/sail-operator/pkg/watches"
+	sharedwaypoint "github.com/istio-ecosystem/sail-operator/pkg/waypoint"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	controllerName = "waypoint"
+
+	// checkInterval is the interval at which the Gateway of a waypoint that isn't programmed yet is checked. The
+	// Gateway API CRDs may not be installed, so Gateways aren't watched.
+	checkInterval = 15 * time.Second
+)
+
+// Reconciler reconciles a Waypoint object
+type Reconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Config config.ReconcilerConfig
+}
+
+func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *runtime.Scheme) *Reconciler {
+	return &Reconciler{
+		Client: client,
+		Scheme: scheme,
+		Config: cfg,
+	}
+}
+
+// result holds the outcome of reconciling a Waypoint.
+type result struct {
+	rev        *v1.IstioRevision
+	programmed bool
+	bound      string   // describes the workloads that use the waypoint
+	conflicts  []string // the namespace or pods whose istio.io/use-waypoint label is set to another waypoint
+}
+
+// +kubebuilder:rbac:groups=sailoperator.io,resources=waypoints,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=sailoperator.io,resources=waypoints/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=sailoperator.io,resources=waypoints/finalizers,verbs=update
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.14.1/pkg/reconcile
+func (r *Reconciler) Reconcile(ctx context.Context, w *v1.Waypoint) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	res, reconcileErr := r.doReconcile(ctx, w)
+
+	log.Info("Reconciliation done. Updating status.")
+	result, statusErr := r.updateStatus(ctx, w, res, reconcileErr)
+
+	return result, errors.Join(reconcileErr, statusErr)
+}
+
+// Suspend updates the status of a Waypoint whose reconciliation is paused.
+func (r *Reconciler) Suspend(ctx context.Context, w *v1.Waypoint) error {
+	status := *w.Status.DeepCopy()
+	status.SetCondition(reconciler.SuspendedCondition(v1.WaypointConditionReconciled, v1.WaypointReasonSuspended))
+	status.State = v1.WaypointReasonSuspended
+	status.RetryCount, status.NextRetryTime = 0, nil
+	return reconciler.UpdateStatus(ctx, r.Client, w, w.Status, status, nil)
+}
+
+// doReconcile deploys the waypoint Gateway and sets the istio.io/use-waypoint label on the namespace or pods in
+// spec.scope.
+func (r *Reconciler) doReconcile(ctx context.Context, w *v1.Waypoint) (*result, error) {
+	log := logf.FromContext(ctx)
+	if w.Spec.TargetRef.Kind == "" || w.Spec.TargetRef.Name == "" {
+		return nil, reconciler.NewValidationError("spec.targetRef not set")
+	}
+
+	log.Info("Retrieving referenced IstioRevision")
+	rev, err := r.resolveRevision(ctx, w)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, reconciler.NewReferenceNotFoundError("referenced resource does not exist", err)
+		}
+		return nil, err
+	}
+	res := &result{rev: rev}
+
+	log.Info("Applying waypoint Gateway", "IstioRevision", rev.Name)
+	if res.programmed, err = r.applyGateway(ctx, w, rev); err != nil {
+		return res, err
+	}
+
+	ns := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: w.Namespace}, ns); err != nil {
+		return res, fmt.Errorf("failed to get namespace %s: %w", w.Namespace, err)
+	}
+	if conflict, err := r.bindNamespace(ctx, w, ns, w.Spec.Scope == v1.WaypointScopeNamespace); err != nil {
+		return res, err
+	} else if conflict != "" {
+		res.conflicts = append(res.conflicts, conflict)
+	}
+
+	bound, conflicts, err := r.bindPods(ctx, w, func(pod *corev1.Pod) bool {
+		return w.Spec.Scope == v1.WaypointScopeServiceAccounts && slices.Contains(w.Spec.ServiceAccounts, pod.Spec.ServiceAccountName)
+	})
+	if err != nil {
+		return res, err
+	}
+	res.conflicts = append(res.conflicts, conflicts...)
+
+	switch w.Spec.Scope {
+	case v1.WaypointScopeServiceAccounts:
+		res.bound = fmt.Sprintf("%d pods use the waypoint", bound)
+	case v1.WaypointScopeNone:
+		res.bound = "the istio.io/use-waypoint label is managed by the user"
+	default:
+		res.bound = fmt.Sprintf("namespace %s uses the waypoint", w.Namespace)
+	}
+	return res, nil
+}
+
+// resolveRevision returns the IstioRevision that controls the waypoint. When the active revision of the referenced
+// Istio changes, the waypoint is only moved to the new revision once the revision is ready.
+func (r *Reconciler) resolveRevision(ctx context.Context, w *v1.Waypoint) (*v1.IstioRevision, error) {
+	rev, err := revision.GetIstioRevisionFromTargetReference(ctx, r.Client, w.Spec.TargetRef)
+	if err != nil {
+		return nil, err
+	}
+	previous := w.Status.IstioRevision
+	if w.Spec.TargetRef.Kind != v1.IstioKind || previous == "" || previous == rev.Name ||
+		rev.Status.GetCondition(v1.IstioRevisionConditionReady).Status == metav1.ConditionTrue {
+		return rev, nil
+	}
+
+	previousRev := &v1.IstioRevision{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: previous}, previousRev); err != nil {
+		if apierrors.IsNotFound(err) {
+			return rev, nil
+		}
+		return nil, err
+	}
+	logf.FromContext(ctx).Info("Waiting for the new active revision to be ready before moving the waypoint", "IstioRevision", rev.Name)
+	return previousRev, nil
+}
+
+// applyGateway creates or updates the waypoint Gateway and the ConfigMap that holds its deployment parameters, and
+// returns whether the Gateway has been programmed.
+func (r *Reconciler) applyGateway(ctx context.Context, w *v1.Waypoint, rev *v1.IstioRevision) (bool, error) {
+	configMapName := ""
+	if w.Spec.Resources != nil {
+		configMapName = sharedwaypoint.ParametersConfigMapName(w.Name)
+		if err := r.applyParameters(ctx, w, configMapName); err != nil {
+			return false, err
+		}
+	} else if err := r.deleteParameters(ctx, w); err != nil {
+		return false, err
+	}
+
+	gw := sharedwaypoint.NewGateway(w.Namespace, w.Name)
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, gw, func() error {
+		if gw.GetResourceVersion() != "" && !metav1.IsControlledBy(gw, w) {
+			return reconciler.NewNameAlreadyExistsError(fmt.Sprintf("Gateway %q already exists and isn't managed by this Waypoint", w.Name), nil)
+		}
+		requirement := v1.WaypointRequirement{Name: w.Name, TrafficType: w.Spec.TrafficType}
+		if err := sharedwaypoint.Configure(gw, requirement, sharedwaypoint.RevisionName(rev)); err != nil {
+			return err
+		}
+		if err := sharedwaypoint.SetParametersRef(gw, configMapName); err != nil {
+			return err
+		}
+		return controllerutil.SetControllerReference(w, gw, r.Scheme)
+	})
+	if meta.IsNoMatchError(err) {
+		return false, reconciler.NewTransientError("the Gateway API CRDs, which are required to deploy waypoints, are not installed")
+	} else if reconciler.IsNameAlreadyExistsError(err) {
+		return false, err
+	} else if err != nil {
+		return false, fmt.Errorf("failed to apply waypoint Gateway %s/%s: %w", w.Namespace, w.Name, err)
+	}
+	return sharedwaypoint.IsProgrammed(gw), nil
+}
+
+func (r *Reconciler) applyParameters(ctx context.Context, w *v1.Waypoint, name string) error {
+	data, err := sharedwaypoint.ParametersData(w.Spec.Resources)
+	if err != nil {
+		return err
+	}
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: w.Namespace, Name: name}}
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		if cm.ResourceVersion != "" && !metav1.IsControlledBy(cm, w) {
+			return reconciler.NewNameAlreadyExistsError(fmt.Sprintf("ConfigMap %q already exists and isn't managed by this Waypoint", name), nil)
+		}
+		if cm.Labels == nil {
+			cm.Labels = map[string]string{}
+		}
+		cm.Labels[constants.ManagedByLabelKey] = constants.ManagedByLabelValue
+		cm.Data = data
+		return controllerutil.SetControllerReference(w, cm, r.Scheme)
+	})
+	if err != nil && !reconciler.IsNameAlreadyExistsError(err) {
+		return fmt.Errorf("failed to apply ConfigMap %s/%s: %w", w.Namespace, name, err)
+	}
+	return err
+}
+
+// deleteParameters deletes the ConfigMap that holds the deployment parameters, if it's managed by the Waypoint.
+func (r *Reconciler) deleteParameters(ctx context.Context, w *v1.Waypoint) error {
+	cm := &corev1.ConfigMap{}
+	name := sharedwaypoint.ParametersConfigMapName(w.Name)
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: w.Namespace, Name: name}, cm); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(cm, w) {
+		return nil
+	}
+	if err := r.Client.Delete(ctx, cm); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete ConfigMap %s/%s: %w", w.Namespace, name, err)
+	}
+	return nil
+}
+
+// Finalize removes the istio.io/use-waypoint labels set by the Waypoint. The Gateway and the ConfigMap are deleted
+// by the garbage collector.
+func (r *Reconciler) Finalize(ctx context.Context, w *v1.Waypoint) error {
+	ns := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: w.Namespace}, ns); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	_, err := r.bindNamespace(ctx, w, ns, false)
+	if err != nil {
+		return err
+	}
+	_, _, err = r.bindPods(ctx, w, func(*corev1.Pod) bool { return false })
+	return err
+}
+
+// bindNamespace sets or removes the istio.io/use-waypoint label on the namespace. If the label is set to another
+// waypoint by someone else, it's left intact and a message describing the conflict is returned.
+func (r *Reconciler) bindNamespace(ctx context.Context, w *v1.Waypoint, ns *corev1.Namespace, bind bool) (string, error) {
+	patch := client.MergeFrom(ns.DeepCopy())
+	if !setUseWaypointLabel(&ns.ObjectMeta, w.Name, bind) {
+		if bind && !isBoundBy(&ns.ObjectMeta, w) {
+			return fmt.Sprintf("namespace %s uses waypoint %q", ns.Name, ns.Labels[constants.UseWaypointLabel]), nil
+		}
+		return "", nil
+	}
+	if err := r.Client.Patch(ctx, ns, patch); err != nil {
+		return "", fmt.Errorf("failed to update the %s label of namespace %s: %w", constants.UseWaypointLabel, ns.Name, err)
+	}
+	return "", nil
+}
+
+// bindPods sets the istio.io/use-waypoint label on the pods in the namespace of the waypoint for which shouldBind
+// returns true, and removes it from the other pods that were labeled by the Waypoint. It returns the number of pods
+// that use the waypoint and the conflicts.
+func (r *Reconciler) bindPods(ctx context.Context, w *v1.Waypoint, shouldBind func(*corev1.Pod) bool) (int, []string, error) {
+	pods := &corev1.PodList{}
+	if err := r.Client.List(ctx, pods, client.InNamespace(w.Namespace)); err != nil {
+		return 0, nil, fmt.Errorf("failed to list pods in namespace %s: %w", w.Namespace, err)
+	}
+
+	bound := 0
+	var conflicts []string
+	var errs []error
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		bind := shouldBind(pod)
+		patch := client.MergeFrom(pod.DeepCopy())
+		if setUseWaypointLabel(&pod.ObjectMeta, w.Name, bind) {
+			if err := r.Client.Patch(ctx, pod, patch); err != nil {
+				errs = append(errs, fmt.Errorf("failed to update the %s label of pod %s/%s: %w", constants.UseWaypointLabel, pod.Namespace, pod.Name, err))
+				continue
+			}
+		}
+		if bind {
+			if isBoundBy(&pod.ObjectMeta, w) {
+				bound++
+			} else {
+				conflicts = append(conflicts, fmt.Sprintf("pod %s uses waypoint %q", pod.Name, pod.Labels[constants.UseWaypointLabel]))
+			}
+		}
+	}
+	return bound, conflicts, errors.Join(errs...)
+}
+
+// setUseWaypointLabel sets the istio.io/use-waypoint label to the name of the waypoint if bind is true and removes it
+// otherwise. A label that wasn't set by the Waypoint is left intact. It returns whether the object was modified.
+func setUseWaypointLabel(obj *metav1.ObjectMeta, name string, bind bool) bool {
+	owned := obj.Annotations[constants.WaypointAnnotation] == name
+	current, labeled := obj.Labels[constants.UseWaypointLabel]
+	if bind {
+		if owned && current == name || labeled && !owned {
+			return false
+		}
+		if obj.Labels == nil {
+			obj.Labels = map[string]string{}
+		}
+		if obj.Annotations == nil {
+			obj.Annotations = map[string]string{}
+		}
+		obj.Labels[constants.UseWaypointLabel] = name
+		obj.Annotations[constants.WaypointAnnotation] = name
+		return true
+	}
+	if !owned {
+		return false
+	}
+	if current == name {
+		delete(obj.Labels, constants.UseWaypointLabel)
+	}
+	delete(obj.Annotations, constants.WaypointAnnotation)
+	return true
+}
+
+func isBoundBy(obj *metav1.ObjectMeta, w *v1.Waypoint) bool {
+	return obj.Annotations[constants.WaypointAnnotation] == w.Name && obj.Labels[constants.UseWaypointLabel] == w.Name
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	logger := mgr.GetLogger().WithName("ctrlr").WithName("waypoint")
+
+	// mainObjectHandler handles the Waypoint watch events
+	mainObjectHandler := wrapEventHandler(logger, &handler.EnqueueRequestForObject{})
+
+	// ownedResourceHandler handles watch events from the ConfigMaps created by the Waypoint
+	ownedResourceHandler := wrapEventHandler(logger,
+		handler.EnqueueRequestForOwner(r.Scheme, mgr.GetRESTMapper(), &v1.Waypoint{}, handler.OnlyControllerOwner()))
+
+	// operatorResourcesHandler handles watch events from operator CRDs Istio and IstioRevision
+	operatorResourcesHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapOperatorResourceToReconcileRequest))
+
+	// nsHandler triggers reconciliation of the waypoints in a namespace when its labels change
+	nsHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToReconcileRequest))
+
+	// podHandler triggers reconciliation of the waypoints whose scope is ServiceAccounts when a pod in their namespace
+	// is created or its labels change
+	podHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapPodToReconcileRequest))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			LogConstructor: func(req *reconcile.Request) logr.Logger {
+				log := logger
+				if req != nil {
+					log = log.WithValues("Waypoint", req.NamespacedName)
+				}
+				return log
+			},
+			MaxConcurrentReconciles: r.Config.MaxConcurrentReconciles,
+			RateLimiter:             reconciler.RateLimiter(r.Config.BackoffPolicyFor(controllerName)),
+		}).
+		// we use the Watches function instead of For(), so that we can wrap the handler so that events that cause the object to be enqueued are logged
+		Watches(&v1.Waypoint{}, mainObjectHandler, builder.WithPredicates(watches.AsPredicate(watches.IgnoreStatusChanges()))).
+		Named(controllerName).
+		Watches(&corev1.ConfigMap{}, ownedResourceHandler).
+		Watches(&corev1.Namespace{}, nsHandler, builder.WithPredicates(watches.AsPredicate(watches.IgnoreStatusChanges()))).
+		Watches(&corev1.Pod{}, podHandler, builder.WithPredicates(watches.AsPredicate(watches.IgnoreStatusChanges()))).
+
+		// cluster-scoped resources
+		Watches(&v1.Istio{}, operatorResourcesHandler).
+		Watches(&v1.IstioRevision{}, operatorResourcesHandler).
+		Complete(reconciler.NewStandardReconcilerWithFinalizer[*v1.Waypoint](r.Client, r.Reconcile, r.Finalize, constants.FinalizerName).
+			WithSuspendFunc(r.Suspend))
+}
+
+func (r *Reconciler) determineStatus(w *v1.Waypoint, res *result, reconcileErr error) v1.WaypointStatus {
+	reconciledCondition := r.determineReconciledCondition(reconcileErr)
+
+	status := *w.Status.DeepCopy()
+	status.ObservedGeneration = w.Generation
+	retry := reconciler.NextRetry(r.Config.BackoffPolicyFor(controllerName), w.Status.RetryCount, reconcileErr)
+	status.RetryCount, status.NextRetryTime = retry.Count, retry.Time
+	if res != nil {
+		status.IstioRevision = res.rev.Name
+	}
+	if reconcileErr == nil {
+		status.SetCondition(determineReadyCondition(res.programmed))
+		status.SetCondition(determineBoundCondition(res))
+	} else {
+		status.SetCondition(v1.StatusCondition{
+			Type:    v1.WaypointConditionReady,
+			Status:  metav1.ConditionUnknown,
+			Reason:  v1.WaypointReasonReconcileError,
+			Message: "cannot determine readiness due to reconciliation error",
+		})
+	}
+	status.SetCondition(reconciledCondition)
+	status.State = reconciler.DeriveState(v1.WaypointReasonHealthy,
+		reconciledCondition, status.GetCondition(v1.WaypointConditionReady), status.GetCondition(v1.WaypointConditionBound))
+	return status
+}
+
+func (r *Reconciler) updateStatus(ctx context.Context, w *v1.Waypoint, res *result, reconcileErr error) (ctrl.Result, error) {
+	status := r.determineStatus(w, res, reconcileErr)
+	var result ctrl.Result
+	if reconcileErr == nil && !res.programmed {
+		result.RequeueAfter = checkInterval
+	}
+	return result, reconciler.UpdateStatus(ctx, r.Client, w, w.Status, status, nil)
+}
+
+func (r *Reconciler) determineReconciledCondition(err error) v1.StatusCondition {
+	c := v1.StatusCondition{Type: v1.WaypointConditionReconciled}
+	if err == nil {
+		c.Status = metav1.ConditionTrue
+		c.Reason = v1.ConditionReason(v1.WaypointConditionReconciled)
+	} else {
+		c.Status = metav1.ConditionFalse
+		c.Message = err.Error()
+		switch {
+		case reconciler.IsNameAlreadyExistsError(err):
+			c.Reason = v1.WaypointReasonNameAlreadyExists
+		case reconciler.IsReferenceNotFoundError(err):
+			c.Reason = v1.WaypointReasonReferenceNotFound
+		default:
+			c.Reason, c.Message = reconciler.DescribeReconcileError(err, v1.WaypointReasonReconcileError)
+		}
+	}
+	return c
+}
+
+func determineReadyCondition(programmed bool) v1.StatusCondition {
+	if programmed {
+		return v1.StatusCondition{Type: v1.WaypointConditionReady, Status: metav1.ConditionTrue, Reason: v1.ConditionReason(v1.WaypointConditionReady)}
+	}
+	return v1.StatusCondition{
+		Type:    v1.WaypointConditionReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1.WaypointReasonGatewayNotProgrammed,
+		Message: "the waypoint Gateway has not been programmed yet",
+	}
+}
+
+func determineBoundCondition(res *result) v1.StatusCondition {
+	c := v1.StatusCondition{Type: v1.WaypointConditionBound}
+	if len(res.conflicts) == 0 {
+		c.Status = metav1.ConditionTrue
+		c.Reason = v1.ConditionReason(v1.WaypointConditionBound)
+		c.Message = res.bound
+	} else {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1.WaypointReasonBindingConflict
+		c.Message = fmt.Sprintf("the %s label was set by someone else: %s", constants.UseWaypointLabel, joinConflicts(res.conflicts))
+	}
+	return c
+}
+
+// joinConflicts joins the conflicts into a message, listing at most five of them.
+func joinConflicts(conflicts []string) string {
+	const maxConflicts = 5
+	if len(conflicts) <= maxConflicts {
+		return strings.Join(conflicts, "; ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(conflicts[:maxConflicts], "; "), len(conflicts)-maxConflicts)
+}
+
+func (r *Reconciler) mapNamespaceToReconcileRequest(ctx context.Context, ns client.Object) []reconcile.Request {
+	return r.listReconcileRequests(ctx, ns.GetName(), func(*v1.Waypoint) bool { return true })
+}
+
+func (r *Reconciler) mapPodToReconcileRequest(ctx context.Context, pod client.Object) []reconcile.Request {
+	return r.listReconcileRequests(ctx, pod.GetNamespace(), func(w *v1.Waypoint) bool {
+		return w.Spec.Scope == v1.WaypointScopeServiceAccounts || pod.GetAnnotations()[constants.WaypointAnnotation] == w.Name
+	})
+}
+
+func (r *Reconciler) listReconcileRequests(ctx context.Context, namespace string, filter func(*v1.Waypoint) bool) []reconcile.Request {
+	list := v1.WaypointList{}
+	if err := r.Client.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list Waypoints")
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range list.Items {
+		if filter(&list.Items[i]) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&list.Items[i])})
+		}
+	}
+	return requests
+}
+
+func (r *Reconciler) mapOperatorResourceToReconcileRequest(ctx context.Context, obj client.Object) []reconcile.Request {
+	var kind string
+	if _, ok := obj.(*v1.Istio); ok {
+		kind = v1.IstioKind
+	} else if _, ok := obj.(*v1.IstioRevision); ok {
+		kind = v1.IstioRevisionKind
+	} else {
+		return nil
+	}
+
+	return r.listReconcileRequests(ctx, "", func(w *v1.Waypoint) bool {
+		ref := w.Spec.TargetRef
+		return ref.Kind == kind && ref.Name == obj.GetName() || kind == v1.IstioRevisionKind && w.Status.IstioRevision == obj.GetName()
+	})
+}
+
+func wrapEventHandler(logger logr.Logger, handler handler.EventHandler) handler.EventHandler {
+	return enqueuelogger.WrapIfNecessary(v1.WaypointKind, logger, handler)
+}
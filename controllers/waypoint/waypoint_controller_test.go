@@ -0,0 +1,359 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package waypoint
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	sharedwaypoint "github.com/istio-ecosystem/sail-operator/pkg/waypoint"
+	"github.com/istio-ecosystem/sail-operator/pkg/waypoint/waypointtest"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	waypointName = "waypoint"
+	namespace    = "app"
+	istioName    = "default"
+	activeRev    = "default-v1-30-0"
+)
+
+func TestDoReconcileNamespaceScope(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+	w := newWaypoint(v1.WaypointScopeNamespace)
+
+	cl := waypointtest.NewFakeClient(
+		true, waypointtest.NewIstio(istioName, activeRev), waypointtest.NewOwnedRevision(istioName, activeRev, activeRev, true),
+		ns, w,
+	)
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	res, err := r.doReconcile(ctx, w)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(res.rev.Name).To(Equal(activeRev))
+	g.Expect(res.conflicts).To(BeEmpty())
+	g.Expect(res.programmed).To(BeFalse())
+
+	gw := waypointtest.GetGateway(g, cl, namespace, waypointName)
+	g.Expect(metav1.IsControlledBy(gw, w)).To(BeTrue())
+	g.Expect(gw.GetLabels()).To(HaveKeyWithValue(constants.IstioRevLabel, activeRev))
+	g.Expect(gw.GetLabels()).To(HaveKeyWithValue(constants.WaypointForLabel, string(v1.WaypointTrafficTypeService)))
+	className, _, _ := unstructured.NestedString(gw.Object, "spec", "gatewayClassName")
+	g.Expect(className).To(Equal(sharedwaypoint.GatewayClassName))
+
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(ns), ns)).To(Succeed())
+	g.Expect(ns.Labels).To(HaveKeyWithValue(constants.UseWaypointLabel, waypointName))
+	g.Expect(ns.Annotations).To(HaveKeyWithValue(constants.WaypointAnnotation, waypointName))
+
+	// changing the scope to None removes the label from the namespace
+	w.Spec.Scope = v1.WaypointScopeNone
+	_, err = r.doReconcile(ctx, w)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(ns), ns)).To(Succeed())
+	g.Expect(ns.Labels).NotTo(HaveKey(constants.UseWaypointLabel))
+	g.Expect(ns.Annotations).NotTo(HaveKey(constants.WaypointAnnotation))
+}
+
+func TestDoReconcileServiceAccountsScope(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+	selected := newPod("selected", "reviews", nil)
+	other := newPod("other", "ratings", nil)
+	conflicting := newPod("conflicting", "reviews", map[string]string{constants.UseWaypointLabel: "custom"})
+	w := newWaypoint(v1.WaypointScopeServiceAccounts)
+	w.Spec.ServiceAccounts = []string{"reviews"}
+
+	cl := waypointtest.NewFakeClient(
+		true, waypointtest.NewIstio(istioName, activeRev), waypointtest.NewOwnedRevision(istioName, activeRev, "", true),
+		ns, selected, other, conflicting, w,
+	)
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	res, err := r.doReconcile(ctx, w)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(res.bound).To(Equal("1 pods use the waypoint"))
+	g.Expect(res.conflicts).To(ConsistOf(ContainSubstring("conflicting")))
+
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(selected), selected)).To(Succeed())
+	g.Expect(selected.Labels).To(HaveKeyWithValue(constants.UseWaypointLabel, waypointName))
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(other), other)).To(Succeed())
+	g.Expect(other.Labels).NotTo(HaveKey(constants.UseWaypointLabel))
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(conflicting), conflicting)).To(Succeed())
+	g.Expect(conflicting.Labels).To(HaveKeyWithValue(constants.UseWaypointLabel, "custom"))
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(ns), ns)).To(Succeed())
+	g.Expect(ns.Labels).NotTo(HaveKey(constants.UseWaypointLabel))
+
+	// removing the service account from the list removes the label from its pods
+	w.Spec.ServiceAccounts = []string{"ratings"}
+	_, err = r.doReconcile(ctx, w)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(selected), selected)).To(Succeed())
+	g.Expect(selected.Labels).NotTo(HaveKey(constants.UseWaypointLabel))
+	g.Expect(selected.Annotations).NotTo(HaveKey(constants.WaypointAnnotation))
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(other), other)).To(Succeed())
+	g.Expect(other.Labels).To(HaveKeyWithValue(constants.UseWaypointLabel, waypointName))
+
+	// finalizing the waypoint removes the labels it set
+	g.Expect(r.Finalize(ctx, w)).To(Succeed())
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(other), other)).To(Succeed())
+	g.Expect(other.Labels).NotTo(HaveKey(constants.UseWaypointLabel))
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(conflicting), conflicting)).To(Succeed())
+	g.Expect(conflicting.Labels).To(HaveKeyWithValue(constants.UseWaypointLabel, "custom"))
+}
+
+func TestDoReconcileNamespaceConflict(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace, Labels: map[string]string{constants.UseWaypointLabel: "custom"}}}
+	w := newWaypoint(v1.WaypointScopeNamespace)
+
+	cl := waypointtest.NewFakeClient(
+		true, waypointtest.NewIstio(istioName, activeRev), waypointtest.NewOwnedRevision(istioName, activeRev, "", true),
+		ns, w,
+	)
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	res, err := r.doReconcile(ctx, w)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(res.conflicts).To(ConsistOf(ContainSubstring(namespace)))
+
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(ns), ns)).To(Succeed())
+	g.Expect(ns.Labels).To(HaveKeyWithValue(constants.UseWaypointLabel, "custom"))
+	g.Expect(ns.Annotations).NotTo(HaveKey(constants.WaypointAnnotation))
+}
+
+func TestDoReconcileResources(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+	w := newWaypoint(v1.WaypointScopeNamespace)
+	w.Spec.Resources = &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+	}
+
+	cl := waypointtest.NewFakeClient(
+		true, waypointtest.NewIstio(istioName, activeRev), waypointtest.NewOwnedRevision(istioName, activeRev, "", true),
+		ns, w,
+	)
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	_, err := r.doReconcile(ctx, w)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	cmKey := types.NamespacedName{Namespace: namespace, Name: sharedwaypoint.ParametersConfigMapName(waypointName)}
+	cm := &corev1.ConfigMap{}
+	g.Expect(cl.Get(ctx, cmKey, cm)).To(Succeed())
+	g.Expect(metav1.IsControlledBy(cm, w)).To(BeTrue())
+	g.Expect(cm.Data).NotTo(BeEmpty())
+
+	gw := waypointtest.GetGateway(g, cl, namespace, waypointName)
+	ref, _, _ := unstructured.NestedString(gw.Object, "spec", "infrastructure", "parametersRef", "name")
+	g.Expect(ref).To(Equal(cmKey.Name))
+
+	// removing the resources deletes the ConfigMap and the reference to it
+	w.Spec.Resources = nil
+	_, err = r.doReconcile(ctx, w)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cl.Get(ctx, cmKey, cm)).NotTo(Succeed())
+	gw = waypointtest.GetGateway(g, cl, namespace, waypointName)
+	_, found, _ := unstructured.NestedMap(gw.Object, "spec", "infrastructure", "parametersRef")
+	g.Expect(found).To(BeFalse())
+}
+
+func TestDoReconcileMovesWaypointOnceRevisionIsReady(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	const newRev = "default-v1-31-0"
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+	istio := waypointtest.NewIstio(istioName, activeRev)
+	istio.Status.ActiveRevisionName = newRev
+	w := newWaypoint(v1.WaypointScopeNamespace)
+	w.Status.IstioRevision = activeRev
+	pending := waypointtest.NewOwnedRevision(istioName, newRev, newRev, false)
+
+	cl := waypointtest.NewFakeClient(true, istio, waypointtest.NewOwnedRevision(istioName, activeRev, activeRev, true), pending, ns, w)
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	res, err := r.doReconcile(ctx, w)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(res.rev.Name).To(Equal(activeRev))
+	g.Expect(waypointtest.GetGateway(g, cl, namespace, waypointName).GetLabels()).To(HaveKeyWithValue(constants.IstioRevLabel, activeRev))
+
+	pending.Status.SetCondition(v1.StatusCondition{Type: v1.IstioRevisionConditionReady, Status: metav1.ConditionTrue})
+	g.Expect(cl.Status().Update(ctx, pending)).To(Succeed())
+
+	res, err = r.doReconcile(ctx, w)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(res.rev.Name).To(Equal(newRev))
+	g.Expect(waypointtest.GetGateway(g, cl, namespace, waypointName).GetLabels()).To(HaveKeyWithValue(constants.IstioRevLabel, newRev))
+}
+
+func TestDoReconcileUnmanagedGateway(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+	existing := sharedwaypoint.NewGateway(namespace, waypointName)
+	w := newWaypoint(v1.WaypointScopeNamespace)
+
+	cl := waypointtest.NewFakeClient(
+		true, waypointtest.NewIstio(istioName, activeRev), waypointtest.NewOwnedRevision(istioName, activeRev, "", true),
+		ns, existing, w,
+	)
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	_, err := r.doReconcile(ctx, w)
+	g.Expect(reconciler.IsNameAlreadyExistsError(err)).To(BeTrue())
+	g.Expect(waypointtest.GetGateway(g, cl, namespace, waypointName).GetOwnerReferences()).To(BeEmpty())
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(ns), ns)).To(Succeed())
+	g.Expect(ns.Labels).NotTo(HaveKey(constants.UseWaypointLabel))
+}
+
+func TestDoReconcileWithoutGatewayAPI(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	w := newWaypoint(v1.WaypointScopeNamespace)
+	cl := waypointtest.NewFakeClient(
+		false, waypointtest.NewIstio(istioName, activeRev), waypointtest.NewOwnedRevision(istioName, activeRev, "", true),
+		w,
+	)
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	_, err := r.doReconcile(ctx, w)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(reconciler.IsTransientError(err)).To(BeTrue())
+}
+
+func TestDoReconcileReferenceNotFound(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	w := newWaypoint(v1.WaypointScopeNamespace)
+	cl := waypointtest.NewFakeClient(true, w)
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	res, err := r.doReconcile(ctx, w)
+	g.Expect(reconciler.IsReferenceNotFoundError(err)).To(BeTrue())
+	g.Expect(res).To(BeNil())
+}
+
+func TestDetermineStatus(t *testing.T) {
+	g := NewWithT(t)
+	r := NewReconciler(newReconcilerTestConfig(t), nil, scheme.Scheme)
+	rev := waypointtest.NewOwnedRevision(istioName, activeRev, "", true)
+
+	status := r.determineStatus(newWaypoint(v1.WaypointScopeNamespace), &result{rev: rev, programmed: true, bound: "bound"}, nil)
+	g.Expect(status.IstioRevision).To(Equal(activeRev))
+	g.Expect(status.GetCondition(v1.WaypointConditionReconciled).Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(status.GetCondition(v1.WaypointConditionReady).Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(status.GetCondition(v1.WaypointConditionBound).Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(status.State).To(Equal(v1.WaypointReasonHealthy))
+
+	status = r.determineStatus(newWaypoint(v1.WaypointScopeNamespace), &result{rev: rev, conflicts: []string{"pod a", "pod b"}}, nil)
+	g.Expect(status.GetCondition(v1.WaypointConditionReady).Reason).To(Equal(v1.WaypointReasonGatewayNotProgrammed))
+	bound := status.GetCondition(v1.WaypointConditionBound)
+	g.Expect(bound.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(bound.Reason).To(Equal(v1.WaypointReasonBindingConflict))
+	g.Expect(bound.Message).To(ContainSubstring("pod a; pod b"))
+
+	status = r.determineStatus(newWaypoint(v1.WaypointScopeNamespace), nil,
+		reconciler.NewReferenceNotFoundError("not found", nil))
+	g.Expect(status.GetCondition(v1.WaypointConditionReconciled).Reason).To(Equal(v1.WaypointReasonReferenceNotFound))
+	g.Expect(status.GetCondition(v1.WaypointConditionReady).Status).To(Equal(metav1.ConditionUnknown))
+	g.Expect(status.State).To(Equal(v1.WaypointReasonReferenceNotFound))
+}
+
+func TestMapToReconcileRequest(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.TODO()
+
+	followsIstio := newWaypoint(v1.WaypointScopeNamespace)
+	followsIstio.Status.IstioRevision = activeRev
+	pinsRevision := &v1.Waypoint{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "pins-revision"},
+		Spec: v1.WaypointSpec{
+			TargetRef: v1.TargetReference{Kind: v1.IstioRevisionKind, Name: "canary"},
+			Scope:     v1.WaypointScopeServiceAccounts,
+		},
+	}
+
+	cl := waypointtest.NewFakeClient(true, followsIstio, pinsRevision)
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+
+	g.Expect(r.mapOperatorResourceToReconcileRequest(ctx, &v1.Istio{ObjectMeta: metav1.ObjectMeta{Name: istioName}})).To(ConsistOf(
+		HaveField("NamespacedName", client.ObjectKeyFromObject(followsIstio)),
+	))
+	g.Expect(r.mapOperatorResourceToReconcileRequest(ctx, &v1.IstioRevision{ObjectMeta: metav1.ObjectMeta{Name: activeRev}})).To(ConsistOf(
+		HaveField("NamespacedName", client.ObjectKeyFromObject(followsIstio)),
+	))
+	g.Expect(r.mapOperatorResourceToReconcileRequest(ctx, &v1.IstioRevision{ObjectMeta: metav1.ObjectMeta{Name: "canary"}})).To(ConsistOf(
+		HaveField("NamespacedName", client.ObjectKeyFromObject(pinsRevision)),
+	))
+	g.Expect(r.mapNamespaceToReconcileRequest(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})).To(ConsistOf(
+		HaveField("NamespacedName", client.ObjectKeyFromObject(followsIstio)),
+	))
+	g.Expect(r.mapPodToReconcileRequest(ctx, newPod("a", "default", nil))).To(BeEmpty())
+	g.Expect(r.mapPodToReconcileRequest(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "a"}})).To(ConsistOf(
+		HaveField("NamespacedName", client.ObjectKeyFromObject(pinsRevision)),
+	))
+}
+
+func newWaypoint(scope v1.WaypointScope) *v1.Waypoint {
+	return &v1.Waypoint{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: waypointName, UID: "waypoint-uid"},
+		Spec: v1.WaypointSpec{
+			TargetRef:   v1.TargetReference{Kind: v1.IstioKind, Name: istioName},
+			TrafficType: v1.WaypointTrafficTypeService,
+			Scope:       scope,
+		},
+	}
+}
+
+func newPod(name, serviceAccount string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels},
+		Spec:       corev1.PodSpec{ServiceAccountName: serviceAccount},
+	}
+}
+
+func newReconcilerTestConfig(t *testing.T) config.ReconcilerConfig {
+	return config.ReconcilerConfig{
+		ResourceFS:              os.DirFS(t.TempDir()),
+		Platform:                config.PlatformKubernetes,
+		DefaultProfile:          "",
+		MaxConcurrentReconciles: 1,
+	}
+}
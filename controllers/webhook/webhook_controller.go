@@ -18,12 +18,15 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/url"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -34,6 +37,8 @@ import (
 	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
 	"github.com/istio-ecosystem/sail-operator/pkg/revision"
 	admissionv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -44,11 +49,17 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"istio.io/istio/pkg/ptr"
 )
 
 const (
 	defaultPeriodSeconds  = 3 // matches the period in the istiod chart
 	defaultTimeoutSeconds = 5 // matches the timeout in the istiod chart
+
+	// latencyChangeThreshold is the smallest change in probe latency that is recorded in the IstioRevision status.
+	// Smaller changes are ignored, so that the status isn't updated after every probe.
+	latencyChangeThreshold = 100 * time.Millisecond
 )
 
 // overrides the default dial context; only used in unit tests
@@ -59,7 +70,7 @@ type Reconciler struct {
 	Config config.ReconcilerConfig
 	client.Client
 	Scheme *runtime.Scheme
-	probe  func(context.Context, *admissionv1.MutatingWebhookConfiguration) (bool, error)
+	probe  func(context.Context, *admissionv1.MutatingWebhookConfiguration) ([]v1.WebhookProbeStatus, error)
 }
 
 func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *runtime.Scheme) *Reconciler {
@@ -72,6 +83,7 @@ func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *ru
 }
 
 // +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=sailoperator.io,resources=istiorevisions/status,verbs=get;update;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -81,11 +93,10 @@ func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *ru
 func (r *Reconciler) Reconcile(ctx context.Context, webhook *admissionv1.MutatingWebhookConfiguration) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
 
-	isReady, err := r.probe(ctx, webhook)
-	reason := ""
-	if err != nil {
-		log.V(3).Error(err, "Probe failed")
-		reason = err.Error()
+	results, err := r.probe(ctx, webhook)
+	isReady, reason := summarizeProbeResults(results, err)
+	if !isReady {
+		log.V(3).Info("Probe failed", "reason", reason)
 	}
 
 	if webhook.Annotations == nil {
@@ -98,54 +109,142 @@ func (r *Reconciler) Reconcile(ctx context.Context, webhook *admissionv1.Mutatin
 	if err != nil {
 		return ctrl.Result{}, err
 	}
+
+	if err := r.updateRevisionStatus(ctx, webhook, results); err != nil {
+		return ctrl.Result{}, err
+	}
 	return ctrl.Result{RequeueAfter: getPeriod(webhook)}, nil
 }
 
-func doProbe(ctx context.Context, webhook *admissionv1.MutatingWebhookConfiguration) (bool, error) {
-	log := logf.FromContext(ctx)
+// summarizeProbeResults returns whether all webhooks are ready and, if not, why the first webhook that isn't ready
+// failed its probe.
+func summarizeProbeResults(results []v1.WebhookProbeStatus, err error) (bool, string) {
+	if err != nil {
+		return false, err.Error()
+	}
+	for _, result := range results {
+		if !result.Ready {
+			return false, fmt.Sprintf("webhook %s: %s", result.Name, result.LastError)
+		}
+	}
+	return len(results) > 0, ""
+}
+
+// updateRevisionStatus records the probe results in the status of the IstioRevision that owns the webhook
+// configuration.
+func (r *Reconciler) updateRevisionStatus(ctx context.Context, webhook *admissionv1.MutatingWebhookConfiguration,
+	results []v1.WebhookProbeStatus,
+) error {
+	revName := getOwnerRevisionName(webhook)
+	if revName == "" {
+		return nil
+	}
+
+	rev := &v1.IstioRevision{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: revName}, rev); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if probeResultsEqual(rev.Status.RemoteProbes, results) {
+		return nil
+	}
+
+	patch := client.MergeFrom(rev.DeepCopy())
+	rev.Status.RemoteProbes = results
+	if err := r.Client.Status().Patch(ctx, rev, patch); err != nil {
+		return fmt.Errorf("failed to update status of IstioRevision %s: %w", revName, err)
+	}
+	return nil
+}
+
+// probeResultsEqual returns true if the probe results are equal, ignoring latency changes smaller than
+// latencyChangeThreshold.
+func probeResultsEqual(a, b []v1.WebhookProbeStatus) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		x, y := a[i], b[i]
+		if x.Latency != nil && y.Latency != nil && (x.Latency.Duration-y.Latency.Duration).Abs() < latencyChangeThreshold {
+			x.Latency, y.Latency = nil, nil
+		}
+		if !equality.Semantic.DeepEqual(x, y) {
+			return false
+		}
+	}
+	return true
+}
+
+// doProbe probes the readiness of the remote control plane through each webhook in the configuration. The webhooks
+// are probed in parallel, and the results are returned in the order of the webhooks.
+func doProbe(ctx context.Context, webhook *admissionv1.MutatingWebhookConfiguration) ([]v1.WebhookProbeStatus, error) {
 	if len(webhook.Webhooks) == 0 {
-		return false, errors.New("mutatingwebhookconfiguration contains no webhooks")
+		return nil, errors.New("mutatingwebhookconfiguration contains no webhooks")
 	}
-	clientConfig := webhook.Webhooks[0].ClientConfig
-	if clientConfig.Service == nil {
-		return false, errors.New("missing webhooks[].clientConfig.service")
+
+	timeout := getTimeout(webhook)
+	results := make([]v1.WebhookProbeStatus, len(webhook.Webhooks))
+	var wg sync.WaitGroup
+	for i, wh := range webhook.Webhooks {
+		wg.Go(func() {
+			results[i] = probeWebhook(ctx, wh.Name, wh.ClientConfig, timeout)
+		})
 	}
+	wg.Wait()
+	return results, nil
+}
 
-	if len(clientConfig.CABundle) == 0 {
-		return false, errors.New("webhooks[].clientConfig.caBundle hasn't been set; check if the remote istiod can access this cluster")
+func probeWebhook(ctx context.Context, name string, clientConfig admissionv1.WebhookClientConfig, timeout time.Duration) v1.WebhookProbeStatus {
+	log := logf.FromContext(ctx)
+	result := v1.WebhookProbeStatus{Name: name}
+	fail := func(reason v1.WebhookProbeFailureReason, err error) v1.WebhookProbeStatus {
+		result.FailureReason = reason
+		result.LastError = err.Error()
+		return result
 	}
-	caCertPool := x509.NewCertPool()
-	if ok := caCertPool.AppendCertsFromPEM(clientConfig.CABundle); !ok {
-		return false, errors.New("failed to append CA bundle to cert pool")
+
+	probeURL, err := getReadinessProbeURL(clientConfig)
+	if err != nil {
+		return fail(v1.WebhookProbeFailureInvalidConfig, err)
+	}
+	result.URL = probeURL
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if len(clientConfig.CABundle) > 0 {
+		caCertPool, caExpiry, err := parseCABundle(clientConfig.CABundle)
+		if err != nil {
+			return fail(v1.WebhookProbeFailureInvalidConfig, err)
+		}
+		tlsConfig.RootCAs = caCertPool
+		result.CAExpiry = caExpiry
+	} else if clientConfig.Service != nil {
+		return fail(v1.WebhookProbeFailureInvalidConfig,
+			errors.New("webhooks[].clientConfig.caBundle hasn't been set; check if the remote istiod can access this cluster"))
 	}
+	// like the API server, we verify URL-based webhooks without a caBundle against the system trust roots
 
 	httpClient := http.Client{
-		Timeout: getTimeout(webhook),
+		Timeout: timeout,
 		Transport: &http.Transport{
-			DialContext: customDialContext,
-			TLSClientConfig: &tls.Config{
-				RootCAs:    caCertPool,
-				MinVersion: tls.VersionTLS12,
-			},
+			DialContext:     customDialContext,
+			TLSClientConfig: tlsConfig,
 		},
 	}
 
-	url, err := getReadinessProbeURL(clientConfig)
-	if err != nil {
-		return false, err
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probeURL, nil)
 	if err != nil {
-		return false, err
+		return fail(v1.WebhookProbeFailureInvalidConfig, err)
 	}
 
-	log.V(3).Info("Executing readiness probe on remote control plane", "url", req.URL.String())
+	log.V(3).Info("Executing readiness probe on remote control plane", "webhook", name, "url", req.URL.String())
+	start := time.Now()
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		log.V(3).Info("Probe failed", "error", err)
-		return false, err
+		log.V(3).Info("Probe failed", "webhook", name, "error", err)
+		return fail(classifyProbeError(err), err)
 	}
+	result.Latency = &metav1.Duration{Duration: time.Since(start).Round(time.Millisecond)}
 	defer func() {
 		// drain and close the body to release the underlying connection. Since httpClient (and its Transport) isn't
 		// reused across probes, close the idle connections so they don't linger indefinitely.
@@ -153,15 +252,74 @@ func doProbe(ctx context.Context, webhook *admissionv1.MutatingWebhookConfigurat
 		_ = resp.Body.Close()
 		httpClient.CloseIdleConnections()
 	}()
-	log.V(3).Info("Probe response", "response", resp.StatusCode)
+	log.V(3).Info("Probe response", "webhook", name, "response", resp.StatusCode)
 
-	return resp.StatusCode == http.StatusOK, nil
+	if resp.StatusCode != http.StatusOK {
+		return fail(v1.WebhookProbeFailureHTTPError, fmt.Errorf("unexpected HTTP status %d", resp.StatusCode))
+	}
+	result.Ready = true
+	return result
+}
+
+// parseCABundle returns a cert pool containing the certificates in the bundle and the expiry time of the first
+// certificate to expire.
+func parseCABundle(caBundle []byte) (*x509.CertPool, *metav1.Time, error) {
+	caCertPool := x509.NewCertPool()
+	var expiry *metav1.Time
+	for block, rest := pem.Decode(caBundle); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		caCertPool.AddCert(cert)
+		if expiry == nil || cert.NotAfter.Before(expiry.Time) {
+			expiry = ptr.Of(metav1.NewTime(cert.NotAfter).Rfc3339Copy())
+		}
+	}
+	if expiry == nil {
+		return nil, nil, errors.New("failed to append CA bundle to cert pool")
+	}
+	return caCertPool, expiry, nil
+}
+
+// classifyProbeError determines whether a failed probe request was caused by a TLS error, a timeout or a
+// connection error.
+func classifyProbeError(err error) v1.WebhookProbeFailureReason {
+	var (
+		certVerificationErr *tls.CertificateVerificationError
+		unknownAuthorityErr x509.UnknownAuthorityError
+		hostnameErr         x509.HostnameError
+		certInvalidErr      x509.CertificateInvalidError
+		recordHeaderErr     tls.RecordHeaderError
+		alertErr            tls.AlertError
+		netErr              net.Error
+	)
+	switch {
+	case errors.As(err, &certVerificationErr), errors.As(err, &unknownAuthorityErr), errors.As(err, &hostnameErr),
+		errors.As(err, &certInvalidErr), errors.As(err, &recordHeaderErr), errors.As(err, &alertErr):
+		return v1.WebhookProbeFailureTLSError
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return v1.WebhookProbeFailureTimeout
+	default:
+		return v1.WebhookProbeFailureConnectionError
+	}
 }
 
 func getReadinessProbeURL(config admissionv1.WebhookClientConfig) (string, error) {
 	switch {
 	case config.URL != nil:
-		return "", errors.New("only webhooks pointing to a Service are supported")
+		u, err := url.Parse(*config.URL)
+		if err != nil {
+			return "", fmt.Errorf("invalid webhooks[].clientConfig.url: %w", err)
+		}
+		if u.Scheme != "https" || u.Host == "" {
+			return "", fmt.Errorf("webhooks[].clientConfig.url %q must be an absolute https URL", *config.URL)
+		}
+		u.Path, u.RawPath, u.RawQuery, u.Fragment = "/ready", "", "", ""
+		return u.String(), nil
 
 	case config.Service != nil:
 		svc := config.Service
@@ -219,6 +377,17 @@ func ownedByRemoteIstioRevisionPredicate(cl client.Client) predicate.Predicate {
 	}
 }
 
+// getOwnerRevisionName returns the name of the IstioRevision that owns the object, or an empty string if the object
+// isn't owned by an IstioRevision.
+func getOwnerRevisionName(obj client.Object) string {
+	for _, ownerRef := range obj.GetOwnerReferences() {
+		if ownerRef.APIVersion == v1.GroupVersion.String() && ownerRef.Kind == v1.IstioRevisionKind {
+			return ownerRef.Name
+		}
+	}
+	return ""
+}
+
 func IsOwnedByRevisionWithRemoteControlPlane(cl client.Client, obj client.Object) bool {
 	for _, ownerRef := range obj.GetOwnerReferences() {
 		if ownerRef.APIVersion == v1.GroupVersion.String() && ownerRef.Kind == v1.IstioRevisionKind {
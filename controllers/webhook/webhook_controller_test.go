@@ -55,40 +55,43 @@ func TestReconcile(t *testing.T) {
 	tests := []struct {
 		name         string
 		setup        func(configuration *admissionv1.MutatingWebhookConfiguration)
-		probeFunc    func(context.Context, *admissionv1.MutatingWebhookConfiguration) (bool, error)
+		probeFunc    func(context.Context, *admissionv1.MutatingWebhookConfiguration) ([]v1.WebhookProbeStatus, error)
 		interceptors interceptor.Funcs
 		expectResult ctrl.Result
 		expectErr    error
 		expectValue  string
+		expectReason string
 	}{
 		{
 			name: "ready",
-			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration) (bool, error) {
-				return true, nil
+			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration) ([]v1.WebhookProbeStatus, error) {
+				return []v1.WebhookProbeStatus{{Name: "sidecar-injector.istio.io", Ready: true}}, nil
 			},
 			expectResult: ctrl.Result{RequeueAfter: defaultPeriodSeconds * time.Second},
 			expectValue:  "true",
 		},
 		{
 			name: "not ready",
-			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration) (bool, error) {
-				return false, nil
+			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration) ([]v1.WebhookProbeStatus, error) {
+				return []v1.WebhookProbeStatus{{Name: "sidecar-injector.istio.io", Ready: false, LastError: "unexpected HTTP status 503"}}, nil
 			},
 			expectResult: ctrl.Result{RequeueAfter: defaultPeriodSeconds * time.Second},
 			expectValue:  "false",
+			expectReason: "webhook sidecar-injector.istio.io: unexpected HTTP status 503",
 		},
 		{
 			name: "probe error",
-			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration) (bool, error) {
-				return false, fmt.Errorf("some error")
+			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration) ([]v1.WebhookProbeStatus, error) {
+				return nil, fmt.Errorf("some error")
 			},
 			expectResult: ctrl.Result{RequeueAfter: defaultPeriodSeconds * time.Second},
 			expectValue:  "false",
+			expectReason: "some error",
 		},
 		{
 			name: "update error",
-			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration) (bool, error) {
-				return true, nil
+			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration) ([]v1.WebhookProbeStatus, error) {
+				return []v1.WebhookProbeStatus{{Name: "sidecar-injector.istio.io", Ready: true}}, nil
 			},
 			interceptors: interceptor.Funcs{
 				Update: func(ctx context.Context, cl client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
@@ -106,8 +109,8 @@ func TestReconcile(t *testing.T) {
 					constants.WebhookReadinessProbePeriodSecondsAnnotationKey: "123",
 				}
 			},
-			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration) (bool, error) {
-				return true, nil
+			probeFunc: func(context.Context, *admissionv1.MutatingWebhookConfiguration) ([]v1.WebhookProbeStatus, error) {
+				return []v1.WebhookProbeStatus{{Name: "sidecar-injector.istio.io", Ready: true}}, nil
 			},
 			expectResult: ctrl.Result{RequeueAfter: 123 * time.Second},
 			expectValue:  "true",
@@ -144,10 +147,132 @@ func TestReconcile(t *testing.T) {
 
 			g.Expect(cl.Get(ctx, kube.Key("istio-sidecar-injector"), webhook)).To(Succeed())
 			g.Expect(webhook.Annotations[constants.WebhookReadinessProbeStatusAnnotationKey]).To(Equal(tt.expectValue), "Unexpected annotation value")
+			g.Expect(webhook.Annotations[constants.WebhookReadinessProbeStatusReasonAnnotationKey]).To(Equal(tt.expectReason), "Unexpected reason")
 		})
 	}
 }
 
+func TestReconcileRecordsProbeResultsInRevisionStatus(t *testing.T) {
+	g := NewWithT(t)
+
+	rev := &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+	}
+	webhook := &admissionv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "istio-sidecar-injector",
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: v1.GroupVersion.String(),
+					Kind:       v1.IstioRevisionKind,
+					Name:       rev.Name,
+				},
+			},
+		},
+	}
+	cl := newFakeClientBuilder().
+		WithObjects(rev, webhook).
+		WithStatusSubresource(&v1.IstioRevision{}).
+		Build()
+
+	results := []v1.WebhookProbeStatus{
+		{
+			Name:          "sidecar-injector.istio.io",
+			URL:           "https://istiod.istio-system.svc:443/ready",
+			FailureReason: v1.WebhookProbeFailureTLSError,
+			LastError:     "tls: failed to verify certificate: x509: certificate signed by unknown authority",
+		},
+	}
+	r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme)
+	r.probe = func(context.Context, *admissionv1.MutatingWebhookConfiguration) ([]v1.WebhookProbeStatus, error) {
+		return results, nil
+	}
+
+	_, err := r.Reconcile(ctx, webhook)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(cl.Get(ctx, kube.Key(rev.Name), rev)).To(Succeed())
+	g.Expect(rev.Status.RemoteProbes).To(Equal(results))
+}
+
+func TestProbeResultsEqual(t *testing.T) {
+	caExpiry := metav1.NewTime(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	result := func(latency time.Duration, lastError string) []v1.WebhookProbeStatus {
+		return []v1.WebhookProbeStatus{
+			{
+				Name:      "sidecar-injector.istio.io",
+				Ready:     lastError == "",
+				LastError: lastError,
+				Latency:   &metav1.Duration{Duration: latency},
+				CAExpiry:  &caExpiry,
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		a, b     []v1.WebhookProbeStatus
+		expected bool
+	}{
+		{
+			name:     "equal",
+			a:        result(10*time.Millisecond, ""),
+			b:        result(10*time.Millisecond, ""),
+			expected: true,
+		},
+		{
+			name:     "small latency change",
+			a:        result(10*time.Millisecond, ""),
+			b:        result(50*time.Millisecond, ""),
+			expected: true,
+		},
+		{
+			name:     "large latency change",
+			a:        result(10*time.Millisecond, ""),
+			b:        result(500*time.Millisecond, ""),
+			expected: false,
+		},
+		{
+			name:     "different error",
+			a:        result(10*time.Millisecond, ""),
+			b:        result(10*time.Millisecond, "unexpected HTTP status 503"),
+			expected: false,
+		},
+		{
+			name:     "different length",
+			a:        result(10*time.Millisecond, ""),
+			b:        nil,
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(probeResultsEqual(tt.a, tt.b)).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestParseCABundle(t *testing.T) {
+	g := NewWithT(t)
+
+	certPEM, _, err := generateSelfSignedCert("istiod.istio-system.svc")
+	g.Expect(err).ToNot(HaveOccurred())
+	cert, _ := pem.Decode(certPEM)
+	parsed, err := x509.ParseCertificate(cert.Bytes)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	pool, expiry, err := parseCABundle(certPEM)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(pool).ToNot(BeNil())
+	g.Expect(expiry.Time).To(BeTemporally("~", parsed.NotAfter, time.Second))
+
+	_, _, err = parseCABundle([]byte("invalid"))
+	g.Expect(err).To(HaveOccurred())
+}
+
 func TestDoProbe(t *testing.T) {
 	svc := admissionv1.ServiceReference{Name: "istiod", Namespace: "istio-system"}
 	host := svc.Name + "." + svc.Namespace + ".svc"
@@ -158,6 +283,11 @@ func TestDoProbe(t *testing.T) {
 		panic(err)
 	}
 
+	otherCertPEM, _, err := generateSelfSignedCert(host)
+	if err != nil {
+		panic(err)
+	}
+
 	// Load the certificate and key
 	cert, err := tls.X509KeyPair(certPEM, keyPEM)
 	if err != nil {
@@ -179,6 +309,7 @@ func TestDoProbe(t *testing.T) {
 		maxDuration    time.Duration
 		expectedResult bool
 		expectedError  string
+		expectedReason v1.WebhookProbeFailureReason
 	}{
 		{
 			name: "No webhooks",
@@ -196,7 +327,8 @@ func TestDoProbe(t *testing.T) {
 				},
 			},
 			expectedResult: false,
-			expectedError:  "missing webhooks[].clientConfig.service",
+			expectedError:  "no URL or Service specified in WebhookClientConfig",
+			expectedReason: v1.WebhookProbeFailureInvalidConfig,
 		},
 		{
 			name: "Missing CA bundle",
@@ -212,6 +344,7 @@ func TestDoProbe(t *testing.T) {
 			},
 			expectedResult: false,
 			expectedError:  "webhooks[].clientConfig.caBundle hasn't been set; check if the remote istiod can access this cluster",
+			expectedReason: v1.WebhookProbeFailureInvalidConfig,
 		},
 		{
 			name: "Invalid CA bundle",
@@ -227,6 +360,7 @@ func TestDoProbe(t *testing.T) {
 			},
 			expectedResult: false,
 			expectedError:  "failed to append CA bundle to cert pool",
+			expectedReason: v1.WebhookProbeFailureInvalidConfig,
 		},
 		{
 			name: "Unsuccessful HTTP response",
@@ -242,7 +376,8 @@ func TestDoProbe(t *testing.T) {
 			},
 			httpStatus:     http.StatusInternalServerError,
 			expectedResult: false,
-			expectedError:  "",
+			expectedError:  "unexpected HTTP status 500",
+			expectedReason: v1.WebhookProbeFailureHTTPError,
 		},
 		{
 			name: "Successful HTTP response",
@@ -260,6 +395,80 @@ func TestDoProbe(t *testing.T) {
 			expectedResult: true,
 			expectedError:  "",
 		},
+		{
+			name: "URL-based webhook",
+			webhook: &admissionv1.MutatingWebhookConfiguration{
+				Webhooks: []admissionv1.MutatingWebhook{
+					{
+						ClientConfig: admissionv1.WebhookClientConfig{
+							URL:      ptr.Of("https://" + host + "/inject/cluster/remote/net/network1"),
+							CABundle: certPEM,
+						},
+					},
+				},
+			},
+			httpStatus:     http.StatusOK,
+			expectedResult: true,
+			expectedError:  "",
+		},
+		{
+			name: "CA bundle doesn't match server certificate",
+			webhook: &admissionv1.MutatingWebhookConfiguration{
+				Webhooks: []admissionv1.MutatingWebhook{
+					{
+						ClientConfig: admissionv1.WebhookClientConfig{
+							Service:  &svc,
+							CABundle: otherCertPEM,
+						},
+					},
+				},
+			},
+			httpStatus:     http.StatusOK,
+			expectedResult: false,
+			expectedError:  "certificate signed by unknown authority",
+			expectedReason: v1.WebhookProbeFailureTLSError,
+		},
+		{
+			name: "Connection refused",
+			webhook: &admissionv1.MutatingWebhookConfiguration{
+				Webhooks: []admissionv1.MutatingWebhook{
+					{
+						ClientConfig: admissionv1.WebhookClientConfig{
+							URL:      ptr.Of("https://127.0.0.1:1/inject"),
+							CABundle: certPEM,
+						},
+					},
+				},
+			},
+			httpStatus:     http.StatusOK,
+			expectedResult: false,
+			expectedError:  "connection refused",
+			expectedReason: v1.WebhookProbeFailureConnectionError,
+		},
+		{
+			name: "Probes all webhooks",
+			webhook: &admissionv1.MutatingWebhookConfiguration{
+				Webhooks: []admissionv1.MutatingWebhook{
+					{
+						Name: "rev.namespace.sidecar-injector.istio.io",
+						ClientConfig: admissionv1.WebhookClientConfig{
+							Service:  &svc,
+							CABundle: certPEM,
+						},
+					},
+					{
+						Name: "rev.object.sidecar-injector.istio.io",
+						ClientConfig: admissionv1.WebhookClientConfig{
+							Service: &svc,
+						},
+					},
+				},
+			},
+			httpStatus:     http.StatusOK,
+			expectedResult: false,
+			expectedError:  "webhook rev.object.sidecar-injector.istio.io: webhooks[].clientConfig.caBundle hasn't been set",
+			expectedReason: v1.WebhookProbeFailureInvalidConfig,
+		},
 		{
 			name: "Context timeout",
 			webhook: &admissionv1.MutatingWebhookConfiguration{
@@ -278,6 +487,7 @@ func TestDoProbe(t *testing.T) {
 			maxDuration:    1500 * time.Millisecond,
 			expectedResult: false,
 			expectedError:  "context deadline exceeded",
+			expectedReason: v1.WebhookProbeFailureTimeout,
 		},
 		{
 			name: "Default probe timeout",
@@ -296,6 +506,7 @@ func TestDoProbe(t *testing.T) {
 			maxDuration:    defaultTimeoutSeconds*time.Second + 500*time.Millisecond,
 			expectedResult: false,
 			expectedError:  "context deadline exceeded",
+			expectedReason: v1.WebhookProbeFailureTimeout,
 		},
 		{
 			name: "Probe timeout annotation",
@@ -319,6 +530,7 @@ func TestDoProbe(t *testing.T) {
 			maxDuration:    1500 * time.Millisecond,
 			expectedResult: false,
 			expectedError:  "context deadline exceeded",
+			expectedReason: v1.WebhookProbeFailureTimeout,
 		},
 	}
 
@@ -365,18 +577,29 @@ func TestDoProbe(t *testing.T) {
 			}
 
 			startTime := time.Now()
-			result, err := doProbe(probeCtx, tt.webhook)
+			results, err := doProbe(probeCtx, tt.webhook)
 			stopTime := time.Now()
 
 			if tt.maxDuration > 0 {
 				g.Expect(stopTime.Sub(startTime)).To(BeNumerically("<=", tt.maxDuration))
 			}
 
+			result, reason := summarizeProbeResults(results, err)
 			g.Expect(result).To(Equal(tt.expectedResult))
 			if tt.expectedError == "" {
-				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(reason).To(BeEmpty())
 			} else {
-				g.Expect(err.Error()).To(ContainSubstring(tt.expectedError))
+				g.Expect(reason).To(ContainSubstring(tt.expectedError))
+			}
+
+			g.Expect(results).To(HaveLen(len(tt.webhook.Webhooks)))
+			for _, r := range results {
+				if !r.Ready {
+					g.Expect(r.FailureReason).To(Equal(tt.expectedReason))
+					break
+				}
+				g.Expect(r.Latency).ToNot(BeNil())
+				g.Expect(r.CAExpiry).ToNot(BeNil())
 			}
 		})
 	}
@@ -509,6 +732,20 @@ func TestGetReadinessProbeURL(t *testing.T) {
 			config: admissionv1.WebhookClientConfig{
 				URL: ptr.Of("https://some.url"),
 			},
+			expectURL: "https://some.url/ready",
+		},
+		{
+			name: "URL with port, path and query",
+			config: admissionv1.WebhookClientConfig{
+				URL: ptr.Of("https://istiod.example.com:15017/inject/cluster/remote?revision=default"),
+			},
+			expectURL: "https://istiod.example.com:15017/ready",
+		},
+		{
+			name: "URL with http scheme",
+			config: admissionv1.WebhookClientConfig{
+				URL: ptr.Of("http://some.url/inject"),
+			},
 			expectErr: true,
 		},
 		{
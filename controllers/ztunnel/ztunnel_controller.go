@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/go-logr/logr"
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
@@ -27,24 +28,31 @@ import (
 	"github.com/istio-ecosystem/sail-operator/pkg/enqueuelogger"
 	"github.com/istio-ecosystem/sail-operator/pkg/errlist"
 	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"github.com/istio-ecosystem/sail-operator/pkg/istiovalues"
+	"github.com/istio-ecosystem/sail-operator/pkg/istioversion"
 	sharedreconcile "github.com/istio-ecosystem/sail-operator/pkg/reconcile"
 	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
 	"github.com/istio-ecosystem/sail-operator/pkg/revision"
+	"github.com/istio-ecosystem/sail-operator/pkg/validation"
 	"github.com/istio-ecosystem/sail-operator/pkg/watches"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"istio.io/istio/pkg/ptr"
 )
 
+const controllerName = "ztunnel"
+
 // Reconciler reconciles the ZTunnel object
 type Reconciler struct {
 	client.Client
@@ -80,37 +88,87 @@ func NewReconciler(cfg config.ReconcilerConfig, client client.Client, scheme *ru
 func (r *Reconciler) Reconcile(ctx context.Context, ztunnel *v1.ZTunnel) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
 
-	rev, reconcileErr := r.doReconcile(ctx, ztunnel)
+	if ztunnel.Status.State == v1.ZTunnelReasonSuspended {
+		// reconciliation was resumed; record the changes made while it was paused before they're reverted. The
+		// summary is stored in the current status, so that determineStatus carries it over to the new one.
+		log.Info("Reconciliation resumed. Checking for drift.")
+		ztunnel.Status.Drift = r.detectDrift(ctx, ztunnel)
+	}
+
+	rev, rollout, reconcileErr := r.doReconcile(ctx, ztunnel)
 
 	log.Info("Reconciliation done. Updating status.")
-	statusErr := r.updateStatus(ctx, ztunnel, rev, reconcileErr)
+	result, statusErr := r.updateStatus(ctx, ztunnel, rev, rollout, reconcileErr)
 
-	return ctrl.Result{}, errors.Join(reconcileErr, statusErr)
+	return result, errors.Join(reconcileErr, statusErr)
 }
 
 func (r *Reconciler) Finalize(ctx context.Context, ztunnel *v1.ZTunnel) error {
-	ztunnelReconciler := r.newZTunnelReconciler()
+	ztunnelReconciler := r.newZTunnelReconciler(nil)
 	return ztunnelReconciler.Uninstall(ctx, ztunnel.Spec.Namespace)
 }
 
-func (r *Reconciler) doReconcile(ctx context.Context, ztunnel *v1.ZTunnel) (rev *v1.IstioRevision, err error) {
+// Suspend updates the status of a ZTunnel whose reconciliation is paused.
+func (r *Reconciler) Suspend(ctx context.Context, ztunnel *v1.ZTunnel) error {
+	status := *ztunnel.Status.DeepCopy()
+	status.SetCondition(reconciler.SuspendedCondition(v1.ZTunnelConditionReconciled, v1.ZTunnelReasonSuspended))
+	status.State = v1.ZTunnelReasonSuspended
+	status.RetryCount, status.NextRetryTime = 0, nil
+	status.Drift = nil
+	return reconciler.UpdateStatus(ctx, r.Client, ztunnel, ztunnel.Status, status, nil)
+}
+
+// detectDrift returns a summary of the resources that were modified while reconciliation was paused.
+func (r *Reconciler) detectDrift(ctx context.Context, ztunnel *v1.ZTunnel) *v1.DriftSummary {
+	drift, err := r.newZTunnelReconciler(nil).DetectDrift(ctx, ztunnel.Spec.Namespace)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to detect drift")
+		return nil
+	}
+	return reconciler.NewDriftSummary(drift)
+}
+
+// doReconcile installs the chart and advances the batched rollout of the DaemonSet. It returns the referenced
+// IstioRevision and the status of the rollout, which is nil unless the rollout strategy is batched.
+func (r *Reconciler) doReconcile(
+	ctx context.Context, ztunnel *v1.ZTunnel,
+) (rev *v1.IstioRevision, rollout *v1.DaemonSetRolloutStatus, err error) {
 	log := logf.FromContext(ctx)
-	ztunnelReconciler := r.newZTunnelReconciler()
+	tlsConfig, err := r.tlsConfig(ctx, ztunnel)
+	if err != nil {
+		return nil, nil, err
+	}
+	ztunnelReconciler := r.newZTunnelReconciler(tlsConfig)
 
 	if err := ztunnelReconciler.Validate(ctx, ztunnel.Spec.Version, ztunnel.Spec.Namespace); err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	if err := r.validateNodePool(ctx, ztunnel); err != nil {
+		return nil, nil, err
+	}
+
+	if r.Config.StrictVersionSkew {
+		err := revision.ValidateComponentVersion(ctx, r.Client, r.Config, istioversion.ComponentZTunnel, ztunnel.Spec.Version, ztunnel.Status.Version)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
 	if ztunnel.Spec.TargetRef != nil {
 		log.Info("Retrieving referenced IstioRevision")
 		rev, err = revision.GetIstioRevisionFromTargetReference(ctx, r.Client, *ztunnel.Spec.TargetRef)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
 	log.Info("Installing ztunnel Helm chart")
-	return rev, r.installHelmChart(ctx, ztunnel, ztunnelReconciler, rev)
+	if err := r.installHelmChart(ctx, ztunnel, ztunnelReconciler, rev); err != nil {
+		return rev, nil, err
+	}
+	rollout, err = ztunnelReconciler.Rollout(ctx, ztunnel.Spec.Namespace, ztunnel.Spec.Rollout, ztunnel.Status.Rollout)
+	return rev, rollout, err
 }
 
 func (r *Reconciler) installHelmChart(ctx context.Context, ztunnel *v1.ZTunnel,
@@ -125,6 +183,8 @@ func (r *Reconciler) installHelmChart(ctx context.Context, ztunnel *v1.ZTunnel,
 		BlockOwnerDeletion: ptr.Of(true),
 	}
 
+	values := istiovalues.ApplyZTunnelNodeSelector(ztunnel.Spec.Values, ztunnel.Spec.NodeSelector)
+	fipsEnabled := istiovalues.ResolveFipsMode(ztunnel.Spec.Compliance)
 	if rev != nil && rev.Spec.Values != nil {
 		revisionValues := helm.FromValues(v1.Values{
 			MeshConfig: rev.Spec.Values.MeshConfig,
@@ -132,20 +192,57 @@ func (r *Reconciler) installHelmChart(ctx context.Context, ztunnel *v1.ZTunnel,
 			Global:     rev.Spec.Values.Global,
 		})
 		return ztunnelReconciler.Install(
-			ctx, ztunnel.Spec.Version, ztunnel.Spec.Namespace, ztunnel.Spec.Values, &ownerReference, revisionValues)
+			ctx, ztunnel.Spec.Version, ztunnel.Spec.Namespace, values, ztunnel.Spec.Rollout, fipsEnabled, &ownerReference, revisionValues)
+	}
+
+	return ztunnelReconciler.Install(
+		ctx, ztunnel.Spec.Version, ztunnel.Spec.Namespace, values, ztunnel.Spec.Rollout, fipsEnabled, &ownerReference)
+}
+
+// tlsConfig returns the TLS settings that are applied to the ztunnel. Like for its active revision, the profile in
+// spec.tlsProfile of the referenced Istio takes precedence over the TLS profile of the platform.
+func (r *Reconciler) tlsConfig(ctx context.Context, ztunnel *v1.ZTunnel) (*config.TLSConfig, error) {
+	platform := r.Config.TLSConfig.Get()
+	if ztunnel.Spec.TargetRef == nil || ztunnel.Spec.TargetRef.Kind != v1.IstioKind {
+		return platform, nil
+	}
+
+	istio := &v1.Istio{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: ztunnel.Spec.TargetRef.Name}, istio); err != nil {
+		return nil, fmt.Errorf("failed to get referenced Istio %q: %w", ztunnel.Spec.TargetRef.Name, err)
+	}
+	tlsConfig, _, err := istiovalues.ResolveTLSConfig(istio.Spec.TLSProfile, platform, logf.FromContext(ctx))
+	return tlsConfig, err
+}
+
+// validateNodePool checks that the ZTunnel doesn't share its namespace or any of its nodes with another ZTunnel.
+func (r *Reconciler) validateNodePool(ctx context.Context, ztunnel *v1.ZTunnel) error {
+	ztunnels := v1.ZTunnelList{}
+	if err := r.Client.List(ctx, &ztunnels); err != nil {
+		return fmt.Errorf("failed to list ZTunnels: %w", err)
 	}
 
-	return ztunnelReconciler.Install(ctx, ztunnel.Spec.Version, ztunnel.Spec.Namespace, ztunnel.Spec.Values, &ownerReference)
+	others := make([]validation.NodePool, 0, len(ztunnels.Items))
+	for i := range ztunnels.Items {
+		others = append(others, nodePool(&ztunnels.Items[i]))
+	}
+	return validation.ValidateNodePool(v1.ZTunnelKind, nodePool(ztunnel), others)
+}
+
+func nodePool(ztunnel *v1.ZTunnel) validation.NodePool {
+	return validation.NodePool{Meta: &ztunnel.ObjectMeta, Namespace: ztunnel.Spec.Namespace, NodeSelector: ztunnel.Spec.NodeSelector}
 }
 
-func (r *Reconciler) newZTunnelReconciler() *sharedreconcile.ZTunnelReconciler {
+// newZTunnelReconciler returns the shared ZTunnelReconciler. The TLS settings are only used to compute the values,
+// so they can be nil when the chart is uninstalled.
+func (r *Reconciler) newZTunnelReconciler(tlsConfig *config.TLSConfig) *sharedreconcile.ZTunnelReconciler {
 	return sharedreconcile.NewZTunnelReconciler(sharedreconcile.Config{
 		ResourceFS:        r.Config.ResourceFS,
 		Platform:          r.Config.Platform,
 		DefaultProfile:    r.Config.DefaultProfile,
 		OperatorNamespace: r.Config.OperatorNamespace,
 		ChartManager:      r.ChartManager,
-		TLSConfig:         r.Config.TLSConfig,
+		TLSConfig:         tlsConfig,
 	}, r.Client)
 }
 
@@ -165,6 +262,12 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	namespaceHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToReconcileRequest))
 
+	// revisionVersionHandler handles IstioRevisions, whose versions determine the VersionSkew condition of every ZTunnel
+	revisionVersionHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapToAllZTunnels))
+
+	// nodePoolHandler handles changes to any ZTunnel, since they can resolve node pool conflicts with other ZTunnels
+	nodePoolHandler := wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapToAllZTunnels))
+
 	b := ctrl.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{
 			LogConstructor: func(req *reconcile.Request) logr.Logger {
@@ -175,43 +278,79 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 				return log
 			},
 			MaxConcurrentReconciles: r.Config.MaxConcurrentReconciles,
+			RateLimiter:             reconciler.RateLimiter(r.Config.BackoffPolicyFor(controllerName)),
 		}).
 		// we use the Watches function instead of For(), so that we can wrap the handler so that events that cause the object to be enqueued are logged
 		Watches(&v1alpha1.ZTunnel{}, mainObjectHandler).
-		Watches(&v1.ZTunnel{}, mainObjectHandler).
-		Named("ztunnel")
+		Watches(&v1.ZTunnel{}, mainObjectHandler, builder.WithPredicates(watches.AsPredicate(watches.IgnoreRetryStatusChanges()))).
+		Watches(&v1.ZTunnel{}, nodePoolHandler, builder.WithPredicates(watches.AsPredicate(watches.IgnoreStatusChanges()))).
+		Named(controllerName)
 
 	watches.RegisterOwnedWatches(b, watches.ZTunnelWatches, ownedResourceHandler, nil)
 
+	// reconcile all ZTunnels when the platform TLS config changes, so that the new settings are applied to their values
+	if r.Config.TLSConfig != nil {
+		b.WatchesRawSource(r.tlsConfigSource(logger))
+	}
+
 	return b.
 		// +lint-watches:ignore: Namespace (not present in charts, but must be watched to reconcile ZTunnel when its namespace is created)
 		Watches(&corev1.Namespace{}, namespaceHandler).
 		Watches(&v1.Istio{}, operatorResourcesHandler).
 		Watches(&v1.IstioRevision{}, operatorResourcesHandler).
-		Complete(reconciler.NewStandardReconcilerWithFinalizer[*v1.ZTunnel](r.Client, r.Reconcile, r.Finalize, constants.FinalizerName))
+		Watches(&v1.IstioRevision{}, revisionVersionHandler, builder.WithPredicates(watches.AsPredicate(watches.IgnoreStatusChanges()))).
+		Complete(reconciler.NewStandardReconcilerWithFinalizer[*v1.ZTunnel](r.Client, r.Reconcile, r.Finalize, constants.FinalizerName).
+			WithSuspendFunc(r.Suspend))
 }
 
-func (r *Reconciler) determineStatus(ctx context.Context, ztunnel *v1.ZTunnel, rev *v1.IstioRevision, reconcileErr error) (v1.ZTunnelStatus, error) {
+// tlsConfigSource returns the source that enqueues all ZTunnels whenever the platform TLS config is replaced.
+func (r *Reconciler) tlsConfigSource(logger logr.Logger) source.Source {
+	return source.Channel(r.Config.TLSConfig.Subscribe(),
+		wrapEventHandler(logger, handler.EnqueueRequestsFromMapFunc(r.mapToAllZTunnels)))
+}
+
+func (r *Reconciler) determineStatus(
+	ctx context.Context, ztunnel *v1.ZTunnel, rev *v1.IstioRevision, rollout *v1.DaemonSetRolloutStatus, reconcileErr error,
+) (v1.ZTunnelStatus, error) {
 	var errs errlist.Builder
 	reconciledCondition := r.determineReconciledCondition(reconcileErr)
 	readyCondition, err := r.determineReadyCondition(ctx, ztunnel)
 	errs.Add(err)
+	versionSkewCondition, err := r.determineVersionSkewCondition(ctx, ztunnel)
+	errs.Add(err)
 
 	status := *ztunnel.Status.DeepCopy()
 	status.ObservedGeneration = ztunnel.Generation
+	retry := reconciler.NextRetry(r.Config.BackoffPolicyFor(controllerName), ztunnel.Status.RetryCount, reconcileErr)
+	status.RetryCount, status.NextRetryTime = retry.Count, retry.Time
+	status.Platform = string(r.Config.Platform)
 	status.SetCondition(reconciledCondition)
 	status.SetCondition(readyCondition)
+	status.SetCondition(versionSkewCondition)
 	status.State = reconciler.DeriveState(v1.ZTunnelReasonHealthy, reconciledCondition, readyCondition)
+	if reconcileErr == nil {
+		status.Version = ztunnel.Spec.Version
+	}
 	status.IstioRevision = ""
 	if rev != nil {
 		status.IstioRevision = rev.Name
 	}
+	if reconcileErr == nil || !sharedreconcile.IsBatchedRollout(ztunnel.Spec.Rollout) {
+		// when reconciliation of a batched rollout fails, the rollout didn't advance, so its previous status is kept
+		status.Rollout = rollout
+	}
+	if reconcileErr == nil {
+		status.Compliance = istiovalues.FipsComplianceStatus(istiovalues.ResolveFipsMode(ztunnel.Spec.Compliance))
+	}
 	return status, errs.Error()
 }
 
-func (r *Reconciler) updateStatus(ctx context.Context, ztunnel *v1.ZTunnel, rev *v1.IstioRevision, reconcileErr error) error {
-	status, err := r.determineStatus(ctx, ztunnel, rev, reconcileErr)
-	return reconciler.UpdateStatus(ctx, r.Client, ztunnel, ztunnel.Status, status, err)
+func (r *Reconciler) updateStatus(
+	ctx context.Context, ztunnel *v1.ZTunnel, rev *v1.IstioRevision, rollout *v1.DaemonSetRolloutStatus, reconcileErr error,
+) (ctrl.Result, error) {
+	status, err := r.determineStatus(ctx, ztunnel, rev, rollout, reconcileErr)
+	result := ctrl.Result{RequeueAfter: sharedreconcile.RolloutRequeueAfter(status.Rollout)}
+	return result, reconciler.UpdateStatus(ctx, r.Client, ztunnel, ztunnel.Status, status, err)
 }
 
 func (r *Reconciler) determineReconciledCondition(err error) v1.StatusCondition {
@@ -221,8 +360,7 @@ func (r *Reconciler) determineReconciledCondition(err error) v1.StatusCondition
 		c.Reason = v1.ConditionReason(v1.ZTunnelConditionReconciled)
 	} else {
 		c.Status = metav1.ConditionFalse
-		c.Reason = v1.ZTunnelReasonReconcileError
-		c.Message = fmt.Sprintf("error reconciling resource: %v", err)
+		c.Reason, c.Message = reconciler.DescribeReconcileError(err, v1.ZTunnelReasonReconcileError)
 	}
 	return c
 }
@@ -239,6 +377,27 @@ func (r *Reconciler) getDaemonSetKey(ztunnel *v1.ZTunnel) client.ObjectKey {
 	}
 }
 
+func (r *Reconciler) determineVersionSkewCondition(ctx context.Context, ztunnel *v1.ZTunnel) (v1.StatusCondition, error) {
+	c := v1.StatusCondition{Type: v1.ZTunnelConditionVersionSkew}
+	skewed, err := revision.CheckComponentSkew(ctx, r.Client, r.Config, istioversion.ComponentZTunnel, ztunnel.Spec.Version)
+	if err != nil {
+		c.Status = metav1.ConditionUnknown
+		c.Reason = v1.ZTunnelReasonVersionSkewCheckFailed
+		c.Message = fmt.Sprintf("failed to check version skew: %v", err)
+		return c, err
+	}
+	if len(skewed) > 0 {
+		c.Status = metav1.ConditionTrue
+		c.Reason = v1.ZTunnelReasonUnsupportedVersionSkew
+		c.Message = strings.Join(skewed, "; ")
+	} else {
+		c.Status = metav1.ConditionFalse
+		c.Reason = v1.ZTunnelReasonSupportedVersionSkew
+		c.Message = "ZTunnel version is supported by all IstioRevisions that use it"
+	}
+	return c, nil
+}
+
 func (r *Reconciler) mapNamespaceToReconcileRequest(ctx context.Context, ns client.Object) []reconcile.Request {
 	log := logf.FromContext(ctx)
 
@@ -283,6 +442,22 @@ func (r *Reconciler) mapOperatorResourceToReconcileRequest(ctx context.Context,
 	return requests
 }
 
+// mapToAllZTunnels enqueues all ZTunnels. It's used for IstioRevisions, since any IstioRevision in ambient mode
+// depends on them, and for ZTunnels, since any ZTunnel can conflict with the node pool of another.
+func (r *Reconciler) mapToAllZTunnels(ctx context.Context, _ client.Object) []reconcile.Request {
+	ztunnels := v1.ZTunnelList{}
+	if err := r.Client.List(ctx, &ztunnels); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list ZTunnels")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(ztunnels.Items))
+	for _, ztunnel := range ztunnels.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: ztunnel.Name}})
+	}
+	return requests
+}
+
 func wrapEventHandler(logger logr.Logger, handler handler.EventHandler) handler.EventHandler {
 	return enqueuelogger.WrapIfNecessary(v1.ZTunnelKind, logger, handler)
 }
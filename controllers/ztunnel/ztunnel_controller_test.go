@@ -16,11 +16,13 @@ package ztunnel
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
@@ -32,10 +34,14 @@ import (
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"istio.io/istio/pkg/ptr"
 )
@@ -540,9 +546,10 @@ func TestDetermineStatus(t *testing.T) {
 	cfg := newReconcilerTestConfig(t)
 
 	tests := []struct {
-		name         string
-		reconcileErr error
-		rev          *v1.IstioRevision
+		name           string
+		reconcileErr   error
+		rev            *v1.IstioRevision
+		expectedReason v1.ConditionReason
 	}{
 		{
 			name: "no error",
@@ -554,8 +561,14 @@ func TestDetermineStatus(t *testing.T) {
 			reconcileErr: nil,
 		},
 		{
-			name:         "reconcile error",
-			reconcileErr: fmt.Errorf("some reconcile error"),
+			name:           "reconcile error",
+			reconcileErr:   fmt.Errorf("some reconcile error"),
+			expectedReason: v1.ZTunnelReasonReconcileError,
+		},
+		{
+			name:           "permission denied",
+			reconcileErr:   apierrors.NewForbidden(appsv1.Resource("daemonsets"), "ztunnel", fmt.Errorf("no RBAC policy matched")),
+			expectedReason: reconciler.ReasonPermissionDenied,
 		},
 	}
 
@@ -574,10 +587,11 @@ func TestDetermineStatus(t *testing.T) {
 				},
 			}
 
-			status, err := r.determineStatus(ctx, ztunnel, tt.rev, tt.reconcileErr)
+			status, err := r.determineStatus(ctx, ztunnel, tt.rev, nil, tt.reconcileErr)
 			g.Expect(err).ToNot(HaveOccurred())
 
 			g.Expect(status.ObservedGeneration).To(Equal(ztunnel.Generation))
+			g.Expect(status.Platform).To(Equal(string(cfg.Platform)))
 
 			reconciledCondition := r.determineReconciledCondition(tt.reconcileErr)
 			readyCondition, err := r.determineReadyCondition(ctx, ztunnel)
@@ -586,6 +600,9 @@ func TestDetermineStatus(t *testing.T) {
 			g.Expect(status.State).To(Equal(reconciler.DeriveState(v1.ZTunnelReasonHealthy, reconciledCondition, readyCondition)))
 			g.Expect(normalize(status.GetCondition(v1.ZTunnelConditionReconciled))).To(Equal(normalize(reconciledCondition)))
 			g.Expect(normalize(status.GetCondition(v1.ZTunnelConditionReady))).To(Equal(normalize(readyCondition)))
+			if tt.expectedReason != "" {
+				g.Expect(status.GetCondition(v1.ZTunnelConditionReconciled).Reason).To(Equal(tt.expectedReason))
+			}
 			if tt.rev != nil {
 				g.Expect(status.IstioRevision).To(Equal(tt.rev.Name))
 			} else {
@@ -595,6 +612,128 @@ func TestDetermineStatus(t *testing.T) {
 	}
 }
 
+func TestDetermineStatusReportsCompliance(t *testing.T) {
+	g := NewWithT(t)
+	cfg := newReconcilerTestConfig(t)
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	r := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+	ztunnel := &v1.ZTunnel{
+		ObjectMeta: metav1.ObjectMeta{Name: "ztunnel"},
+		Spec:       v1.ZTunnelSpec{Compliance: &v1.Compliance{FIPS: v1.FIPSModeEnabled}},
+		Status:     v1.ZTunnelStatus{Compliance: &v1.ComplianceStatus{FIPS: v1.FIPSModeDisabled}},
+	}
+
+	status, err := r.determineStatus(context.TODO(), ztunnel, nil, nil, nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(status.Compliance).To(Equal(&v1.ComplianceStatus{FIPS: v1.FIPSModeEnabled}))
+
+	// when reconciliation fails, the new mode wasn't applied, so the previous status is kept
+	status, err = r.determineStatus(context.TODO(), ztunnel, nil, nil, fmt.Errorf("some reconcile error"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(status.Compliance).To(Equal(&v1.ComplianceStatus{FIPS: v1.FIPSModeDisabled}))
+}
+
+func TestTLSConfig(t *testing.T) {
+	platform := &config.TLSConfig{CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, MinVersion: tls.VersionTLS12}
+	istioWithProfile := &v1.Istio{
+		ObjectMeta: metav1.ObjectMeta{Name: "modern"},
+		Spec:       v1.IstioSpec{TLSProfile: &v1.TLSProfile{Type: v1.TLSProfileModern}},
+	}
+	istioWithoutProfile := &v1.Istio{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	istioWithInvalidProfile := &v1.Istio{
+		ObjectMeta: metav1.ObjectMeta{Name: "invalid"},
+		Spec:       v1.IstioSpec{TLSProfile: &v1.TLSProfile{Type: v1.TLSProfileCustom}},
+	}
+
+	testCases := []struct {
+		name             string
+		targetRef        *v1.TargetReference
+		expectMinVersion uint16
+		expectErr        func(error) bool
+	}{
+		{
+			name:             "no targetRef",
+			expectMinVersion: tls.VersionTLS12,
+		},
+		{
+			name:             "targetRef to IstioRevision",
+			targetRef:        &v1.TargetReference{Kind: v1.IstioRevisionKind, Name: "default"},
+			expectMinVersion: tls.VersionTLS12,
+		},
+		{
+			name:             "Istio without tlsProfile",
+			targetRef:        &v1.TargetReference{Kind: v1.IstioKind, Name: istioWithoutProfile.Name},
+			expectMinVersion: tls.VersionTLS12,
+		},
+		{
+			name:             "Istio with tlsProfile",
+			targetRef:        &v1.TargetReference{Kind: v1.IstioKind, Name: istioWithProfile.Name},
+			expectMinVersion: tls.VersionTLS13,
+		},
+		{
+			name:      "Istio with invalid tlsProfile",
+			targetRef: &v1.TargetReference{Kind: v1.IstioKind, Name: istioWithInvalidProfile.Name},
+			expectErr: reconciler.IsValidationError,
+		},
+		{
+			name:      "Istio not found",
+			targetRef: &v1.TargetReference{Kind: v1.IstioKind, Name: "missing"},
+			expectErr: apierrors.IsNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			cfg := newReconcilerTestConfig(t)
+			cfg.TLSConfig = config.NewTLSConfigSource(platform)
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+				WithObjects(istioWithProfile, istioWithoutProfile, istioWithInvalidProfile).Build()
+			r := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+			ztunnel := &v1.ZTunnel{ObjectMeta: metav1.ObjectMeta{Name: "default"}, Spec: v1.ZTunnelSpec{TargetRef: tc.targetRef}}
+			tlsConfig, err := r.tlsConfig(context.TODO(), ztunnel)
+			if tc.expectErr != nil {
+				g.Expect(tc.expectErr(err)).To(BeTrue())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(tlsConfig.MinVersion).To(Equal(tc.expectMinVersion))
+		})
+	}
+}
+
+func TestTLSConfigSourceEnqueuesAllZTunnels(t *testing.T) {
+	g := NewWithT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := newReconcilerTestConfig(t)
+	cfg.TLSConfig = config.NewTLSConfigSource(&config.TLSConfig{MinVersion: tls.VersionTLS12})
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		&v1.ZTunnel{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+		&v1.ZTunnel{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+	).Build()
+	r := NewReconciler(cfg, cl, scheme.Scheme, nil)
+
+	queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer queue.ShutDown()
+	g.Expect(r.tlsConfigSource(logr.Discard()).Start(ctx, queue)).To(Succeed())
+
+	cfg.TLSConfig.Set(&config.TLSConfig{MinVersion: tls.VersionTLS13})
+	g.Eventually(queue.Len).Should(Equal(2))
+	var requests []reconcile.Request
+	for queue.Len() > 0 {
+		req, _ := queue.Get()
+		requests = append(requests, req)
+		queue.Done(req)
+	}
+	g.Expect(requests).To(ConsistOf(
+		reconcile.Request{NamespacedName: types.NamespacedName{Name: "a"}},
+		reconcile.Request{NamespacedName: types.NamespacedName{Name: "b"}},
+	))
+}
+
 func normalize(condition v1.StatusCondition) v1.StatusCondition {
 	condition.LastTransitionTime = metav1.Time{}
 	return condition
@@ -608,3 +747,34 @@ func newReconcilerTestConfig(t *testing.T) config.ReconcilerConfig {
 		MaxConcurrentReconciles: 1,
 	}
 }
+
+func TestDetermineVersionSkewCondition(t *testing.T) {
+	obj := &v1.ZTunnel{ObjectMeta: metav1.ObjectMeta{Name: "default"}, Spec: v1.ZTunnelSpec{Version: istioversion.Default, Namespace: "ztunnel"}}
+
+	t.Run("no skewed revisions", func(t *testing.T) {
+		g := NewWithT(t)
+		cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme, nil)
+
+		c, err := r.determineVersionSkewCondition(context.TODO(), obj)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(c.Type).To(Equal(v1.ZTunnelConditionVersionSkew))
+		g.Expect(c.Status).To(Equal(metav1.ConditionFalse))
+		g.Expect(c.Reason).To(Equal(v1.ZTunnelReasonSupportedVersionSkew))
+	})
+
+	t.Run("check failed", func(t *testing.T) {
+		g := NewWithT(t)
+		cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithInterceptorFuncs(interceptor.Funcs{
+			List: func(_ context.Context, _ client.WithWatch, _ client.ObjectList, _ ...client.ListOption) error {
+				return fmt.Errorf("simulated error")
+			},
+		}).Build()
+		r := NewReconciler(newReconcilerTestConfig(t), cl, scheme.Scheme, nil)
+
+		c, err := r.determineVersionSkewCondition(context.TODO(), obj)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(c.Status).To(Equal(metav1.ConditionUnknown))
+		g.Expect(c.Reason).To(Equal(v1.ZTunnelReasonVersionSkewCheckFailed))
+	})
+}
@@ -123,7 +123,7 @@ func main() {
 	}
 
 	// Render in a stable order
-	order := []string{"Istio", "IstioRevision", "IstioRevisionTag", "IstioCNI", "ZTunnel"}
+	order := []string{"Istio", "IstioRevision", "IstioRevisionTag", "IstioRevisionBinding", "IstioCNI", "ZTunnel", "AmbientEnrollment", "Migration", "Waypoint"}
 	for cr := range crGroups {
 		if !contains(order, cr) {
 			order = append(order, cr)
@@ -50,26 +50,33 @@ cat >> "${OUT}" << 'GOHEADER'
 
 package install
 
-import "github.com/istio-ecosystem/sail-operator/pkg/config"
+import (
+	"maps"
 
-func init() {
-	config.Config.ImageDigests = map[string]config.IstioImageConfig{
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+)
+
+// imageDigests are the operand images of the versions embedded in the operator.
+var imageDigests = map[string]config.IstioImageConfig{
 GOHEADER
 
 for ver in "${VERSION_ORDER[@]}"; do
   [ "${SEEN_VERSIONS[$ver]}" -eq 4 ] || { echo "Warning: skipping version ${ver} (missing components)" >&2; continue; }
   cat >> "${OUT}" << GOENTRY
-		"v${ver}": {
-			IstiodImage:  "${IMAGES[$ver.istiod]}",
-			ProxyImage:   "${IMAGES[$ver.proxy]}",
-			CNIImage:     "${IMAGES[$ver.cni]}",
-			ZTunnelImage: "${IMAGES[$ver.ztunnel]}",
-		},
+	"v${ver}": {
+		IstiodImage:  "${IMAGES[$ver.istiod]}",
+		ProxyImage:   "${IMAGES[$ver.proxy]}",
+		CNIImage:     "${IMAGES[$ver.cni]}",
+		ZTunnelImage: "${IMAGES[$ver.ztunnel]}",
+	},
 GOENTRY
 done
 
 cat >> "${OUT}" << 'GOFOOTER'
-	}
+}
+
+func init() {
+	config.Config.ImageDigests = maps.Clone(imageDigests)
 }
 GOFOOTER
 
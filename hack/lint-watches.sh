@@ -89,3 +89,4 @@ check_watches() {
 check_watches "./controllers/istiorevision/istiorevision_controller.go:./pkg/watches/istiod.go" "./resources/*/charts/istiod ./resources/*/charts/istiod-remote ./resources/*/charts/base"
 check_watches "./controllers/istiocni/istiocni_controller.go:./pkg/watches/cni.go" "./resources/*/charts/cni"
 check_watches "./controllers/ztunnel/ztunnel_controller.go:./pkg/watches/ztunnel.go" "./resources/*/charts/ztunnel"
+check_watches "./controllers/istio/istio_controller.go:./pkg/watches/gateway.go" "./resources/*/charts/gateway"
@@ -0,0 +1,103 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultBackoffPolicy is used by controllers that have no backoff policy configured.
+var DefaultBackoffPolicy = BackoffPolicy{
+	InitialDelay: 5 * time.Second,
+	MaxDelay:     5 * time.Minute,
+}
+
+// BackoffPolicy defines how long a controller waits before retrying a failed reconciliation. The delay starts
+// at InitialDelay and doubles with each consecutive failure, up to MaxDelay.
+type BackoffPolicy struct {
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+}
+
+// Delay returns the delay before the given retry; the first retry has retryCount 1.
+func (p BackoffPolicy) Delay(retryCount int32) time.Duration {
+	if retryCount < 1 {
+		retryCount = 1
+	}
+	delay := p.InitialDelay
+	for i := int32(1); i < retryCount && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxDelay)
+}
+
+// IsZero returns true if the policy is not set.
+func (p BackoffPolicy) IsZero() bool {
+	return p.InitialDelay == 0 && p.MaxDelay == 0
+}
+
+// BackoffPolicyFor returns the backoff policy of the named controller. If the controller has no policy of its
+// own, the common Backoff policy is returned, and if that isn't set either, DefaultBackoffPolicy.
+func (c ReconcilerConfig) BackoffPolicyFor(controller string) BackoffPolicy {
+	if p, ok := c.ControllerBackoff[controller]; ok && !p.IsZero() {
+		return p
+	}
+	if !c.Backoff.IsZero() {
+		return c.Backoff
+	}
+	return DefaultBackoffPolicy
+}
+
+// ParseBackoffPolicies parses per-controller backoff policies in the format
+// "<controller>=<initialDelay>/<maxDelay>[,<controller>=<initialDelay>/<maxDelay>...]", e.g. "istiocni=10s/10m".
+func ParseBackoffPolicies(s string) (map[string]BackoffPolicy, error) {
+	policies := map[string]BackoffPolicy{}
+	if strings.TrimSpace(s) == "" {
+		return policies, nil
+	}
+	for _, entry := range strings.Split(s, ",") {
+		controller, delays, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || controller == "" {
+			return nil, fmt.Errorf("invalid backoff policy %q: expected <controller>=<initialDelay>/<maxDelay>", entry)
+		}
+		initial, maxDelay, ok := strings.Cut(delays, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid backoff policy %q: expected <controller>=<initialDelay>/<maxDelay>", entry)
+		}
+		policy, err := newBackoffPolicy(initial, maxDelay)
+		if err != nil {
+			return nil, fmt.Errorf("invalid backoff policy for controller %q: %w", controller, err)
+		}
+		policies[controller] = policy
+	}
+	return policies, nil
+}
+
+func newBackoffPolicy(initialDelay, maxDelay string) (BackoffPolicy, error) {
+	initial, err := time.ParseDuration(initialDelay)
+	if err != nil {
+		return BackoffPolicy{}, fmt.Errorf("invalid initial delay: %w", err)
+	}
+	maximum, err := time.ParseDuration(maxDelay)
+	if err != nil {
+		return BackoffPolicy{}, fmt.Errorf("invalid max delay: %w", err)
+	}
+	if initial <= 0 || maximum < initial {
+		return BackoffPolicy{}, fmt.Errorf("initial delay must be positive and not greater than max delay")
+	}
+	return BackoffPolicy{InitialDelay: initial, MaxDelay: maximum}, nil
+}
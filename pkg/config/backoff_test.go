@@ -0,0 +1,111 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoffPolicyDelay(t *testing.T) {
+	policy := BackoffPolicy{InitialDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	tests := []struct {
+		retryCount int32
+		expected   time.Duration
+	}{
+		{retryCount: 0, expected: time.Second},
+		{retryCount: 1, expected: time.Second},
+		{retryCount: 2, expected: 2 * time.Second},
+		{retryCount: 3, expected: 4 * time.Second},
+		{retryCount: 4, expected: 8 * time.Second},
+		{retryCount: 5, expected: 10 * time.Second},
+		{retryCount: 1000, expected: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, policy.Delay(tt.retryCount), "retryCount %d", tt.retryCount)
+	}
+}
+
+func TestBackoffPolicyFor(t *testing.T) {
+	common := BackoffPolicy{InitialDelay: time.Second, MaxDelay: time.Minute}
+	cni := BackoffPolicy{InitialDelay: 10 * time.Second, MaxDelay: 10 * time.Minute}
+
+	assert.Equal(t, DefaultBackoffPolicy, ReconcilerConfig{}.BackoffPolicyFor("istiocni"))
+
+	cfg := ReconcilerConfig{
+		Backoff:           common,
+		ControllerBackoff: map[string]BackoffPolicy{"istiocni": cni},
+	}
+	assert.Equal(t, cni, cfg.BackoffPolicyFor("istiocni"))
+	assert.Equal(t, common, cfg.BackoffPolicyFor("ztunnel"))
+}
+
+func TestParseBackoffPolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string]BackoffPolicy
+		wantErr  bool
+	}{
+		{
+			name:     "empty",
+			input:    "",
+			expected: map[string]BackoffPolicy{},
+		},
+		{
+			name:  "multiple controllers",
+			input: "istiocni=10s/10m, ztunnel=1s/30s",
+			expected: map[string]BackoffPolicy{
+				"istiocni": {InitialDelay: 10 * time.Second, MaxDelay: 10 * time.Minute},
+				"ztunnel":  {InitialDelay: time.Second, MaxDelay: 30 * time.Second},
+			},
+		},
+		{
+			name:    "missing controller",
+			input:   "=10s/10m",
+			wantErr: true,
+		},
+		{
+			name:    "missing max delay",
+			input:   "istiocni=10s",
+			wantErr: true,
+		},
+		{
+			name:    "invalid duration",
+			input:   "istiocni=ten/10m",
+			wantErr: true,
+		},
+		{
+			name:    "initial delay greater than max delay",
+			input:   "istiocni=10m/10s",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policies, err := ParseBackoffPolicies(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, policies)
+		})
+	}
+}
@@ -24,7 +24,8 @@ import (
 var Config = OperatorConfig{}
 
 type OperatorConfig struct {
-	ImageDigests map[string]IstioImageConfig `properties:"images"`
+	ImageDigests    map[string]IstioImageConfig `properties:"images"`
+	RegistryMirrors map[string]RegistryMirror   `properties:"registryMirrors"`
 }
 
 type IstioImageConfig struct {
@@ -40,7 +41,11 @@ type ReconcilerConfig struct {
 	DefaultProfile          string
 	OperatorNamespace       string
 	MaxConcurrentReconciles int
-	TLSConfig               *TLSConfig
+	TLSConfig               *TLSConfigSource
+	ManageIstioCRDs         bool
+	Backoff                 BackoffPolicy
+	ControllerBackoff       map[string]BackoffPolicy
+	StrictVersionSkew       bool
 }
 
 func Read(configFile string) error {
@@ -77,5 +82,9 @@ func decodePropertiesInto(p *properties.Properties, cfg *OperatorConfig) error {
 		newImageDigests[strings.ReplaceAll(k, "_", ".")] = v
 	}
 	cfg.ImageDigests = newImageDigests
+	// the decoder creates an empty map even if no registry mirrors are configured
+	if len(cfg.RegistryMirrors) == 0 {
+		cfg.RegistryMirrors = nil
+	}
 	return nil
 }
@@ -50,6 +50,26 @@ images.v1_20_0.ztunnel=ztunnel-test
 			},
 			success: true,
 		},
+		{
+			name: "registry mirrors",
+			data: `
+images.v1_20_0.istiod=istiod-test
+images.v1_20_0.proxy=proxy-test
+images.v1_20_0.cni=cni-test
+images.v1_20_0.ztunnel=ztunnel-test
+registryMirrors.internal.source=registry.istio.io/release
+registryMirrors.internal.mirror=registry.example.com/istio
+`,
+			expectedConfig: OperatorConfig{
+				ImageDigests: map[string]IstioImageConfig{
+					"v1.20.0": testImages,
+				},
+				RegistryMirrors: map[string]RegistryMirror{
+					"internal": {Source: "registry.istio.io/release", Mirror: "registry.example.com/istio"},
+				},
+			},
+			success: true,
+		},
 		{
 			name: "empty data",
 			data: "",
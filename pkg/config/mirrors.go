@@ -0,0 +1,120 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// RegistryMirror is a rule of the registry rewrite policy. The operand images in the Source repository, or in any
+// repository below it, are pulled from the Mirror repository instead. The tag or digest of the image is kept, so
+// the mirror must contain the same digests as the source.
+type RegistryMirror struct {
+	Source string `properties:"source"`
+	Mirror string `properties:"mirror"`
+}
+
+// RewriteImage returns the image or hub rewritten according to the registry mirrors. If the sources of several
+// mirrors match, the most specific one is used. The image is returned unchanged if no source matches.
+func (c OperatorConfig) RewriteImage(image string) string {
+	var match RegistryMirror
+	for _, m := range c.RegistryMirrors {
+		if m.Source != "" && matchesSource(image, m.Source) && len(m.Source) > len(match.Source) {
+			match = m
+		}
+	}
+	if match.Source == "" {
+		return image
+	}
+	return match.Mirror + strings.TrimPrefix(image, match.Source)
+}
+
+// ValidateRegistryMirrors checks that the registry mirrors are well-formed and that no mirror is itself rewritten.
+func (c OperatorConfig) ValidateRegistryMirrors() error {
+	var errs []error
+	sources := make(map[string]string, len(c.RegistryMirrors))
+	for _, name := range slices.Sorted(maps.Keys(c.RegistryMirrors)) {
+		m := c.RegistryMirrors[name]
+		if m.Source == "" || m.Mirror == "" {
+			errs = append(errs, fmt.Errorf("registry mirror %q: both source and mirror must be set", name))
+			continue
+		}
+		if hasTagOrDigest(m.Source) || hasTagOrDigest(m.Mirror) {
+			errs = append(errs, fmt.Errorf("registry mirror %q: source and mirror must not contain a tag or digest", name))
+			continue
+		}
+		if other, found := sources[m.Source]; found {
+			errs = append(errs, fmt.Errorf("registry mirror %q: source %q is already defined in registry mirror %q", name, m.Source, other))
+			continue
+		}
+		sources[m.Source] = name
+	}
+	// the rewritten images must not be rewritten again, since the final values of a component may be computed
+	// from the values of another one, e.g. the values of a ZTunnel from those of the IstioRevision
+	for _, name := range slices.Sorted(maps.Keys(c.RegistryMirrors)) {
+		m := c.RegistryMirrors[name]
+		for _, source := range slices.Sorted(maps.Keys(sources)) {
+			if m.Mirror != "" && matchesSource(m.Mirror, source) {
+				errs = append(errs, fmt.Errorf("registry mirror %q: mirror %q is rewritten by registry mirror %q", name, m.Mirror, sources[source]))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CheckRegistryMirrorSources checks that the source of each registry mirror matches at least one of the given
+// operand images, so that a misspelled source doesn't go unnoticed. Since the images may also be set in the values
+// of the resources, a source that doesn't match isn't necessarily wrong.
+func (c OperatorConfig) CheckRegistryMirrorSources(images []string) error {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(c.RegistryMirrors)) {
+		m := c.RegistryMirrors[name]
+		if m.Source != "" && !slices.ContainsFunc(images, func(image string) bool { return matchesSource(image, m.Source) }) {
+			errs = append(errs, fmt.Errorf("registry mirror %q: source %q doesn't match any operand image", name, m.Source))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// matchesSource returns whether the image or hub is in the source repository or in a repository below it.
+func matchesSource(image, source string) bool {
+	rest, found := strings.CutPrefix(image, source)
+	if !found {
+		return false
+	}
+	switch {
+	case rest == "", rest[0] == '/', rest[0] == '@':
+		return true
+	case rest[0] == ':':
+		// a tag, unless it's the port of the registry host
+		return !strings.Contains(rest, "/")
+	default:
+		return false
+	}
+}
+
+// hasTagOrDigest returns whether the repository reference ends with a tag or digest. A reference without a slash
+// is a registry host, which may contain a port.
+func hasTagOrDigest(ref string) bool {
+	if strings.Contains(ref, "@") {
+		return true
+	}
+	i := strings.LastIndex(ref, "/")
+	return i >= 0 && strings.Contains(ref[i+1:], ":")
+}
@@ -0,0 +1,218 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import "testing"
+
+func TestRewriteImage(t *testing.T) {
+	cfg := OperatorConfig{
+		RegistryMirrors: map[string]RegistryMirror{
+			"release": {Source: "registry.istio.io/release", Mirror: "registry.example.com/istio"},
+			"pilot":   {Source: "registry.istio.io/release/pilot", Mirror: "registry.example.com/istio-pilot"},
+			"local":   {Source: "localhost:5000", Mirror: "registry.example.com/local"},
+		},
+	}
+
+	testCases := []struct {
+		name  string
+		image string
+		want  string
+	}{
+		{
+			name:  "tag",
+			image: "registry.istio.io/release/proxyv2:1.30.3",
+			want:  "registry.example.com/istio/proxyv2:1.30.3",
+		},
+		{
+			name:  "digest",
+			image: "registry.istio.io/release/proxyv2@sha256:abc123",
+			want:  "registry.example.com/istio/proxyv2@sha256:abc123",
+		},
+		{
+			name:  "hub",
+			image: "registry.istio.io/release",
+			want:  "registry.example.com/istio",
+		},
+		{
+			name:  "most specific source",
+			image: "registry.istio.io/release/pilot:1.30.3",
+			want:  "registry.example.com/istio-pilot:1.30.3",
+		},
+		{
+			name:  "source is prefix of repository name",
+			image: "registry.istio.io/release/pilot-extra:1.30.3",
+			want:  "registry.example.com/istio/pilot-extra:1.30.3",
+		},
+		{
+			name:  "registry with port",
+			image: "localhost:5000/proxyv2:1.30.3",
+			want:  "registry.example.com/local/proxyv2:1.30.3",
+		},
+		{
+			name:  "source is prefix of registry host",
+			image: "registry.istio.io.example.com/release/proxyv2:1.30.3",
+			want:  "registry.istio.io.example.com/release/proxyv2:1.30.3",
+		},
+		{
+			name:  "no match",
+			image: "docker.io/istio/proxyv2:1.30.3",
+			want:  "docker.io/istio/proxyv2:1.30.3",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := cfg.RewriteImage(tc.image); got != tc.want {
+				t.Errorf("RewriteImage(%q) = %q, want %q", tc.image, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateRegistryMirrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mirrors map[string]RegistryMirror
+		wantErr bool
+	}{
+		{
+			name: "no mirrors",
+		},
+		{
+			name: "valid",
+			mirrors: map[string]RegistryMirror{
+				"release": {Source: "registry.istio.io/release", Mirror: "registry.example.com/istio"},
+				"ztunnel": {Source: "registry.istio.io/release/ztunnel", Mirror: "registry.example.com:5000/ztunnel"},
+			},
+		},
+		{
+			name: "registry host",
+			mirrors: map[string]RegistryMirror{
+				"registry": {Source: "registry.istio.io", Mirror: "registry.example.com:5000"},
+			},
+		},
+		{
+			name: "missing mirror",
+			mirrors: map[string]RegistryMirror{
+				"release": {Source: "registry.istio.io/release"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "source with tag",
+			mirrors: map[string]RegistryMirror{
+				"pilot": {Source: "registry.istio.io/release/pilot:1.30.3", Mirror: "registry.example.com/istio/pilot"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mirror with digest",
+			mirrors: map[string]RegistryMirror{
+				"pilot": {Source: "registry.istio.io/release/pilot", Mirror: "registry.example.com/istio/pilot@sha256:abc123"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mirror is rewritten",
+			mirrors: map[string]RegistryMirror{
+				"release":  {Source: "registry.istio.io/release", Mirror: "registry.example.com/istio"},
+				"registry": {Source: "registry.example.com", Mirror: "registry.example.org"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mirror below its source",
+			mirrors: map[string]RegistryMirror{
+				"release": {Source: "registry.istio.io/release", Mirror: "registry.istio.io/release/mirror"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate source",
+			mirrors: map[string]RegistryMirror{
+				"a": {Source: "registry.istio.io/release", Mirror: "registry.example.com/a"},
+				"b": {Source: "registry.istio.io/release", Mirror: "registry.example.com/b"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := OperatorConfig{RegistryMirrors: tc.mirrors}
+			err := cfg.ValidateRegistryMirrors()
+			if tc.wantErr && err == nil {
+				t.Fatal("expected error but got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatal("expected no error but got:", err)
+			}
+		})
+	}
+}
+
+func TestCheckRegistryMirrorSources(t *testing.T) {
+	images := []string{
+		"registry.istio.io/release/pilot@sha256:abc123",
+		"registry.istio.io/release/proxyv2@sha256:def456",
+		"registry.istio.io/release/install-cni@sha256:ghi789",
+		"registry.istio.io/release/ztunnel@sha256:jkl012",
+	}
+
+	testCases := []struct {
+		name    string
+		mirrors map[string]RegistryMirror
+		wantErr bool
+	}{
+		{
+			name: "no mirrors",
+		},
+		{
+			name: "matching sources",
+			mirrors: map[string]RegistryMirror{
+				"release":  {Source: "registry.istio.io/release", Mirror: "registry.example.com/istio"},
+				"ztunnel":  {Source: "registry.istio.io/release/ztunnel", Mirror: "registry.example.com/ztunnel"},
+				"registry": {Source: "registry.istio.io", Mirror: "registry.example.com:5000"},
+			},
+		},
+		{
+			name: "source doesn't match any image",
+			mirrors: map[string]RegistryMirror{
+				"release": {Source: "registry.istio.io/relase", Mirror: "registry.example.com/istio"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "source is prefix of repository name",
+			mirrors: map[string]RegistryMirror{
+				"pilot": {Source: "registry.istio.io/release/pil", Mirror: "registry.example.com/istio"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := OperatorConfig{RegistryMirrors: tc.mirrors}
+			err := cfg.CheckRegistryMirrorSources(images)
+			if tc.wantErr && err == nil {
+				t.Fatal("expected error but got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatal("expected no error but got:", err)
+			}
+		})
+	}
+}
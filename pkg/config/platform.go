@@ -15,10 +15,15 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
@@ -28,31 +33,133 @@ const (
 	PlatformUndefined  Platform = ""
 	PlatformOpenShift  Platform = "openshift"
 	PlatformKubernetes Platform = "kubernetes"
+	PlatformGKE        Platform = "gke"
+	PlatformEKS        Platform = "eks"
+	PlatformAKS        Platform = "aks"
+	PlatformK3s        Platform = "k3s"
+	PlatformK3d        Platform = "k3d"
+	PlatformMicroK8s   Platform = "microk8s"
+	PlatformMinikube   Platform = "minikube"
 )
 
+// HelmPlatform returns the value of values.global.platform for the platform, or
+// an empty string if the Istio charts have no platform profile for it.
+func (p Platform) HelmPlatform() string {
+	switch p {
+	case PlatformOpenShift, PlatformGKE, PlatformK3s, PlatformK3d, PlatformMicroK8s, PlatformMinikube:
+		return string(p)
+	default:
+		return ""
+	}
+}
+
 const (
 	openshiftKind            = "OpenShiftAPIServer"
 	openshiftResourceGroup   = "operator.openshift.io"
 	openshiftResourceVersion = "v1"
+
+	// nodeSampleSize is the number of nodes inspected by the node-based heuristics
+	nodeSampleSize = 10
 )
 
+// platformAPIGroups maps API groups that are only present on a specific managed
+// Kubernetes offering to that offering. They are used when the nodes can't be read.
+var platformAPIGroups = map[string]Platform{
+	"networking.gke.io":    PlatformGKE,
+	"vpcresources.k8s.aws": PlatformEKS,
+}
+
 func DetectPlatform(cfg *rest.Config) (Platform, error) {
-	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	clientset, err := kubernetes.NewForConfig(cfg)
 	if err != nil {
-		return "", fmt.Errorf("failed to create discoveryClient: %w", err)
+		return "", fmt.Errorf("failed to create clientset: %w", err)
+	}
+	return detectPlatform(context.Background(), clientset)
+}
+
+func detectPlatform(ctx context.Context, clientset kubernetes.Interface) (Platform, error) {
+	dc := clientset.Discovery()
+	if openshift, err := isOpenShift(dc); err != nil {
+		return "", err
+	} else if openshift {
+		return PlatformOpenShift, nil
+	}
+
+	// the heuristics below are best-effort; if they fail, we fall back to generic Kubernetes
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{Limit: nodeSampleSize})
+	if err == nil {
+		for i := range nodes.Items {
+			if platform := platformFromNode(&nodes.Items[i]); platform != PlatformUndefined {
+				return platform, nil
+			}
+		}
+	}
+
+	groups, err := dc.ServerGroups()
+	if err == nil {
+		for _, group := range groups.Groups {
+			if platform, ok := platformAPIGroups[group.Name]; ok {
+				return platform, nil
+			}
+		}
 	}
+	return PlatformKubernetes, nil
+}
 
+func isOpenShift(dc discovery.DiscoveryInterface) (bool, error) {
 	resources, err := dc.ServerResourcesForGroupVersion(openshiftResourceGroup + "/" + openshiftResourceVersion)
 	if errors.IsNotFound(err) {
-		return PlatformKubernetes, nil
+		return false, nil
 	} else if err != nil {
-		return "", err
+		return false, err
 	}
 
 	for _, apiResource := range resources.APIResources {
 		if apiResource.Kind == openshiftKind {
-			return PlatformOpenShift, nil
+			return true, nil
 		}
 	}
-	return PlatformKubernetes, nil
+	return false, nil
+}
+
+// platformFromNode determines the platform from the node's provider ID, labels
+// and kubelet version. It returns PlatformUndefined if none of them match.
+func platformFromNode(node *corev1.Node) Platform {
+	providerID := node.Spec.ProviderID
+	kubeletVersion := node.Status.NodeInfo.KubeletVersion
+	switch {
+	case hasLabel(node, "minikube.k8s.io/name"):
+		return PlatformMinikube
+	case hasLabel(node, "microk8s.io/cluster"):
+		return PlatformMicroK8s
+	case strings.HasPrefix(providerID, "k3s://") || strings.Contains(kubeletVersion, "+k3s"):
+		// k3d runs k3s in containers and prefixes the node names with "k3d-"
+		if strings.HasPrefix(node.Name, "k3d-") {
+			return PlatformK3d
+		}
+		return PlatformK3s
+	case hasLabel(node, "cloud.google.com/gke-nodepool") || strings.Contains(kubeletVersion, "-gke."):
+		// a gce:// provider ID alone doesn't mean GKE, since self-managed clusters on GCE use it too
+		return PlatformGKE
+	case strings.Contains(kubeletVersion, "-eks-") || hasLabelPrefix(node, "eks.amazonaws.com/"):
+		return PlatformEKS
+	case strings.HasPrefix(providerID, "azure://") && hasLabel(node, "kubernetes.azure.com/cluster"):
+		return PlatformAKS
+	default:
+		return PlatformUndefined
+	}
+}
+
+func hasLabel(node *corev1.Node, key string) bool {
+	_, ok := node.Labels[key]
+	return ok
+}
+
+func hasLabelPrefix(node *corev1.Node, prefix string) bool {
+	for key := range node.Labels {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
 }
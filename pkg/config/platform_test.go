@@ -0,0 +1,195 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDetectPlatform(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources []*metav1.APIResourceList
+		nodes     []runtime.Object
+		expected  Platform
+	}{
+		{
+			name:     "generic kubernetes",
+			nodes:    []runtime.Object{newNode("node-1", "", "v1.33.1", nil)},
+			expected: PlatformKubernetes,
+		},
+		{
+			name: "openshift",
+			resources: []*metav1.APIResourceList{{
+				GroupVersion: "operator.openshift.io/v1",
+				APIResources: []metav1.APIResource{{Kind: "OpenShiftAPIServer"}},
+			}},
+			nodes:    []runtime.Object{newNode("node-1", "gce://project/zone/node-1", "v1.33.1", nil)},
+			expected: PlatformOpenShift,
+		},
+		{
+			name: "openshift group without OpenShiftAPIServer",
+			resources: []*metav1.APIResourceList{{
+				GroupVersion: "operator.openshift.io/v1",
+				APIResources: []metav1.APIResource{{Kind: "Something"}},
+			}},
+			expected: PlatformKubernetes,
+		},
+		{
+			name: "node takes precedence over API groups",
+			resources: []*metav1.APIResourceList{{
+				GroupVersion: "vpcresources.k8s.aws/v1beta1",
+			}},
+			nodes:    []runtime.Object{newNode("node-1", "gce://project/zone/node-1", "v1.33.1", map[string]string{"cloud.google.com/gke-nodepool": "default-pool"})},
+			expected: PlatformGKE,
+		},
+		{
+			name: "API group when nodes don't match",
+			resources: []*metav1.APIResourceList{{
+				GroupVersion: "networking.gke.io/v1",
+			}},
+			nodes:    []runtime.Object{newNode("node-1", "", "v1.33.1", nil)},
+			expected: PlatformGKE,
+		},
+		{
+			name: "EKS API group",
+			resources: []*metav1.APIResourceList{{
+				GroupVersion: "vpcresources.k8s.aws/v1beta1",
+			}},
+			expected: PlatformEKS,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clientset := fake.NewClientset(tc.nodes...)
+			clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = tc.resources
+
+			platform, err := detectPlatform(t.Context(), clientset)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, platform)
+		})
+	}
+}
+
+func TestPlatformFromNode(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     *corev1.Node
+		expected Platform
+	}{
+		{
+			name:     "unknown",
+			node:     newNode("node-1", "", "v1.33.1", nil),
+			expected: PlatformUndefined,
+		},
+		{
+			name:     "GKE node pool label",
+			node:     newNode("gke-node", "gce://project/us-central1-a/gke-node", "v1.33.1", map[string]string{"cloud.google.com/gke-nodepool": "default-pool"}),
+			expected: PlatformGKE,
+		},
+		{
+			name:     "self-managed on GCE",
+			node:     newNode("node-1", "gce://project/us-central1-a/node-1", "v1.33.1", nil),
+			expected: PlatformUndefined,
+		},
+		{
+			name:     "GKE kubelet version",
+			node:     newNode("gke-node", "", "v1.33.1-gke.1000", nil),
+			expected: PlatformGKE,
+		},
+		{
+			name:     "EKS kubelet version",
+			node:     newNode("ip-10-0-0-1", "aws:///us-east-1a/i-0123", "v1.33.1-eks-1234abc", nil),
+			expected: PlatformEKS,
+		},
+		{
+			name:     "EKS node group label",
+			node:     newNode("ip-10-0-0-1", "aws:///us-east-1a/i-0123", "v1.33.1", map[string]string{"eks.amazonaws.com/nodegroup": "default"}),
+			expected: PlatformEKS,
+		},
+		{
+			name:     "self-managed on AWS",
+			node:     newNode("ip-10-0-0-1", "aws:///us-east-1a/i-0123", "v1.33.1", nil),
+			expected: PlatformUndefined,
+		},
+		{
+			name:     "AKS",
+			node:     newNode("aks-pool-1", "azure:///subscriptions/x/vm-1", "v1.33.1", map[string]string{"kubernetes.azure.com/cluster": "mc_rg"}),
+			expected: PlatformAKS,
+		},
+		{
+			name:     "k3s",
+			node:     newNode("server-0", "k3s://server-0", "v1.33.1+k3s1", nil),
+			expected: PlatformK3s,
+		},
+		{
+			name:     "k3d",
+			node:     newNode("k3d-test-server-0", "k3s://k3d-test-server-0", "v1.33.1+k3s1", nil),
+			expected: PlatformK3d,
+		},
+		{
+			name:     "microk8s",
+			node:     newNode("ubuntu", "", "v1.33.1", map[string]string{"microk8s.io/cluster": "true"}),
+			expected: PlatformMicroK8s,
+		},
+		{
+			name:     "minikube",
+			node:     newNode("minikube", "", "v1.33.1", map[string]string{"minikube.k8s.io/name": "minikube"}),
+			expected: PlatformMinikube,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, platformFromNode(tc.node))
+		})
+	}
+}
+
+func TestHelmPlatform(t *testing.T) {
+	for platform, expected := range map[Platform]string{
+		PlatformUndefined:  "",
+		PlatformKubernetes: "",
+		PlatformOpenShift:  "openshift",
+		PlatformGKE:        "gke",
+		PlatformEKS:        "",
+		PlatformAKS:        "",
+		PlatformK3s:        "k3s",
+		PlatformK3d:        "k3d",
+		PlatformMicroK8s:   "microk8s",
+		PlatformMinikube:   "minikube",
+	} {
+		assert.Equal(t, expected, platform.HelmPlatform(), "platform %q", platform)
+	}
+}
+
+func newNode(name, providerID, kubeletVersion string, labels map[string]string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec:       corev1.NodeSpec{ProviderID: providerID},
+		Status: corev1.NodeStatus{
+			NodeInfo: corev1.NodeSystemInfo{KubeletVersion: kubeletVersion},
+		},
+	}
+}
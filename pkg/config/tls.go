@@ -18,12 +18,15 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"sync"
 
 	"github.com/go-logr/logr"
 	configv1 "github.com/openshift/api/config/v1"
 	openshifttls "github.com/openshift/controller-runtime-common/pkg/tls"
 	openshiftcrypto "github.com/openshift/library-go/pkg/crypto"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
 // TLSConfig represents the TLS configuration to be applied globally.
@@ -35,6 +38,9 @@ type TLSConfig struct {
 	// Zero means no minimum version is configured.
 	MinVersion uint16
 
+	// Groups is the list of ECDH curves (key exchange groups) to use.
+	Groups []configv1.TLSGroup
+
 	// OpenShift holds OpenShift-specific TLS configuration.
 	// It is nil when not running on OpenShift.
 	OpenShift *OpenShiftTLS
@@ -51,6 +57,73 @@ type OpenShiftTLS struct {
 	TLSConfigFunc func(*tls.Config)
 }
 
+// TLSConfigSource holds the TLSConfig currently in effect. The TLSConfig can be replaced while the operator is
+// running (e.g. when the TLS profile of the OpenShift APIServer changes), in which case all subscribers are notified.
+// A nil TLSConfigSource holds no TLSConfig.
+type TLSConfigSource struct {
+	mu          sync.RWMutex
+	tlsConfig   *TLSConfig
+	subscribers []chan event.GenericEvent
+}
+
+// NewTLSConfigSource returns a TLSConfigSource that holds the given TLSConfig.
+func NewTLSConfigSource(tlsConfig *TLSConfig) *TLSConfigSource {
+	return &TLSConfigSource{tlsConfig: tlsConfig}
+}
+
+// Get returns the TLSConfig currently in effect. It returns nil if the source is nil.
+func (s *TLSConfigSource) Get() *TLSConfig {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tlsConfig
+}
+
+// Set replaces the TLSConfig and notifies all subscribers.
+func (s *TLSConfigSource) Set(tlsConfig *TLSConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tlsConfig = tlsConfig
+	for _, ch := range s.subscribers {
+		// the channel is buffered, so a notification that is still pending already covers this change
+		select {
+		case ch <- event.GenericEvent{Object: &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "tls-config"}}}:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel that receives an event whenever the TLSConfig is replaced. It's meant to be used as a
+// controller source, so that the controller can enqueue all objects that depend on the TLSConfig.
+func (s *TLSConfigSource) Subscribe() <-chan event.GenericEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch := make(chan event.GenericEvent, 1)
+	s.subscribers = append(s.subscribers, ch)
+	return ch
+}
+
+// ApplyToServer configures the server *tls.Config so that each TLS handshake uses the TLS profile that is currently
+// in effect instead of the one in effect when the server was started. It can be appended to the metrics server
+// TLS options.
+func (s *TLSConfigSource) ApplyToServer(c *tls.Config) {
+	c.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		tlsConfig := s.Get()
+		if tlsConfig == nil || tlsConfig.OpenShift == nil || tlsConfig.OpenShift.TLSConfigFunc == nil {
+			// nil means the handshake uses the server's config
+			return nil, nil
+		}
+		// the clone is made here and not when the option is applied, because the server sets the certificate after
+		// applying its TLS options
+		cfg := c.Clone()
+		cfg.GetConfigForClient = nil
+		tlsConfig.OpenShift.TLSConfigFunc(cfg)
+		return cfg, nil
+	}
+}
+
 // These functions are ripped directly from:
 // https://github.com/openshift/controller-runtime-common/blob/64ee174f5e2ebc630fbb554dd114d7a7a878693f/pkg/tls/tls.go#L134-L168
 // until https://github.com/openshift/controller-runtime-common/issues/19 is resolved.
@@ -118,23 +191,35 @@ func NewTLSConfigForOpenShift(profileSpec configv1.TLSProfileSpec, adherencePoli
 	}
 
 	if openshiftcrypto.ShouldHonorClusterTLSProfile(adherencePolicy) {
-		tlsConfigFunc, _ := openshifttls.NewTLSConfigFromProfile(profileSpec)
-		tlsConfig.OpenShift.TLSConfigFunc = tlsConfigFunc
-
-		goTLSConfig := &tls.Config{MinVersion: tls.VersionTLS12}
-		tlsConfigFunc(goTLSConfig)
-		tlsConfig.MinVersion = goTLSConfig.MinVersion
-		// Resolve cipher suites directly from the profile spec
-		// instead of reading them back from the tls.Config set by tlsConfigFunc,
-		// because the controller-runtime-common lib filters out the CipherSuites when
-		// MinTLSVersion is 1.3. We still need the cipher IDs to configure non-Go components like Envoy.
-		cipherSuites, unsupportedCiphers := cipherCodes(profileSpec.Ciphers)
-		tlsConfig.CipherSuites = cipherSuites
-
-		if len(unsupportedCiphers) > 0 {
-			log.Info("Some ciphers from TLS profile are unsupported and will be ignored", "unsupportedCiphers", unsupportedCiphers)
-		}
+		profileConfig := NewTLSConfigFromProfile(profileSpec, log)
+		tlsConfig.CipherSuites = profileConfig.CipherSuites
+		tlsConfig.MinVersion = profileConfig.MinVersion
+		tlsConfig.Groups = profileConfig.Groups
+		tlsConfig.OpenShift.TLSConfigFunc, _ = openshifttls.NewTLSConfigFromProfile(profileSpec)
 	}
 
 	return tlsConfig
 }
+
+// NewTLSConfigFromProfile builds a TLSConfig from the given profile spec,
+// regardless of the platform.
+func NewTLSConfigFromProfile(profileSpec configv1.TLSProfileSpec, log logr.Logger) *TLSConfig {
+	tlsConfigFunc, _ := openshifttls.NewTLSConfigFromProfile(profileSpec)
+	goTLSConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	tlsConfigFunc(goTLSConfig)
+
+	// Resolve cipher suites directly from the profile spec
+	// instead of reading them back from the tls.Config set by tlsConfigFunc,
+	// because the controller-runtime-common lib filters out the CipherSuites when
+	// MinTLSVersion is 1.3. We still need the cipher IDs to configure non-Go components like Envoy.
+	cipherSuites, unsupportedCiphers := cipherCodes(profileSpec.Ciphers)
+	if len(unsupportedCiphers) > 0 {
+		log.Info("Some ciphers from TLS profile are unsupported and will be ignored", "unsupportedCiphers", unsupportedCiphers)
+	}
+
+	return &TLSConfig{
+		CipherSuites: cipherSuites,
+		MinVersion:   goTLSConfig.MinVersion,
+		Groups:       profileSpec.Groups,
+	}
+}
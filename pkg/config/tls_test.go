@@ -26,6 +26,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
 
@@ -149,3 +150,69 @@ func TestFetchTLSConfigForOpenShift(t *testing.T) {
 		})
 	}
 }
+
+func TestNewTLSConfigFromProfile(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+
+	tlsConfig := NewTLSConfigFromProfile(*configv1.TLSProfiles[configv1.TLSProfileModernType], log)
+	assert.Equal(t, uint16(tls.VersionTLS13), tlsConfig.MinVersion)
+	assert.Equal(t, modernTLSCiphers, tlsConfig.CipherSuites)
+	assert.Nil(t, tlsConfig.OpenShift)
+
+	tlsConfig = NewTLSConfigFromProfile(configv1.TLSProfileSpec{
+		Ciphers:       []string{"ECDHE-RSA-AES128-GCM-SHA256", "unsupported"},
+		MinTLSVersion: configv1.VersionTLS12,
+		Groups:        []configv1.TLSGroup{configv1.TLSGroupX25519},
+	}, log)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, tlsConfig.CipherSuites)
+	assert.Equal(t, []configv1.TLSGroup{configv1.TLSGroupX25519}, tlsConfig.Groups)
+}
+
+func TestTLSConfigSource(t *testing.T) {
+	var nilSource *TLSConfigSource
+	assert.Nil(t, nilSource.Get())
+
+	initial := &TLSConfig{MinVersion: tls.VersionTLS12}
+	source := NewTLSConfigSource(initial)
+	assert.Same(t, initial, source.Get())
+
+	ch1 := source.Subscribe()
+	ch2 := source.Subscribe()
+
+	updated := &TLSConfig{MinVersion: tls.VersionTLS13}
+	source.Set(updated)
+	// a second change while the first notification is still pending must not block
+	source.Set(updated)
+	assert.Same(t, updated, source.Get())
+
+	for _, ch := range []<-chan event.GenericEvent{ch1, ch2} {
+		require.Len(t, ch, 1)
+		evt := <-ch
+		assert.NotNil(t, evt.Object)
+	}
+}
+
+func TestTLSConfigSourceApplyToServer(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	source := NewTLSConfigSource(&TLSConfig{})
+
+	serverConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	source.ApplyToServer(serverConfig)
+	require.NotNil(t, serverConfig.GetConfigForClient)
+
+	// without a profile to honor, the handshake uses the server's config
+	cfg, err := serverConfig.GetConfigForClient(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	assert.Nil(t, cfg)
+
+	modern := *configv1.TLSProfiles[configv1.TLSProfileModernType]
+	source.Set(NewTLSConfigForOpenShift(modern, configv1.TLSAdherencePolicyStrictAllComponents, log))
+
+	cfg, err = serverConfig.GetConfigForClient(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.Equal(t, uint16(tls.VersionTLS13), cfg.MinVersion)
+	assert.Nil(t, cfg.GetConfigForClient)
+	assert.Equal(t, uint16(tls.VersionTLS12), serverConfig.MinVersion, "the server's config must not be modified")
+}
@@ -92,6 +92,39 @@ const (
 	// IstioSidecarInjectLabel is the label that is used to configure injection for specific workloads
 	IstioSidecarInjectLabel = "sidecar.istio.io/inject"
 
+	// DataplaneModeLabel is the label that adds a namespace or workload to the ambient mesh
+	DataplaneModeLabel = "istio.io/dataplane-mode"
+
+	// DataplaneModeAmbient is the DataplaneModeLabel value that enables ambient mode
+	DataplaneModeAmbient = "ambient"
+
+	// UseWaypointLabel is the label that routes the traffic of a namespace, service or pod through a waypoint
+	UseWaypointLabel = "istio.io/use-waypoint"
+
+	// WaypointForLabel is the label on a waypoint Gateway that defines the type of traffic it handles
+	WaypointForLabel = "istio.io/waypoint-for"
+
+	// AmbientEnrollmentAnnotation is set on namespaces enrolled in ambient mode by an AmbientEnrollment, and holds its name
+	AmbientEnrollmentAnnotation = MetadataNamespace + "/ambient-enrollment"
+
+	// AmbientWaypointAnnotation is set on namespaces enrolled in ambient mode by an AmbientEnrollment, and holds the name
+	// of the waypoint deployed by the operator in the namespace
+	AmbientWaypointAnnotation = MetadataNamespace + "/ambient-waypoint"
+
+	// MigrationAnnotation is set on namespaces migrated to ambient mode by a Migration, and holds its name
+	MigrationAnnotation = MetadataNamespace + "/migration"
+
+	// MigrationPreviousLabelsAnnotation is set on namespaces migrated to ambient mode by a Migration, and holds the
+	// sidecar injection labels that were removed from the namespace, so that they can be restored on rollback
+	MigrationPreviousLabelsAnnotation = MetadataNamespace + "/migration-previous-labels"
+
+	// WaypointAnnotation is set on namespaces and pods whose istio.io/use-waypoint label was set by a Waypoint, and
+	// holds its name
+	WaypointAnnotation = MetadataNamespace + "/waypoint"
+
+	// AmbientRedirectionAnnotation is set by istio-cni on pods whose traffic is redirected to ztunnel
+	AmbientRedirectionAnnotation = "ambient.istio.io/redirection"
+
 	// IstiodChartName is the name of the chart that installs istiod
 	IstiodChartName = "istiod"
 
@@ -19,6 +19,8 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"strings"
+	"text/template"
 
 	"github.com/go-logr/logr"
 	"github.com/istio-ecosystem/sail-operator/pkg/constants"
@@ -40,10 +42,16 @@ import (
 // reconciliation loop: install/upgrade and uninstall.
 type ChartReconciler interface {
 	UpgradeOrInstallChart(ctx context.Context, resourceFS fs.FS, chartPath string, values Values,
-		namespace, releaseName string, ownerReference *metav1.OwnerReference) (release.Releaser, error)
+		namespace, releaseName string, ownerReference *metav1.OwnerReference, opts ...InstallOption) (release.Releaser, error)
 	UninstallChart(ctx context.Context, releaseName, namespace string) (*release.UninstallReleaseResponse, error)
 }
 
+// DriftDetector is implemented by chart managers that can detect changes made to the resources of a release
+// outside of Helm.
+type DriftDetector interface {
+	DetectDrift(ctx context.Context, namespace, releaseName string) ([]string, error)
+}
+
 type ChartManager struct {
 	restClientGetter genericclioptions.RESTClientGetter
 	driver           string
@@ -62,6 +70,30 @@ func WithManagedByValue(v string) ChartManagerOption {
 	}
 }
 
+// InstallOption is a functional option for configuring a single install or upgrade of a chart.
+type InstallOption func(*installOptions)
+
+type installOptions struct {
+	clusterRoleNameSuffix  string
+	onDeleteUpdateStrategy bool
+}
+
+// WithClusterRoleNameSuffix appends the suffix to the names of the ClusterRoles and ClusterRoleBindings rendered
+// by the chart, so that several releases of the same chart can be installed in different namespaces.
+func WithClusterRoleNameSuffix(suffix string) InstallOption {
+	return func(o *installOptions) {
+		o.clusterRoleNameSuffix = suffix
+	}
+}
+
+// WithOnDeleteUpdateStrategy sets the update strategy of the DaemonSets rendered by the chart to OnDelete, so that
+// the caller controls when the pods are replaced.
+func WithOnDeleteUpdateStrategy() InstallOption {
+	return func(o *installOptions) {
+		o.onDeleteUpdateStrategy = true
+	}
+}
+
 // NewChartManager creates a new Helm chart manager using cfg as the configuration
 // that Helm will use to connect to the cluster when installing or uninstalling
 // charts, and using the specified driver to store information about releases
@@ -90,23 +122,28 @@ func (h *ChartManager) newActionConfig(ctx context.Context, namespace string) (*
 // It loads the chart from an fs.FS (e.g., embed.FS or os.DirFS).
 func (h *ChartManager) UpgradeOrInstallChart(
 	ctx context.Context, resourceFS fs.FS, chartPath string, values Values,
-	namespace, releaseName string, ownerReference *metav1.OwnerReference,
+	namespace, releaseName string, ownerReference *metav1.OwnerReference, opts ...InstallOption,
 ) (release.Releaser, error) {
 	loadedChart, err := LoadChart(resourceFS, chartPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load chart from fs: %w", err)
 	}
 
-	return h.upgradeOrInstallChart(ctx, loadedChart, values, namespace, releaseName, ownerReference)
+	return h.upgradeOrInstallChart(ctx, loadedChart, values, namespace, releaseName, ownerReference, opts...)
 }
 
 // upgradeOrInstallChart is the internal implementation that works with an already-loaded chart
 func (h *ChartManager) upgradeOrInstallChart(
 	ctx context.Context, chart *chartv2.Chart, values Values,
-	namespace, releaseName string, ownerReference *metav1.OwnerReference,
+	namespace, releaseName string, ownerReference *metav1.OwnerReference, opts ...InstallOption,
 ) (release.Releaser, error) {
 	log := logf.FromContext(ctx)
 
+	options := installOptions{}
+	for _, o := range opts {
+		o(&options)
+	}
+
 	cfg, err := h.newActionConfig(ctx, namespace)
 	if err != nil {
 		return nil, err
@@ -173,7 +210,7 @@ func (h *ChartManager) upgradeOrInstallChart(
 		log.V(2).Info("Performing helm upgrade", "chartName", chart.Name())
 
 		updateAction := action.NewUpgrade(cfg)
-		updateAction.PostRenderer = NewHelmPostRenderer(ownerReference, "", true, h.managedByValue)
+		updateAction.PostRenderer = NewHelmPostRenderer(ownerReference, "", true, h.managedByValue, options.clusterRoleNameSuffix, options.onDeleteUpdateStrategy)
 		updateAction.MaxHistory = 1
 		updateAction.SkipCRDs = true
 		updateAction.DisableOpenAPIValidation = true
@@ -188,7 +225,7 @@ func (h *ChartManager) upgradeOrInstallChart(
 		log.V(2).Info("Performing helm install", "chartName", chart.Name())
 
 		installAction := action.NewInstall(cfg)
-		installAction.PostRenderer = NewHelmPostRenderer(ownerReference, "", false, h.managedByValue)
+		installAction.PostRenderer = NewHelmPostRenderer(ownerReference, "", false, h.managedByValue, options.clusterRoleNameSuffix, options.onDeleteUpdateStrategy)
 		installAction.Namespace = namespace
 		installAction.ReleaseName = releaseName
 		installAction.SkipCRDs = true
@@ -233,6 +270,67 @@ func (h *ChartManager) UninstallChart(ctx context.Context, releaseName, namespac
 	return resp, nil
 }
 
+// renderErrorMarkers are the fragments that Helm includes in the messages of errors that occur while
+// rendering chart templates. Helm doesn't return typed errors for these, so the messages are checked.
+var renderErrorMarkers = []string{
+	"parse error at (",
+	"parse error in (",
+	"execution error at (",
+	"execution error in (",
+	"YAML parse error on ",
+	"values don't meet the specifications of the schema",
+}
+
+// IsRenderError returns true if the error was returned by Helm while rendering the chart templates,
+// e.g. because of invalid values or a call to the fail function in a template.
+func IsRenderError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var execErr template.ExecError
+	if errors.As(err, &execErr) {
+		return true
+	}
+	msg := err.Error()
+	for _, marker := range renderErrorMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// The following functions recognize the errors that Helm returns when the API server rejects a resource of
+// the chart. Helm doesn't always preserve the type of these errors, so their messages are checked as well.
+
+// IsWebhookError returns true if an admission webhook that must approve a resource of the chart could not be
+// called.
+func IsWebhookError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "failed calling webhook")
+}
+
+// IsQuotaError returns true if a resource of the chart could not be created because a ResourceQuota was exceeded.
+func IsQuotaError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "exceeded quota")
+}
+
+// IsConflictError returns true if a resource of the chart already exists and is managed by something else,
+// e.g. by another Helm release.
+func IsConflictError(err error) bool {
+	return apierrors.IsAlreadyExists(err) ||
+		(err != nil && strings.Contains(err.Error(), "cannot be imported into the current release"))
+}
+
+// IsForbiddenError returns true if the operator isn't allowed to manage a resource of the chart. Requests
+// rejected by the gc admission plugin because its RESTMapper doesn't know the owner's type yet aren't
+// included, as this resolves itself.
+func IsForbiddenError(err error) bool {
+	if err == nil || strings.Contains(err.Error(), "RESTMapping") {
+		return false
+	}
+	return apierrors.IsForbidden(err) || strings.Contains(err.Error(), " is forbidden: User ")
+}
+
 func getRelease(cfg *action.Configuration, releaseName string) (release.Releaser, error) {
 	getAction := action.NewGet(cfg)
 	rel, err := getAction.Run(releaseName)
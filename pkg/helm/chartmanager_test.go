@@ -16,8 +16,11 @@ package helm
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"testing"
+	"testing/fstest"
 
 	"github.com/istio-ecosystem/sail-operator/pkg/test"
 	. "github.com/istio-ecosystem/sail-operator/pkg/test/util/ginkgo"
@@ -26,7 +29,9 @@ import (
 	releasecommon "helm.sh/helm/v4/pkg/release/common"
 	releasev1 "helm.sh/helm/v4/pkg/release/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -234,3 +239,89 @@ func setReleaseStatus(g *WithT, helm *ChartManager, ns, releaseName string, stat
 	concreteRel.SetStatus(status, "simulated status")
 	g.Expect(cfg.Releases.Update(rel)).To(Succeed())
 }
+
+func TestIsRenderError(t *testing.T) {
+	g := NewWithT(t)
+	chartFS := fstest.MapFS{
+		"chart/Chart.yaml":               {Data: []byte("apiVersion: v2\nname: test-chart\nversion: 0.1.0\n")},
+		"chart/templates/configmap.yaml": {Data: []byte(`{{ if .Values.fail }}{{ fail "value not allowed" }}{{ end }}`)},
+	}
+
+	_, err := RenderChart(chartFS, "chart", Values{"fail": true}, "test-ns", "my-release")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(IsRenderError(err)).To(BeTrue())
+	g.Expect(IsRenderError(fmt.Errorf("failed to install: %w", err))).To(BeTrue())
+
+	g.Expect(IsRenderError(nil)).To(BeFalse())
+	g.Expect(IsRenderError(errors.New("connection refused"))).To(BeFalse())
+}
+
+func TestAPIServerErrors(t *testing.T) {
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+
+	tests := []struct {
+		name              string
+		err               error
+		expectedWebhook   bool
+		expectedQuota     bool
+		expectedConflict  bool
+		expectedForbidden bool
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "unrecognized error",
+			err:  errors.New("connection refused"),
+		},
+		{
+			name:              "forbidden",
+			err:               apierrors.NewForbidden(deployments, "istiod", errors.New("no RBAC policy matched")),
+			expectedForbidden: true,
+		},
+		{
+			name: "forbidden message wrapped by Helm",
+			err: errors.New(`failed to create resource: deployments.apps "istiod" is forbidden: ` +
+				`User "system:serviceaccount:sail-operator:sail-operator" cannot create resource "deployments"`),
+			expectedForbidden: true,
+		},
+		{
+			name: "RESTMapping error from the gc admission plugin",
+			err:  apierrors.NewForbidden(deployments, "istiod", errors.New("cannot set blockOwnerDeletion in this case because cannot find RESTMapping")),
+		},
+		{
+			name:             "already exists",
+			err:              apierrors.NewAlreadyExists(deployments, "istiod"),
+			expectedConflict: true,
+		},
+		{
+			name: "resource owned by another release",
+			err: errors.New(`rendered manifests contain a resource that already exists. Unable to continue with install: ` +
+				`ServiceAccount "istiod" in namespace "istio-system" exists and cannot be imported into the current release`),
+			expectedConflict: true,
+		},
+		{
+			name: "quota exceeded",
+			err: apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "istiod-abc",
+				errors.New("exceeded quota: compute, requested: cpu=500m, used: cpu=2, limited: cpu=2")),
+			expectedQuota:     true,
+			expectedForbidden: true,
+		},
+		{
+			name: "webhook unreachable",
+			err: errors.New(`Internal error occurred: failed calling webhook "validation.istio.io": ` +
+				`Post "https://istiod.istio-system.svc:443/validate": dial tcp: connection refused`),
+			expectedWebhook: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(IsWebhookError(tt.err)).To(Equal(tt.expectedWebhook))
+			g.Expect(IsQuotaError(tt.err)).To(Equal(tt.expectedQuota))
+			g.Expect(IsConflictError(tt.err)).To(Equal(tt.expectedConflict))
+			g.Expect(IsForbiddenError(tt.err)).To(Equal(tt.expectedForbidden))
+		})
+	}
+}
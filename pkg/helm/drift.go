@@ -0,0 +1,264 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	releasev1 "helm.sh/helm/v4/pkg/release/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// DetectDrift compares the resources in the manifest of the specified release with the resources in the
+// cluster and returns the resources that were modified or deleted outside of Helm, in the format
+// "<Kind> <namespace>/<name>". Only the fields set in the manifest are compared, so fields defaulted by the
+// API server or set by other controllers aren't reported. Resource quantities are compared by value and the
+// caBundle and failurePolicy of webhooks, which istiod patches, are ignored. If the release doesn't exist, nil
+// is returned.
+func (h *ChartManager) DetectDrift(ctx context.Context, namespace, releaseName string) ([]string, error) {
+	cfg, err := h.newActionConfig(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	rel, err := getRelease(cfg, releaseName)
+	if err != nil || rel == nil {
+		return nil, err
+	}
+	relV1, ok := rel.(*releasev1.Release)
+	if !ok {
+		return nil, fmt.Errorf("unexpected release type %T for helm release %s", rel, releaseName)
+	}
+
+	resources, err := cfg.KubeClient.Build(strings.NewReader(relV1.Manifest), false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest of helm release %s: %w", releaseName, err)
+	}
+
+	var drifted []string
+	for _, info := range resources {
+		desired, err := toUnstructured(info.Object)
+		if err != nil {
+			return nil, err
+		}
+
+		id := resourceID(info)
+		obj, err := resource.NewHelper(info.Client, info.Mapping).Get(info.Namespace, info.Name)
+		if apierrors.IsNotFound(err) {
+			drifted = append(drifted, id+" (deleted)")
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to get %s: %w", id, err)
+		}
+
+		live, err := toUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+		if !isSubset(desiredState(desired), live) {
+			drifted = append(drifted, id)
+		}
+	}
+	return drifted, nil
+}
+
+func resourceID(info *resource.Info) string {
+	kind := info.Mapping.GroupVersionKind.Kind
+	if info.Namespace == "" {
+		return kind + " " + info.Name
+	}
+	return kind + " " + info.Namespace + "/" + info.Name
+}
+
+func toUnstructured(obj runtime.Object) (map[string]any, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u.Object, nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
+
+// desiredState returns the fields of the object that are compared with the live object: everything except
+// the status and the metadata, apart from the labels and annotations, and the webhook fields managed by istiod.
+func desiredState(obj map[string]any) map[string]any {
+	desired := make(map[string]any, len(obj))
+	for k, v := range obj {
+		switch k {
+		case "status":
+			continue
+		case "metadata":
+			metadata, _ := v.(map[string]any)
+			desiredMetadata := map[string]any{}
+			for _, field := range []string{"labels", "annotations"} {
+				if value, found := metadata[field]; found {
+					desiredMetadata[field] = value
+				}
+			}
+			desired[k] = desiredMetadata
+		case "webhooks":
+			if isWebhookConfiguration(obj["kind"]) {
+				desired[k] = desiredWebhooks(v)
+			} else {
+				desired[k] = v
+			}
+		default:
+			desired[k] = v
+		}
+	}
+	return desired
+}
+
+func isWebhookConfiguration(kind any) bool {
+	return kind == "MutatingWebhookConfiguration" || kind == "ValidatingWebhookConfiguration"
+}
+
+// desiredWebhooks returns copies of the webhooks without the fields that istiod patches at runtime: the
+// caBundle of the client config and the failurePolicy, which istiod changes from Ignore to Fail once the
+// webhook is ready.
+func desiredWebhooks(v any) any {
+	webhooks, ok := v.([]any)
+	if !ok {
+		return v
+	}
+	desired := make([]any, 0, len(webhooks))
+	for _, w := range webhooks {
+		webhook, ok := w.(map[string]any)
+		if !ok {
+			desired = append(desired, w)
+			continue
+		}
+		desiredWebhook := make(map[string]any, len(webhook))
+		for k, v := range webhook {
+			switch k {
+			case "failurePolicy":
+				continue
+			case "clientConfig":
+				if clientConfig, ok := v.(map[string]any); ok {
+					desiredClientConfig := make(map[string]any, len(clientConfig))
+					for ck, cv := range clientConfig {
+						if ck != "caBundle" {
+							desiredClientConfig[ck] = cv
+						}
+					}
+					v = desiredClientConfig
+				}
+			}
+			desiredWebhook[k] = v
+		}
+		desired = append(desired, desiredWebhook)
+	}
+	return desired
+}
+
+// isSubset returns true if all the fields in expected are present in actual with the same values. Lists must
+// have the same length and each of their elements must be a subset of the corresponding element in actual.
+// Resource quantities are compared by value, since the API server normalizes them, e.g. 2048Mi to 2Gi.
+func isSubset(expected, actual any) bool {
+	return isSubsetOf(expected, actual, false)
+}
+
+// isSubsetOf is isSubset, where quantities indicates that the values of the expected map are resource quantities.
+func isSubsetOf(expected, actual any, quantities bool) bool {
+	switch e := expected.(type) {
+	case map[string]any:
+		a, ok := actual.(map[string]any)
+		if !ok {
+			return len(e) == 0 && actual == nil
+		}
+		for k, ev := range e {
+			if ev == nil {
+				continue
+			}
+			av, found := a[k]
+			if !found {
+				return false
+			}
+			if quantities {
+				if !equalQuantities(ev, av) {
+					return false
+				}
+			} else if !isSubsetOf(ev, av, k == "limits" || k == "requests") {
+				return false
+			}
+		}
+		return true
+	case []any:
+		a, ok := actual.([]any)
+		if !ok || len(a) != len(e) {
+			return len(e) == 0 && actual == nil
+		}
+		for i := range e {
+			if !isSubsetOf(e[i], a[i], false) {
+				return false
+			}
+		}
+		return true
+	default:
+		if en, ok := toFloat(expected); ok {
+			an, ok := toFloat(actual)
+			return ok && en == an
+		}
+		return reflect.DeepEqual(expected, actual)
+	}
+}
+
+// equalQuantities returns true if the values are equal resource quantities. Values that aren't quantities are
+// compared like any other value.
+func equalQuantities(expected, actual any) bool {
+	eq, err := toQuantity(expected)
+	if err != nil {
+		return isSubsetOf(expected, actual, false)
+	}
+	aq, err := toQuantity(actual)
+	if err != nil {
+		return false
+	}
+	return eq.Cmp(aq) == 0
+}
+
+// toQuantity parses a quantity, which can be a string or, when it's parsed from YAML, a number.
+func toQuantity(v any) (apiresource.Quantity, error) {
+	switch q := v.(type) {
+	case string:
+		return apiresource.ParseQuantity(q)
+	case int64, int, int32, float64:
+		return apiresource.ParseQuantity(fmt.Sprint(q))
+	default:
+		return apiresource.Quantity{}, fmt.Errorf("unexpected quantity type %T", v)
+	}
+}
+
+// toFloat converts the numeric types used in unstructured objects to float64, so that values parsed from
+// YAML and JSON can be compared.
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestIsSubset(t *testing.T) {
+	deployment := func(replicas any, image string) map[string]any {
+		return map[string]any{
+			"metadata": map[string]any{
+				"labels": map[string]any{"app": "istiod"},
+			},
+			"spec": map[string]any{
+				"replicas": replicas,
+				"template": map[string]any{
+					"spec": map[string]any{
+						"containers": []any{
+							map[string]any{"name": "discovery", "image": image},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		expected any
+		actual   any
+		want     bool
+	}{
+		{
+			name:     "equal",
+			expected: deployment(int64(1), "pilot:1.0"),
+			actual:   deployment(int64(1), "pilot:1.0"),
+			want:     true,
+		},
+		{
+			name:     "numbers of different types",
+			expected: deployment(int64(1), "pilot:1.0"),
+			actual:   deployment(float64(1), "pilot:1.0"),
+			want:     true,
+		},
+		{
+			name:     "additional fields in actual",
+			expected: map[string]any{"spec": map[string]any{"replicas": int64(1)}},
+			actual:   map[string]any{"spec": map[string]any{"replicas": int64(1), "revisionHistoryLimit": int64(10)}},
+			want:     true,
+		},
+		{
+			name:     "modified value",
+			expected: deployment(int64(1), "pilot:1.0"),
+			actual:   deployment(int64(3), "pilot:1.0"),
+			want:     false,
+		},
+		{
+			name:     "modified list element",
+			expected: deployment(int64(1), "pilot:1.0"),
+			actual:   deployment(int64(1), "pilot:debug"),
+			want:     false,
+		},
+		{
+			name:     "missing field",
+			expected: map[string]any{"spec": map[string]any{"replicas": int64(1)}},
+			actual:   map[string]any{"spec": map[string]any{}},
+			want:     false,
+		},
+		{
+			name:     "list with additional element",
+			expected: []any{"a"},
+			actual:   []any{"a", "b"},
+			want:     false,
+		},
+		{
+			name:     "null in expected",
+			expected: map[string]any{"spec": map[string]any{"selector": nil}},
+			actual:   map[string]any{"spec": map[string]any{}},
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(isSubset(tt.expected, tt.actual)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestDesiredState(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]any{
+			"name":            "istio",
+			"namespace":       "istio-system",
+			"labels":          map[string]any{"app": "istiod"},
+			"ownerReferences": []any{map[string]any{"name": "default"}},
+		},
+		"data":   map[string]any{"mesh": "{}"},
+		"status": map[string]any{},
+	}
+
+	g.Expect(desiredState(obj)).To(Equal(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]any{
+			"labels": map[string]any{"app": "istiod"},
+		},
+		"data": map[string]any{"mesh": "{}"},
+	}))
+}
+
+func TestIsSubsetNormalizedQuantities(t *testing.T) {
+	deployment := func(memory any, cpu any) map[string]any {
+		return map[string]any{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"spec": map[string]any{
+				"template": map[string]any{
+					"spec": map[string]any{
+						"containers": []any{
+							map[string]any{
+								"name": "discovery",
+								"resources": map[string]any{
+									"requests": map[string]any{"memory": memory, "cpu": cpu},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	g := NewWithT(t)
+	g.Expect(isSubset(desiredState(deployment("2048Mi", "500m")), deployment("2Gi", "500m"))).To(BeTrue())
+	g.Expect(isSubset(desiredState(deployment("2048Mi", int64(1))), deployment("2Gi", "1"))).To(BeTrue())
+	g.Expect(isSubset(desiredState(deployment("2048Mi", "500m")), deployment("4Gi", "500m"))).To(BeFalse())
+}
+
+func TestIsSubsetWebhookFieldsManagedByIstiod(t *testing.T) {
+	webhookConfiguration := func(kind, caBundle, failurePolicy, path string) map[string]any {
+		return map[string]any{
+			"apiVersion": "admissionregistration.k8s.io/v1",
+			"kind":       kind,
+			"webhooks": []any{
+				map[string]any{
+					"name":          "validation.istio.io",
+					"failurePolicy": failurePolicy,
+					"clientConfig": map[string]any{
+						"caBundle": caBundle,
+						"service":  map[string]any{"name": "istiod", "path": path},
+					},
+				},
+			},
+		}
+	}
+
+	for _, kind := range []string{"ValidatingWebhookConfiguration", "MutatingWebhookConfiguration"} {
+		t.Run(kind, func(t *testing.T) {
+			g := NewWithT(t)
+			rendered := webhookConfiguration(kind, "", "Ignore", "/validate")
+
+			g.Expect(isSubset(desiredState(rendered), webhookConfiguration(kind, "Y2EtYnVuZGxl", "Fail", "/validate"))).To(BeTrue())
+			g.Expect(isSubset(desiredState(rendered), webhookConfiguration(kind, "Y2EtYnVuZGxl", "Fail", "/other"))).To(BeFalse())
+			// the rendered object is left intact
+			g.Expect(rendered).To(Equal(webhookConfiguration(kind, "", "Ignore", "/validate")))
+		})
+	}
+}
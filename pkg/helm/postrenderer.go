@@ -24,6 +24,8 @@ import (
 	"gopkg.in/yaml.v3"
 	"helm.sh/helm/v4/pkg/postrenderer"
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -39,20 +41,29 @@ const (
 // - adds the specified OwnerReference
 // It also removes the failurePolicy field from ValidatingWebhookConfigurations on updates, so
 // the in-cluster setting stays as-is, to prevent clashing with the istiod validation controller.
-func NewHelmPostRenderer(ownerReference *metav1.OwnerReference, ownerNamespace string, isUpdate bool, managedByValue string) postrenderer.PostRenderer {
+// If clusterRoleNameSuffix isn't empty, it's appended to the names of ClusterRoles and ClusterRoleBindings.
+// If onDeleteUpdateStrategy is true, the update strategy of DaemonSets is set to OnDelete.
+func NewHelmPostRenderer(
+	ownerReference *metav1.OwnerReference, ownerNamespace string, isUpdate bool, managedByValue, clusterRoleNameSuffix string,
+	onDeleteUpdateStrategy bool,
+) postrenderer.PostRenderer {
 	return HelmPostRenderer{
-		ownerReference: ownerReference,
-		ownerNamespace: ownerNamespace,
-		isUpdate:       isUpdate,
-		managedByValue: managedByValue,
+		ownerReference:         ownerReference,
+		ownerNamespace:         ownerNamespace,
+		isUpdate:               isUpdate,
+		managedByValue:         managedByValue,
+		clusterRoleNameSuffix:  clusterRoleNameSuffix,
+		onDeleteUpdateStrategy: onDeleteUpdateStrategy,
 	}
 }
 
 type HelmPostRenderer struct {
-	ownerReference *metav1.OwnerReference
-	ownerNamespace string
-	isUpdate       bool
-	managedByValue string
+	ownerReference         *metav1.OwnerReference
+	ownerNamespace         string
+	isUpdate               bool
+	managedByValue         string
+	clusterRoleNameSuffix  string
+	onDeleteUpdateStrategy bool
 }
 
 var _ postrenderer.PostRenderer = HelmPostRenderer{}
@@ -86,6 +97,20 @@ func (pr HelmPostRenderer) Run(renderedManifests *bytes.Buffer) (modifiedManifes
 			return nil, err
 		}
 
+		if pr.clusterRoleNameSuffix != "" {
+			manifest, err = pr.addClusterRoleNameSuffix(manifest)
+			if err != nil {
+				return nil, fmt.Errorf("error renaming cluster role: %v", err)
+			}
+		}
+
+		if pr.onDeleteUpdateStrategy {
+			manifest, err = pr.setOnDeleteUpdateStrategy(manifest)
+			if err != nil {
+				return nil, fmt.Errorf("error setting DaemonSet update strategy: %v", err)
+			}
+		}
+
 		// Strip ValidatingWebhookConfiguration webhooks[].failurePolicy field if we're upgrading,
 		// to avoid overwriting the value set in-cluster by the istiod validation controller. On
 		// initial install we still want to set the field per the Helm template.
@@ -180,3 +205,46 @@ func (pr HelmPostRenderer) addManagedByLabel(manifest map[string]any) (map[strin
 	err := unstructured.SetNestedField(manifest, pr.managedByValue, "metadata", "labels", constants.ManagedByLabelKey)
 	return manifest, err
 }
+
+// addClusterRoleNameSuffix appends the suffix to the name of a ClusterRole or ClusterRoleBinding, and to the
+// ClusterRole referenced by a ClusterRoleBinding.
+func (pr HelmPostRenderer) addClusterRoleNameSuffix(manifest map[string]any) (map[string]any, error) {
+	apiVersion, _, _ := unstructured.NestedString(manifest, "apiVersion")
+	if apiVersion != rbacv1.SchemeGroupVersion.String() {
+		return manifest, nil
+	}
+	kind, _, _ := unstructured.NestedString(manifest, "kind")
+	switch kind {
+	case "ClusterRole":
+	case "ClusterRoleBinding":
+		roleKind, _, _ := unstructured.NestedString(manifest, "roleRef", "kind")
+		roleName, _, _ := unstructured.NestedString(manifest, "roleRef", "name")
+		if roleKind == "ClusterRole" && roleName != "" {
+			if err := unstructured.SetNestedField(manifest, roleName+pr.clusterRoleNameSuffix, "roleRef", "name"); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return manifest, nil
+	}
+
+	name, _, err := unstructured.NestedString(manifest, "metadata", "name")
+	if err != nil {
+		return nil, err
+	}
+	err = unstructured.SetNestedField(manifest, name+pr.clusterRoleNameSuffix, "metadata", "name")
+	return manifest, err
+}
+
+// setOnDeleteUpdateStrategy replaces the update strategy of a DaemonSet with OnDelete, so that its pods are only
+// replaced when the operator deletes them.
+func (pr HelmPostRenderer) setOnDeleteUpdateStrategy(manifest map[string]any) (map[string]any, error) {
+	apiVersion, _, _ := unstructured.NestedString(manifest, "apiVersion")
+	kind, _, _ := unstructured.NestedString(manifest, "kind")
+	if apiVersion != appsv1.SchemeGroupVersion.String() || kind != "DaemonSet" {
+		return manifest, nil
+	}
+
+	err := unstructured.SetNestedField(manifest, map[string]any{"type": string(appsv1.OnDeleteDaemonSetStrategyType)}, "spec", "updateStrategy")
+	return manifest, err
+}
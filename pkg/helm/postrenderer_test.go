@@ -25,12 +25,14 @@ import (
 
 func TestHelmPostRenderer(t *testing.T) {
 	testCases := []struct {
-		name           string
-		ownerReference *metav1.OwnerReference
-		ownerNamespace string
-		isUpdate       bool
-		input          string
-		expected       string
+		name                  string
+		ownerReference        *metav1.OwnerReference
+		ownerNamespace        string
+		isUpdate              bool
+		clusterRoleNameSuffix string
+		onDelete              bool
+		input                 string
+		expected              string
 	}{
 		{
 			name: "cluster-scoped owner",
@@ -299,6 +301,111 @@ metadata:
       uid: "123"
 spec:
   replicas: 1
+`,
+		},
+		{
+			name:                  "cluster role name suffix",
+			clusterRoleNameSuffix: "-gpu",
+			input: `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: istio-cni
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: istio-cni
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: istio-cni
+subjects:
+  - kind: ServiceAccount
+    name: istio-cni
+    namespace: istio-cni-gpu
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: istio-cni
+  namespace: istio-cni-gpu
+`,
+			expected: `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  labels:
+    managed-by: sail-operator
+  name: istio-cni-gpu
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  labels:
+    managed-by: sail-operator
+  name: istio-cni-gpu
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: istio-cni-gpu
+subjects:
+  - kind: ServiceAccount
+    name: istio-cni
+    namespace: istio-cni-gpu
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  labels:
+    managed-by: sail-operator
+  name: istio-cni
+  namespace: istio-cni-gpu
+`,
+		},
+		{
+			name:     "OnDelete update strategy",
+			onDelete: true,
+			input: `apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: ztunnel
+  namespace: ztunnel
+spec:
+  updateStrategy:
+    rollingUpdate:
+      maxSurge: 1
+      maxUnavailable: 0
+    type: RollingUpdate
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: istiod
+  namespace: istio-system
+spec:
+  strategy:
+    type: RollingUpdate
+`,
+			expected: `apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  labels:
+    managed-by: sail-operator
+  name: ztunnel
+  namespace: ztunnel
+spec:
+  updateStrategy:
+    type: OnDelete
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    managed-by: sail-operator
+  name: istiod
+  namespace: istio-system
+spec:
+  strategy:
+    type: RollingUpdate
 `,
 		},
 	}
@@ -306,10 +413,12 @@ spec:
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			postRenderer := HelmPostRenderer{
-				ownerReference: tc.ownerReference,
-				ownerNamespace: tc.ownerNamespace,
-				isUpdate:       tc.isUpdate,
-				managedByValue: constants.ManagedByLabelValue,
+				ownerReference:         tc.ownerReference,
+				ownerNamespace:         tc.ownerNamespace,
+				isUpdate:               tc.isUpdate,
+				managedByValue:         constants.ManagedByLabelValue,
+				clusterRoleNameSuffix:  tc.clusterRoleNameSuffix,
+				onDeleteUpdateStrategy: tc.onDelete,
 			}
 
 			actual, err := postRenderer.Run(bytes.NewBufferString(tc.input))
@@ -0,0 +1,136 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package install
+
+import (
+	"context"
+	"fmt"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"istio.io/istio/pkg/log"
+)
+
+// migrationPageSize is the number of objects listed per request during a
+// storage version migration.
+const migrationPageSize = 500
+
+// staleStoredVersions returns the versions in the CRD's status.storedVersions,
+// other than the storage version, that the CRD no longer defines, no longer
+// serves or marks as deprecated.
+func staleStoredVersions(crd *apiextensionsv1.CustomResourceDefinition) []string {
+	var stale []string
+	for _, name := range crd.Status.StoredVersions {
+		version := findVersion(crd, name)
+		if version == nil || (!version.Storage && (!version.Served || version.Deprecated)) {
+			stale = append(stale, name)
+		}
+	}
+	return stale
+}
+
+// removedStoredVersions returns the versions in the existing CRD's
+// status.storedVersions that the updated CRD no longer defines.
+func removedStoredVersions(existing, updated *apiextensionsv1.CustomResourceDefinition) []string {
+	var removed []string
+	for _, name := range existing.Status.StoredVersions {
+		if findVersion(updated, name) == nil {
+			removed = append(removed, name)
+		}
+	}
+	return removed
+}
+
+func findVersion(crd *apiextensionsv1.CustomResourceDefinition, name string) *apiextensionsv1.CustomResourceDefinitionVersion {
+	for i := range crd.Spec.Versions {
+		if crd.Spec.Versions[i].Name == name {
+			return &crd.Spec.Versions[i]
+		}
+	}
+	return nil
+}
+
+func storageVersion(crd *apiextensionsv1.CustomResourceDefinition) string {
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			return version.Name
+		}
+	}
+	return ""
+}
+
+// migrateStoredVersions re-writes all objects of the given CRD so that the API
+// server persists them in the CRD's storage version, and then prunes the CRD's
+// status.storedVersions down to that version. The CRD is updated in place.
+func (m *CRDManager) migrateStoredVersions(ctx context.Context, crd *apiextensionsv1.CustomResourceDefinition) error {
+	version := storageVersion(crd)
+	if version == "" {
+		return fmt.Errorf("failed to migrate CRD %s: no storage version", crd.Name)
+	}
+	log.Infof("migrating objects of CRD %s from stored versions %v to %s", crd.Name, crd.Status.StoredVersions, version)
+
+	listKind := crd.Spec.Names.ListKind
+	if listKind == "" {
+		listKind = crd.Spec.Names.Kind + "List"
+	}
+	listOpts := []client.ListOption{client.Limit(migrationPageSize)}
+	for {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(schema.GroupVersionKind{Group: crd.Spec.Group, Version: version, Kind: listKind})
+		if err := m.cl.List(ctx, list, listOpts...); err != nil {
+			return fmt.Errorf("failed to list objects of CRD %s: %w", crd.Name, err)
+		}
+		for i := range list.Items {
+			if err := m.migrateObject(ctx, &list.Items[i]); err != nil {
+				return fmt.Errorf("failed to migrate %s %s: %w", crd.Spec.Names.Kind, client.ObjectKeyFromObject(&list.Items[i]), err)
+			}
+		}
+		if list.GetContinue() == "" {
+			break
+		}
+		listOpts = []client.ListOption{client.Limit(migrationPageSize), client.Continue(list.GetContinue())}
+	}
+
+	// all objects were written, so none of them is stored in the old versions anymore
+	crd.Status.StoredVersions = []string{version}
+	if err := m.cl.Status().Update(ctx, crd); err != nil {
+		return fmt.Errorf("failed to prune stored versions of CRD %s: %w", crd.Name, err)
+	}
+	return nil
+}
+
+// migrateObject writes the object back unchanged. The object was read in the storage version, so this is
+// enough for the API server to re-encode it. On a conflict, the object is read again and written back, so
+// that every object is known to be re-written before the stored versions are pruned.
+func (m *CRDManager) migrateObject(ctx context.Context, obj *unstructured.Unstructured) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		err := m.cl.Update(ctx, obj)
+		if apierrors.IsConflict(err) {
+			if getErr := m.cl.Get(ctx, client.ObjectKeyFromObject(obj), obj); getErr != nil {
+				return getErr
+			}
+		}
+		return err
+	})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
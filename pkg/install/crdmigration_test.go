@@ -0,0 +1,261 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package install
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestStaleStoredVersions(t *testing.T) {
+	spec := apiextensionsv1.JSONSchemaProps{Type: "object"}
+	deprecated := crdVersion("v1alpha1", true, spec)
+	deprecated.Deprecated = true
+	storage := crdVersion("v1", true, spec)
+	storage.Storage = true
+
+	tests := []struct {
+		name     string
+		crd      *apiextensionsv1.CustomResourceDefinition
+		expected []string
+	}{
+		{
+			name:     "only storage version stored",
+			crd:      newTestCRD([]string{"v1"}, deprecated, storage),
+			expected: nil,
+		},
+		{
+			name:     "served version stored",
+			crd:      newTestCRD([]string{"v1beta1", "v1"}, crdVersion("v1beta1", true, spec), storage),
+			expected: nil,
+		},
+		{
+			name:     "deprecated version stored",
+			crd:      newTestCRD([]string{"v1alpha1", "v1"}, deprecated, storage),
+			expected: []string{"v1alpha1"},
+		},
+		{
+			name:     "unserved version stored",
+			crd:      newTestCRD([]string{"v1beta1", "v1"}, crdVersion("v1beta1", false, spec), storage),
+			expected: []string{"v1beta1"},
+		},
+		{
+			name:     "undefined version stored",
+			crd:      newTestCRD([]string{"v1beta1", "v1"}, storage),
+			expected: []string{"v1beta1"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(staleStoredVersions(tc.crd)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestApplyCRD_migrateStoredVersions(t *testing.T) {
+	spec := apiextensionsv1.JSONSchemaProps{Type: "object"}
+	alpha := crdVersion("v1alpha1", true, spec)
+	storage := crdVersion("v1", true, spec)
+	storage.Storage = true
+	deprecated := *alpha.DeepCopy()
+	deprecated.Deprecated = true
+
+	tests := []struct {
+		name           string
+		existing       []apiextensionsv1.CustomResourceDefinitionVersion
+		storedVersions []string
+		updated        []apiextensionsv1.CustomResourceDefinitionVersion
+		migrate        bool
+		expectMigrated []string
+		expectStored   []string
+	}{
+		{
+			name:           "deprecated stored version",
+			existing:       []apiextensionsv1.CustomResourceDefinitionVersion{alpha, storage},
+			storedVersions: []string{"v1alpha1", "v1"},
+			updated:        []apiextensionsv1.CustomResourceDefinitionVersion{deprecated, storage},
+			migrate:        true,
+			expectMigrated: []string{"v1alpha1"},
+			expectStored:   []string{"v1"},
+		},
+		{
+			name:           "removed stored version",
+			existing:       []apiextensionsv1.CustomResourceDefinitionVersion{alpha, storage},
+			storedVersions: []string{"v1alpha1", "v1"},
+			updated:        []apiextensionsv1.CustomResourceDefinitionVersion{storage},
+			migrate:        true,
+			expectMigrated: []string{"v1alpha1"},
+			expectStored:   []string{"v1"},
+		},
+		{
+			name:           "served stored version",
+			existing:       []apiextensionsv1.CustomResourceDefinitionVersion{alpha, storage},
+			storedVersions: []string{"v1alpha1", "v1"},
+			updated:        []apiextensionsv1.CustomResourceDefinitionVersion{alpha, storage},
+			migrate:        true,
+			expectStored:   []string{"v1alpha1", "v1"},
+		},
+		{
+			name:           "migration disabled",
+			existing:       []apiextensionsv1.CustomResourceDefinitionVersion{alpha, storage},
+			storedVersions: []string{"v1alpha1", "v1"},
+			updated:        []apiextensionsv1.CustomResourceDefinitionVersion{deprecated, storage},
+			expectStored:   []string{"v1alpha1", "v1"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			existing := newTestCRD(tc.storedVersions, tc.existing...)
+			cl := newMigrationTestClient(g, existing, interceptor.Funcs{})
+			m := NewCRDManager(cl, nil, defaultCRDOwnershipLabelKey, defaultCRDOwnershipLabelValue, false)
+
+			crd := newTestCRD(nil, tc.updated...)
+			crd.Spec.Names.ListKind = "VirtualServiceList"
+
+			ctx := t.Context()
+			info, err := m.applyCRD(ctx, crd, "v1.30.0", Options{MigrateStoredVersions: tc.migrate})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(info.MigratedVersions).To(Equal(tc.expectMigrated))
+
+			var actual apiextensionsv1.CustomResourceDefinition
+			g.Expect(cl.Get(ctx, types.NamespacedName{Name: crd.Name}, &actual)).To(Succeed())
+			g.Expect(actual.Status.StoredVersions).To(Equal(tc.expectStored))
+
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(migrationTestGVK.GroupVersion().WithKind("VirtualServiceList"))
+			g.Expect(cl.List(ctx, list)).To(Succeed())
+			g.Expect(list.Items).To(HaveLen(2))
+			for _, item := range list.Items {
+				if len(tc.expectMigrated) > 0 {
+					g.Expect(item.GetResourceVersion()).NotTo(Equal("999"), "object %s was not re-written", item.GetName())
+				} else {
+					g.Expect(item.GetResourceVersion()).To(Equal("999"), "object %s was re-written", item.GetName())
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateStoredVersions_conflicts(t *testing.T) {
+	spec := apiextensionsv1.JSONSchemaProps{Type: "object"}
+	alpha := crdVersion("v1alpha1", true, spec)
+	alpha.Deprecated = true
+	storage := crdVersion("v1", true, spec)
+	storage.Storage = true
+
+	tests := []struct {
+		name         string
+		conflicts    int
+		expectErr    bool
+		expectStored []string
+	}{
+		{
+			name:         "retries after conflict",
+			conflicts:    1,
+			expectStored: []string{"v1"},
+		},
+		{
+			name:         "keeps stored versions when conflicts persist",
+			conflicts:    100,
+			expectErr:    true,
+			expectStored: []string{"v1alpha1", "v1"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			conflicts := tc.conflicts
+			var updated []string
+			cl := newMigrationTestClient(g, newTestCRD([]string{"v1alpha1", "v1"}, alpha, storage), interceptor.Funcs{
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+					if obj.GetName() == "reviews" && conflicts > 0 {
+						conflicts--
+						return apierrors.NewConflict(migrationTestGVK.GroupVersion().WithResource("virtualservices").GroupResource(),
+							obj.GetName(), nil)
+					}
+					if err := c.Update(ctx, obj, opts...); err != nil {
+						return err
+					}
+					updated = append(updated, obj.GetName())
+					return nil
+				},
+			})
+			m := NewCRDManager(cl, nil, defaultCRDOwnershipLabelKey, defaultCRDOwnershipLabelValue, false)
+
+			ctx := t.Context()
+			var crd apiextensionsv1.CustomResourceDefinition
+			g.Expect(cl.Get(ctx, types.NamespacedName{Name: newTestCRD(nil).Name}, &crd)).To(Succeed())
+
+			err := m.migrateStoredVersions(ctx, &crd)
+			if tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(updated).To(ConsistOf("reviews", "ratings"))
+			}
+
+			var actual apiextensionsv1.CustomResourceDefinition
+			g.Expect(cl.Get(ctx, types.NamespacedName{Name: crd.Name}, &actual)).To(Succeed())
+			g.Expect(actual.Status.StoredVersions).To(Equal(tc.expectStored))
+		})
+	}
+}
+
+var migrationTestGVK = schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1", Kind: "VirtualService"}
+
+// newMigrationTestClient returns a fake client with the given CRD and two VirtualServices.
+func newMigrationTestClient(g *WithT, crd *apiextensionsv1.CustomResourceDefinition, funcs interceptor.Funcs) client.Client {
+	crd.Spec.Names.ListKind = "VirtualServiceList"
+	objs := []client.Object{crd}
+	for _, name := range []string{"reviews", "ratings"} {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(migrationTestGVK)
+		obj.SetNamespace("bookinfo")
+		obj.SetName(name)
+		objs = append(objs, obj)
+	}
+
+	s := runtime.NewScheme()
+	g.Expect(apiextensionsv1.AddToScheme(s)).To(Succeed())
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(migrationTestGVK, meta.RESTScopeNamespace)
+	mapper.Add(apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"), meta.RESTScopeRoot)
+	return fake.NewClientBuilder().
+		WithScheme(s).
+		WithRESTMapper(mapper).
+		WithObjects(objs...).
+		WithStatusSubresource(&apiextensionsv1.CustomResourceDefinition{}).
+		WithInterceptorFuncs(funcs).
+		Build()
+}
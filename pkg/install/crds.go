@@ -45,17 +45,34 @@ const (
 	crdManagedByLibrary
 )
 
-type crdManager struct {
+// CRDManager installs and updates the Istio CRDs. It is used by the Library
+// and by the operator when it is configured to manage the Istio CRDs itself.
+type CRDManager struct {
 	cl                  client.Client
 	crdFS               fs.FS
 	ownershipLabelKey   string
 	ownershipLabelValue string
+	adoptUnlabeled      bool
+}
+
+// NewCRDManager creates a CRDManager that loads CRDs from crdFS and marks the
+// CRDs it manages with the given ownership label. If adoptUnlabeled is true,
+// existing CRDs that carry neither the ownership label nor the OLM label are
+// taken over instead of being left alone.
+func NewCRDManager(cl client.Client, crdFS fs.FS, ownershipLabelKey, ownershipLabelValue string, adoptUnlabeled bool) *CRDManager {
+	return &CRDManager{
+		cl:                  cl,
+		crdFS:               crdFS,
+		ownershipLabelKey:   ownershipLabelKey,
+		ownershipLabelValue: ownershipLabelValue,
+		adoptUnlabeled:      adoptUnlabeled,
+	}
 }
 
 // Reconcile installs or updates CRDs based on the provided options.
 // The version parameter should be the resolved Istio version (e.g. "v1.30.3"),
 // not an alias (e.g. "v1.30-latest").
-func (m *crdManager) Reconcile(ctx context.Context, opts Options, version string) ([]CRDInfo, error) {
+func (m *CRDManager) Reconcile(ctx context.Context, opts Options, version string) ([]CRDInfo, error) {
 	if !opts.ManageCRDs {
 		return nil, nil
 	}
@@ -66,19 +83,26 @@ func (m *crdManager) Reconcile(ctx context.Context, opts Options, version string
 	}
 
 	var infos []CRDInfo
+	var refused []string
 	for _, crd := range crds {
-		info, err := m.applyCRD(ctx, crd, version, opts.OverwriteOLMManagedCRD)
+		info, err := m.applyCRD(ctx, crd, version, opts)
 		if err != nil {
 			return infos, err
 		}
+		if info.UpgradeVerdict == CRDUpgradeRefused {
+			refused = append(refused, info.Name)
+		}
 		infos = append(infos, info)
 	}
+	if len(refused) > 0 {
+		return infos, fmt.Errorf("refused to update CRDs that failed the upgrade safety checks: %s", strings.Join(refused, ", "))
+	}
 	return infos, nil
 }
 
-func (m *crdManager) applyCRD(
+func (m *CRDManager) applyCRD(
 	ctx context.Context, crd *apiextensionsv1.CustomResourceDefinition,
-	version string, overwriteOLM OverwriteOLMManagedCRDFunc,
+	version string, opts Options,
 ) (CRDInfo, error) {
 	name := crd.GetName()
 	info := CRDInfo{Name: name, Managed: true}
@@ -99,7 +123,7 @@ func (m *crdManager) applyCRD(
 
 	switch ownership := m.classifyCRD(existing); ownership {
 	case crdManagedByOLM:
-		if overwriteOLM == nil || !overwriteOLM(ctx, existing) {
+		if opts.OverwriteOLMManagedCRD == nil || !opts.OverwriteOLMManagedCRD(ctx, existing) {
 			info.Managed = false
 			info.Ready = m.isCRDReady(existing)
 			return info, nil
@@ -117,6 +141,29 @@ func (m *crdManager) applyCRD(
 		return info, nil
 	}
 
+	if opts.MigrateStoredVersions {
+		// the API server rejects an update that removes a version still listed in
+		// status.storedVersions, so such versions must be migrated beforehand
+		if removed := removedStoredVersions(existing, crd); len(removed) > 0 {
+			if err := m.migrateStoredVersions(ctx, existing); err != nil {
+				return info, err
+			}
+			info.MigratedVersions = append(info.MigratedVersions, removed...)
+		}
+	}
+
+	info.UpgradeVerdict = CRDUpgradeSafe
+	if info.UpgradeIssues = checkCRDUpgrade(existing, crd); len(info.UpgradeIssues) > 0 {
+		if opts.CRDUpgradePolicy == CRDUpgradePolicyRefuse {
+			log.Warnf("refusing to update CRD %s: %s", name, strings.Join(info.UpgradeIssues, "; "))
+			info.UpgradeVerdict = CRDUpgradeRefused
+			info.Ready = m.isCRDReady(existing)
+			return info, nil
+		}
+		log.Warnf("updating CRD %s despite failed upgrade safety checks: %s", name, strings.Join(info.UpgradeIssues, "; "))
+		info.UpgradeVerdict = CRDUpgradeUnsafe
+	}
+
 	crd.SetResourceVersion(existing.ResourceVersion)
 	m.setManagedByLabel(crd)
 	setVersionAnnotation(crd, version)
@@ -124,6 +171,19 @@ func (m *crdManager) applyCRD(
 		return info, fmt.Errorf("failed to update CRD %s: %w", name, err)
 	}
 	info.Ready = m.isCRDReady(existing)
+
+	if opts.MigrateStoredVersions {
+		updated := &apiextensionsv1.CustomResourceDefinition{}
+		if err := m.cl.Get(ctx, types.NamespacedName{Name: name}, updated); err != nil {
+			return info, fmt.Errorf("failed to get CRD %s: %w", name, err)
+		}
+		if stale := staleStoredVersions(updated); len(stale) > 0 {
+			if err := m.migrateStoredVersions(ctx, updated); err != nil {
+				return info, err
+			}
+			info.MigratedVersions = append(info.MigratedVersions, stale...)
+		}
+	}
 	return info, nil
 }
 
@@ -158,7 +218,7 @@ func isDowngrade(existing *apiextensionsv1.CustomResourceDefinition, newVersion
 	return newSemver.LessThan(existingSemver)
 }
 
-func (m *crdManager) setManagedByLabel(crd *apiextensionsv1.CustomResourceDefinition) {
+func (m *CRDManager) setManagedByLabel(crd *apiextensionsv1.CustomResourceDefinition) {
 	labels := crd.GetLabels()
 	if labels == nil {
 		labels = map[string]string{}
@@ -167,17 +227,22 @@ func (m *crdManager) setManagedByLabel(crd *apiextensionsv1.CustomResourceDefini
 	crd.SetLabels(labels)
 }
 
-func (m *crdManager) classifyCRD(crd *apiextensionsv1.CustomResourceDefinition) crdOwnership {
+func (m *CRDManager) classifyCRD(crd *apiextensionsv1.CustomResourceDefinition) crdOwnership {
 	if _, ok := crd.Labels[OLMManagedLabel]; ok {
 		return crdManagedByOLM
 	}
 	if crd.Labels != nil && crd.Labels[m.ownershipLabelKey] == m.ownershipLabelValue {
 		return crdManagedByLibrary
 	}
+	if m.adoptUnlabeled {
+		if _, ok := crd.Labels[m.ownershipLabelKey]; !ok {
+			return crdManagedByLibrary
+		}
+	}
 	return crdUnmanaged
 }
 
-func (m *crdManager) isCRDReady(crd *apiextensionsv1.CustomResourceDefinition) bool {
+func (m *CRDManager) isCRDReady(crd *apiextensionsv1.CustomResourceDefinition) bool {
 	for _, cond := range crd.Status.Conditions {
 		if cond.Type == apiextensionsv1.Established && cond.Status == apiextensionsv1.ConditionTrue {
 			return true
@@ -186,7 +251,7 @@ func (m *crdManager) isCRDReady(crd *apiextensionsv1.CustomResourceDefinition) b
 	return false
 }
 
-func (m *crdManager) loadCRDs(opts Options) ([]*apiextensionsv1.CustomResourceDefinition, error) {
+func (m *CRDManager) loadCRDs(opts Options) ([]*apiextensionsv1.CustomResourceDefinition, error) {
 	targetKinds := targetCRDKinds(opts.IncludeAllCRDs, opts.Values)
 
 	var crds []*apiextensionsv1.CustomResourceDefinition
@@ -28,8 +28,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
-func defaultCRDManager() *crdManager {
-	return &crdManager{
+func defaultCRDManager() *CRDManager {
+	return &CRDManager{
 		ownershipLabelKey:   defaultCRDOwnershipLabelKey,
 		ownershipLabelValue: defaultCRDOwnershipLabelValue,
 	}
@@ -133,7 +133,7 @@ func TestClassifyCRDCustomOwnershipLabel(t *testing.T) {
 		customKey   = "ingress.operator.openshift.io/owned"
 		customValue = "true"
 	)
-	m := &crdManager{
+	m := &CRDManager{
 		ownershipLabelKey:   customKey,
 		ownershipLabelValue: customValue,
 	}
@@ -155,7 +155,7 @@ func TestClassifyCRDCustomOwnershipLabel(t *testing.T) {
 }
 
 func TestIsCRDReady(t *testing.T) {
-	m := &crdManager{}
+	m := &CRDManager{}
 
 	tests := []struct {
 		name   string
@@ -261,7 +261,7 @@ func TestIstioCRD(t *testing.T) {
 
 func TestLoadCRDs_excludesSailOperatorCRDs(t *testing.T) {
 	g := NewWithT(t)
-	m := &crdManager{crdFS: fstest.MapFS{
+	m := &CRDManager{crdFS: fstest.MapFS{
 		"crds.yaml": &fstest.MapFile{Data: []byte(testManifestsWithSailOperator)},
 	}}
 	crds, err := m.loadCRDs(Options{ManageCRDs: true, IncludeAllCRDs: true})
@@ -281,7 +281,7 @@ func TestLoadCRDs_filterByPilotInclude(t *testing.T) {
 			},
 		},
 	}
-	m := &crdManager{crdFS: fstest.MapFS{
+	m := &CRDManager{crdFS: fstest.MapFS{
 		"crds.yaml": &fstest.MapFile{Data: []byte(testManifests)},
 	}}
 	crds, err := m.loadCRDs(Options{ManageCRDs: true, Values: vals})
@@ -292,7 +292,7 @@ func TestLoadCRDs_filterByPilotInclude(t *testing.T) {
 
 func TestLoadCRDs_noFilterIncludesAllCRDs(t *testing.T) {
 	g := NewWithT(t)
-	m := &crdManager{crdFS: fstest.MapFS{
+	m := &CRDManager{crdFS: fstest.MapFS{
 		"crds.yaml": &fstest.MapFile{Data: []byte(testManifests)},
 	}}
 	crds, err := m.loadCRDs(Options{ManageCRDs: true})
@@ -387,7 +387,7 @@ func TestLoadCRDs(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			g := NewWithT(t)
-			m := &crdManager{crdFS: tc.files}
+			m := &CRDManager{crdFS: tc.files}
 			crds, err := m.loadCRDs(tc.opts)
 			g.Expect(err).NotTo(HaveOccurred())
 			g.Expect(crds).To(HaveLen(tc.expectLen))
@@ -422,7 +422,7 @@ func TestUnmanagedCRDNotTakenOver(t *testing.T) {
 	g.Expect(apiextensionsv1.AddToScheme(s)).To(Succeed())
 	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(existingVS, existingGW).Build()
 
-	m := &crdManager{
+	m := &CRDManager{
 		cl:                  cl,
 		crdFS:               chart.CRDsFS,
 		ownershipLabelKey:   defaultCRDOwnershipLabelKey,
@@ -483,7 +483,7 @@ func TestCRDOwnershipLabelCustom(t *testing.T) {
 	g.Expect(apiextensionsv1.AddToScheme(s)).To(Succeed())
 	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(existingOwned, existingUnmanaged).Build()
 
-	m := &crdManager{
+	m := &CRDManager{
 		cl:                  cl,
 		crdFS:               chart.CRDsFS,
 		ownershipLabelKey:   customKey,
@@ -633,7 +633,7 @@ func TestApplyCRD_skipsDowngrade(t *testing.T) {
 	g.Expect(apiextensionsv1.AddToScheme(s)).To(Succeed())
 	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(existing).Build()
 
-	m := &crdManager{
+	m := &CRDManager{
 		cl:                  cl,
 		ownershipLabelKey:   defaultCRDOwnershipLabelKey,
 		ownershipLabelValue: defaultCRDOwnershipLabelValue,
@@ -650,7 +650,7 @@ func TestApplyCRD_skipsDowngrade(t *testing.T) {
 	}
 
 	ctx := t.Context()
-	info, err := m.applyCRD(ctx, crd, "v1.29.0", nil)
+	info, err := m.applyCRD(ctx, crd, "v1.29.0", Options{})
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(info.Managed).To(BeTrue())
 	g.Expect(info.Ready).To(BeTrue())
@@ -683,7 +683,7 @@ func TestApplyCRD_updatesWhenNewer(t *testing.T) {
 	g.Expect(apiextensionsv1.AddToScheme(s)).To(Succeed())
 	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(existing).Build()
 
-	m := &crdManager{
+	m := &CRDManager{
 		cl:                  cl,
 		ownershipLabelKey:   defaultCRDOwnershipLabelKey,
 		ownershipLabelValue: defaultCRDOwnershipLabelValue,
@@ -700,7 +700,7 @@ func TestApplyCRD_updatesWhenNewer(t *testing.T) {
 	}
 
 	ctx := t.Context()
-	info, err := m.applyCRD(ctx, crd, "v1.30.0", nil)
+	info, err := m.applyCRD(ctx, crd, "v1.30.0", Options{})
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(info.Managed).To(BeTrue())
 
@@ -0,0 +1,182 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package install
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// CRDUpgradePolicy determines what the library does when updating a CRD
+// would break existing objects or clients.
+type CRDUpgradePolicy string
+
+const (
+	// CRDUpgradePolicyWarn logs the problems found by the upgrade safety
+	// checks and updates the CRD anyway. This is the default.
+	CRDUpgradePolicyWarn CRDUpgradePolicy = "Warn"
+	// CRDUpgradePolicyRefuse leaves a CRD that fails the upgrade safety
+	// checks unchanged and fails the reconciliation.
+	CRDUpgradePolicyRefuse CRDUpgradePolicy = "Refuse"
+)
+
+// CRDUpgradeVerdict is the result of the upgrade safety checks for a CRD.
+// It is empty if the CRD wasn't updated, e.g. because it was created or is
+// not managed by the library.
+type CRDUpgradeVerdict string
+
+const (
+	// CRDUpgradeSafe means no problems were found and the CRD was updated.
+	CRDUpgradeSafe CRDUpgradeVerdict = "Safe"
+	// CRDUpgradeUnsafe means problems were found, but the CRD was updated
+	// because of CRDUpgradePolicyWarn.
+	CRDUpgradeUnsafe CRDUpgradeVerdict = "Unsafe"
+	// CRDUpgradeRefused means problems were found and the CRD was left
+	// unchanged because of CRDUpgradePolicyRefuse.
+	CRDUpgradeRefused CRDUpgradeVerdict = "Refused"
+)
+
+// checkCRDUpgrade compares the existing CRD with the one that is about to
+// replace it and returns the problems the update would cause:
+//   - stored versions that are removed or no longer served, which makes the
+//     objects stored in them unreadable;
+//   - fields that are removed from the schema of a version, which causes
+//     their values to be pruned;
+//   - validation that is tightened, which causes existing objects to be
+//     rejected on their next update.
+func checkCRDUpgrade(existing, updated *apiextensionsv1.CustomResourceDefinition) []string {
+	var issues []string
+
+	newVersions := map[string]*apiextensionsv1.CustomResourceDefinitionVersion{}
+	for i := range updated.Spec.Versions {
+		newVersions[updated.Spec.Versions[i].Name] = &updated.Spec.Versions[i]
+	}
+
+	for _, stored := range existing.Status.StoredVersions {
+		if v, found := newVersions[stored]; !found {
+			issues = append(issues, fmt.Sprintf("stored version %s is removed", stored))
+		} else if !v.Served {
+			issues = append(issues, fmt.Sprintf("stored version %s is no longer served", stored))
+		}
+	}
+
+	for _, oldVersion := range existing.Spec.Versions {
+		newVersion, found := newVersions[oldVersion.Name]
+		if !found || oldVersion.Schema == nil || newVersion.Schema == nil {
+			continue
+		}
+		for _, issue := range compareSchemas("", oldVersion.Schema.OpenAPIV3Schema, newVersion.Schema.OpenAPIV3Schema) {
+			issues = append(issues, fmt.Sprintf("%s: %s", oldVersion.Name, issue))
+		}
+	}
+	return issues
+}
+
+// compareSchemas recursively compares two schemas and returns the fields
+// that were dropped and the validations that were tightened.
+func compareSchemas(path string, old, updated *apiextensionsv1.JSONSchemaProps) []string {
+	if old == nil || updated == nil {
+		return nil
+	}
+	field := path
+	if field == "" {
+		field = "<root>"
+	}
+
+	var issues []string
+	if old.Type != "" && updated.Type != old.Type {
+		issues = append(issues, fmt.Sprintf("type of %s changed from %s to %s", field, old.Type, updated.Type))
+	}
+	for _, name := range updated.Required {
+		if !slices.Contains(old.Required, name) {
+			issues = append(issues, fmt.Sprintf("field %s became required", joinPath(path, name)))
+		}
+	}
+	if len(updated.Enum) > 0 {
+		for _, value := range old.Enum {
+			if !slices.ContainsFunc(updated.Enum, func(v apiextensionsv1.JSON) bool { return string(v.Raw) == string(value.Raw) }) {
+				issues = append(issues, fmt.Sprintf("enum value %s of %s was removed", value.Raw, field))
+			}
+		}
+		if len(old.Enum) == 0 {
+			issues = append(issues, fmt.Sprintf("%s is now restricted to an enum", field))
+		}
+	}
+	if updated.Pattern != "" && updated.Pattern != old.Pattern {
+		issues = append(issues, fmt.Sprintf("pattern of %s changed to %q", field, updated.Pattern))
+	}
+	issues = append(issues, checkUpperBound(field, "maxLength", old.MaxLength, updated.MaxLength)...)
+	issues = append(issues, checkUpperBound(field, "maxItems", old.MaxItems, updated.MaxItems)...)
+	issues = append(issues, checkUpperBound(field, "maxProperties", old.MaxProperties, updated.MaxProperties)...)
+	issues = append(issues, checkLowerBound(field, "minLength", old.MinLength, updated.MinLength)...)
+	issues = append(issues, checkLowerBound(field, "minItems", old.MinItems, updated.MinItems)...)
+	issues = append(issues, checkLowerBound(field, "minProperties", old.MinProperties, updated.MinProperties)...)
+	if updated.Maximum != nil && (old.Maximum == nil || *updated.Maximum < *old.Maximum) {
+		issues = append(issues, fmt.Sprintf("maximum of %s was lowered to %v", field, *updated.Maximum))
+	}
+	if updated.Minimum != nil && (old.Minimum == nil || *updated.Minimum > *old.Minimum) {
+		issues = append(issues, fmt.Sprintf("minimum of %s was raised to %v", field, *updated.Minimum))
+	}
+	for _, rule := range updated.XValidations {
+		if !slices.ContainsFunc(old.XValidations, func(r apiextensionsv1.ValidationRule) bool { return r.Rule == rule.Rule }) {
+			issues = append(issues, fmt.Sprintf("validation rule %q was added to %s", rule.Rule, field))
+		}
+	}
+
+	// fields that are not in the schema are preserved if unknown fields are preserved
+	preserved := updated.XPreserveUnknownFields != nil && *updated.XPreserveUnknownFields
+	for _, name := range slices.Sorted(maps.Keys(old.Properties)) {
+		oldProp := old.Properties[name]
+		newProp, found := updated.Properties[name]
+		if !found {
+			if !preserved {
+				issues = append(issues, fmt.Sprintf("field %s was removed", joinPath(path, name)))
+			}
+			continue
+		}
+		issues = append(issues, compareSchemas(joinPath(path, name), &oldProp, &newProp)...)
+	}
+	if old.Items != nil && updated.Items != nil {
+		issues = append(issues, compareSchemas(path+"[*]", old.Items.Schema, updated.Items.Schema)...)
+	}
+	if old.AdditionalProperties != nil && updated.AdditionalProperties != nil {
+		issues = append(issues, compareSchemas(path+"[*]", old.AdditionalProperties.Schema, updated.AdditionalProperties.Schema)...)
+	}
+	return issues
+}
+
+func checkUpperBound(field, name string, old, updated *int64) []string {
+	if updated != nil && (old == nil || *updated < *old) {
+		return []string{fmt.Sprintf("%s of %s was lowered to %d", name, field, *updated)}
+	}
+	return nil
+}
+
+func checkLowerBound(field, name string, old, updated *int64) []string {
+	if updated != nil && *updated > 0 && (old == nil || *updated > *old) {
+		return []string{fmt.Sprintf("%s of %s was raised to %d", name, field, *updated)}
+	}
+	return nil
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,251 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package install
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"istio.io/istio/pkg/ptr"
+)
+
+func newTestCRD(storedVersions []string, versions ...apiextensionsv1.CustomResourceDefinitionVersion) *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "virtualservices.networking.istio.io",
+			Labels: map[string]string{
+				defaultCRDOwnershipLabelKey: defaultCRDOwnershipLabelValue,
+			},
+		},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group:    "networking.istio.io",
+			Names:    apiextensionsv1.CustomResourceDefinitionNames{Kind: "VirtualService"},
+			Versions: versions,
+		},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{
+			StoredVersions: storedVersions,
+		},
+	}
+}
+
+func crdVersion(name string, served bool, spec apiextensionsv1.JSONSchemaProps) apiextensionsv1.CustomResourceDefinitionVersion {
+	return apiextensionsv1.CustomResourceDefinitionVersion{
+		Name:   name,
+		Served: served,
+		Schema: &apiextensionsv1.CustomResourceValidation{
+			OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+				Type:       "object",
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{"spec": spec},
+			},
+		},
+	}
+}
+
+func TestCheckCRDUpgrade(t *testing.T) {
+	hostsSpec := apiextensionsv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"hosts": {Type: "array", Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{Type: "string"}}},
+			"mode":  {Type: "string", Enum: []apiextensionsv1.JSON{{Raw: []byte(`"A"`)}, {Raw: []byte(`"B"`)}}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		existing *apiextensionsv1.CustomResourceDefinition
+		updated  *apiextensionsv1.CustomResourceDefinition
+		expected []string
+	}{
+		{
+			name:     "identical",
+			existing: newTestCRD([]string{"v1"}, crdVersion("v1", true, hostsSpec)),
+			updated:  newTestCRD(nil, crdVersion("v1", true, hostsSpec)),
+			expected: nil,
+		},
+		{
+			name:     "new version and field added",
+			existing: newTestCRD([]string{"v1"}, crdVersion("v1", true, hostsSpec)),
+			updated: newTestCRD(nil,
+				crdVersion("v1", true, withProperty(hostsSpec, "gateways", apiextensionsv1.JSONSchemaProps{Type: "string"})),
+				crdVersion("v2", true, hostsSpec)),
+			expected: nil,
+		},
+		{
+			name:     "stored version removed",
+			existing: newTestCRD([]string{"v1alpha1", "v1"}, crdVersion("v1alpha1", true, hostsSpec), crdVersion("v1", true, hostsSpec)),
+			updated:  newTestCRD(nil, crdVersion("v1", true, hostsSpec)),
+			expected: []string{"stored version v1alpha1 is removed"},
+		},
+		{
+			name:     "stored version no longer served",
+			existing: newTestCRD([]string{"v1alpha1"}, crdVersion("v1alpha1", true, hostsSpec)),
+			updated:  newTestCRD(nil, crdVersion("v1alpha1", false, hostsSpec)),
+			expected: []string{"stored version v1alpha1 is no longer served"},
+		},
+		{
+			name:     "field removed",
+			existing: newTestCRD([]string{"v1"}, crdVersion("v1", true, hostsSpec)),
+			updated:  newTestCRD(nil, crdVersion("v1", true, withoutProperty(hostsSpec, "mode"))),
+			expected: []string{"v1: field spec.mode was removed"},
+		},
+		{
+			name:     "field removed but unknown fields preserved",
+			existing: newTestCRD([]string{"v1"}, crdVersion("v1", true, hostsSpec)),
+			updated: newTestCRD(nil, crdVersion("v1", true, func() apiextensionsv1.JSONSchemaProps {
+				spec := withoutProperty(hostsSpec, "mode")
+				spec.XPreserveUnknownFields = ptr.Of(true)
+				return spec
+			}())),
+			expected: nil,
+		},
+		{
+			name:     "validation tightened",
+			existing: newTestCRD([]string{"v1"}, crdVersion("v1", true, hostsSpec)),
+			updated: newTestCRD(nil, crdVersion("v1", true, func() apiextensionsv1.JSONSchemaProps {
+				spec := withProperty(hostsSpec, "mode", apiextensionsv1.JSONSchemaProps{
+					Type: "string", Enum: []apiextensionsv1.JSON{{Raw: []byte(`"A"`)}},
+				})
+				spec = withProperty(spec, "hosts", apiextensionsv1.JSONSchemaProps{
+					Type:     "array",
+					MaxItems: ptr.Of(int64(10)),
+					Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{
+						Type: "string", MaxLength: ptr.Of(int64(253)),
+					}},
+				})
+				spec.Required = []string{"hosts"}
+				spec.XValidations = apiextensionsv1.ValidationRules{{Rule: "size(self.hosts) > 0"}}
+				return spec
+			}())),
+			expected: []string{
+				"v1: field spec.hosts became required",
+				`v1: validation rule "size(self.hosts) > 0" was added to spec`,
+				"v1: maxItems of spec.hosts was lowered to 10",
+				"v1: maxLength of spec.hosts[*] was lowered to 253",
+				`v1: enum value "B" of spec.mode was removed`,
+			},
+		},
+		{
+			name:     "type changed",
+			existing: newTestCRD([]string{"v1"}, crdVersion("v1", true, hostsSpec)),
+			updated:  newTestCRD(nil, crdVersion("v1", true, withProperty(hostsSpec, "mode", apiextensionsv1.JSONSchemaProps{Type: "integer"}))),
+			expected: []string{"v1: type of spec.mode changed from string to integer"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(checkCRDUpgrade(tc.existing, tc.updated)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func withProperty(schema apiextensionsv1.JSONSchemaProps, name string, prop apiextensionsv1.JSONSchemaProps) apiextensionsv1.JSONSchemaProps {
+	schema = *schema.DeepCopy()
+	schema.Properties[name] = prop
+	return schema
+}
+
+func withoutProperty(schema apiextensionsv1.JSONSchemaProps, name string) apiextensionsv1.JSONSchemaProps {
+	schema = *schema.DeepCopy()
+	delete(schema.Properties, name)
+	return schema
+}
+
+func TestApplyCRD_upgradePolicy(t *testing.T) {
+	spec := apiextensionsv1.JSONSchemaProps{
+		Type:       "object",
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{"hosts": {Type: "string"}},
+	}
+
+	tests := []struct {
+		name            string
+		policy          CRDUpgradePolicy
+		updated         apiextensionsv1.JSONSchemaProps
+		expectVerdict   CRDUpgradeVerdict
+		expectIssues    []string
+		expectUpdateRan bool
+	}{
+		{
+			name:            "safe update",
+			policy:          CRDUpgradePolicyRefuse,
+			updated:         withProperty(spec, "gateways", apiextensionsv1.JSONSchemaProps{Type: "string"}),
+			expectVerdict:   CRDUpgradeSafe,
+			expectUpdateRan: true,
+		},
+		{
+			name:            "unsafe update with warn policy",
+			policy:          CRDUpgradePolicyWarn,
+			updated:         withoutProperty(spec, "hosts"),
+			expectVerdict:   CRDUpgradeUnsafe,
+			expectIssues:    []string{"v1: field spec.hosts was removed"},
+			expectUpdateRan: true,
+		},
+		{
+			name:            "unsafe update with default policy",
+			updated:         withoutProperty(spec, "hosts"),
+			expectVerdict:   CRDUpgradeUnsafe,
+			expectIssues:    []string{"v1: field spec.hosts was removed"},
+			expectUpdateRan: true,
+		},
+		{
+			name:            "unsafe update with refuse policy",
+			policy:          CRDUpgradePolicyRefuse,
+			updated:         withoutProperty(spec, "hosts"),
+			expectVerdict:   CRDUpgradeRefused,
+			expectIssues:    []string{"v1: field spec.hosts was removed"},
+			expectUpdateRan: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			existing := newTestCRD([]string{"v1"}, crdVersion("v1", true, spec))
+			s := runtime.NewScheme()
+			g.Expect(apiextensionsv1.AddToScheme(s)).To(Succeed())
+			cl := fake.NewClientBuilder().WithScheme(s).WithObjects(existing).Build()
+			m := &CRDManager{
+				cl:                  cl,
+				ownershipLabelKey:   defaultCRDOwnershipLabelKey,
+				ownershipLabelValue: defaultCRDOwnershipLabelValue,
+			}
+
+			crd := newTestCRD(nil, crdVersion("v1", true, tc.updated))
+			crd.Labels = nil
+
+			ctx := t.Context()
+			info, err := m.applyCRD(ctx, crd, "v1.30.0", Options{CRDUpgradePolicy: tc.policy})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(info.UpgradeVerdict).To(Equal(tc.expectVerdict))
+			g.Expect(info.UpgradeIssues).To(Equal(tc.expectIssues))
+
+			var actual apiextensionsv1.CustomResourceDefinition
+			g.Expect(cl.Get(ctx, types.NamespacedName{Name: crd.Name}, &actual)).To(Succeed())
+			if tc.expectUpdateRan {
+				g.Expect(actual.Annotations).To(HaveKeyWithValue("app.kubernetes.io/version", "v1.30.0"))
+			} else {
+				g.Expect(actual.Annotations).NotTo(HaveKey("app.kubernetes.io/version"))
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package install
+
+import (
+	"context"
+)
+
+// subscriberBufferSize is the number of events buffered for each subscriber.
+const subscriberBufferSize = 16
+
+// Phase represents the stage of the installation lifecycle.
+type Phase string
+
+const (
+	// PhaseInstalling means the library is reconciling a new generation of
+	// the options and nothing has been installed yet.
+	PhaseInstalling Phase = "Installing"
+	// PhaseUpgrading means the library is reconciling a new generation of
+	// the options on top of an existing installation.
+	PhaseUpgrading Phase = "Upgrading"
+	// PhaseInstalled means the last reconciliation succeeded.
+	PhaseInstalled Phase = "Installed"
+	// PhaseFailed means the last reconciliation failed. It is retried.
+	PhaseFailed Phase = "Failed"
+	// PhaseUninstalled means Uninstall completed.
+	PhaseUninstalled Phase = "Uninstalled"
+)
+
+// StatusEvent describes a change of the installation status. An event is
+// published when the library starts reconciling a new generation of the
+// options, and when a reconciliation results in a different phase, CRD
+// state or error than the previous one. Reconciliations triggered by drift
+// that don't change any of these don't produce events.
+type StatusEvent struct {
+	// Generation of the options the event refers to.
+	Generation uint64
+
+	PreviousPhase Phase
+	Phase         Phase
+
+	PreviousCRDState CRDManagementState
+	CRDState         CRDManagementState
+
+	// Error is the reconciliation error, if any.
+	Error error
+
+	// Status is the installation status at the time of the event. For
+	// PhaseInstalling and PhaseUpgrading, it is the status of the previous
+	// reconciliation.
+	Status Status
+}
+
+// Subscribe returns a channel that receives a StatusEvent for each change of
+// the installation status. The channel is closed when ctx is cancelled.
+//
+// Each subscriber has its own buffer of 16 events, and publishing never
+// blocks the reconciliation loop. When a subscriber falls behind and its
+// buffer is full, the oldest buffered event is discarded to make room for
+// the new one. The most recent event is therefore always delivered, but
+// intermediate transitions may be lost; subscribers that need the complete
+// state should call Status after receiving an event.
+func (l *Library) Subscribe(ctx context.Context) <-chan StatusEvent {
+	ch := make(chan StatusEvent, subscriberBufferSize)
+
+	l.subscribersMu.Lock()
+	if l.subscribers == nil {
+		l.subscribers = map[chan StatusEvent]struct{}{}
+	}
+	l.subscribers[ch] = struct{}{}
+	l.subscribersMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		l.subscribersMu.Lock()
+		delete(l.subscribers, ch)
+		close(ch)
+		l.subscribersMu.Unlock()
+	}()
+
+	return ch
+}
+
+// publish delivers the event to all subscribers without blocking.
+func (l *Library) publish(event StatusEvent) {
+	l.subscribersMu.Lock()
+	defer l.subscribersMu.Unlock()
+
+	for ch := range l.subscribers {
+		select {
+		case ch <- event:
+			continue
+		default:
+		}
+
+		// the buffer is full; discard the oldest event to make room
+		select {
+		case <-ch:
+		default:
+		}
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// beginReconcile publishes a PhaseInstalling or PhaseUpgrading event when the
+// library starts reconciling a generation it hasn't reconciled before.
+func (l *Library) beginReconcile(generation uint64) {
+	l.statusMu.Lock()
+	current := l.currentStatus
+	if current.Generation == generation && (l.phase == PhaseInstalled || l.phase == PhaseFailed) {
+		l.statusMu.Unlock()
+		return
+	}
+	phase := PhaseInstalling
+	if current.Installed {
+		phase = PhaseUpgrading
+	}
+	event := StatusEvent{
+		Generation:       generation,
+		PreviousPhase:    l.phase,
+		Phase:            phase,
+		PreviousCRDState: current.CRDState,
+		CRDState:         current.CRDState,
+		Status:           current,
+	}
+	l.phase = phase
+	l.statusMu.Unlock()
+
+	l.publish(event)
+}
+
+// setStatus stores the given status and publishes an event if its phase,
+// CRD state or error differ from the previous ones.
+func (l *Library) setStatus(status Status) {
+	l.statusMu.Lock()
+	previous := l.currentStatus
+	previousPhase := l.phase
+	l.currentStatus = status
+	l.phase = status.Phase
+	l.statusMu.Unlock()
+
+	if previousPhase == status.Phase &&
+		previous.CRDState == status.CRDState &&
+		errorMessage(previous.Error) == errorMessage(status.Error) {
+		return
+	}
+
+	l.publish(StatusEvent{
+		Generation:       status.Generation,
+		PreviousPhase:    previousPhase,
+		Phase:            status.Phase,
+		PreviousCRDState: previous.CRDState,
+		CRDState:         status.CRDState,
+		Error:            status.Error,
+		Status:           status,
+	})
+}
+
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,169 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package install
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/istio-ecosystem/sail-operator/pkg/istioversion"
+	"github.com/istio-ecosystem/sail-operator/resources"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	ctrlreconcile "sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestSubscribe_phaseTransitions(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cl := fake.NewClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "istio-system"}},
+	).Build()
+	l := &Library{
+		chartManager: &recordingChartReconciler{},
+		cl:           cl,
+		resourceFS:   resources.FS,
+		triggerCh:    make(chan event.GenericEvent, 1),
+		notifyCh:     make(chan struct{}, 1),
+	}
+	reconciler := &libraryReconciler{lib: l}
+	events := l.Subscribe(ctx)
+
+	l.desiredOpts = &Options{Namespace: "istio-system", Version: istioversion.Default}
+	l.generation = 1
+	_, err := reconciler.Reconcile(ctx, ctrlreconcile.Request{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	ev := <-events
+	g.Expect(ev.Generation).To(Equal(uint64(1)))
+	g.Expect(ev.PreviousPhase).To(BeEmpty())
+	g.Expect(ev.Phase).To(Equal(PhaseInstalling))
+	ev = <-events
+	g.Expect(ev.PreviousPhase).To(Equal(PhaseInstalling))
+	g.Expect(ev.Phase).To(Equal(PhaseInstalled))
+	g.Expect(ev.Status.Installed).To(BeTrue())
+
+	// a reconciliation that doesn't change anything produces no events
+	_, err = reconciler.Reconcile(ctx, ctrlreconcile.Request{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(events).To(BeEmpty())
+
+	l.generation = 2
+	_, err = reconciler.Reconcile(ctx, ctrlreconcile.Request{})
+	g.Expect(err).NotTo(HaveOccurred())
+	ev = <-events
+	g.Expect(ev.Generation).To(Equal(uint64(2)))
+	g.Expect(ev.PreviousPhase).To(Equal(PhaseInstalled))
+	g.Expect(ev.Phase).To(Equal(PhaseUpgrading))
+	ev = <-events
+	g.Expect(ev.Phase).To(Equal(PhaseInstalled))
+
+	// a failing reconciliation reports the error
+	l.desiredOpts = &Options{Namespace: "missing", Version: istioversion.Default}
+	l.generation = 3
+	_, err = reconciler.Reconcile(ctx, ctrlreconcile.Request{})
+	g.Expect(err).To(HaveOccurred())
+	g.Expect((<-events).Phase).To(Equal(PhaseUpgrading))
+	ev = <-events
+	g.Expect(ev.PreviousPhase).To(Equal(PhaseUpgrading))
+	g.Expect(ev.Phase).To(Equal(PhaseFailed))
+	g.Expect(ev.Error).To(MatchError(err))
+
+	g.Expect(l.Uninstall(ctx, "istio-system", "default")).To(Succeed())
+	ev = <-events
+	g.Expect(ev.PreviousPhase).To(Equal(PhaseFailed))
+	g.Expect(ev.Phase).To(Equal(PhaseUninstalled))
+	g.Expect(ev.Error).NotTo(HaveOccurred())
+}
+
+func TestSubscribe_crdStateChange(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	l := &Library{}
+	events := l.Subscribe(ctx)
+
+	l.setStatus(Status{Generation: 1, Phase: PhaseInstalled, CRDState: CRDManagementStateNotReady})
+	<-events
+
+	l.setStatus(Status{Generation: 1, Phase: PhaseInstalled, CRDState: CRDManagementStateReady})
+	ev := <-events
+	g.Expect(ev.PreviousCRDState).To(Equal(CRDManagementStateNotReady))
+	g.Expect(ev.CRDState).To(Equal(CRDManagementStateReady))
+
+	// an identical status is not published
+	l.setStatus(Status{Generation: 1, Phase: PhaseInstalled, CRDState: CRDManagementStateReady})
+	g.Expect(events).To(BeEmpty())
+
+	// a different error is
+	l.setStatus(Status{Generation: 1, Phase: PhaseFailed, CRDState: CRDManagementStateReady, Error: errors.New("first")})
+	<-events
+	l.setStatus(Status{Generation: 1, Phase: PhaseFailed, CRDState: CRDManagementStateReady, Error: errors.New("second")})
+	g.Expect((<-events).Error).To(MatchError("second"))
+}
+
+func TestSubscribe_dropsOldestEventWhenFull(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	l := &Library{}
+	slow := l.Subscribe(ctx)
+	fast := l.Subscribe(ctx)
+
+	const published = subscriberBufferSize + 4
+	var received []uint64
+	for i := uint64(1); i <= published; i++ {
+		l.publish(StatusEvent{Generation: i})
+		received = append(received, (<-fast).Generation)
+	}
+	g.Expect(received).To(HaveLen(published))
+
+	g.Expect(slow).To(HaveLen(subscriberBufferSize))
+	g.Expect((<-slow).Generation).To(Equal(uint64(published - subscriberBufferSize + 1)))
+	var last StatusEvent
+	for len(slow) > 0 {
+		last = <-slow
+	}
+	g.Expect(last.Generation).To(Equal(uint64(published)))
+}
+
+func TestSubscribe_closesChannelWhenContextCancelled(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &Library{}
+	events := l.Subscribe(ctx)
+
+	cancel()
+	g.Eventually(events).Should(BeClosed())
+
+	l.subscribersMu.Lock()
+	g.Expect(l.subscribers).To(BeEmpty())
+	l.subscribersMu.Unlock()
+
+	// publishing after the subscriber is gone must not panic
+	l.publish(StatusEvent{Generation: 1})
+}
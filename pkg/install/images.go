@@ -0,0 +1,28 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package install
+
+import "slices"
+
+// OperandImages returns the istiod, proxy, CNI and ztunnel images of all the versions embedded in the operator,
+// sorted and without duplicates.
+func OperandImages() []string {
+	var images []string
+	for _, v := range imageDigests {
+		images = append(images, v.IstiodImage, v.ProxyImage, v.CNIImage, v.ZTunnelImage)
+	}
+	slices.Sort(images)
+	return slices.Compact(images)
+}
@@ -0,0 +1,34 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package install
+
+import (
+	"slices"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestOperandImages(t *testing.T) {
+	g := NewWithT(t)
+
+	images := OperandImages()
+
+	g.Expect(images).NotTo(BeEmpty())
+	g.Expect(images).To(ContainElement(imageDigests["v1.30.3"].IstiodImage))
+	g.Expect(images).To(ContainElement(imageDigests["v1.30.3"].ZTunnelImage))
+	g.Expect(slices.IsSorted(images)).To(BeTrue())
+	g.Expect(slices.Compact(slices.Clone(images))).To(HaveLen(len(images)))
+}
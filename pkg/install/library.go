@@ -18,9 +18,12 @@ import (
 	"context"
 	"fmt"
 	"io/fs"
+	"maps"
 	"os"
 	"reflect"
+	"slices"
 	"sync"
+	"time"
 
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
@@ -63,6 +66,8 @@ type libraryOptions struct {
 	burst                  int
 	crdOwnershipLabelKey   string
 	crdOwnershipLabelValue string
+	driftDetection         bool
+	driftDebounce          time.Duration
 }
 
 // WithQPS sets the maximum sustained queries-per-second to the API server.
@@ -85,6 +90,22 @@ func WithCRDOwnershipLabel(key, value string) LibraryOption {
 	}
 }
 
+// WithDriftDetection enables or disables drift detection. When enabled (the
+// default), the library watches the resources it installed, identified by
+// the managed-by=sail-library label, and reconciles again whenever they
+// change. When disabled, the library only reconciles on Apply, Enqueue and
+// CRD changes, and the embedder is responsible for detecting drift.
+func WithDriftDetection(enabled bool) LibraryOption {
+	return func(o *libraryOptions) { o.driftDetection = enabled }
+}
+
+// WithDriftDebounce sets how long the library waits after detecting drift
+// before it reconciles. Changes that occur within this window are coalesced
+// into a single reconciliation. Defaults to 0, which reconciles immediately.
+func WithDriftDebounce(debounce time.Duration) LibraryOption {
+	return func(o *libraryOptions) { o.driftDebounce = debounce }
+}
+
 // Options specifies the desired state for the istiod installation.
 type Options struct {
 	Namespace      string
@@ -95,12 +116,159 @@ type Options struct {
 	ManageCRDs     bool
 	IncludeAllCRDs bool
 
+	// Compliance sets the compliance policies of istiod and ztunnel. FIPS
+	// mode defaults to Auto, which enables it if FIPS mode is enabled on the
+	// node that the embedding process runs on.
+	Compliance *v1.Compliance
+
+	// Revisions lists additional istiod revisions to run alongside the one
+	// described by Version, Revision and Values, e.g. the new revision during
+	// a canary upgrade. Revisions removed from the list are uninstalled.
+	Revisions []RevisionOptions
+
+	// Tags maps revision tag names to the name of the revision they point to.
+	// Tags removed from the map are uninstalled.
+	Tags map[string]string
+
+	// CNI, if set, installs the istio-cni node agent alongside istiod.
+	// Setting it to nil after it was installed removes it.
+	CNI *CNIOptions
+
+	// ZTunnel, if set, installs the ztunnel node proxy alongside istiod.
+	// Setting it to nil after it was installed removes it.
+	ZTunnel *ZTunnelOptions
+
+	// CRDUpgradePolicy determines what happens when updating a managed CRD
+	// would remove stored versions or fields, or tighten validation.
+	// Defaults to CRDUpgradePolicyWarn.
+	CRDUpgradePolicy CRDUpgradePolicy
+
+	// MigrateStoredVersions, if true, migrates the objects of a managed CRD
+	// when one of the versions in its status.storedVersions is deprecated, no
+	// longer served or about to be removed. The objects are re-written in the
+	// current storage version and status.storedVersions is then pruned.
+	MigrateStoredVersions bool
+
 	// OverwriteOLMManagedCRD is called when a CRD is detected with OLM
 	// ownership labels. Skipped by optionsEqual since function values
 	// are not comparable.
 	OverwriteOLMManagedCRD OverwriteOLMManagedCRDFunc
 }
 
+// RevisionOptions specifies the desired state of an additional istiod
+// revision. It is installed into Options.Namespace.
+type RevisionOptions struct {
+	// Name of the revision. Must differ from Options.Revision and from the
+	// names of all other revisions.
+	Name string
+	// Version of Istio to install. Defaults to Options.Version.
+	Version string
+	Values  *v1.Values
+}
+
+// CNIOptions specifies the desired state of the istio-cni component.
+// The component is installed with the same version as istiod.
+type CNIOptions struct {
+	// Namespace to install istio-cni into. Defaults to Options.Namespace.
+	Namespace string
+	// Profile is the built-in profile to apply, e.g. "ambient".
+	Profile string
+	Values  *v1.CNIValues
+}
+
+// ZTunnelOptions specifies the desired state of the ztunnel component.
+// The component is installed with the same version as istiod.
+type ZTunnelOptions struct {
+	// Namespace to install ztunnel into. Defaults to Options.Namespace.
+	Namespace string
+	Values    *v1.ZTunnelValues
+}
+
+// revisions returns all istiod revisions described by the options, with
+// the revision described by Version, Revision and Values first.
+func (o Options) revisions() []RevisionOptions {
+	primary := RevisionOptions{Name: o.Revision, Version: o.Version, Values: o.Values}
+	if primary.Name == "" {
+		primary.Name = v1.DefaultRevision
+	}
+	revisions := []RevisionOptions{primary}
+	for _, rev := range o.Revisions {
+		if rev.Version == "" {
+			rev.Version = o.Version
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions
+}
+
+// hasRevision returns true if the options describe a revision with the given name.
+func (o Options) hasRevision(name string) bool {
+	return slices.ContainsFunc(o.revisions(), func(rev RevisionOptions) bool {
+		return rev.Name == name
+	})
+}
+
+// cniNamespace returns the namespace istio-cni is installed into, or an
+// empty string if the component is not requested.
+func (o Options) cniNamespace() string {
+	if o.CNI == nil {
+		return ""
+	}
+	if o.CNI.Namespace != "" {
+		return o.CNI.Namespace
+	}
+	return o.Namespace
+}
+
+// zTunnelNamespace returns the namespace ztunnel is installed into, or an
+// empty string if the component is not requested.
+func (o Options) zTunnelNamespace() string {
+	if o.ZTunnel == nil {
+		return ""
+	}
+	if o.ZTunnel.Namespace != "" {
+		return o.ZTunnel.Namespace
+	}
+	return o.Namespace
+}
+
+// deepCopyOptions returns a copy of opts that shares no mutable state with
+// the original, so that callers can't modify the library's desired state.
+func deepCopyOptions(opts Options) Options {
+	copied := opts
+	if copied.Values != nil {
+		copied.Values = copied.Values.DeepCopy()
+	}
+	if opts.Revisions != nil {
+		copied.Revisions = make([]RevisionOptions, len(opts.Revisions))
+		for i, rev := range opts.Revisions {
+			if rev.Values != nil {
+				rev.Values = rev.Values.DeepCopy()
+			}
+			copied.Revisions[i] = rev
+		}
+	}
+	copied.Tags = maps.Clone(opts.Tags)
+	if opts.Compliance != nil {
+		copied.Compliance = opts.Compliance.DeepCopy()
+	}
+	if opts.CNI != nil {
+		cni := *opts.CNI
+		if cni.Values != nil {
+			cni.Values = cni.Values.DeepCopy()
+		}
+		copied.CNI = &cni
+	}
+	if opts.ZTunnel != nil {
+		zt := *opts.ZTunnel
+		if zt.Values != nil {
+			zt.Values = zt.Values.DeepCopy()
+		}
+		copied.ZTunnel = &zt
+	}
+	return copied
+}
+
 // optionsEqual compares two Options for equality, skipping function
 // fields (OverwriteOLMManagedCRD, TLSConfigFunc). Used by Apply to
 // suppress no-op reconciliation triggers.
@@ -110,12 +278,42 @@ func optionsEqual(a, b Options) bool {
 		a.Revision != b.Revision ||
 		a.ManageCRDs != b.ManageCRDs ||
 		a.IncludeAllCRDs != b.IncludeAllCRDs ||
-		!openShiftTLSEqual(a.OpenShiftTLS, b.OpenShiftTLS) {
+		a.CRDUpgradePolicy != b.CRDUpgradePolicy ||
+		a.MigrateStoredVersions != b.MigrateStoredVersions ||
+		!openShiftTLSEqual(a.OpenShiftTLS, b.OpenShiftTLS) ||
+		!reflect.DeepEqual(a.Compliance, b.Compliance) ||
+		!slices.EqualFunc(a.Revisions, b.Revisions, revisionOptionsEqual) ||
+		!maps.Equal(a.Tags, b.Tags) ||
+		!cniOptionsEqual(a.CNI, b.CNI) ||
+		!zTunnelOptionsEqual(a.ZTunnel, b.ZTunnel) {
 		return false
 	}
 	return reflect.DeepEqual(helm.FromValues(a.Values), helm.FromValues(b.Values))
 }
 
+func revisionOptionsEqual(a, b RevisionOptions) bool {
+	return a.Name == b.Name &&
+		a.Version == b.Version &&
+		reflect.DeepEqual(helm.FromValues(a.Values), helm.FromValues(b.Values))
+}
+
+func cniOptionsEqual(a, b *CNIOptions) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Namespace == b.Namespace &&
+		a.Profile == b.Profile &&
+		reflect.DeepEqual(helm.FromValues(a.Values), helm.FromValues(b.Values))
+}
+
+func zTunnelOptionsEqual(a, b *ZTunnelOptions) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Namespace == b.Namespace &&
+		reflect.DeepEqual(helm.FromValues(a.Values), helm.FromValues(b.Values))
+}
+
 func openShiftTLSEqual(a, b *config.OpenShiftTLS) bool {
 	if a == nil && b == nil {
 		return true
@@ -137,14 +335,40 @@ const (
 	CRDManagementStateError    CRDManagementState = "Error"
 )
 
-// CRDInfo contains information about a managed CRD.
+// CRDInfo contains information about a managed CRD. UpgradeVerdict and
+// UpgradeIssues report the result of the upgrade safety checks performed
+// before the CRD is updated.
 type CRDInfo struct {
-	Name    string
-	Managed bool
-	Ready   bool
+	Name           string
+	Managed        bool
+	Ready          bool
+	UpgradeVerdict CRDUpgradeVerdict
+	UpgradeIssues  []string
+	// MigratedVersions lists the stored versions whose objects were migrated
+	// to the storage version during this reconciliation.
+	MigratedVersions []string
+}
+
+// ComponentStatus contains the state of an optional component (istio-cni
+// or ztunnel) of the installation.
+type ComponentStatus struct {
+	Namespace string
+	Installed bool
+	Error     error
 }
 
-// Status contains the current state of the installation.
+// RevisionStatus contains the state of a single istiod revision.
+type RevisionStatus struct {
+	Name      string
+	Version   string
+	Installed bool
+	Error     error
+}
+
+// Status contains the current state of the installation. Installed is true
+// when all istiod revisions are installed; Revisions lists the state of each
+// of them, starting with the revision described by Options.Revision. CNI and
+// ZTunnel are nil unless the component was requested.
 type Status struct {
 	Generation uint64
 	CRDState   CRDManagementState
@@ -152,6 +376,10 @@ type Status struct {
 	CRDs       []CRDInfo
 	Installed  bool
 	Version    string
+	Phase      Phase
+	Revisions  []RevisionStatus
+	CNI        *ComponentStatus
+	ZTunnel    *ComponentStatus
 	Error      error
 }
 
@@ -170,6 +398,8 @@ type Library struct {
 	platform               config.Platform
 	crdOwnershipLabelKey   string
 	crdOwnershipLabelValue string
+	driftDetection         bool
+	driftDebounce          time.Duration
 
 	triggerCh chan event.GenericEvent
 	notifyCh  chan struct{}
@@ -179,6 +409,16 @@ type Library struct {
 	generation    uint64
 	desiredOpts   *Options
 	currentStatus Status
+	// phase is the phase of the last published event. Guarded by statusMu.
+	phase Phase
+
+	subscribersMu sync.Mutex
+	subscribers   map[chan StatusEvent]struct{}
+
+	// reconciledOpts are the options of the last reconciliation. They are
+	// used to remove optional components that are no longer requested.
+	// Guarded by lifecycleMu.
+	reconciledOpts *Options
 }
 
 // ValidateOptions checks that the provided options are valid.
@@ -186,7 +426,39 @@ func ValidateOptions(opts Options) error {
 	if opts.Namespace == "" {
 		return fmt.Errorf("namespace must not be empty")
 	}
-	return istioversion.ValidateVersion(opts.Version)
+	if err := istioversion.ValidateVersion(opts.Version); err != nil {
+		return err
+	}
+	switch opts.CRDUpgradePolicy {
+	case "", CRDUpgradePolicyWarn, CRDUpgradePolicyRefuse:
+	default:
+		return fmt.Errorf("unknown CRD upgrade policy %q", opts.CRDUpgradePolicy)
+	}
+
+	revisions := opts.revisions()
+	names := make(map[string]bool, len(revisions))
+	for _, rev := range revisions {
+		if rev.Name == "" {
+			return fmt.Errorf("revision name must not be empty")
+		}
+		if names[rev.Name] {
+			return fmt.Errorf("duplicate revision %q", rev.Name)
+		}
+		names[rev.Name] = true
+		if err := istioversion.ValidateVersion(rev.Version); err != nil {
+			return fmt.Errorf("revision %q: %w", rev.Name, err)
+		}
+	}
+
+	for _, tag := range slices.Sorted(maps.Keys(opts.Tags)) {
+		if names[tag] {
+			return fmt.Errorf("revision tag %q conflicts with the revision of the same name", tag)
+		}
+		if target := opts.Tags[tag]; !names[target] {
+			return fmt.Errorf("revision tag %q references unknown revision %q", tag, target)
+		}
+	}
+	return nil
 }
 
 func validateCRDOwnershipLabel(key, value string) error {
@@ -208,6 +480,7 @@ func New(kubeConfig *rest.Config, resourceFS, crdFS fs.FS, opts ...LibraryOption
 		burst:                  defaultBurst,
 		crdOwnershipLabelKey:   defaultCRDOwnershipLabelKey,
 		crdOwnershipLabelValue: defaultCRDOwnershipLabelValue,
+		driftDetection:         true,
 	}
 	for _, fn := range opts {
 		fn(&o)
@@ -215,6 +488,9 @@ func New(kubeConfig *rest.Config, resourceFS, crdFS fs.FS, opts ...LibraryOption
 	if err := validateCRDOwnershipLabel(o.crdOwnershipLabelKey, o.crdOwnershipLabelValue); err != nil {
 		return nil, err
 	}
+	if o.driftDebounce < 0 {
+		return nil, fmt.Errorf("drift debounce must not be negative")
+	}
 
 	cfg := rest.CopyConfig(kubeConfig)
 	cfg.QPS = o.qps
@@ -244,6 +520,8 @@ func New(kubeConfig *rest.Config, resourceFS, crdFS fs.FS, opts ...LibraryOption
 		platform:               platform,
 		crdOwnershipLabelKey:   o.crdOwnershipLabelKey,
 		crdOwnershipLabelValue: o.crdOwnershipLabelValue,
+		driftDetection:         o.driftDetection,
+		driftDebounce:          o.driftDebounce,
 		triggerCh:              make(chan event.GenericEvent, 1),
 		notifyCh:               make(chan struct{}, 1),
 	}, nil
@@ -257,7 +535,8 @@ func FromDirectory(path string) fs.FS {
 // Apply validates the desired installation state and triggers reconciliation.
 // If the options are identical to the previously applied options, this is a
 // no-op — no new reconciliation is triggered. Use Enqueue to force a
-// reconciliation with the current options (e.g. after drift detection).
+// reconciliation with the current options (e.g. when drift detection is
+// disabled and the embedder detected drift itself).
 func (l *Library) Apply(opts Options) error {
 	if err := ValidateOptions(opts); err != nil {
 		return fmt.Errorf("invalid options: %w", err)
@@ -270,10 +549,7 @@ func (l *Library) Apply(opts Options) error {
 		return nil
 	}
 	l.generation++
-	copied := opts
-	if copied.Values != nil {
-		copied.Values = copied.Values.DeepCopy()
-	}
+	copied := deepCopyOptions(opts)
 	l.desiredOpts = &copied
 	l.mu.Unlock()
 	l.sendTrigger()
@@ -307,10 +583,11 @@ func (l *Library) Status() Status {
 	return l.currentStatus
 }
 
-// Uninstall removes the istiod Helm release. It holds the lifecycle lock
-// so that Apply and the reconciliation loop cannot run concurrently,
-// preventing a race where an in-flight reconcile reinstalls istiod
-// immediately after the Helm uninstall completes.
+// Uninstall removes the istiod Helm release, along with all other revisions,
+// revision tags and the istio-cni and ztunnel releases installed by the
+// library. It holds the lifecycle lock so that Apply and the reconciliation
+// loop cannot run concurrently, preventing a race where an in-flight
+// reconcile reinstalls istiod immediately after the Helm uninstall completes.
 // On success, the status is cleared so that Status() reflects the
 // uninstalled state and a subsequent Apply with the same options will
 // trigger a fresh installation.
@@ -324,13 +601,17 @@ func (l *Library) Uninstall(ctx context.Context, namespace, revision string) err
 	l.mu.Unlock()
 
 	log.Infof("Uninstalling: namespace=%s, revision=%s", namespace, revision)
-	if err := inst.uninstall(ctx, namespace, revision); err != nil {
+	if err := inst.pruneComponents(ctx, l.reconciledOpts, nil); err != nil {
 		return err
 	}
+	if l.reconciledOpts == nil || l.reconciledOpts.Namespace != namespace || !l.reconciledOpts.hasRevision(revision) {
+		if err := inst.uninstall(ctx, namespace, revision); err != nil {
+			return err
+		}
+	}
+	l.reconciledOpts = nil
 
-	l.statusMu.Lock()
-	l.currentStatus = Status{}
-	l.statusMu.Unlock()
+	l.setStatus(Status{Phase: PhaseUninstalled})
 	log.Infof("Uninstall complete: namespace=%s, revision=%s", namespace, revision)
 	return nil
 }
@@ -23,6 +23,7 @@ import (
 
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"github.com/istio-ecosystem/sail-operator/pkg/istiovalues"
 	"github.com/istio-ecosystem/sail-operator/pkg/istioversion"
 	"github.com/istio-ecosystem/sail-operator/resources"
 	. "github.com/onsi/gomega"
@@ -109,6 +110,64 @@ func TestValidateOptions(t *testing.T) {
 			opts:    Options{Namespace: "istio-system", Version: "v0.9.0"},
 			wantErr: true,
 		},
+		{
+			name: "additional revisions and tags",
+			opts: Options{
+				Namespace: "istio-system", Version: "v1.0.0", Revision: "old",
+				Revisions: []RevisionOptions{{Name: "new"}},
+				Tags:      map[string]string{"default": "new", "stable": "old"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "additional revision without name",
+			opts: Options{
+				Namespace: "istio-system", Version: "v1.0.0",
+				Revisions: []RevisionOptions{{Version: "v1.0.0"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate revision",
+			opts: Options{
+				Namespace: "istio-system", Version: "v1.0.0",
+				Revisions: []RevisionOptions{{Name: "default"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "additional revision with unsupported version",
+			opts: Options{
+				Namespace: "istio-system", Version: "v1.0.0",
+				Revisions: []RevisionOptions{{Name: "new", Version: "v99.0.0"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "tag references unknown revision",
+			opts: Options{
+				Namespace: "istio-system", Version: "v1.0.0",
+				Tags: map[string]string{"stable": "missing"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "tag conflicts with revision",
+			opts: Options{
+				Namespace: "istio-system", Version: "v1.0.0", Revision: "old",
+				Revisions: []RevisionOptions{{Name: "new"}},
+				Tags:      map[string]string{"new": "old"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown CRD upgrade policy",
+			opts: Options{
+				Namespace: "istio-system", Version: "v1.0.0",
+				CRDUpgradePolicy: "Ignore",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -349,7 +408,7 @@ var _ helm.ChartReconciler = (*slowChartReconciler)(nil)
 
 func (m *slowChartReconciler) UpgradeOrInstallChart(
 	_ context.Context, _ fs.FS, _ string, _ helm.Values,
-	_, _ string, _ *metav1.OwnerReference,
+	_, _ string, _ *metav1.OwnerReference, _ ...helm.InstallOption,
 ) (release.Releaser, error) {
 	m.mu.Lock()
 	m.ops = append(m.ops, "install_start")
@@ -455,3 +514,325 @@ func TestUninstall_raceWithReconcile(t *testing.T) {
 		"expected the last Helm operation to be uninstall, but got ops=%v — "+
 			"this means a concurrent reconcile re-installed istiod after Uninstall", ops)
 }
+
+func TestApply_triggersWhenComponentOptionsChange(t *testing.T) {
+	g := NewWithT(t)
+
+	savedMap := istioversion.Map
+	savedEOL := istioversion.EOL
+	defer func() { istioversion.Map = savedMap; istioversion.EOL = savedEOL }()
+	istioversion.Map = map[string]istioversion.VersionInfo{"v1.0.0": {Name: "v1.0.0"}}
+	istioversion.EOL = nil
+
+	l := &Library{
+		triggerCh: make(chan event.GenericEvent, 1),
+	}
+
+	opts := Options{Namespace: "istio-system", Version: "v1.0.0"}
+	g.Expect(l.Apply(opts)).To(Succeed())
+	<-l.triggerCh
+
+	opts.CNI = &CNIOptions{Namespace: "istio-cni", Profile: "ambient"}
+	g.Expect(l.Apply(opts)).To(Succeed())
+	g.Expect(l.generation).To(Equal(uint64(2)))
+	<-l.triggerCh
+
+	// Mutating the caller's copy must not affect the stored options.
+	opts.CNI.Profile = "default"
+	g.Expect(l.desiredOpts.CNI.Profile).To(Equal("ambient"))
+
+	opts.CNI.Profile = "ambient"
+	g.Expect(l.Apply(opts)).To(Succeed())
+	g.Expect(l.generation).To(Equal(uint64(2)), "generation should not increment when intent is unchanged")
+
+	opts.ZTunnel = &ZTunnelOptions{Namespace: "ztunnel"}
+	g.Expect(l.Apply(opts)).To(Succeed())
+	g.Expect(l.generation).To(Equal(uint64(3)))
+	<-l.triggerCh
+
+	opts.Compliance = &v1.Compliance{FIPS: v1.FIPSModeEnabled}
+	g.Expect(l.Apply(opts)).To(Succeed())
+	g.Expect(l.generation).To(Equal(uint64(4)))
+}
+
+// recordingChartReconciler records the Helm operations performed by the
+// installer in the form "<op> <namespace>/<release>", and the values of the
+// installed releases.
+type recordingChartReconciler struct {
+	ops    []string
+	values map[string]helm.Values
+}
+
+var _ helm.ChartReconciler = (*recordingChartReconciler)(nil)
+
+func (m *recordingChartReconciler) UpgradeOrInstallChart(
+	_ context.Context, _ fs.FS, _ string, values helm.Values,
+	namespace, releaseName string, _ *metav1.OwnerReference, _ ...helm.InstallOption,
+) (release.Releaser, error) {
+	m.ops = append(m.ops, "install "+namespace+"/"+releaseName)
+	if m.values == nil {
+		m.values = map[string]helm.Values{}
+	}
+	m.values[namespace+"/"+releaseName] = values
+	return nil, nil
+}
+
+func (m *recordingChartReconciler) UninstallChart(
+	_ context.Context, releaseName, namespace string,
+) (*release.UninstallReleaseResponse, error) {
+	m.ops = append(m.ops, "uninstall "+namespace+"/"+releaseName)
+	return &release.UninstallReleaseResponse{Info: "ok"}, nil
+}
+
+func TestReconcile_optionalComponents(t *testing.T) {
+	g := NewWithT(t)
+
+	mock := &recordingChartReconciler{}
+	cl := fake.NewClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "istio-system"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "istio-cni"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ztunnel"}},
+	).Build()
+
+	l := &Library{
+		chartManager: mock,
+		cl:           cl,
+		resourceFS:   resources.FS,
+		triggerCh:    make(chan event.GenericEvent, 1),
+		notifyCh:     make(chan struct{}, 1),
+	}
+	reconciler := &libraryReconciler{lib: l}
+
+	l.desiredOpts = &Options{
+		Namespace: "istio-system",
+		Version:   istioversion.Default,
+		Revision:  "test",
+		CNI:       &CNIOptions{Namespace: "istio-cni", Profile: "ambient"},
+		ZTunnel:   &ZTunnelOptions{Namespace: "ztunnel"},
+	}
+	_, err := reconciler.Reconcile(context.Background(), ctrlreconcile.Request{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(mock.ops).To(Equal([]string{
+		"install istio-cni/istio-cni",
+		"install istio-system/test-istiod",
+		"install ztunnel/ztunnel",
+	}))
+
+	status := l.Status()
+	g.Expect(status.Installed).To(BeTrue())
+	g.Expect(status.CNI).To(Equal(&ComponentStatus{Namespace: "istio-cni", Installed: true}))
+	g.Expect(status.ZTunnel).To(Equal(&ComponentStatus{Namespace: "ztunnel", Installed: true}))
+
+	// Dropping CNI and moving ztunnel removes the stale releases first.
+	mock.ops = nil
+	l.desiredOpts = &Options{
+		Namespace: "istio-system",
+		Version:   istioversion.Default,
+		Revision:  "test",
+		ZTunnel:   &ZTunnelOptions{Namespace: "istio-system"},
+	}
+	_, err = reconciler.Reconcile(context.Background(), ctrlreconcile.Request{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(mock.ops).To(Equal([]string{
+		"uninstall ztunnel/ztunnel",
+		"uninstall istio-cni/istio-cni",
+		"install istio-system/test-istiod",
+		"install istio-system/ztunnel",
+	}))
+	g.Expect(l.Status().CNI).To(BeNil())
+
+	// Uninstall removes the remaining components along with istiod.
+	mock.ops = nil
+	g.Expect(l.Uninstall(context.Background(), "istio-system", "test")).To(Succeed())
+	g.Expect(mock.ops).To(Equal([]string{
+		"uninstall istio-system/ztunnel",
+		"uninstall istio-system/test-istiod",
+	}))
+}
+
+func TestReconcile_compliance(t *testing.T) {
+	savedFipsEnabled := istiovalues.FipsEnabled
+	defer func() { istiovalues.FipsEnabled = savedFipsEnabled }()
+	istiovalues.FipsEnabled = true
+
+	tests := []struct {
+		name       string
+		compliance *v1.Compliance
+		expectFips bool
+	}{
+		{name: "auto follows the node", expectFips: true},
+		{name: "disabled", compliance: &v1.Compliance{FIPS: v1.FIPSModeDisabled}},
+		{name: "enabled", compliance: &v1.Compliance{FIPS: v1.FIPSModeEnabled}, expectFips: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			mock := &recordingChartReconciler{}
+			cl := fake.NewClientBuilder().WithObjects(
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "istio-system"}},
+			).Build()
+			l := &Library{
+				chartManager: mock,
+				cl:           cl,
+				resourceFS:   resources.FS,
+				triggerCh:    make(chan event.GenericEvent, 1),
+				notifyCh:     make(chan struct{}, 1),
+			}
+			reconciler := &libraryReconciler{lib: l}
+
+			l.desiredOpts = &Options{
+				Namespace:  "istio-system",
+				Version:    istioversion.Default,
+				Revision:   "test",
+				Compliance: tt.compliance,
+			}
+			_, err := reconciler.Reconcile(context.Background(), ctrlreconcile.Request{})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			values := mock.values["istio-system/test-istiod"]
+			policy, found, err := values.GetString("pilot.env.COMPLIANCE_POLICY")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(found).To(Equal(tt.expectFips))
+			if tt.expectFips {
+				g.Expect(policy).To(Equal("fips-140-2"))
+			}
+		})
+	}
+}
+
+func TestReconcile_stopsWhenComponentFails(t *testing.T) {
+	g := NewWithT(t)
+
+	mock := &recordingChartReconciler{}
+	cl := fake.NewClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "istio-system"}},
+	).Build()
+
+	l := &Library{
+		chartManager: mock,
+		cl:           cl,
+		resourceFS:   resources.FS,
+		triggerCh:    make(chan event.GenericEvent, 1),
+		notifyCh:     make(chan struct{}, 1),
+	}
+	l.desiredOpts = &Options{
+		Namespace: "istio-system",
+		Version:   istioversion.Default,
+		Revision:  "test",
+		CNI:       &CNIOptions{Namespace: "missing"},
+	}
+
+	_, err := (&libraryReconciler{lib: l}).Reconcile(context.Background(), ctrlreconcile.Request{})
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("istio-cni"))
+	g.Expect(mock.ops).To(BeEmpty(), "istiod must not be installed when istio-cni fails")
+
+	status := l.Status()
+	g.Expect(status.Installed).To(BeFalse())
+	g.Expect(status.CNI.Installed).To(BeFalse())
+	g.Expect(status.CNI.Error).To(HaveOccurred())
+}
+
+func TestReconcile_revisionsAndTags(t *testing.T) {
+	g := NewWithT(t)
+
+	mock := &recordingChartReconciler{}
+	cl := fake.NewClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "istio-system"}},
+	).Build()
+
+	l := &Library{
+		chartManager: mock,
+		cl:           cl,
+		resourceFS:   resources.FS,
+		triggerCh:    make(chan event.GenericEvent, 1),
+		notifyCh:     make(chan struct{}, 1),
+	}
+	reconciler := &libraryReconciler{lib: l}
+
+	// Canary: run the new revision alongside the old one and move the default tag to it.
+	l.desiredOpts = &Options{
+		Namespace: "istio-system",
+		Version:   istioversion.Default,
+		Revision:  "old",
+		Revisions: []RevisionOptions{{Name: "new"}},
+		Tags:      map[string]string{"default": "new"},
+	}
+	_, err := reconciler.Reconcile(context.Background(), ctrlreconcile.Request{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(mock.ops).To(Equal([]string{
+		"install istio-system/old-istiod",
+		"install istio-system/new-istiod",
+		"install istio-system/default-revisiontags",
+		"install istio-system/default-base",
+	}))
+	g.Expect(l.Status().Revisions).To(Equal([]RevisionStatus{
+		{Name: "old", Version: istioversion.Default, Installed: true},
+		{Name: "new", Version: istioversion.Default, Installed: true},
+	}))
+
+	// Completing the upgrade prunes the old revision.
+	mock.ops = nil
+	l.desiredOpts = &Options{
+		Namespace: "istio-system",
+		Version:   istioversion.Default,
+		Revision:  "new",
+		Tags:      map[string]string{"default": "new"},
+	}
+	_, err = reconciler.Reconcile(context.Background(), ctrlreconcile.Request{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(mock.ops).To(Equal([]string{
+		"uninstall istio-system/old-istiod",
+		"install istio-system/new-istiod",
+		"install istio-system/default-revisiontags",
+		"install istio-system/default-base",
+	}))
+
+	// Removing the tag uninstalls its releases.
+	mock.ops = nil
+	l.desiredOpts = &Options{
+		Namespace: "istio-system",
+		Version:   istioversion.Default,
+		Revision:  "new",
+	}
+	_, err = reconciler.Reconcile(context.Background(), ctrlreconcile.Request{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(mock.ops).To(Equal([]string{
+		"uninstall istio-system/default-revisiontags",
+		"uninstall istio-system/default-base",
+		"install istio-system/new-istiod",
+	}))
+}
+
+func TestReconcile_failingRevisionDoesNotBlockOthers(t *testing.T) {
+	g := NewWithT(t)
+
+	mock := &recordingChartReconciler{}
+	cl := fake.NewClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "istio-system"}},
+	).Build()
+
+	l := &Library{
+		chartManager: mock,
+		cl:           cl,
+		resourceFS:   resources.FS,
+		triggerCh:    make(chan event.GenericEvent, 1),
+		notifyCh:     make(chan struct{}, 1),
+	}
+	inst := l.newInstaller("istio-system")
+	// the first revision has no values and therefore fails validation
+	revisions := []resolvedRevision{
+		{RevisionOptions: RevisionOptions{Name: "old", Version: istioversion.Default}, resolvedVersion: istioversion.Default},
+		{RevisionOptions: RevisionOptions{Name: "new", Version: istioversion.Default}, resolvedVersion: istioversion.Default, values: &v1.Values{}},
+	}
+
+	statuses, err := inst.reconcileRevisions(context.Background(), "istio-system", revisions)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("values not set"))
+	g.Expect(statuses).To(HaveLen(2))
+	g.Expect(statuses[0].Installed).To(BeFalse())
+	g.Expect(statuses[0].Error).To(HaveOccurred())
+	g.Expect(statuses[1]).To(Equal(RevisionStatus{Name: "new", Version: istioversion.Default, Installed: true}))
+	g.Expect(mock.ops).To(Equal([]string{"install istio-system/new-istiod"}))
+}
@@ -20,7 +20,6 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/istio-ecosystem/sail-operator/pkg/watches"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -39,8 +38,9 @@ var readOnlyTypes = map[reflect.Type]bool{
 
 // LibraryRBACRules returns the RBAC PolicyRules that the library consumer
 // needs to grant to the service account running the library. Rules are
-// derived from watches.IstiodWatches plus static entries for CRDs,
-// namespaces, and Helm release storage.
+// derived from the watch lists of the istiod, istio-cni and ztunnel charts
+// plus static entries for CRDs, namespaces, nodes (used for platform
+// detection), and Helm release storage.
 func LibraryRBACRules() []rbacv1.PolicyRule {
 	type ruleKey struct {
 		apiGroup string
@@ -55,7 +55,7 @@ func LibraryRBACRules() []rbacv1.PolicyRule {
 		}
 	}
 
-	for _, wr := range watches.IstiodWatches {
+	for _, wr := range libraryWatches() {
 		gvks, _, err := clientgoscheme.Scheme.ObjectKinds(wr.Object)
 		if err != nil || len(gvks) == 0 {
 			continue
@@ -69,6 +69,7 @@ func LibraryRBACRules() []rbacv1.PolicyRule {
 	}
 
 	addEntry("", "namespaces", readOnlyVerbs)
+	addEntry("", "nodes", readOnlyVerbs)
 	addEntry("", "endpoints", readOnlyVerbs)
 	addEntry("", "pods", readOnlyVerbs)
 	addEntry("", "secrets", helmManagedVerbs)
@@ -93,3 +94,42 @@ func LibraryRBACRules() []rbacv1.PolicyRule {
 
 	return rules
 }
+
+// istioResources lists, per API group, the resources of the Istio CRDs that
+// the library manages and whose objects a stored version migration rewrites.
+var istioResources = map[string][]string{
+	"extensions.istio.io": {"trafficextensions", "wasmplugins"},
+	"networking.istio.io": {
+		"destinationrules", "envoyfilters", "gateways", "proxyconfigs", "serviceentries",
+		"sidecars", "virtualservices", "workloadentries", "workloadgroups",
+	},
+	"security.istio.io":  {"authorizationpolicies", "peerauthentications", "requestauthentications"},
+	"telemetry.istio.io": {"telemetries"},
+}
+
+// StoredVersionMigrationRBACRules returns the additional RBAC PolicyRules
+// that the library consumer needs to grant when Options.MigrateStoredVersions
+// is enabled: read and re-write access to the Istio custom resources, and
+// permission to prune the stored versions in the CRD status.
+func StoredVersionMigrationRBACRules() []rbacv1.PolicyRule {
+	rules := []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{"apiextensions.k8s.io"},
+			Resources: []string{"customresourcedefinitions/status"},
+			Verbs:     []string{"update", "patch"},
+		},
+	}
+	groups := make([]string, 0, len(istioResources))
+	for group := range istioResources {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{group},
+			Resources: istioResources[group],
+			Verbs:     []string{"get", "list", "update"},
+		})
+	}
+	return rules
+}
@@ -36,7 +36,7 @@ func TestLibraryRBACRules(t *testing.T) {
 	g.Expect(rulesByGroup).To(HaveKey(""))
 	g.Expect(rulesByGroup[""]).To(ContainElements("configmaps", "secrets", "serviceaccounts", "services", "namespaces"))
 	g.Expect(rulesByGroup).To(HaveKey("apps"))
-	g.Expect(rulesByGroup["apps"]).To(ContainElement("deployments"))
+	g.Expect(rulesByGroup["apps"]).To(ContainElements("deployments", "daemonsets"))
 	g.Expect(rulesByGroup).To(HaveKey("apiextensions.k8s.io"))
 	g.Expect(rulesByGroup["apiextensions.k8s.io"]).To(ContainElement("customresourcedefinitions"))
 	g.Expect(rulesByGroup).To(HaveKey("rbac.authorization.k8s.io"))
@@ -77,3 +77,18 @@ func TestLibraryRBACRules_CRDsNoDelete(t *testing.T) {
 	}
 	t.Fatal("customresourcedefinitions rule not found")
 }
+
+func TestStoredVersionMigrationRBACRules(t *testing.T) {
+	g := NewWithT(t)
+	rules := StoredVersionMigrationRBACRules()
+	g.Expect(rules).To(HaveLen(5))
+	g.Expect(rules[0].Resources).To(Equal([]string{"customresourcedefinitions/status"}))
+	for _, rule := range rules {
+		g.Expect(rule.Verbs).NotTo(ContainElements("*", "delete", "create"))
+		g.Expect(rule.Resources).NotTo(ContainElement("*"))
+	}
+	g.Expect(rules[2].APIGroups).To(Equal([]string{"networking.istio.io"}))
+	g.Expect(rules[2].Resources).To(ContainElements("virtualservices", "destinationrules", "envoyfilters"))
+	g.Expect(rules[3].APIGroups).To(Equal([]string{"security.istio.io"}))
+	g.Expect(rules[3].Resources).To(ContainElements("authorizationpolicies", "peerauthentications"))
+}
@@ -16,9 +16,16 @@ package install
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
 
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/istiovalues"
 	"github.com/istio-ecosystem/sail-operator/pkg/istioversion"
 	sharedreconcile "github.com/istio-ecosystem/sail-operator/pkg/reconcile"
 	"github.com/istio-ecosystem/sail-operator/pkg/revision"
@@ -28,6 +35,7 @@ import (
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -58,21 +66,27 @@ func (r *libraryReconciler) Reconcile(ctx context.Context, _ ctrlreconcile.Reque
 		return ctrlreconcile.Result{}, nil
 	}
 
-	optsCopy := *opts
-	if optsCopy.Values != nil {
-		optsCopy.Values = optsCopy.Values.DeepCopy()
-	}
+	optsCopy := deepCopyOptions(*opts)
 
 	log.Info("Reconciling")
+	r.lib.beginReconcile(gen)
 	inst := r.lib.newInstaller(optsCopy.Namespace)
-	status := inst.reconcile(ctx, optsCopy)
+	var status Status
+	if err := inst.pruneComponents(ctx, r.lib.reconciledOpts, &optsCopy); err != nil {
+		status = Status{Version: optsCopy.Version, Error: err}
+	} else {
+		r.lib.reconciledOpts = &optsCopy
+		status = inst.reconcile(ctx, optsCopy)
+	}
 	status.Generation = gen
+	status.Phase = PhaseInstalled
+	if status.Error != nil {
+		status.Phase = PhaseFailed
+	}
 	log.Infof("Reconcile complete: installed=%v, error=%v", status.Installed, status.Error)
 	r.lib.lifecycleMu.Unlock()
 
-	r.lib.statusMu.Lock()
-	r.lib.currentStatus = status
-	r.lib.statusMu.Unlock()
+	r.lib.setStatus(status)
 
 	select {
 	case r.lib.notifyCh <- struct{}{}:
@@ -86,31 +100,87 @@ func (r *libraryReconciler) Reconcile(ctx context.Context, _ ctrlreconcile.Reque
 	return ctrlreconcile.Result{}, nil
 }
 
+// libraryRequest is the single reconcile request used by the library; all
+// events map to it, since the library always reconciles the whole installation.
+var libraryRequest = ctrlreconcile.Request{NamespacedName: types.NamespacedName{Name: "sail-library"}}
+
 func (l *Library) setupController(mgr ctrl.Manager) error {
 	fixedKeyHandler := handler.EnqueueRequestsFromMapFunc(
 		func(_ context.Context, _ client.Object) []ctrlreconcile.Request {
-			return []ctrlreconcile.Request{{NamespacedName: types.NamespacedName{Name: "sail-library"}}}
+			return []ctrlreconcile.Request{libraryRequest}
 		},
 	)
 
-	managedByPred, err := predicate.LabelSelectorPredicate(metav1.LabelSelector{
-		MatchLabels: map[string]string{managedByLabelKey: managedByValue},
-	})
-	if err != nil {
-		return fmt.Errorf("failed to create label predicate: %w", err)
-	}
-
 	b := ctrl.NewControllerManagedBy(mgr).
 		Named("sail-library").
 		WithOptions(controller.Options{SkipNameValidation: ptr.Of(true)}).
 		WatchesRawSource(source.Channel(l.triggerCh, fixedKeyHandler))
 
-	watches.RegisterOwnedWatches(b, watches.IstiodWatches, fixedKeyHandler, nil, managedByPred)
+	if l.driftDetection {
+		managedByPred, err := predicate.LabelSelectorPredicate(metav1.LabelSelector{
+			MatchLabels: map[string]string{managedByLabelKey: managedByValue},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create label predicate: %w", err)
+		}
+
+		driftHandler := handler.EventHandler(fixedKeyHandler)
+		if l.driftDebounce > 0 {
+			driftHandler = &debouncedHandler{delay: l.driftDebounce}
+		}
+
+		watches.RegisterOwnedWatches(b, libraryWatches(), driftHandler, nil, managedByPred)
+	}
+
+	// CRDs are watched even without drift detection, so that the library notices when they become established
 	b.Watches(&apiextensionsv1.CustomResourceDefinition{}, fixedKeyHandler)
 
 	return b.Complete(&libraryReconciler{lib: l})
 }
 
+// debouncedHandler enqueues the library request with a delay. Events that
+// arrive while the request is already waiting are coalesced into it, so a
+// burst of changes results in a single reconciliation.
+type debouncedHandler struct {
+	delay time.Duration
+}
+
+var _ handler.EventHandler = &debouncedHandler{}
+
+func (h *debouncedHandler) Create(_ context.Context, _ event.CreateEvent, q workqueue.TypedRateLimitingInterface[ctrlreconcile.Request]) {
+	q.AddAfter(libraryRequest, h.delay)
+}
+
+func (h *debouncedHandler) Update(_ context.Context, _ event.UpdateEvent, q workqueue.TypedRateLimitingInterface[ctrlreconcile.Request]) {
+	q.AddAfter(libraryRequest, h.delay)
+}
+
+func (h *debouncedHandler) Delete(_ context.Context, _ event.DeleteEvent, q workqueue.TypedRateLimitingInterface[ctrlreconcile.Request]) {
+	q.AddAfter(libraryRequest, h.delay)
+}
+
+func (h *debouncedHandler) Generic(_ context.Context, _ event.GenericEvent, q workqueue.TypedRateLimitingInterface[ctrlreconcile.Request]) {
+	q.AddAfter(libraryRequest, h.delay)
+}
+
+// libraryWatches returns the resource types produced by the istiod, istio-cni
+// and ztunnel charts. Types shared by several charts are listed only once.
+func libraryWatches() []watches.WatchedResource {
+	var result []watches.WatchedResource
+	seen := map[reflect.Type]bool{}
+	for _, list := range [][]watches.WatchedResource{watches.IstiodWatches, watches.CNIWatches, watches.ZTunnelWatches} {
+		for _, wr := range list {
+			t := reflect.TypeOf(wr.Object)
+			if seen[t] {
+				continue
+			}
+			seen[t] = true
+			result = append(result, wr)
+		}
+	}
+	return result
+}
+
 // Start begins the reconciliation loop and drift-detection watches.
 // The returned channel receives a notification each time a reconciliation
 // completes. The loop runs until the context is cancelled or Stop is called.
@@ -158,31 +228,40 @@ func (l *Library) sendTrigger() {
 }
 
 func (l *Library) newInstaller(namespace string) *installer {
+	defaultProfile := ""
+	if l.platform == config.PlatformOpenShift {
+		defaultProfile = "openshift"
+	}
 	cfg := sharedreconcile.Config{
 		ResourceFS:        l.resourceFS,
+		Platform:          l.platform,
+		DefaultProfile:    defaultProfile,
 		ChartManager:      l.chartManager,
 		OperatorNamespace: namespace,
 	}
 	return &installer{
-		istiodReconciler: sharedreconcile.NewIstiodReconciler(cfg, l.cl),
-		crdManager: &crdManager{
-			cl:                  l.cl,
-			crdFS:               l.crdFS,
-			ownershipLabelKey:   l.crdOwnershipLabelKey,
-			ownershipLabelValue: l.crdOwnershipLabelValue,
-		},
-		cfg:      cfg,
-		platform: l.platform,
+		istiodReconciler:  sharedreconcile.NewIstiodReconciler(cfg, l.cl),
+		cniReconciler:     sharedreconcile.NewCNIReconciler(cfg, l.cl),
+		zTunnelReconciler: sharedreconcile.NewZTunnelReconciler(cfg, l.cl),
+		crdManager:        NewCRDManager(l.cl, l.crdFS, l.crdOwnershipLabelKey, l.crdOwnershipLabelValue, false),
+		cfg:               cfg,
+		platform:          l.platform,
 	}
 }
 
 type installer struct {
-	istiodReconciler *sharedreconcile.IstiodReconciler
-	crdManager       *crdManager
-	cfg              sharedreconcile.Config
-	platform         config.Platform
+	istiodReconciler  *sharedreconcile.IstiodReconciler
+	cniReconciler     *sharedreconcile.CNIReconciler
+	zTunnelReconciler *sharedreconcile.ZTunnelReconciler
+	crdManager        *CRDManager
+	cfg               sharedreconcile.Config
+	platform          config.Platform
 }
 
+// reconcile installs the requested components in dependency order: CRDs,
+// istio-cni, the istiod revisions, revision tags and finally ztunnel. It
+// stops at the first component that fails, since the components that follow
+// depend on it.
 func (inst *installer) reconcile(ctx context.Context, opts Options) Status {
 	status := Status{Version: opts.Version}
 
@@ -197,16 +276,6 @@ func (inst *installer) reconcile(ctx context.Context, opts Options) Status {
 		return status
 	}
 
-	revisionName := opts.Revision
-	if revisionName == "" {
-		revisionName = "default"
-	}
-
-	defaultProfile := ""
-	if inst.platform == config.PlatformOpenShift {
-		defaultProfile = "openshift"
-	}
-
 	var tlsCfg *config.TLSConfig
 	if opts.OpenShiftTLS != nil {
 		tlsCfg = config.NewTLSConfigForOpenShift(
@@ -216,20 +285,16 @@ func (inst *installer) reconcile(ctx context.Context, opts Options) Status {
 		)
 	}
 
-	values, err := revision.ComputeValues(
-		opts.Values,
-		opts.Namespace,
-		resolvedVersion,
-		inst.platform,
-		defaultProfile,
-		"",
-		inst.cfg.ResourceFS,
-		revisionName,
-		tlsCfg,
-	)
-	if err != nil {
-		status.Error = fmt.Errorf("failed to compute values: %w", err)
-		return status
+	fipsEnabled := istiovalues.ResolveFipsMode(opts.Compliance)
+
+	var revisions []resolvedRevision
+	for _, rev := range opts.revisions() {
+		resolved, err := inst.resolveRevision(opts.Namespace, rev, tlsCfg, fipsEnabled)
+		if err != nil {
+			status.Error = err
+			return status
+		}
+		revisions = append(revisions, resolved)
 	}
 
 	if opts.ManageCRDs {
@@ -245,20 +310,179 @@ func (inst *installer) reconcile(ctx context.Context, opts Options) Status {
 		status.CRDState, status.CRDMessage = AggregateState(crdInfos)
 	}
 
-	if err := inst.istiodReconciler.Validate(ctx, resolvedVersion, opts.Namespace, values); err != nil {
+	if opts.CNI != nil {
+		status.CNI = inst.reconcileCNI(ctx, resolvedVersion, opts)
+		if status.CNI.Error != nil {
+			status.Error = fmt.Errorf("failed to install istio-cni: %w", status.CNI.Error)
+			return status
+		}
+	}
+
+	status.Revisions, err = inst.reconcileRevisions(ctx, opts.Namespace, revisions)
+	if err != nil {
+		status.Error = err
+		return status
+	}
+
+	status.Installed = true
+
+	if err := inst.reconcileTags(ctx, opts, revisions); err != nil {
 		status.Error = err
 		return status
 	}
 
-	if err := inst.istiodReconciler.Install(ctx, resolvedVersion, opts.Namespace, values, revisionName, nil); err != nil {
-		status.Error = fmt.Errorf("failed to install istiod: %w", err)
+	if opts.ZTunnel != nil {
+		status.ZTunnel = inst.reconcileZTunnel(ctx, resolvedVersion, opts, fipsEnabled)
+		if status.ZTunnel.Error != nil {
+			status.Error = fmt.Errorf("failed to install ztunnel: %w", status.ZTunnel.Error)
+			return status
+		}
+	}
+
+	return status
+}
+
+// resolvedRevision is an istiod revision whose version has been resolved and
+// whose Helm values have been computed.
+type resolvedRevision struct {
+	RevisionOptions
+	resolvedVersion string
+	values          *v1.Values
+}
+
+func (inst *installer) resolveRevision(
+	namespace string, rev RevisionOptions, tlsCfg *config.TLSConfig, fipsEnabled bool,
+) (resolvedRevision, error) {
+	resolvedVersion, err := istioversion.Resolve(rev.Version)
+	if err != nil {
+		return resolvedRevision{}, fmt.Errorf("failed to resolve version of revision %q: %w", rev.Name, err)
+	}
+
+	values, err := revision.ComputeValues(
+		rev.Values,
+		namespace,
+		resolvedVersion,
+		inst.platform,
+		inst.cfg.DefaultProfile,
+		"",
+		inst.cfg.ResourceFS,
+		rev.Name,
+		tlsCfg,
+		fipsEnabled,
+	)
+	if err != nil {
+		return resolvedRevision{}, fmt.Errorf("failed to compute values of revision %q: %w", rev.Name, err)
+	}
+
+	return resolvedRevision{RevisionOptions: rev, resolvedVersion: resolvedVersion, values: values}, nil
+}
+
+// reconcileRevisions installs all istiod revisions. A failing revision does
+// not prevent the others from being installed; the returned error joins the
+// errors of all failed revisions.
+func (inst *installer) reconcileRevisions(ctx context.Context, namespace string, revisions []resolvedRevision) ([]RevisionStatus, error) {
+	statuses := make([]RevisionStatus, 0, len(revisions))
+	var errs []error
+	for _, rev := range revisions {
+		status := RevisionStatus{Name: rev.Name, Version: rev.Version}
+		if err := inst.istiodReconciler.Validate(ctx, rev.resolvedVersion, namespace, rev.values); err != nil {
+			status.Error = err
+		} else if err := inst.istiodReconciler.Install(ctx, rev.resolvedVersion, namespace, rev.values, rev.Name, nil); err != nil {
+			status.Error = fmt.Errorf("failed to install istiod: %w", err)
+		} else {
+			status.Installed = true
+		}
+		if status.Error != nil {
+			errs = append(errs, status.Error)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, errors.Join(errs...)
+}
+
+func (inst *installer) reconcileTags(ctx context.Context, opts Options, revisions []resolvedRevision) error {
+	for _, tag := range slices.Sorted(maps.Keys(opts.Tags)) {
+		target := opts.Tags[tag]
+		i := slices.IndexFunc(revisions, func(rev resolvedRevision) bool { return rev.Name == target })
+		if i < 0 {
+			return fmt.Errorf("revision tag %q references unknown revision %q", tag, target)
+		}
+		rev := revisions[i]
+		if err := inst.istiodReconciler.InstallRevisionTag(ctx, rev.resolvedVersion, opts.Namespace, tag, rev.Name, rev.values, nil); err != nil {
+			return fmt.Errorf("failed to install revision tag %q: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+func (inst *installer) reconcileCNI(ctx context.Context, version string, opts Options) *ComponentStatus {
+	status := &ComponentStatus{Namespace: opts.cniNamespace()}
+	if err := inst.cniReconciler.Validate(ctx, version, status.Namespace); err != nil {
+		status.Error = err
+		return status
+	}
+	if err := inst.cniReconciler.Install(ctx, version, status.Namespace, opts.CNI.Values, opts.CNI.Profile, nil, nil); err != nil {
+		status.Error = err
 		return status
 	}
+	status.Installed = true
+	return status
+}
 
+func (inst *installer) reconcileZTunnel(ctx context.Context, version string, opts Options, fipsEnabled bool) *ComponentStatus {
+	status := &ComponentStatus{Namespace: opts.zTunnelNamespace()}
+	if err := inst.zTunnelReconciler.Validate(ctx, version, status.Namespace); err != nil {
+		status.Error = err
+		return status
+	}
+	if err := inst.zTunnelReconciler.Install(ctx, version, status.Namespace, opts.ZTunnel.Values, nil, fipsEnabled, nil); err != nil {
+		status.Error = err
+		return status
+	}
 	status.Installed = true
 	return status
 }
 
+// pruneComponents uninstalls the revisions, revision tags and optional
+// components that were installed according to prev but are no longer part
+// of desired, or that moved to a different namespace. If desired is nil,
+// everything installed according to prev is removed. Components are removed
+// in reverse dependency order.
+func (inst *installer) pruneComponents(ctx context.Context, prev, desired *Options) error {
+	if prev == nil {
+		return nil
+	}
+	if desired == nil {
+		desired = &Options{}
+	}
+	if ns := prev.zTunnelNamespace(); ns != "" && ns != desired.zTunnelNamespace() {
+		if err := inst.zTunnelReconciler.Uninstall(ctx, ns); err != nil {
+			return err
+		}
+	}
+	sameNamespace := prev.Namespace == desired.Namespace
+	for _, tag := range slices.Sorted(maps.Keys(prev.Tags)) {
+		if _, found := desired.Tags[tag]; !found || !sameNamespace {
+			if err := inst.istiodReconciler.UninstallRevisionTag(ctx, prev.Namespace, tag); err != nil {
+				return err
+			}
+		}
+	}
+	for _, rev := range prev.revisions() {
+		if !sameNamespace || !desired.hasRevision(rev.Name) {
+			if err := inst.istiodReconciler.Uninstall(ctx, prev.Namespace, rev.Name); err != nil {
+				return err
+			}
+		}
+	}
+	if ns := prev.cniNamespace(); ns != "" && ns != desired.cniNamespace() {
+		if err := inst.cniReconciler.Uninstall(ctx, ns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (inst *installer) uninstall(ctx context.Context, namespace, revisionName string) error {
 	return inst.istiodReconciler.Uninstall(ctx, namespace, revisionName)
 }
@@ -15,14 +15,19 @@
 package install
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/istio-ecosystem/sail-operator/pkg/constants"
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	ctrlreconcile "sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 func TestManagedByWatchPredicate(t *testing.T) {
@@ -47,3 +52,35 @@ func TestManagedByWatchPredicate(t *testing.T) {
 	}
 	g.Expect(pred.Create(event.CreateEvent{Object: wrongKey})).To(BeFalse())
 }
+
+func TestDriftDetectionOptions(t *testing.T) {
+	g := NewWithT(t)
+
+	o := libraryOptions{driftDetection: true}
+	WithDriftDetection(false)(&o)
+	WithDriftDebounce(2 * time.Second)(&o)
+	g.Expect(o.driftDetection).To(BeFalse())
+	g.Expect(o.driftDebounce).To(Equal(2 * time.Second))
+
+	_, err := New(&rest.Config{}, nil, nil, WithDriftDebounce(-time.Second))
+	g.Expect(err).To(MatchError("drift debounce must not be negative"))
+}
+
+func TestDebouncedHandlerCoalescesEvents(t *testing.T) {
+	g := NewWithT(t)
+
+	q := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[ctrlreconcile.Request]())
+	defer q.ShutDown()
+
+	h := &debouncedHandler{delay: 100 * time.Millisecond}
+	h.Update(context.Background(), event.UpdateEvent{}, q)
+	h.Delete(context.Background(), event.DeleteEvent{}, q)
+	h.Create(context.Background(), event.CreateEvent{}, q)
+	g.Expect(q.Len()).To(Equal(0), "request must not be enqueued before the debounce delay")
+
+	g.Eventually(q.Len).Should(Equal(1))
+	req, _ := q.Get()
+	g.Expect(req).To(Equal(libraryRequest))
+	q.Done(req)
+	g.Consistently(q.Len, 300*time.Millisecond).Should(Equal(0))
+}
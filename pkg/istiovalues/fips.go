@@ -15,7 +15,9 @@
 package istiovalues
 
 import (
+	"fmt"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
@@ -25,10 +27,15 @@ import (
 )
 
 var (
+	// FipsEnabled is true if FIPS mode is enabled on the node that the operator runs on. It determines whether
+	// FIPS mode is enabled for the components that use FIPSModeAuto.
 	FipsEnabled        bool
 	FipsEnableFilePath = "/proc/sys/crypto/fips_enabled"
 )
 
+// FipsImageVariants lists the image variants that support FIPS mode. The empty string is the default variant.
+var FipsImageVariants = []string{"", "distroless"}
+
 var istio1_30 = semver.MustParse("1.30.0")
 
 // detectFipsMode checks if FIPS mode is enabled in the system.
@@ -52,9 +59,43 @@ func detectFipsMode(filepath string) {
 	}
 }
 
-// ApplyFipsValues sets pilot.env.COMPLIANCE_POLICY if FIPS mode is enabled in the system.
-func ApplyFipsValues(values *v1.Values) {
-	if !FipsEnabled || values == nil {
+// ResolveFipsMode returns whether FIPS mode is enabled for a component with the given compliance policies.
+// FIPS mode is enabled if it's explicitly enabled, or if the mode is Auto (or not set) and FIPS mode is
+// enabled in the system.
+func ResolveFipsMode(compliance *v1.Compliance) bool {
+	mode := v1.FIPSModeAuto
+	if compliance != nil && compliance.FIPS != "" {
+		mode = compliance.FIPS
+	}
+	switch mode {
+	case v1.FIPSModeEnabled:
+		return true
+	case v1.FIPSModeDisabled:
+		return false
+	default:
+		return FipsEnabled
+	}
+}
+
+// FipsComplianceStatus returns the status that reports whether FIPS mode is in effect.
+func FipsComplianceStatus(fipsEnabled bool) *v1.ComplianceStatus {
+	if fipsEnabled {
+		return &v1.ComplianceStatus{FIPS: v1.FIPSModeEnabled}
+	}
+	return &v1.ComplianceStatus{FIPS: v1.FIPSModeDisabled}
+}
+
+// ValidateFipsImageVariant returns an error if FIPS mode is enabled and the given image variant doesn't support it.
+func ValidateFipsImageVariant(fipsEnabled bool, variant *string) error {
+	if !fipsEnabled || variant == nil || slices.Contains(FipsImageVariants, *variant) {
+		return nil
+	}
+	return fmt.Errorf("image variant %q doesn't support FIPS mode; use the default or the distroless variant, or disable FIPS mode", *variant)
+}
+
+// ApplyFipsValues sets pilot.env.COMPLIANCE_POLICY if FIPS mode is enabled.
+func ApplyFipsValues(values *v1.Values, fipsEnabled bool) {
+	if !fipsEnabled || values == nil {
 		return
 	}
 	if values.Pilot == nil {
@@ -68,11 +109,11 @@ func ApplyFipsValues(values *v1.Values) {
 	}
 }
 
-// ApplyZTunnelFipsValues sets ztunnel.env.TLS12_ENABLED if FIPS mode is enabled in the system.
+// ApplyZTunnelFipsValues sets ztunnel.env.TLS12_ENABLED if FIPS mode is enabled.
 // For versions > 1.30, TLS12_ENABLED is removed because ztunnel
 // defaults to using only FIPS 140-3 approved ciphers.
-func ApplyZTunnelFipsValues(values *v1.ZTunnelValues, version string) {
-	if !FipsEnabled || values == nil {
+func ApplyZTunnelFipsValues(values *v1.ZTunnelValues, version string, fipsEnabled bool) {
+	if !fipsEnabled || values == nil {
 		return
 	}
 
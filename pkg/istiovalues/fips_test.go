@@ -21,6 +21,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+
+	"istio.io/istio/pkg/ptr"
 )
 
 func TestDetectFipsMode(t *testing.T) {
@@ -61,6 +63,107 @@ func TestDetectFipsMode(t *testing.T) {
 	}
 }
 
+func TestResolveFipsMode(t *testing.T) {
+	tests := []struct {
+		name            string
+		compliance      *v1.Compliance
+		hostFipsEnabled bool
+		expectEnabled   bool
+	}{
+		{
+			name:            "nil compliance follows the host",
+			compliance:      nil,
+			hostFipsEnabled: true,
+			expectEnabled:   true,
+		},
+		{
+			name:            "empty mode follows the host",
+			compliance:      &v1.Compliance{},
+			hostFipsEnabled: false,
+			expectEnabled:   false,
+		},
+		{
+			name:            "Auto follows the host",
+			compliance:      &v1.Compliance{FIPS: v1.FIPSModeAuto},
+			hostFipsEnabled: true,
+			expectEnabled:   true,
+		},
+		{
+			name:            "Enabled on a non-FIPS host",
+			compliance:      &v1.Compliance{FIPS: v1.FIPSModeEnabled},
+			hostFipsEnabled: false,
+			expectEnabled:   true,
+		},
+		{
+			name:            "Disabled on a FIPS host",
+			compliance:      &v1.Compliance{FIPS: v1.FIPSModeDisabled},
+			hostFipsEnabled: true,
+			expectEnabled:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			originalFipsEnabled := FipsEnabled
+			t.Cleanup(func() { FipsEnabled = originalFipsEnabled })
+			FipsEnabled = tt.hostFipsEnabled
+
+			if actual := ResolveFipsMode(tt.compliance); actual != tt.expectEnabled {
+				t.Errorf("expected FIPS mode enabled to be %v, got %v", tt.expectEnabled, actual)
+			}
+		})
+	}
+}
+
+func TestValidateFipsImageVariant(t *testing.T) {
+	tests := []struct {
+		name        string
+		fipsEnabled bool
+		variant     *string
+		expectErr   bool
+	}{
+		{
+			name:        "FIPS disabled with debug variant",
+			fipsEnabled: false,
+			variant:     ptr.Of("debug"),
+			expectErr:   false,
+		},
+		{
+			name:        "FIPS enabled with default variant",
+			fipsEnabled: true,
+			variant:     nil,
+			expectErr:   false,
+		},
+		{
+			name:        "FIPS enabled with empty variant",
+			fipsEnabled: true,
+			variant:     ptr.Of(""),
+			expectErr:   false,
+		},
+		{
+			name:        "FIPS enabled with distroless variant",
+			fipsEnabled: true,
+			variant:     ptr.Of("distroless"),
+			expectErr:   false,
+		},
+		{
+			name:        "FIPS enabled with debug variant",
+			fipsEnabled: true,
+			variant:     ptr.Of("debug"),
+			expectErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFipsImageVariant(tt.fipsEnabled, tt.variant)
+			if tt.expectErr != (err != nil) {
+				t.Errorf("expected error: %v, got: %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
 func TestApplyFipsValues(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -125,10 +228,7 @@ func TestApplyFipsValues(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			originalFipsEnabled := FipsEnabled
-			t.Cleanup(func() { FipsEnabled = originalFipsEnabled })
-			FipsEnabled = tt.fipsEnabled
-			ApplyFipsValues(tt.inputValues)
+			ApplyFipsValues(tt.inputValues, tt.fipsEnabled)
 
 			if diff := cmp.Diff(tt.expectValues, tt.inputValues); diff != "" {
 				t.Errorf("COMPLIANCE_POLICY env wasn't applied properly; diff (-expected, +actual):\n%v", diff)
@@ -252,10 +352,7 @@ func TestApplyZTunnelFipsValues(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			originalFipsEnabled := FipsEnabled
-			t.Cleanup(func() { FipsEnabled = originalFipsEnabled })
-			FipsEnabled = tt.fipsEnabled
-			ApplyZTunnelFipsValues(tt.inputValues, tt.version)
+			ApplyZTunnelFipsValues(tt.inputValues, tt.version, tt.fipsEnabled)
 
 			if diff := cmp.Diff(tt.expectValues, tt.inputValues); diff != "" {
 				t.Errorf("TLS12_ENABLED env wasn't applied properly; diff (-expected, +actual):\n%v", diff)
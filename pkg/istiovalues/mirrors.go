@@ -0,0 +1,91 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istiovalues
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"gopkg.in/yaml.v3"
+)
+
+// chartDefaultsKey is the key under which the charts nest their default values
+const chartDefaultsKey = "_internal_defaults_do_not_set"
+
+// ApplyRegistryMirrors rewrites the images and hubs at the given keys of the final Helm values of the chart
+// according to the registry mirrors in the operator configuration. A key that isn't set in the values is rewritten
+// from the default in the chart's values.yaml, so that the images the chart pulls by default are mirrored as well.
+// The values are left intact; if any image is rewritten, a modified copy is returned.
+func ApplyRegistryMirrors(values helm.Values, cfg config.OperatorConfig, resourceFS fs.FS, chartPath string, keys ...string) (helm.Values, error) {
+	if len(cfg.RegistryMirrors) == 0 {
+		return values, nil
+	}
+
+	defaults, err := getChartDefaults(resourceFS, chartPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result, copied := values, false
+	for _, key := range keys {
+		image, found, err := values.GetString(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get value %s: %w", key, err)
+		}
+		if !found {
+			if image, _, err = defaults.GetString(key); err != nil {
+				return nil, fmt.Errorf("failed to get default value %s of chart %s: %w", key, chartPath, err)
+			}
+		}
+		if rewritten := cfg.RewriteImage(image); rewritten != image {
+			if !copied {
+				// copy the values, since they may share nested maps with e.g. the spec of the resource
+				if result = helm.FromValues(values); result == nil {
+					result = helm.Values{}
+				}
+				copied = true
+			}
+			if err := result.Set(key, rewritten); err != nil {
+				return nil, fmt.Errorf("failed to set value %s: %w", key, err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// getChartDefaults returns the default values in the values.yaml file of the chart. A chart without a values.yaml
+// file has no defaults.
+func getChartDefaults(resourceFS fs.FS, chartPath string) (helm.Values, error) {
+	file := path.Join(chartPath, "values.yaml")
+	fileContents, err := fs.ReadFile(resourceFS, file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return helm.Values{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read chart values file %v: %w", file, err)
+	}
+
+	var values map[string]any
+	if err := yaml.Unmarshal(fileContents, &values); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal chart values file %v: %w", file, err)
+	}
+	if defaults, ok := values[chartDefaultsKey].(map[string]any); ok {
+		return defaults, nil
+	}
+	return values, nil
+}
@@ -0,0 +1,141 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istiovalues
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"github.com/stretchr/testify/assert"
+)
+
+var mirrorConfig = config.OperatorConfig{
+	RegistryMirrors: map[string]config.RegistryMirror{
+		"release": {Source: "registry.istio.io/release", Mirror: "registry.example.com/istio"},
+	},
+}
+
+var mirrorChartFS = fstest.MapFS{
+	"v1.30.3/charts/istiod/values.yaml": &fstest.MapFile{Data: []byte(`
+_internal_defaults_do_not_set:
+  hub: ""
+  image: pilot
+  global:
+    hub: registry.istio.io/release
+    proxy:
+      image: proxyv2
+`)},
+}
+
+const mirrorChartPath = "v1.30.3/charts/istiod"
+
+var mirrorKeys = []string{"global.hub", "global.proxy.image", "pilot.hub", "pilot.image"}
+
+func TestApplyRegistryMirrors(t *testing.T) {
+	testCases := []struct {
+		name         string
+		config       config.OperatorConfig
+		chartPath    string
+		inputValues  helm.Values
+		expectValues helm.Values
+	}{
+		{
+			name:         "no-mirrors",
+			config:       config.OperatorConfig{},
+			chartPath:    mirrorChartPath,
+			inputValues:  helm.Values{"pilot": map[string]any{"image": "registry.istio.io/release/pilot:1.30.3"}},
+			expectValues: helm.Values{"pilot": map[string]any{"image": "registry.istio.io/release/pilot:1.30.3"}},
+		},
+		{
+			name:         "chart-default-hub",
+			config:       mirrorConfig,
+			chartPath:    mirrorChartPath,
+			inputValues:  nil,
+			expectValues: helm.Values{"global": map[string]any{"hub": "registry.example.com/istio"}},
+		},
+		{
+			name:      "digests",
+			config:    mirrorConfig,
+			chartPath: mirrorChartPath,
+			inputValues: helm.Values{
+				"pilot": map[string]any{"image": "registry.istio.io/release/pilot@sha256:abc123"},
+				"global": map[string]any{
+					"hub":   "registry.istio.io/release",
+					"proxy": map[string]any{"image": "registry.istio.io/release/proxyv2@sha256:def456"},
+				},
+			},
+			expectValues: helm.Values{
+				"pilot": map[string]any{"image": "registry.example.com/istio/pilot@sha256:abc123"},
+				"global": map[string]any{
+					"hub":   "registry.example.com/istio",
+					"proxy": map[string]any{"image": "registry.example.com/istio/proxyv2@sha256:def456"},
+				},
+			},
+		},
+		{
+			name:      "user-supplied-hub",
+			config:    mirrorConfig,
+			chartPath: mirrorChartPath,
+			inputValues: helm.Values{
+				"pilot":  map[string]any{"hub": "registry.istio.io/release"},
+				"global": map[string]any{"hub": "docker.io/istio", "tag": "1.30.3"},
+			},
+			expectValues: helm.Values{
+				"pilot":  map[string]any{"hub": "registry.example.com/istio"},
+				"global": map[string]any{"hub": "docker.io/istio", "tag": "1.30.3"},
+			},
+		},
+		{
+			name:         "chart-without-values",
+			config:       mirrorConfig,
+			chartPath:    "v1.30.3/charts/gateway",
+			inputValues:  helm.Values{"pilot": map[string]any{"image": "pilot"}},
+			expectValues: helm.Values{"pilot": map[string]any{"image": "pilot"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ApplyRegistryMirrors(tc.inputValues, tc.config, mirrorChartFS, tc.chartPath, mirrorKeys...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectValues, result); diff != "" {
+				t.Errorf("unexpected result; diff (-expected, +actual):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestApplyRegistryMirrorsKeepsSharedValues(t *testing.T) {
+	proxy := map[string]any{"image": "registry.istio.io/release/proxyv2:1.30.3"}
+	values := helm.Values{"global": map[string]any{"proxy": proxy}}
+
+	result, err := ApplyRegistryMirrors(values, mirrorConfig, mirrorChartFS, mirrorChartPath, mirrorKeys...)
+	assert.NoError(t, err)
+
+	image, _, _ := result.GetString("global.proxy.image")
+	assert.Equal(t, "registry.example.com/istio/proxyv2:1.30.3", image)
+	assert.Equal(t, "registry.istio.io/release/proxyv2:1.30.3", proxy["image"])
+}
+
+func TestApplyRegistryMirrorsInvalidValue(t *testing.T) {
+	values := helm.Values{"global": map[string]any{"hub": 1}}
+
+	_, err := ApplyRegistryMirrors(values, mirrorConfig, mirrorChartFS, mirrorChartPath, mirrorKeys...)
+	assert.Error(t, err)
+}
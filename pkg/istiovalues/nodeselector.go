@@ -0,0 +1,97 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istiovalues
+
+import (
+	"sort"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ApplyCNINodeSelector restricts the istio-cni-node Pods to the nodes whose labels match the selector, in addition
+// to any node affinity set by the user. The given values are not modified.
+func ApplyCNINodeSelector(values *v1.CNIValues, selector map[string]string) *v1.CNIValues {
+	if len(selector) == 0 {
+		return values
+	}
+
+	if values == nil {
+		values = &v1.CNIValues{}
+	} else {
+		values = values.DeepCopy()
+	}
+	if values.Cni == nil {
+		values.Cni = &v1.CNIConfig{}
+	}
+	values.Cni.Affinity = requireNodeLabels(values.Cni.Affinity, selector)
+	return values
+}
+
+// ApplyZTunnelNodeSelector restricts the ztunnel Pods to the nodes whose labels match the selector, in addition
+// to any node affinity set by the user. The given values are not modified.
+func ApplyZTunnelNodeSelector(values *v1.ZTunnelValues, selector map[string]string) *v1.ZTunnelValues {
+	if len(selector) == 0 {
+		return values
+	}
+
+	if values == nil {
+		values = &v1.ZTunnelValues{}
+	} else {
+		values = values.DeepCopy()
+	}
+	if values.ZTunnel == nil {
+		values.ZTunnel = &v1.ZTunnelConfig{}
+	}
+	values.ZTunnel.Affinity = requireNodeLabels(values.ZTunnel.Affinity, selector)
+	return values
+}
+
+// requireNodeLabels adds the labels to every required node selector term of the affinity. Since the terms are
+// ORed, each of them must require the labels for the Pods to only be scheduled to the matching nodes.
+func requireNodeLabels(affinity *corev1.Affinity, labels map[string]string) *corev1.Affinity {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	requirements := make([]corev1.NodeSelectorRequirement, 0, len(keys))
+	for _, key := range keys {
+		requirements = append(requirements, corev1.NodeSelectorRequirement{
+			Key:      key,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{labels[key]},
+		})
+	}
+
+	if affinity == nil {
+		affinity = &corev1.Affinity{}
+	}
+	if affinity.NodeAffinity == nil {
+		affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	if affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	nodeSelector := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(nodeSelector.NodeSelectorTerms) == 0 {
+		nodeSelector.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+	}
+	for i := range nodeSelector.NodeSelectorTerms {
+		term := &nodeSelector.NodeSelectorTerms[i]
+		term.MatchExpressions = append(term.MatchExpressions, requirements...)
+	}
+	return affinity
+}
@@ -0,0 +1,104 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istiovalues
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestApplyCNINodeSelector(t *testing.T) {
+	selector := map[string]string{"pool": "gpu", "arch": "amd64"}
+	required := []corev1.NodeSelectorRequirement{
+		{Key: "arch", Operator: corev1.NodeSelectorOpIn, Values: []string{"amd64"}},
+		{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"gpu"}},
+	}
+	userTerm := func(zone string) corev1.NodeSelectorTerm {
+		return corev1.NodeSelectorTerm{
+			MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{zone}},
+			},
+		}
+	}
+	withTerms := func(terms ...corev1.NodeSelectorTerm) *corev1.Affinity {
+		return &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: terms},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		values   *v1.CNIValues
+		selector map[string]string
+		expected *v1.CNIValues
+	}{
+		{
+			name:     "no selector",
+			values:   &v1.CNIValues{Cni: &v1.CNIConfig{}},
+			expected: &v1.CNIValues{Cni: &v1.CNIConfig{}},
+		},
+		{
+			name:     "no values",
+			selector: selector,
+			expected: &v1.CNIValues{Cni: &v1.CNIConfig{
+				Affinity: withTerms(corev1.NodeSelectorTerm{MatchExpressions: required}),
+			}},
+		},
+		{
+			name:     "user affinity",
+			values:   &v1.CNIValues{Cni: &v1.CNIConfig{Affinity: withTerms(userTerm("a"), userTerm("b"))}},
+			selector: selector,
+			expected: &v1.CNIValues{Cni: &v1.CNIConfig{Affinity: withTerms(
+				corev1.NodeSelectorTerm{MatchExpressions: append(userTerm("a").MatchExpressions, required...)},
+				corev1.NodeSelectorTerm{MatchExpressions: append(userTerm("b").MatchExpressions, required...)},
+			)}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			original := tc.values.DeepCopy()
+			actual := ApplyCNINodeSelector(tc.values, tc.selector)
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected values; diff (-expected, +actual):\n%v", diff)
+			}
+			assert.Equal(t, original, tc.values, "input values must not be modified")
+		})
+	}
+}
+
+func TestApplyZTunnelNodeSelector(t *testing.T) {
+	actual := ApplyZTunnelNodeSelector(nil, map[string]string{"pool": "gpu"})
+	expected := &v1.ZTunnelValues{ZTunnel: &v1.ZTunnelConfig{Affinity: &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"gpu"}},
+					},
+				}},
+			},
+		},
+	}}}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected values; diff (-expected, +actual):\n%v", diff)
+	}
+}
@@ -41,8 +41,8 @@ func ApplyProfilesAndPlatform(
 	}
 	values := helm.Values(MergeOverwrite(defaultValues, userValues))
 
-	if platform != config.PlatformKubernetes && platform != config.PlatformUndefined {
-		if err = values.SetIfAbsent("global.platform", string(platform)); err != nil {
+	if helmPlatform := platform.HelmPlatform(); helmPlatform != "" {
+		if err = values.SetIfAbsent("global.platform", helmPlatform); err != nil {
 			return nil, fmt.Errorf("failed to set global.platform: %w", err)
 		}
 	}
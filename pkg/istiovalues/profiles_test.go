@@ -19,10 +19,13 @@ import (
 	"os"
 	"path"
 	"testing"
+	"testing/fstest"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
 	"github.com/istio-ecosystem/sail-operator/pkg/helm"
 	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 func TestGetValuesFromProfiles(t *testing.T) {
@@ -255,6 +258,48 @@ func TestResolve(t *testing.T) {
 	}
 }
 
+func TestApplyProfilesAndPlatform(t *testing.T) {
+	resourceFS := fstest.MapFS{
+		"my-version/profiles/default.yaml": &fstest.MapFile{Data: []byte(`
+apiVersion: sailoperator.io/v1
+kind: IstioProfile
+spec:
+  values:
+    pilot:
+      enabled: true`)},
+	}
+
+	tests := []struct {
+		platform       config.Platform
+		userValues     helm.Values
+		expectPlatform string
+	}{
+		{platform: config.PlatformUndefined},
+		{platform: config.PlatformKubernetes},
+		{platform: config.PlatformEKS},
+		{platform: config.PlatformAKS},
+		{platform: config.PlatformOpenShift, expectPlatform: "openshift"},
+		{platform: config.PlatformGKE, expectPlatform: "gke"},
+		{platform: config.PlatformK3d, expectPlatform: "k3d"},
+		{platform: config.PlatformMinikube, expectPlatform: "minikube"},
+		{
+			platform:       config.PlatformGKE,
+			userValues:     helm.Values{"global": map[string]any{"platform": "k3s"}},
+			expectPlatform: "k3s",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(string(tc.platform), func(t *testing.T) {
+			g := NewWithT(t)
+			values, err := ApplyProfilesAndPlatform(resourceFS, "my-version", tc.platform, "default", "", tc.userValues)
+			g.Expect(err).NotTo(HaveOccurred())
+			platform, _, err := unstructured.NestedString(values, "global", "platform")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(platform).To(Equal(tc.expectPlatform))
+		})
+	}
+}
+
 func Must(t *testing.T, err error) {
 	t.Helper()
 	if err != nil {
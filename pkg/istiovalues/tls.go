@@ -16,11 +16,14 @@ package istiovalues
 
 import (
 	"crypto/tls"
+	"fmt"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/go-logr/logr"
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
 	configv1 "github.com/openshift/api/config/v1"
 
 	"istio.io/istio/pkg/log"
@@ -75,10 +78,10 @@ func ApplyTLSConfig(tlsConfig *config.TLSConfig, istioVersion string, values *v1
 		values.MeshConfig.MeshMTLS.CipherSuites = nil
 	}
 
-	// Configure the Ecdhcurves if they are available on Openshift configuration. Normalize before
+	// Configure the Ecdhcurves if they are available on the TLS profile. Normalize before
 	// copying the names, given Openshift allows a different naming from NIST
-	if tlsConfig.OpenShift != nil && len(tlsConfig.OpenShift.TLSProfileSpec.Groups) > 0 {
-		ecdhCurves := copyECDHCurvesToConfig(tlsConfig.OpenShift.TLSProfileSpec.Groups)
+	if len(tlsConfig.Groups) > 0 {
+		ecdhCurves := copyECDHCurvesToConfig(tlsConfig.Groups)
 		if len(values.MeshConfig.TlsDefaults.EcdhCurves) == 0 {
 			values.MeshConfig.TlsDefaults.EcdhCurves = ecdhCurves
 		}
@@ -107,6 +110,67 @@ func ApplyTLSConfig(tlsConfig *config.TLSConfig, istioVersion string, values *v1
 	}
 }
 
+// ResolveTLSConfig returns the TLS settings for the given spec.tlsProfile of an Istio, and the status that reports
+// them. The profile takes precedence over the platform TLS settings, e.g. the TLS profile of the OpenShift
+// APIServer, which are returned if the profile is nil.
+func ResolveTLSConfig(profile *v1.TLSProfile, platform *config.TLSConfig, log logr.Logger) (*config.TLSConfig, *v1.TLSProfileStatus, error) {
+	if profile == nil {
+		if platform == nil || platform.OpenShift == nil || len(platform.CipherSuites) == 0 {
+			return platform, nil, nil
+		}
+		return platform, newTLSProfileStatus(v1.TLSProfileSourceAPIServer, "", platform.OpenShift.TLSProfileSpec, platform), nil
+	}
+
+	profileSpec, err := tlsProfileSpec(profile)
+	if err != nil {
+		return nil, nil, err
+	}
+	tlsConfig := config.NewTLSConfigFromProfile(profileSpec, log)
+	if len(tlsConfig.CipherSuites) == 0 {
+		return nil, nil, reconciler.NewValidationError("spec.tlsProfile doesn't contain any supported cipher suite")
+	}
+	return tlsConfig, newTLSProfileStatus(v1.TLSProfileSourceIstio, profile.Type, profileSpec, tlsConfig), nil
+}
+
+// tlsProfileSpec converts the TLS profile to the profile spec used by OpenShift, which defines the predefined profiles.
+func tlsProfileSpec(profile *v1.TLSProfile) (configv1.TLSProfileSpec, error) {
+	if profile.Type != v1.TLSProfileCustom {
+		if profileSpec, ok := configv1.TLSProfiles[configv1.TLSProfileType(profile.Type)]; ok {
+			return *profileSpec, nil
+		}
+		return configv1.TLSProfileSpec{}, reconciler.NewValidationError(fmt.Sprintf("unknown spec.tlsProfile.type %q", profile.Type))
+	}
+	if profile.Custom == nil {
+		return configv1.TLSProfileSpec{}, reconciler.NewValidationError("spec.tlsProfile.custom must be set when type is Custom")
+	}
+
+	profileSpec := configv1.TLSProfileSpec{
+		Ciphers:       profile.Custom.Ciphers,
+		MinTLSVersion: configv1.TLSProtocolVersion(profile.Custom.MinTLSVersion),
+	}
+	for _, curve := range profile.Custom.Curves {
+		profileSpec.Groups = append(profileSpec.Groups, configv1.TLSGroup(curve))
+	}
+	return profileSpec, nil
+}
+
+func newTLSProfileStatus(source v1.TLSProfileSource, profileType v1.TLSProfileType, profileSpec configv1.TLSProfileSpec,
+	tlsConfig *config.TLSConfig,
+) *v1.TLSProfileStatus {
+	status := &v1.TLSProfileStatus{
+		Source:        source,
+		Type:          profileType,
+		MinTLSVersion: string(profileSpec.MinTLSVersion),
+	}
+	for _, id := range tlsConfig.CipherSuites {
+		status.Ciphers = append(status.Ciphers, tls.CipherSuiteName(id))
+	}
+	for _, group := range tlsConfig.Groups {
+		status.Curves = append(status.Curves, string(group))
+	}
+	return status
+}
+
 func tlsProtocolVersion(v uint16) v1.MeshConfigTLSConfigTLSProtocol {
 	switch v {
 	case tls.VersionTLS12:
@@ -264,15 +264,11 @@ func TestApplyTLSConfig(t *testing.T) {
 			tlsConfig: &config.TLSConfig{
 				CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
 				MinVersion:   tls.VersionTLS13,
-				OpenShift: &config.OpenShiftTLS{
-					TLSProfileSpec: configv1.TLSProfileSpec{
-						Groups: []configv1.TLSGroup{
-							configv1.TLSGroupX25519MLKEM768,
-							configv1.TLSGroupX25519,
-							configv1.TLSGroupSecP256r1,
-							configv1.TLSGroupSecP384r1,
-						},
-					},
+				Groups: []configv1.TLSGroup{
+					configv1.TLSGroupX25519MLKEM768,
+					configv1.TLSGroupX25519,
+					configv1.TLSGroupSecP256r1,
+					configv1.TLSGroupSecP384r1,
 				},
 			},
 			istioVersion: "1.30.0",
@@ -0,0 +1,151 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istioversion
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// Component identifies a node component that is installed independently of the control plane and whose
+// version can therefore differ from the version of the control plane.
+type Component string
+
+const (
+	ComponentCNI     Component = "IstioCNI"
+	ComponentZTunnel Component = "ZTunnel"
+)
+
+// DefaultSkewPolicy is used for components that have no skew policy in versions.yaml. The component may be
+// one minor version older than the control plane, but never newer.
+var DefaultSkewPolicy = SkewPolicy{Older: 1, Newer: 0}
+
+// SkewPolicy defines the supported version skew between a component and the control plane, in minor versions.
+type SkewPolicy struct {
+	// Older is the number of minor versions the component may be behind the control plane.
+	Older int `json:"older"`
+	// Newer is the number of minor versions the component may be ahead of the control plane.
+	Newer int `json:"newer"`
+}
+
+// SkewPolicies represents the skew section of versions.yaml
+type SkewPolicies struct {
+	CNI     *SkewPolicy `json:"cni,omitempty"`
+	ZTunnel *SkewPolicy `json:"ztunnel,omitempty"`
+}
+
+// skewPolicies contains the skew policy of each component
+var skewPolicies map[Component]SkewPolicy
+
+func mustParseSkewPolicies(yamlBytes []byte) map[Component]SkewPolicy {
+	versions := Versions{}
+	if err := yaml.Unmarshal(yamlBytes, &versions); err != nil {
+		panic(fmt.Errorf("failed to parse versions data: %w", err))
+	}
+
+	policies := map[Component]SkewPolicy{
+		ComponentCNI:     DefaultSkewPolicy,
+		ComponentZTunnel: DefaultSkewPolicy,
+	}
+	for component, policy := range map[Component]*SkewPolicy{ComponentCNI: versions.Skew.CNI, ComponentZTunnel: versions.Skew.ZTunnel} {
+		if policy == nil {
+			continue
+		}
+		if policy.Older < 0 || policy.Newer < 0 {
+			panic(fmt.Errorf("skew policy for %s must not be negative", component))
+		}
+		policies[component] = *policy
+	}
+	return policies
+}
+
+// GetSkewPolicy returns the supported version skew between the component and the control plane.
+func GetSkewPolicy(component Component) SkewPolicy {
+	if policy, ok := skewPolicies[component]; ok {
+		return policy
+	}
+	return DefaultSkewPolicy
+}
+
+// SkewError is returned by CheckSkew when the version of a component is outside the skew supported by the
+// version of the control plane.
+type SkewError struct {
+	Component           Component
+	ComponentVersion    string
+	ControlPlaneVersion string
+	Policy              SkewPolicy
+}
+
+func (e *SkewError) Error() string {
+	return fmt.Sprintf("%s version %s is not supported with control plane version %s; "+
+		"the %s version may be at most %d minor version(s) older and %d minor version(s) newer than the control plane",
+		e.Component, e.ComponentVersion, e.ControlPlaneVersion, e.Component, e.Policy.Older, e.Policy.Newer)
+}
+
+// CheckSkew returns a *SkewError if the component version is outside the skew supported by the control plane
+// version. Versions that are not listed in versions.yaml, such as end-of-life versions, aren't checked.
+func CheckSkew(component Component, controlPlaneVersion, componentVersion string) error {
+	controlPlane, ok := Map[controlPlaneVersion]
+	if !ok {
+		return nil
+	}
+	comp, ok := Map[componentVersion]
+	if !ok {
+		return nil
+	}
+	policy := GetSkewPolicy(component)
+	if !withinSkew(policy, controlPlane.Version, comp.Version) {
+		return &SkewError{
+			Component:           component,
+			ComponentVersion:    componentVersion,
+			ControlPlaneVersion: controlPlaneVersion,
+			Policy:              policy,
+		}
+	}
+	return nil
+}
+
+// CompatibleVersions returns the names of the supported versions of the component that can be used with the
+// specified control plane version. Together with List, it forms the compatibility matrix derived from
+// versions.yaml. Aliases aren't included.
+func CompatibleVersions(component Component, controlPlaneVersion string) []string {
+	controlPlane, ok := Map[controlPlaneVersion]
+	if !ok {
+		return nil
+	}
+	policy := GetSkewPolicy(component)
+	var compatible []string
+	for _, v := range List {
+		if withinSkew(policy, controlPlane.Version, v.Version) {
+			compatible = append(compatible, v.Name)
+		}
+	}
+	return compatible
+}
+
+// withinSkew returns true if the minor version distance between the component and the control plane is within
+// the policy. Components of a different major version are never supported.
+func withinSkew(policy SkewPolicy, controlPlane, component *semver.Version) bool {
+	if controlPlane == nil || component == nil {
+		return true
+	}
+	if controlPlane.Major() != component.Major() {
+		return false
+	}
+	diff := int64(component.Minor()) - int64(controlPlane.Minor())
+	return diff >= -int64(policy.Older) && diff <= int64(policy.Newer)
+}
@@ -0,0 +1,102 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istioversion
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSkewPolicies(t *testing.T) {
+	policies := mustParseSkewPolicies([]byte(`
+skew:
+  ztunnel:
+    older: 2
+    newer: 1
+versions: []
+`))
+	assert.Equal(t, map[Component]SkewPolicy{
+		ComponentCNI:     DefaultSkewPolicy,
+		ComponentZTunnel: {Older: 2, Newer: 1},
+	}, policies)
+
+	assert.Panics(t, func() {
+		mustParseSkewPolicies([]byte(`
+skew:
+  cni:
+    older: -1
+`))
+	})
+}
+
+func TestCheckSkew(t *testing.T) {
+	withVersions(t, map[Component]SkewPolicy{ComponentCNI: {Older: 1, Newer: 0}},
+		"v1.30.0", "v1.29.0", "v1.28.0", "v2.0.0")
+
+	tests := []struct {
+		name         string
+		controlPlane string
+		component    string
+		expectErr    bool
+	}{
+		{name: "same version", controlPlane: "v1.29.0", component: "v1.29.0"},
+		{name: "component one minor older", controlPlane: "v1.30.0", component: "v1.29.0"},
+		{name: "component two minors older", controlPlane: "v1.30.0", component: "v1.28.0", expectErr: true},
+		{name: "component newer", controlPlane: "v1.29.0", component: "v1.30.0", expectErr: true},
+		{name: "different major version", controlPlane: "v2.0.0", component: "v1.30.0", expectErr: true},
+		{name: "alias", controlPlane: "latest", component: "v1.29.0"},
+		{name: "unknown control plane version", controlPlane: "v1.20.0", component: "v1.30.0"},
+		{name: "unknown component version", controlPlane: "v1.30.0", component: "v1.20.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckSkew(ComponentCNI, tt.controlPlane, tt.component)
+			if tt.expectErr {
+				var skewErr *SkewError
+				assert.ErrorAs(t, err, &skewErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCompatibleVersions(t *testing.T) {
+	withVersions(t, map[Component]SkewPolicy{ComponentZTunnel: {Older: 1, Newer: 1}},
+		"v1.30.0", "v1.29.1", "v1.29.0", "v1.28.0")
+
+	assert.Equal(t, []string{"v1.30.0", "v1.29.1", "v1.29.0", "v1.28.0"}, CompatibleVersions(ComponentZTunnel, "v1.29.0"))
+	assert.Equal(t, []string{"v1.29.1", "v1.29.0", "v1.28.0"}, CompatibleVersions(ComponentZTunnel, "v1.28.0"))
+	assert.Nil(t, CompatibleVersions(ComponentZTunnel, "v1.20.0"))
+}
+
+// withVersions replaces the supported versions and skew policies for the duration of the test. The first
+// version is also made available under the "latest" alias.
+func withVersions(t *testing.T, policies map[Component]SkewPolicy, names ...string) {
+	origList, origMap, origPolicies := List, Map, skewPolicies
+	t.Cleanup(func() {
+		List, Map, skewPolicies = origList, origMap, origPolicies
+	})
+
+	List, Map, skewPolicies = nil, map[string]VersionInfo{}, policies
+	for _, name := range names {
+		v := VersionInfo{Name: name, Version: semver.MustParse(name)}
+		List = append(List, v)
+		Map[name] = v
+	}
+	Map["latest"] = List[0]
+}
@@ -39,6 +39,7 @@ var (
 // Versions represents the top-level structure of versions.yaml
 type Versions struct {
 	Versions []VersionInfo `json:"versions"`
+	Skew     SkewPolicies  `json:"skew,omitempty"`
 }
 
 // AliasInfo contains information about version aliases
@@ -125,6 +126,7 @@ func init() {
 	}
 
 	List, Default, Base, New, Map, aliasList, EOL = mustParseVersionsYaml(data)
+	skewPolicies = mustParseSkewPolicies(data)
 }
 
 func mustParseVersionsYaml(yamlBytes []byte) (
@@ -0,0 +1,53 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: remote
+  cluster:
+    server: https://remote.example.com:6443
+contexts:
+- name: remote
+  context:
+    cluster: remote
+    user: istiod
+current-context: remote
+users:
+- name: istiod
+  user:
+    token: some-token
+`
+
+func TestNewRemoteClient(t *testing.T) {
+	t.Run("valid kubeconfig", func(t *testing.T) {
+		cl, err := NewRemoteClient([]byte(testKubeconfig))
+		require.NoError(t, err)
+		assert.NotNil(t, cl)
+	})
+
+	t.Run("invalid kubeconfig", func(t *testing.T) {
+		_, err := NewRemoteClient([]byte("not a kubeconfig"))
+		assert.ErrorContains(t, err, "invalid kubeconfig")
+	})
+}
@@ -0,0 +1,244 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/istio-ecosystem/sail-operator/pkg/revision"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// check inspects the resources of a kind for features that aren't supported in ambient mode. The inspect function
+// returns an empty severity if the resource is supported.
+type check struct {
+	gvk     schema.GroupVersionKind
+	inspect func(obj *unstructured.Unstructured, waypoint bool) (v1.MigrationFindingSeverity, string)
+}
+
+var checks = []check{
+	{
+		gvk:     schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1alpha3", Kind: "EnvoyFilter"},
+		inspect: inspectEnvoyFilter,
+	},
+	{
+		gvk:     schema.GroupVersionKind{Group: "security.istio.io", Version: "v1", Kind: "PeerAuthentication"},
+		inspect: inspectPeerAuthentication,
+	},
+	{
+		gvk:     schema.GroupVersionKind{Group: "security.istio.io", Version: "v1", Kind: "AuthorizationPolicy"},
+		inspect: inspectAuthorizationPolicy,
+	},
+	{
+		gvk:     schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1", Kind: "Sidecar"},
+		inspect: inspectSidecar,
+	},
+	{
+		gvk:     schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1", Kind: "VirtualService"},
+		inspect: inspectVirtualService,
+	},
+}
+
+// Preflight inspects the Istio resources and the pods in the given namespaces and returns the findings, sorted by
+// namespace, kind and name. The waypoint argument specifies whether a waypoint is deployed in the namespaces. Kinds
+// whose CRD isn't installed are skipped.
+func Preflight(ctx context.Context, cl client.Client, namespaces []string, waypoint bool) ([]v1.MigrationFinding, error) {
+	var findings []v1.MigrationFinding
+	for _, c := range checks {
+		for _, ns := range namespaces {
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(c.gvk.GroupVersion().WithKind(c.gvk.Kind + "List"))
+			if err := cl.List(ctx, list, client.InNamespace(ns)); err != nil {
+				if meta.IsNoMatchError(err) {
+					break
+				}
+				return nil, fmt.Errorf("failed to list %s resources in namespace %s: %w", c.gvk.Kind, ns, err)
+			}
+			for i := range list.Items {
+				obj := &list.Items[i]
+				if severity, message := c.inspect(obj, waypoint); severity != "" {
+					findings = append(findings, v1.MigrationFinding{
+						Severity:  severity,
+						Kind:      c.gvk.Kind,
+						Namespace: obj.GetNamespace(),
+						Name:      obj.GetName(),
+						Message:   message,
+					})
+				}
+			}
+		}
+	}
+	for _, ns := range namespaces {
+		podFindings, err := inspectPods(ctx, cl, ns)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, podFindings...)
+	}
+	slices.SortFunc(findings, func(a, b v1.MigrationFinding) int {
+		return cmp.Or(strings.Compare(a.Namespace, b.Namespace), strings.Compare(a.Kind, b.Kind), strings.Compare(a.Name, b.Name))
+	})
+	return findings, nil
+}
+
+// HasBlockingFindings returns true if any of the findings blocks the migration.
+func HasBlockingFindings(findings []v1.MigrationFinding) bool {
+	return slices.ContainsFunc(findings, func(f v1.MigrationFinding) bool { return f.Severity == v1.MigrationFindingBlocking })
+}
+
+// inspectPods reports the pods with a sidecar that the migration can't remove by restarting their Deployment,
+// StatefulSet or DaemonSet. Without intervention, the namespace would never leave the RestartingWorkloads phase.
+func inspectPods(ctx context.Context, cl client.Client, namespace string) ([]v1.MigrationFinding, error) {
+	pods := &corev1.PodList{}
+	if err := cl.List(ctx, pods, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
+	}
+	var findings []v1.MigrationFinding
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed ||
+			revision.GetInjectedRevisionFromPod(pod.Annotations) == "" {
+			continue
+		}
+		if severity, message := inspectPod(pod); severity != "" {
+			findings = append(findings, v1.MigrationFinding{
+				Severity:  severity,
+				Kind:      "Pod",
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+				Message:   message,
+			})
+		}
+	}
+	return findings, nil
+}
+
+func inspectPod(pod *corev1.Pod) (v1.MigrationFindingSeverity, string) {
+	if pod.Labels[constants.IstioSidecarInjectLabel] == "true" {
+		return v1.MigrationFindingBlocking, fmt.Sprintf("the pod enables sidecar injection with the %s label, so it gets a "+
+			"sidecar again when it's restarted; remove the label from the pod template", constants.IstioSidecarInjectLabel)
+	}
+	owner := metav1.GetControllerOf(pod)
+	switch {
+	case owner == nil:
+		return v1.MigrationFindingBlocking, "the pod isn't managed by a controller, so the migration can't restart it; " +
+			"delete the pod, or set spec.skipPreflightChecks and recreate it once the namespace is labeled for ambient mode"
+	case owner.Kind == "Job":
+		return v1.MigrationFindingWarning, "pods of Jobs aren't restarted by the migration; the namespace is migrated once the Job completes"
+	case owner.Kind != "ReplicaSet" && owner.Kind != "StatefulSet" && owner.Kind != "DaemonSet":
+		return v1.MigrationFindingBlocking, fmt.Sprintf("the pod is managed by a %s, which the migration can't restart; "+
+			"set spec.skipPreflightChecks and restart the %s once the namespace is labeled for ambient mode", owner.Kind, owner.Kind)
+	}
+	return "", ""
+}
+
+func inspectEnvoyFilter(obj *unstructured.Unstructured, _ bool) (v1.MigrationFindingSeverity, string) {
+	// EnvoyFilters that target a waypoint are applied in ambient mode
+	if targetRefs, _, _ := unstructured.NestedSlice(obj.Object, "spec", "targetRefs"); len(targetRefs) > 0 {
+		return "", ""
+	}
+	return v1.MigrationFindingBlocking, "EnvoyFilters that select workloads aren't applied in ambient mode, because the workloads have no sidecar"
+}
+
+func inspectPeerAuthentication(obj *unstructured.Unstructured, _ bool) (v1.MigrationFindingSeverity, string) {
+	disabled := func(mtls map[string]any) bool { return mtls["mode"] == "DISABLE" }
+	if mtls, found, _ := unstructured.NestedMap(obj.Object, "spec", "mtls"); found && disabled(mtls) {
+		return v1.MigrationFindingBlocking, "ztunnel doesn't support disabling mutual TLS"
+	}
+	portLevel, _, _ := unstructured.NestedMap(obj.Object, "spec", "portLevelMtls")
+	for port, value := range portLevel {
+		if mtls, ok := value.(map[string]any); ok && disabled(mtls) {
+			return v1.MigrationFindingBlocking, fmt.Sprintf("ztunnel doesn't support disabling mutual TLS on port %s", port)
+		}
+	}
+	return "", ""
+}
+
+func inspectAuthorizationPolicy(obj *unstructured.Unstructured, _ bool) (v1.MigrationFindingSeverity, string) {
+	// policies that target a waypoint are enforced by the waypoint, which supports L7 attributes
+	if targetRefs, _, _ := unstructured.NestedSlice(obj.Object, "spec", "targetRefs"); len(targetRefs) > 0 {
+		return "", ""
+	}
+	rules, _, _ := unstructured.NestedSlice(obj.Object, "spec", "rules")
+	if slices.ContainsFunc(rules, usesL7Attributes) {
+		return v1.MigrationFindingBlocking,
+			"the policy uses L7 attributes, which ztunnel can't enforce; attach the policy to a waypoint with spec.targetRefs"
+	}
+	return "", ""
+}
+
+// usesL7Attributes returns true if an AuthorizationPolicy rule matches HTTP attributes.
+func usesL7Attributes(r any) bool {
+	rule := asMap(r)
+	from, _, _ := unstructured.NestedSlice(rule, "from")
+	for _, f := range from {
+		if source, ok := asMap(f)["source"].(map[string]any); ok && hasAnyKey(source, "requestPrincipals", "notRequestPrincipals") {
+			return true
+		}
+	}
+	to, _, _ := unstructured.NestedSlice(rule, "to")
+	for _, t := range to {
+		if operation, ok := asMap(t)["operation"].(map[string]any); ok &&
+			hasAnyKey(operation, "hosts", "notHosts", "methods", "notMethods", "paths", "notPaths") {
+			return true
+		}
+	}
+	when, _, _ := unstructured.NestedSlice(rule, "when")
+	for _, w := range when {
+		if key, ok := asMap(w)["key"].(string); ok && strings.HasPrefix(key, "request.") {
+			return true
+		}
+	}
+	return false
+}
+
+func asMap(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+func hasAnyKey(m map[string]any, keys ...string) bool {
+	return slices.ContainsFunc(keys, func(key string) bool {
+		_, found := m[key]
+		return found
+	})
+}
+
+func inspectSidecar(_ *unstructured.Unstructured, _ bool) (v1.MigrationFindingSeverity, string) {
+	return v1.MigrationFindingWarning, "Sidecar resources are ignored in ambient mode"
+}
+
+func inspectVirtualService(obj *unstructured.Unstructured, waypoint bool) (v1.MigrationFindingSeverity, string) {
+	if waypoint {
+		return "", ""
+	}
+	// VirtualServices that are only bound to gateways aren't affected by the migration
+	gateways, found, _ := unstructured.NestedStringSlice(obj.Object, "spec", "gateways")
+	if found && !slices.Contains(gateways, "mesh") {
+		return "", ""
+	}
+	return v1.MigrationFindingWarning, "L7 routing rules are only applied to ambient workloads by a waypoint; set spec.waypoint to deploy one"
+}
@@ -0,0 +1,189 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/ptr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func newObject(apiVersion, kind, namespace, name string, spec map[string]any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]any{"namespace": namespace, "name": name},
+		"spec":       spec,
+	}}
+}
+
+func newPod(namespace, name string, injected bool, ownerKind string, labels map[string]string) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels}}
+	if injected {
+		pod.Annotations = map[string]string{"istio.io/rev": "default"}
+	}
+	if ownerKind != "" {
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: name, Controller: ptr.Of(true)}}
+	}
+	return pod
+}
+
+func TestPreflight(t *testing.T) {
+	objects := []client.Object{
+		newObject("networking.istio.io/v1alpha3", "EnvoyFilter", "ns1", "workload-filter", map[string]any{
+			"workloadSelector": map[string]any{"labels": map[string]any{"app": "foo"}},
+		}),
+		newObject("networking.istio.io/v1alpha3", "EnvoyFilter", "ns1", "waypoint-filter", map[string]any{
+			"targetRefs": []any{map[string]any{"kind": "Gateway", "name": "waypoint"}},
+		}),
+		newObject("security.istio.io/v1", "PeerAuthentication", "ns1", "disabled", map[string]any{
+			"mtls": map[string]any{"mode": "DISABLE"},
+		}),
+		newObject("security.istio.io/v1", "PeerAuthentication", "ns1", "port-disabled", map[string]any{
+			"mtls":          map[string]any{"mode": "STRICT"},
+			"portLevelMtls": map[string]any{"8080": map[string]any{"mode": "DISABLE"}},
+		}),
+		newObject("security.istio.io/v1", "PeerAuthentication", "ns1", "strict", map[string]any{
+			"mtls": map[string]any{"mode": "STRICT"},
+		}),
+		newObject("security.istio.io/v1", "AuthorizationPolicy", "ns2", "l4", map[string]any{
+			"rules": []any{map[string]any{
+				"from": []any{map[string]any{"source": map[string]any{"principals": []any{"cluster.local/ns/ns2/sa/foo"}}}},
+				"to":   []any{map[string]any{"operation": map[string]any{"ports": []any{"8080"}}}},
+			}},
+		}),
+		newObject("security.istio.io/v1", "AuthorizationPolicy", "ns2", "paths", map[string]any{
+			"rules": []any{map[string]any{
+				"to": []any{map[string]any{"operation": map[string]any{"paths": []any{"/admin"}}}},
+			}},
+		}),
+		newObject("security.istio.io/v1", "AuthorizationPolicy", "ns2", "headers", map[string]any{
+			"rules": []any{map[string]any{
+				"when": []any{map[string]any{"key": "request.headers[x-foo]", "values": []any{"bar"}}},
+			}},
+		}),
+		newObject("security.istio.io/v1", "AuthorizationPolicy", "ns2", "waypoint-paths", map[string]any{
+			"targetRefs": []any{map[string]any{"kind": "Gateway", "name": "waypoint"}},
+			"rules": []any{map[string]any{
+				"to": []any{map[string]any{"operation": map[string]any{"paths": []any{"/admin"}}}},
+			}},
+		}),
+		newObject("networking.istio.io/v1", "Sidecar", "ns2", "default", map[string]any{}),
+		newObject("networking.istio.io/v1", "VirtualService", "ns2", "mesh-routes", map[string]any{
+			"hosts": []any{"foo"},
+		}),
+		newObject("networking.istio.io/v1", "VirtualService", "ns2", "ingress-routes", map[string]any{
+			"hosts":    []any{"foo.example.com"},
+			"gateways": []any{"ingress"},
+		}),
+		newObject("networking.istio.io/v1alpha3", "EnvoyFilter", "other", "not-inspected", map[string]any{}),
+		newPod("ns1", "deployment-pod", true, "ReplicaSet", nil),
+		newPod("ns1", "statefulset-pod", true, "StatefulSet", nil),
+		newPod("ns1", "no-sidecar", false, "", nil),
+		newPod("ns1", "bare", true, "", nil),
+		newPod("ns1", "job-pod", true, "Job", nil),
+		newPod("ns1", "custom-controller", true, "Rollout", nil),
+		newPod("ns1", "inject-label", true, "ReplicaSet", map[string]string{"sidecar.istio.io/inject": "true"}),
+		newPod("other", "not-inspected", true, "", nil),
+	}
+
+	tests := []struct {
+		name     string
+		waypoint bool
+		expected []v1.MigrationFinding
+	}{
+		{
+			name: "without waypoint",
+			expected: []v1.MigrationFinding{
+				{Severity: v1.MigrationFindingBlocking, Kind: "EnvoyFilter", Namespace: "ns1", Name: "workload-filter"},
+				{Severity: v1.MigrationFindingBlocking, Kind: "PeerAuthentication", Namespace: "ns1", Name: "disabled"},
+				{Severity: v1.MigrationFindingBlocking, Kind: "PeerAuthentication", Namespace: "ns1", Name: "port-disabled"},
+				{Severity: v1.MigrationFindingBlocking, Kind: "Pod", Namespace: "ns1", Name: "bare"},
+				{Severity: v1.MigrationFindingBlocking, Kind: "Pod", Namespace: "ns1", Name: "custom-controller"},
+				{Severity: v1.MigrationFindingBlocking, Kind: "Pod", Namespace: "ns1", Name: "inject-label"},
+				{Severity: v1.MigrationFindingWarning, Kind: "Pod", Namespace: "ns1", Name: "job-pod"},
+				{Severity: v1.MigrationFindingBlocking, Kind: "AuthorizationPolicy", Namespace: "ns2", Name: "headers"},
+				{Severity: v1.MigrationFindingBlocking, Kind: "AuthorizationPolicy", Namespace: "ns2", Name: "paths"},
+				{Severity: v1.MigrationFindingWarning, Kind: "Sidecar", Namespace: "ns2", Name: "default"},
+				{Severity: v1.MigrationFindingWarning, Kind: "VirtualService", Namespace: "ns2", Name: "mesh-routes"},
+			},
+		},
+		{
+			name:     "with waypoint",
+			waypoint: true,
+			expected: []v1.MigrationFinding{
+				{Severity: v1.MigrationFindingBlocking, Kind: "EnvoyFilter", Namespace: "ns1", Name: "workload-filter"},
+				{Severity: v1.MigrationFindingBlocking, Kind: "PeerAuthentication", Namespace: "ns1", Name: "disabled"},
+				{Severity: v1.MigrationFindingBlocking, Kind: "PeerAuthentication", Namespace: "ns1", Name: "port-disabled"},
+				{Severity: v1.MigrationFindingBlocking, Kind: "Pod", Namespace: "ns1", Name: "bare"},
+				{Severity: v1.MigrationFindingBlocking, Kind: "Pod", Namespace: "ns1", Name: "custom-controller"},
+				{Severity: v1.MigrationFindingBlocking, Kind: "Pod", Namespace: "ns1", Name: "inject-label"},
+				{Severity: v1.MigrationFindingWarning, Kind: "Pod", Namespace: "ns1", Name: "job-pod"},
+				{Severity: v1.MigrationFindingBlocking, Kind: "AuthorizationPolicy", Namespace: "ns2", Name: "headers"},
+				{Severity: v1.MigrationFindingBlocking, Kind: "AuthorizationPolicy", Namespace: "ns2", Name: "paths"},
+				{Severity: v1.MigrationFindingWarning, Kind: "Sidecar", Namespace: "ns2", Name: "default"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build()
+			findings, err := Preflight(context.Background(), cl, []string{"ns2", "ns1"}, tt.waypoint)
+			require.NoError(t, err)
+
+			// the messages are verified separately
+			for i := range findings {
+				assert.NotEmpty(t, findings[i].Message)
+				findings[i].Message = ""
+			}
+			assert.Equal(t, tt.expected, findings)
+			assert.True(t, HasBlockingFindings(findings))
+		})
+	}
+}
+
+func TestPreflightSkipsMissingCRDs(t *testing.T) {
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(newObject("networking.istio.io/v1", "Sidecar", "ns1", "default", map[string]any{})).
+		WithInterceptorFuncs(interceptor.Funcs{
+			List: func(ctx context.Context, cl client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				if gvk := list.GetObjectKind().GroupVersionKind(); gvk.Group == "security.istio.io" {
+					return &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: gvk.Group, Kind: gvk.Kind}}
+				}
+				return cl.List(ctx, list, opts...)
+			},
+		}).
+		Build()
+
+	findings, err := Preflight(context.Background(), cl, []string{"ns1"}, false)
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "Sidecar", findings[0].Kind)
+	assert.False(t, HasBlockingFindings(findings))
+}
@@ -26,12 +26,14 @@ import (
 	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
 	"github.com/istio-ecosystem/sail-operator/pkg/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
-	cniReleaseName = "istio-cni"
-	cniChartName   = "cni"
+	cniReleaseName   = "istio-cni"
+	cniChartName     = "cni"
+	cniDaemonSetName = "istio-cni-node"
 )
 
 // CNIReconciler handles reconciliation of the istio-cni component.
@@ -66,7 +68,7 @@ func (r *CNIReconciler) Validate(ctx context.Context, version, namespace string)
 	return nil
 }
 
-// ComputeValues computes the final Helm values by applying digests, vendor defaults, and profiles.
+// ComputeValues computes the final Helm values by applying digests, vendor defaults, profiles, and registry mirrors.
 func (r *CNIReconciler) ComputeValues(version string, userValues *v1.CNIValues, profile string) (helm.Values, error) {
 	resolvedVersion, err := istioversion.Resolve(version)
 	if err != nil {
@@ -92,11 +94,22 @@ func (r *CNIReconciler) ComputeValues(version string, userValues *v1.CNIValues,
 		return nil, fmt.Errorf("failed to apply profile: %w", err)
 	}
 
+	// Rewrite the final images, including those set by the user and the chart's default hub, according to the registry mirrors
+	mergedHelmValues, err = istiovalues.ApplyRegistryMirrors(mergedHelmValues, config.Config, r.cfg.ResourceFS,
+		GetChartPath(resolvedVersion, cniChartName), "global.hub", "cni.hub", "cni.image")
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply registry mirrors: %w", err)
+	}
+
 	return mergedHelmValues, nil
 }
 
 // Install installs or upgrades the istio-cni Helm chart.
-func (r *CNIReconciler) Install(ctx context.Context, version, namespace string, values *v1.CNIValues, profile string, ownerRef *metav1.OwnerReference) error {
+// With a batched rollout strategy, the DaemonSet doesn't replace its pods itself; see Rollout.
+func (r *CNIReconciler) Install(
+	ctx context.Context, version, namespace string, values *v1.CNIValues, profile string, rollout *v1.DaemonSetRolloutStrategy,
+	ownerRef *metav1.OwnerReference,
+) error {
 	mergedHelmValues, err := r.ComputeValues(version, values, profile)
 	if err != nil {
 		return err
@@ -116,13 +129,22 @@ func (r *CNIReconciler) Install(ctx context.Context, version, namespace string,
 		namespace,
 		cniReleaseName,
 		ownerRef,
+		append(instanceInstallOptions(ownerRef), rolloutInstallOptions(rollout)...)...,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to install/update Helm chart %q: %w", cniChartName, err)
+		return installError(err, "failed to install/update Helm chart %q", cniChartName)
 	}
 	return nil
 }
 
+// Rollout advances the batched rollout of the istio-cni DaemonSet and returns its status. It returns nil if the
+// rollout strategy isn't batched.
+func (r *CNIReconciler) Rollout(
+	ctx context.Context, namespace string, rollout *v1.DaemonSetRolloutStrategy, previous *v1.DaemonSetRolloutStatus,
+) (*v1.DaemonSetRolloutStatus, error) {
+	return rolloutDaemonSet(ctx, r.client, types.NamespacedName{Namespace: namespace, Name: cniDaemonSetName}, rollout, previous)
+}
+
 // Uninstall removes the istio-cni Helm chart.
 func (r *CNIReconciler) Uninstall(ctx context.Context, namespace string) error {
 	_, err := r.cfg.ChartManager.UninstallChart(ctx, cniReleaseName, namespace)
@@ -131,3 +153,8 @@ func (r *CNIReconciler) Uninstall(ctx context.Context, namespace string) error {
 	}
 	return nil
 }
+
+// DetectDrift returns the resources of the istio-cni Helm release that were modified outside of the operator.
+func (r *CNIReconciler) DetectDrift(ctx context.Context, namespace string) ([]string, error) {
+	return detectDrift(ctx, r.cfg.ChartManager, namespace, cniReleaseName)
+}
@@ -17,13 +17,20 @@ package reconcile
 import (
 	"context"
 	"testing"
+	"testing/fstest"
 
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/istioversion"
 	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
 	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"istio.io/istio/pkg/ptr"
 )
 
 func TestCNIReconciler_Validate(t *testing.T) {
@@ -97,3 +104,31 @@ func TestCNIReconciler_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestCNIReconciler_ComputeValuesRegistryMirrors(t *testing.T) {
+	savedConfig := config.Config
+	t.Cleanup(func() { config.Config = savedConfig })
+	config.Config = config.OperatorConfig{
+		RegistryMirrors: map[string]config.RegistryMirror{
+			"release": {Source: "registry.istio.io/release", Mirror: "registry.example.com/istio"},
+		},
+	}
+
+	version, err := istioversion.Resolve(istioversion.Default)
+	require.NoError(t, err)
+	resourceFS := fstest.MapFS{
+		version + "/profiles/default.yaml":  &fstest.MapFile{Data: []byte("apiVersion: sailoperator.io/v1\nkind: IstioCNI\nspec:\n")},
+		version + "/charts/cni/values.yaml": &fstest.MapFile{Data: []byte("_internal_defaults_do_not_set:\n  global:\n    hub: registry.istio.io/release\n")},
+	}
+	r := NewCNIReconciler(Config{ResourceFS: resourceFS, Platform: config.PlatformKubernetes}, nil)
+
+	userValues := &v1.CNIValues{Cni: &v1.CNIConfig{Image: ptr.Of("registry.istio.io/release/install-cni:1.30.3")}}
+	values, err := r.ComputeValues(istioversion.Default, userValues, "")
+	require.NoError(t, err)
+
+	hub, _, _ := values.GetString("global.hub")
+	assert.Equal(t, "registry.example.com/istio", hub)
+	image, _, _ := values.GetString("cni.image")
+	assert.Equal(t, "registry.example.com/istio/install-cni:1.30.3", image)
+	assert.Equal(t, "registry.istio.io/release/install-cni:1.30.3", *userValues.Cni.Image)
+}
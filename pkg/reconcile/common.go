@@ -18,13 +18,20 @@
 package reconcile
 
 import (
+	"context"
+	"fmt"
 	"io/fs"
 	"path"
 
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
 	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// defaultInstanceName is the name of the IstioCNI and ZTunnel instances that aren't scoped to a node pool.
+const defaultInstanceName = "default"
+
 // Config holds configuration needed for component reconciliation.
 // It contains all the dependencies required by reconcilers to validate,
 // compute values, and install Helm charts.
@@ -44,7 +51,8 @@ type Config struct {
 	// ChartManager handles Helm chart installation and upgrades
 	ChartManager helm.ChartReconciler
 
-	// TLSConfig holds the TLS configuration from the platform (e.g. OpenShift APIServer).
+	// TLSConfig holds the TLS configuration from the platform (e.g. OpenShift APIServer) or from the spec.tlsProfile
+	// of an Istio.
 	// Nil when not applicable.
 	TLSConfig *config.TLSConfig
 }
@@ -53,3 +61,48 @@ type Config struct {
 func GetChartPath(version, chartName string) string {
 	return path.Join(version, "charts", chartName)
 }
+
+// installError wraps an error returned by the ChartManager when installing or upgrading a chart. Errors that
+// Helm reports while rendering the chart templates or that the API server returns for the resources of the
+// chart are converted to the typed errors recognized by reconciler.ClassifyError.
+func installError(err error, format string, args ...any) error {
+	message := fmt.Sprintf(format, args...)
+	detailed := fmt.Sprintf("%s: %v", message, err)
+	switch {
+	case helm.IsRenderError(err):
+		return reconciler.NewChartRenderError(detailed, err)
+	case helm.IsWebhookError(err):
+		return reconciler.NewWebhookUnreachableError(detailed, err)
+	case helm.IsQuotaError(err):
+		return reconciler.NewQuotaExceededError(detailed, err)
+	case helm.IsForbiddenError(err):
+		return reconciler.NewPermissionDeniedError(detailed, err)
+	case helm.IsConflictError(err):
+		return reconciler.NewResourceConflictError(detailed, err)
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+// instanceInstallOptions returns the options for installing the chart of the IstioCNI or ZTunnel instance that
+// owns the release. Instances other than "default" are scoped to a node pool and run alongside other instances,
+// so the names of their ClusterRoles get the instance name as a suffix to keep them from clashing.
+func instanceInstallOptions(ownerRef *metav1.OwnerReference) []helm.InstallOption {
+	if ownerRef == nil || ownerRef.Name == defaultInstanceName {
+		return nil
+	}
+	return []helm.InstallOption{helm.WithClusterRoleNameSuffix("-" + ownerRef.Name)}
+}
+
+// detectDrift returns the resources of the release that were modified outside of Helm. If the ChartManager
+// can't detect drift, nil is returned.
+func detectDrift(ctx context.Context, chartManager helm.ChartReconciler, namespace, releaseName string) ([]string, error) {
+	detector, ok := chartManager.(helm.DriftDetector)
+	if !ok {
+		return nil, nil
+	}
+	drift, err := detector.DetectDrift(ctx, namespace, releaseName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect drift of Helm release %q: %w", releaseName, err)
+	}
+	return drift, nil
+}
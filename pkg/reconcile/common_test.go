@@ -15,9 +15,19 @@
 package reconcile
 
 import (
+	"context"
+	"errors"
+	"io/fs"
 	"testing"
 
+	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v4/pkg/release"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func TestGetChartPath(t *testing.T) {
@@ -45,3 +55,91 @@ func TestGetChartPath(t *testing.T) {
 		})
 	}
 }
+
+type fakeChartManager struct{}
+
+func (fakeChartManager) UpgradeOrInstallChart(context.Context, fs.FS, string, helm.Values, string, string,
+	*metav1.OwnerReference, ...helm.InstallOption,
+) (release.Releaser, error) {
+	return nil, nil
+}
+
+func (fakeChartManager) UninstallChart(context.Context, string, string) (*release.UninstallReleaseResponse, error) {
+	return nil, nil
+}
+
+type fakeDriftDetector struct {
+	fakeChartManager
+	drift map[string][]string
+	err   error
+}
+
+func (d fakeDriftDetector) DetectDrift(_ context.Context, _, releaseName string) ([]string, error) {
+	return d.drift[releaseName], d.err
+}
+
+func TestDetectDrift(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("chart manager without drift detection", func(t *testing.T) {
+		drift, err := detectDrift(ctx, fakeChartManager{}, "istio-system", "istio-cni")
+		require.NoError(t, err)
+		assert.Nil(t, drift)
+	})
+
+	t.Run("drift detected", func(t *testing.T) {
+		detector := fakeDriftDetector{drift: map[string][]string{"istio-cni": {"DaemonSet istio-system/istio-cni-node"}}}
+		drift, err := detectDrift(ctx, detector, "istio-system", "istio-cni")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"DaemonSet istio-system/istio-cni-node"}, drift)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		detector := fakeDriftDetector{err: errors.New("boom")}
+		_, err := detectDrift(ctx, detector, "istio-system", "istio-cni")
+		assert.ErrorContains(t, err, `failed to detect drift of Helm release "istio-cni": boom`)
+	})
+}
+
+func TestInstanceInstallOptions(t *testing.T) {
+	assert.Empty(t, instanceInstallOptions(nil))
+	assert.Empty(t, instanceInstallOptions(&metav1.OwnerReference{Name: "default"}))
+	assert.Len(t, instanceInstallOptions(&metav1.OwnerReference{Name: "gpu"}), 1)
+}
+
+func TestInstallError(t *testing.T) {
+	renderErr := errors.New(`template: istiod/templates/deployment.yaml:10:3: execution error at (istiod/templates/deployment.yaml:10:3): invalid`)
+	quotaErr := apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "istiod-abc", errors.New("exceeded quota: compute"))
+
+	tests := []struct {
+		name     string
+		err      error
+		expected func(error) bool
+	}{
+		{name: "render error", err: renderErr, expected: reconciler.IsChartRenderError},
+		{name: "webhook error", err: errors.New(`failed calling webhook "validation.istio.io"`), expected: reconciler.IsWebhookUnreachableError},
+		{name: "quota error", err: quotaErr, expected: reconciler.IsQuotaExceededError},
+		{
+			name:     "forbidden error",
+			err:      apierrors.NewForbidden(schema.GroupResource{Resource: "services"}, "istiod", errors.New("no RBAC policy matched")),
+			expected: reconciler.IsPermissionDeniedError,
+		},
+		{name: "conflict error", err: errors.New("ServiceAccount \"istiod\" exists and cannot be imported into the current release"), expected: reconciler.IsResourceConflictError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := installError(tt.err, "failed to install/update Helm chart %q", "istiod")
+			assert.True(t, tt.expected(err))
+			assert.ErrorIs(t, err, tt.err)
+			assert.Contains(t, err.Error(), `failed to install/update Helm chart "istiod"`)
+		})
+	}
+
+	t.Run("unrecognized error", func(t *testing.T) {
+		cause := errors.New("connection refused")
+		err := installError(cause, "failed to install/update Helm chart %q", "istiod")
+		_, _, classified := reconciler.ClassifyError(err)
+		assert.False(t, classified)
+		assert.ErrorIs(t, err, cause)
+	})
+}
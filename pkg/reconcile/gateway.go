@@ -0,0 +1,146 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/istio-ecosystem/sail-operator/pkg/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	gatewayChartName = "gateway"
+
+	// proxyImageAnnotation overrides the proxy image that is injected into the gateway pods
+	proxyImageAnnotation = "sidecar.istio.io/proxyImage"
+)
+
+// GatewayReconciler handles reconciliation of gateways installed from the gateway chart.
+// Each gateway is a separate Helm release named after the gateway, with a suffix that keeps it from
+// colliding with the releases of istiod and the other components.
+type GatewayReconciler struct {
+	cfg    Config
+	client client.Client
+}
+
+// NewGatewayReconciler creates a new GatewayReconciler.
+func NewGatewayReconciler(cfg Config, client client.Client) *GatewayReconciler {
+	return &GatewayReconciler{
+		cfg:    cfg,
+		client: client,
+	}
+}
+
+// Validate performs general validation of a gateway specification.
+// This includes basic field validation and Kubernetes API checks (namespace exists).
+func (r *GatewayReconciler) Validate(ctx context.Context, name, namespace string) error {
+	if name == "" {
+		return reconciler.NewValidationError("gateway name not set")
+	}
+	if namespace == "" {
+		return reconciler.NewValidationError(fmt.Sprintf("namespace of gateway %q not set", name))
+	}
+
+	// Validate target namespace exists
+	if err := validation.ValidateTargetNamespace(ctx, r.client, namespace); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ComputeValues computes the final Helm values for a gateway. The revision is always set to the given
+// revision name, so that the gateway is injected by that revision, and the name and the platform are set to
+// the gateway name and the detected platform, unless the user set them explicitly. The proxy image set by
+// the user is rewritten according to the registry mirrors.
+func (r *GatewayReconciler) ComputeValues(name string, userValues json.RawMessage, revisionName string) (helm.Values, error) {
+	values := helm.Values{}
+	if len(userValues) > 0 {
+		if err := json.Unmarshal(userValues, &values); err != nil {
+			return nil, reconciler.NewValidationError(fmt.Sprintf("invalid gateway values: %s", err))
+		}
+		if values == nil {
+			values = helm.Values{}
+		}
+	}
+
+	// The "default" revision is represented by an empty revision in the charts (see istiovalues.ApplyOverrides)
+	if revisionName == v1.DefaultRevision {
+		revisionName = ""
+	}
+	if err := values.Set("revision", revisionName); err != nil {
+		return nil, fmt.Errorf("failed to set revision: %w", err)
+	}
+
+	// The chart names the Deployment and Service after the release, unless the name is set
+	if err := values.SetIfAbsent("name", name); err != nil {
+		return nil, fmt.Errorf("failed to set name: %w", err)
+	}
+
+	if platform := r.cfg.Platform.HelmPlatform(); platform != "" {
+		if err := values.SetIfAbsent("global.platform", platform); err != nil {
+			return nil, fmt.Errorf("failed to set platform: %w", err)
+		}
+	}
+
+	// The gateway is injected with the proxy image of the revision, whose values are rewritten according to the
+	// registry mirrors already, unless the user overrides the image with the proxyImage annotation
+	if annotations, ok := values["podAnnotations"].(map[string]any); ok {
+		if image, ok := annotations[proxyImageAnnotation].(string); ok {
+			annotations[proxyImageAnnotation] = config.Config.RewriteImage(image)
+		}
+	}
+	return values, nil
+}
+
+// Install installs or upgrades the gateway Helm chart for the gateway with the given name.
+func (r *GatewayReconciler) Install(
+	ctx context.Context, version, name, namespace string, userValues json.RawMessage, revisionName string, ownerRef *metav1.OwnerReference,
+) error {
+	values, err := r.ComputeValues(name, userValues, revisionName)
+	if err != nil {
+		return err
+	}
+
+	chartPath := GetChartPath(version, gatewayChartName)
+	_, err = r.cfg.ChartManager.UpgradeOrInstallChart(ctx, r.cfg.ResourceFS, chartPath, values, namespace, getGatewayReleaseName(name), ownerRef)
+	if err != nil {
+		return installError(err, "failed to install/update Helm chart %q for gateway %s/%s", gatewayChartName, namespace, name)
+	}
+	return nil
+}
+
+// Uninstall removes the Helm release of the gateway with the given name.
+func (r *GatewayReconciler) Uninstall(ctx context.Context, name, namespace string) error {
+	_, err := r.cfg.ChartManager.UninstallChart(ctx, getGatewayReleaseName(name), namespace)
+	if err != nil {
+		return fmt.Errorf("failed to uninstall Helm chart %q for gateway %s/%s: %w", gatewayChartName, namespace, name, err)
+	}
+	return nil
+}
+
+// getGatewayReleaseName returns the name of the Helm release of the gateway. The suffix keeps a gateway from
+// upgrading the release of another component, e.g. a gateway named "default-istiod" in the namespace of istiod.
+func getGatewayReleaseName(name string) string {
+	return getReleaseName(name, gatewayChartName)
+}
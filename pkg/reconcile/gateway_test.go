@@ -0,0 +1,222 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"testing"
+
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v4/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGatewayReconciler_Validate(t *testing.T) {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "istio-ingress",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		gateway     string
+		namespace   string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:        "missing name",
+			gateway:     "",
+			namespace:   "istio-ingress",
+			wantErr:     true,
+			errContains: "gateway name not set",
+		},
+		{
+			name:        "missing namespace",
+			gateway:     "ingress",
+			namespace:   "",
+			wantErr:     true,
+			errContains: `namespace of gateway "ingress" not set`,
+		},
+		{
+			name:        "namespace not found",
+			gateway:     "ingress",
+			namespace:   "other",
+			wantErr:     true,
+			errContains: `namespace "other" doesn't exist`,
+		},
+		{
+			name:      "valid",
+			gateway:   "ingress",
+			namespace: "istio-ingress",
+			wantErr:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(ns).Build()
+
+			r := NewGatewayReconciler(Config{}, cl)
+			err := r.Validate(context.Background(), tt.gateway, tt.namespace)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.True(t, reconciler.IsValidationError(err), "expected validation error")
+				assert.Contains(t, err.Error(), tt.errContains)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGatewayReconciler_ComputeValues(t *testing.T) {
+	tests := []struct {
+		name         string
+		platform     config.Platform
+		userValues   string
+		revisionName string
+		expected     helm.Values
+		wantErr      bool
+	}{
+		{
+			name:         "no user values",
+			platform:     config.PlatformKubernetes,
+			revisionName: "my-rev",
+			expected:     helm.Values{"revision": "my-rev", "name": "my-gateway"},
+		},
+		{
+			name:         "default revision",
+			platform:     config.PlatformKubernetes,
+			userValues:   `{"replicaCount": 2}`,
+			revisionName: "default",
+			expected:     helm.Values{"revision": "", "name": "my-gateway", "replicaCount": float64(2)},
+		},
+		{
+			name:         "revision overrides user value",
+			platform:     config.PlatformKubernetes,
+			userValues:   `{"revision": "other"}`,
+			revisionName: "my-rev",
+			expected:     helm.Values{"revision": "my-rev", "name": "my-gateway"},
+		},
+		{
+			name:         "sets detected platform",
+			platform:     config.PlatformOpenShift,
+			revisionName: "my-rev",
+			expected:     helm.Values{"revision": "my-rev", "name": "my-gateway", "global": map[string]any{"platform": "openshift"}},
+		},
+		{
+			name:         "keeps platform set by user",
+			platform:     config.PlatformOpenShift,
+			userValues:   `{"global": {"platform": "k3s"}}`,
+			revisionName: "my-rev",
+			expected:     helm.Values{"revision": "my-rev", "name": "my-gateway", "global": map[string]any{"platform": "k3s"}},
+		},
+		{
+			name:         "keeps name set by user",
+			platform:     config.PlatformKubernetes,
+			userValues:   `{"name": "ingress"}`,
+			revisionName: "my-rev",
+			expected:     helm.Values{"revision": "my-rev", "name": "ingress"},
+		},
+		{
+			name:         "invalid values",
+			platform:     config.PlatformKubernetes,
+			userValues:   `["not", "an", "object"]`,
+			revisionName: "my-rev",
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewGatewayReconciler(Config{Platform: tt.platform}, nil)
+			var userValues json.RawMessage
+			if tt.userValues != "" {
+				userValues = json.RawMessage(tt.userValues)
+			}
+
+			values, err := r.ComputeValues("my-gateway", userValues, tt.revisionName)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.True(t, reconciler.IsValidationError(err), "expected validation error")
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, values)
+		})
+	}
+}
+
+func TestGatewayReconciler_ComputeValuesRegistryMirrors(t *testing.T) {
+	savedConfig := config.Config
+	t.Cleanup(func() { config.Config = savedConfig })
+	config.Config = config.OperatorConfig{
+		RegistryMirrors: map[string]config.RegistryMirror{
+			"release": {Source: "registry.istio.io/release", Mirror: "registry.example.com/istio"},
+		},
+	}
+
+	r := NewGatewayReconciler(Config{Platform: config.PlatformKubernetes}, nil)
+	userValues := json.RawMessage(`{"podAnnotations": {"sidecar.istio.io/proxyImage": "registry.istio.io/release/proxyv2:1.30.3"}}`)
+
+	values, err := r.ComputeValues("my-gateway", userValues, "my-rev")
+	assert.NoError(t, err)
+	assert.Equal(t, helm.Values{
+		"revision":       "my-rev",
+		"name":           "my-gateway",
+		"podAnnotations": map[string]any{"sidecar.istio.io/proxyImage": "registry.example.com/istio/proxyv2:1.30.3"},
+	}, values)
+}
+
+type releaseRecorder struct {
+	fakeChartManager
+	installed   []string
+	uninstalled []string
+}
+
+func (r *releaseRecorder) UpgradeOrInstallChart(_ context.Context, _ fs.FS, _ string, _ helm.Values, _, releaseName string,
+	_ *metav1.OwnerReference, _ ...helm.InstallOption,
+) (release.Releaser, error) {
+	r.installed = append(r.installed, releaseName)
+	return nil, nil
+}
+
+func (r *releaseRecorder) UninstallChart(_ context.Context, releaseName, _ string) (*release.UninstallReleaseResponse, error) {
+	r.uninstalled = append(r.uninstalled, releaseName)
+	return nil, nil
+}
+
+func TestGatewayReconciler_ReleaseName(t *testing.T) {
+	chartManager := &releaseRecorder{}
+	r := NewGatewayReconciler(Config{Platform: config.PlatformKubernetes, ChartManager: chartManager}, nil)
+
+	// a gateway must not upgrade the release of istiod, even if it has the same name
+	assert.NoError(t, r.Install(context.Background(), "v1.30.3", "default-istiod", "istio-system", nil, "default", nil))
+	assert.NoError(t, r.Uninstall(context.Background(), "default-istiod", "istio-system"))
+
+	assert.Equal(t, []string{"default-istiod-gateway"}, chartManager.installed)
+	assert.Equal(t, []string{"default-istiod-gateway"}, chartManager.uninstalled)
+}
@@ -27,6 +27,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+const revisionTagsChartName = "revisiontags"
+
 // IstiodReconciler handles reconciliation of the istiod component.
 type IstiodReconciler struct {
 	cfg    Config
@@ -88,7 +90,7 @@ func (r *IstiodReconciler) Install(
 		ownerRef,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to install/update Helm chart %q: %w", constants.IstiodChartName, err)
+		return installError(err, "failed to install/update Helm chart %q", constants.IstiodChartName)
 	}
 
 	// Install base chart for default revision
@@ -106,7 +108,7 @@ func (r *IstiodReconciler) Install(
 			ownerRef,
 		)
 		if err != nil {
-			return fmt.Errorf("failed to install/update Helm chart %q: %w", constants.BaseChartName, err)
+			return installError(err, "failed to install/update Helm chart %q", constants.BaseChartName)
 		}
 	}
 
@@ -132,6 +134,89 @@ func (r *IstiodReconciler) Uninstall(ctx context.Context, namespace, revisionNam
 	return nil
 }
 
+// DetectDrift returns the resources of the istiod Helm releases that were modified outside of the operator.
+func (r *IstiodReconciler) DetectDrift(ctx context.Context, namespace, revisionName string) ([]string, error) {
+	drift, err := detectDrift(ctx, r.cfg.ChartManager, namespace, getReleaseName(revisionName, constants.IstiodChartName))
+	if err != nil {
+		return nil, err
+	}
+
+	if revisionName == v1.DefaultRevision {
+		baseDrift, err := detectDrift(ctx, r.cfg.ChartManager, r.cfg.OperatorNamespace, getReleaseName(revisionName, constants.BaseChartName))
+		if err != nil {
+			return nil, err
+		}
+		drift = append(drift, baseDrift...)
+	}
+	return drift, nil
+}
+
+// InstallRevisionTag installs or upgrades the revisiontags Helm chart, which
+// points the given tag at revisionName. The values must be the computed
+// values of the target revision. For the default tag, the base chart is also
+// installed so that the default validation webhook follows the tag.
+func (r *IstiodReconciler) InstallRevisionTag(
+	ctx context.Context,
+	version, namespace, tagName, revisionName string,
+	values *v1.Values,
+	ownerRef *metav1.OwnerReference,
+) error {
+	helmValues := helm.FromValues(values)
+	if err := helmValues.SetStringSlice("revisionTags", []string{tagName}); err != nil {
+		return err
+	}
+
+	_, err := r.cfg.ChartManager.UpgradeOrInstallChart(
+		ctx,
+		r.cfg.ResourceFS,
+		GetChartPath(version, revisionTagsChartName),
+		helmValues,
+		namespace,
+		getReleaseName(tagName, revisionTagsChartName),
+		ownerRef,
+	)
+	if err != nil {
+		return installError(err, "failed to install/update Helm chart %q", revisionTagsChartName)
+	}
+
+	if tagName == v1.DefaultRevisionTag {
+		if err := helmValues.Set("defaultRevision", revisionName); err != nil {
+			return err
+		}
+		_, err := r.cfg.ChartManager.UpgradeOrInstallChart(
+			ctx,
+			r.cfg.ResourceFS,
+			GetChartPath(version, constants.BaseChartName),
+			helmValues,
+			r.cfg.OperatorNamespace,
+			getReleaseName(tagName, constants.BaseChartName),
+			ownerRef,
+		)
+		if err != nil {
+			return installError(err, "failed to install/update Helm chart %q", constants.BaseChartName)
+		}
+	}
+
+	return nil
+}
+
+// UninstallRevisionTag removes the Helm charts installed by InstallRevisionTag.
+func (r *IstiodReconciler) UninstallRevisionTag(ctx context.Context, namespace, tagName string) error {
+	releaseName := getReleaseName(tagName, revisionTagsChartName)
+	if _, err := r.cfg.ChartManager.UninstallChart(ctx, releaseName, namespace); err != nil {
+		return fmt.Errorf("failed to uninstall Helm chart %q: %w", revisionTagsChartName, err)
+	}
+
+	if tagName == v1.DefaultRevisionTag {
+		baseReleaseName := getReleaseName(tagName, constants.BaseChartName)
+		if _, err := r.cfg.ChartManager.UninstallChart(ctx, baseReleaseName, r.cfg.OperatorNamespace); err != nil {
+			return fmt.Errorf("failed to uninstall Helm chart %q: %w", constants.BaseChartName, err)
+		}
+	}
+
+	return nil
+}
+
 // getReleaseName returns the Helm release name for a given revision and chart.
 func getReleaseName(revisionName, chartName string) string {
 	return fmt.Sprintf("%s-%s", revisionName, chartName)
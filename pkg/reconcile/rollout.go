@@ -0,0 +1,313 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// RolloutCheckInterval is the interval at which a batched rollout is checked while it isn't complete. The
+	// workloads on the updated nodes aren't watched, so their readiness is polled.
+	RolloutCheckInterval = 15 * time.Second
+
+	// rolloutNodeTimeout is how long the rollout waits for the new pod and the workloads on a node to be ready
+	// before it marks the node as failed.
+	rolloutNodeTimeout = 5 * time.Minute
+
+	// templateGenerationAnnotation and templateGenerationLabel are set by the DaemonSet controller on the DaemonSet
+	// and its pods. A pod is up to date when its label matches the annotation.
+	templateGenerationAnnotation = "deprecated.daemonset.template.generation"
+	templateGenerationLabel      = "pod-template-generation"
+
+	// podNodeNameField is the field by which the pods on a node are listed. The API server supports it as a field
+	// selector, and IndexPodsByNode registers a cache index with the same name.
+	podNodeNameField = "spec.nodeName"
+)
+
+// podFailureReasons are the container waiting reasons that mark a node as failed without waiting for the timeout.
+var podFailureReasons = []string{"CrashLoopBackOff", "ImagePullBackOff", "ErrImagePull", "CreateContainerConfigError", "InvalidImageName"}
+
+// IsBatchedRollout returns true if the rollout strategy replaces the pods of the DaemonSet in batches of nodes.
+func IsBatchedRollout(rollout *v1.DaemonSetRolloutStrategy) bool {
+	return rollout != nil && rollout.Type == v1.DaemonSetRolloutBatched
+}
+
+// RolloutRequeueAfter returns the time after which the rollout needs to be checked again, or zero if it's complete.
+func RolloutRequeueAfter(status *v1.DaemonSetRolloutStatus) time.Duration {
+	if status == nil || status.State == v1.DaemonSetRolloutComplete {
+		return 0
+	}
+	return RolloutCheckInterval
+}
+
+// IndexPodsByNode registers the cache index that batched rollouts use to list the workload pods on a node. It must
+// be registered once per manager, before the manager is started.
+func IndexPodsByNode(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &corev1.Pod{}, podNodeNameField, podNodeName)
+}
+
+func podNodeName(obj client.Object) []string {
+	return []string{obj.(*corev1.Pod).Spec.NodeName}
+}
+
+func rolloutInstallOptions(rollout *v1.DaemonSetRolloutStrategy) []helm.InstallOption {
+	if !IsBatchedRollout(rollout) {
+		return nil
+	}
+	return []helm.InstallOption{helm.WithOnDeleteUpdateStrategy()}
+}
+
+// rolloutDaemonSet advances the batched rollout of the DaemonSet. It waits until the new pods and the workloads
+// on the nodes of the current batch are ready, and then deletes the outdated pods on the next batch of nodes, so
+// that the DaemonSet controller replaces them. The rollout is paused while any node has failed. The returned status
+// is based on the status returned by the previous call.
+func rolloutDaemonSet(
+	ctx context.Context, cl client.Client, key types.NamespacedName, rollout *v1.DaemonSetRolloutStrategy, previous *v1.DaemonSetRolloutStatus,
+) (*v1.DaemonSetRolloutStatus, error) {
+	if !IsBatchedRollout(rollout) {
+		return nil, nil
+	}
+
+	ds := &appsv1.DaemonSet{}
+	if err := cl.Get(ctx, key, ds); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get DaemonSet %s: %w", key, err)
+	}
+
+	daemonPods, err := listDaemonPods(ctx, cl, ds)
+	if err != nil {
+		return nil, err
+	}
+
+	generation := ds.Annotations[templateGenerationAnnotation]
+	status := &v1.DaemonSetRolloutStatus{
+		TotalNodes: int32(len(daemonPods)),
+	}
+	var outdatedNodes []string
+	for node, pods := range daemonPods {
+		if newPod(pods, generation) != nil {
+			status.UpdatedNodes++
+		}
+		if slices.ContainsFunc(pods, func(pod *corev1.Pod) bool {
+			return pod.DeletionTimestamp == nil && pod.Labels[templateGenerationLabel] != generation
+		}) {
+			outdatedNodes = append(outdatedNodes, node)
+		}
+	}
+	slices.Sort(outdatedNodes)
+
+	// check the nodes of the current batch and the nodes that failed
+	now := metav1.Now()
+	waiting := false
+	if previous != nil {
+		workloadPods, err := listWorkloadPods(ctx, cl, ds, previous.Nodes)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range previous.Nodes {
+			if len(daemonPods[node.Name]) == 0 {
+				// the node was removed or no longer runs the DaemonSet, so there's nothing to wait for
+				gone, err := isNodeGone(ctx, cl, node, now)
+				if err != nil {
+					return nil, err
+				}
+				if gone {
+					continue
+				}
+			}
+			state, message := checkNode(daemonPods[node.Name], workloadPods[node.Name], generation, node.StartTime)
+			switch {
+			case state == "":
+				continue
+			case state == v1.NodeRolloutUpdating && now.Sub(node.StartTime.Time) > rolloutNodeTimeout:
+				state = v1.NodeRolloutFailed
+				message = fmt.Sprintf("timed out after %s: %s", rolloutNodeTimeout, message)
+			}
+			waiting = waiting || state == v1.NodeRolloutUpdating
+			node.State = state
+			node.Message = message
+			status.Nodes = append(status.Nodes, node)
+		}
+	}
+
+	switch {
+	case slices.ContainsFunc(status.Nodes, func(node v1.NodeRolloutStatus) bool { return node.State == v1.NodeRolloutFailed }):
+		status.State = v1.DaemonSetRolloutPaused
+		return status, nil
+	case waiting:
+		status.State = v1.DaemonSetRolloutInProgress
+		return status, nil
+	case len(outdatedNodes) == 0:
+		status.State = v1.DaemonSetRolloutComplete
+		return status, nil
+	}
+
+	// start the next batch
+	batchSize := max(int(rollout.BatchSize), 1)
+	for _, node := range outdatedNodes[:min(batchSize, len(outdatedNodes))] {
+		for _, pod := range daemonPods[node] {
+			if pod.DeletionTimestamp != nil || pod.Labels[templateGenerationLabel] == generation {
+				continue
+			}
+			if err := cl.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+				return nil, fmt.Errorf("failed to delete pod %s/%s: %w", pod.Namespace, pod.Name, err)
+			}
+		}
+		status.Nodes = append(status.Nodes, v1.NodeRolloutStatus{
+			Name:      node,
+			State:     v1.NodeRolloutUpdating,
+			StartTime: now,
+			Message:   "waiting for the new pod to be ready",
+		})
+	}
+	status.State = v1.DaemonSetRolloutInProgress
+	return status, nil
+}
+
+// listDaemonPods returns the pods of the DaemonSet, indexed by node.
+func listDaemonPods(ctx context.Context, cl client.Client, ds *appsv1.DaemonSet) (map[string][]*corev1.Pod, error) {
+	selector, err := metav1.LabelSelectorAsSelector(ds.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector in DaemonSet %s/%s: %w", ds.Namespace, ds.Name, err)
+	}
+	podList := &corev1.PodList{}
+	if err := cl.List(ctx, podList, client.InNamespace(ds.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("failed to list pods of DaemonSet %s/%s: %w", ds.Namespace, ds.Name, err)
+	}
+	pods := map[string][]*corev1.Pod{}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Spec.NodeName != "" && isOwnedBy(pod, ds) {
+			pods[pod.Spec.NodeName] = append(pods[pod.Spec.NodeName], pod)
+		}
+	}
+	return pods, nil
+}
+
+// listWorkloadPods returns the pods on the given nodes that don't belong to the DaemonSet, indexed by node.
+func listWorkloadPods(ctx context.Context, cl client.Client, ds *appsv1.DaemonSet, nodes []v1.NodeRolloutStatus) (map[string][]*corev1.Pod, error) {
+	pods := map[string][]*corev1.Pod{}
+	for _, node := range nodes {
+		podList := &corev1.PodList{}
+		if err := cl.List(ctx, podList, client.MatchingFields{podNodeNameField: node.Name}); err != nil {
+			return nil, fmt.Errorf("failed to list pods on node %s: %w", node.Name, err)
+		}
+		for i := range podList.Items {
+			pod := &podList.Items[i]
+			if !isOwnedBy(pod, ds) {
+				pods[node.Name] = append(pods[node.Name], pod)
+			}
+		}
+	}
+	return pods, nil
+}
+
+func isOwnedBy(pod *corev1.Pod, ds *appsv1.DaemonSet) bool {
+	ref := metav1.GetControllerOf(pod)
+	return ref != nil && ref.UID == ds.UID
+}
+
+// checkNode returns the state of the rollout on the node and a message describing what it's waiting for. An
+// empty state means that the new pod and the workloads on the node are ready. Workload pods that weren't ready
+// before the rollout started on the node are ignored.
+func checkNode(daemonPods, workloadPods []*corev1.Pod, generation string, startTime metav1.Time) (v1.NodeRolloutState, string) {
+	pod := newPod(daemonPods, generation)
+	if pod == nil {
+		return v1.NodeRolloutUpdating, "waiting for the new pod to be created"
+	}
+	if reason := podFailureReason(pod); reason != "" {
+		return v1.NodeRolloutFailed, fmt.Sprintf("pod %s failed: %s", pod.Name, reason)
+	}
+	if !isPodReady(pod) {
+		return v1.NodeRolloutUpdating, fmt.Sprintf("waiting for pod %s to be ready", pod.Name)
+	}
+
+	var notReady []string
+	for _, workload := range workloadPods {
+		if workload.DeletionTimestamp != nil || workload.Status.Phase == corev1.PodSucceeded || workload.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if ready := podReadyCondition(workload); ready == nil || ready.Status == corev1.ConditionTrue || ready.LastTransitionTime.Before(&startTime) {
+			continue
+		}
+		notReady = append(notReady, workload.Namespace+"/"+workload.Name)
+	}
+	if len(notReady) > 0 {
+		slices.Sort(notReady)
+		return v1.NodeRolloutUpdating, fmt.Sprintf("waiting for workload pods to be ready: %s", strings.Join(notReady, ", "))
+	}
+	return "", ""
+}
+
+// isNodeGone returns true if the node of the batch no longer exists, or if the DaemonSet controller didn't create
+// a pod on it before the timeout. Such nodes are dropped from the batch, so they don't pause the rollout forever.
+func isNodeGone(ctx context.Context, cl client.Client, node v1.NodeRolloutStatus, now metav1.Time) (bool, error) {
+	if err := cl.Get(ctx, types.NamespacedName{Name: node.Name}, &corev1.Node{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to get node %s: %w", node.Name, err)
+	}
+	return node.State == v1.NodeRolloutFailed || now.Sub(node.StartTime.Time) > rolloutNodeTimeout, nil
+}
+
+// newPod returns the up-to-date pod of the DaemonSet that isn't being deleted, or nil if there's none.
+func newPod(pods []*corev1.Pod, generation string) *corev1.Pod {
+	for _, pod := range pods {
+		if pod.DeletionTimestamp == nil && pod.Labels[templateGenerationLabel] == generation {
+			return pod
+		}
+	}
+	return nil
+}
+
+func podFailureReason(pod *corev1.Pod) string {
+	for _, cs := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses) {
+		if cs.State.Waiting != nil && slices.Contains(podFailureReasons, cs.State.Waiting.Reason) {
+			return cs.State.Waiting.Reason
+		}
+	}
+	return ""
+}
+
+func podReadyCondition(pod *corev1.Pod) *corev1.PodCondition {
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == corev1.PodReady {
+			return &pod.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	ready := podReadyCondition(pod)
+	return ready != nil && ready.Status == corev1.ConditionTrue
+}
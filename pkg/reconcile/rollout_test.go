@@ -0,0 +1,313 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/ptr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRolloutDaemonSet(t *testing.T) {
+	const ns = "ztunnel"
+	key := types.NamespacedName{Namespace: ns, Name: ztunnelDaemonSetName}
+	batched := &v1.DaemonSetRolloutStrategy{Type: v1.DaemonSetRolloutBatched, BatchSize: 2}
+	started := metav1.NewTime(time.Now().Add(-time.Minute).Truncate(time.Second))
+
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        ztunnelDaemonSetName,
+			Namespace:   ns,
+			UID:         "ds-uid",
+			Annotations: map[string]string{templateGenerationAnnotation: "2"},
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "ztunnel"}},
+		},
+	}
+	daemonPod := func(node, generation string, ready bool) *corev1.Pod {
+		pod := workloadPod(ns, "ztunnel-"+node+"-"+generation, node, ready, started.Add(-time.Hour))
+		pod.Labels = map[string]string{"app": "ztunnel", templateGenerationLabel: generation}
+		pod.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "apps/v1", Kind: "DaemonSet", Name: ztunnelDaemonSetName, UID: ds.UID, Controller: ptr.Of(true),
+		}}
+		return pod
+	}
+	updating := func(node string) v1.NodeRolloutStatus {
+		return v1.NodeRolloutStatus{Name: node, State: v1.NodeRolloutUpdating, StartTime: started}
+	}
+
+	tests := []struct {
+		name            string
+		rollout         *v1.DaemonSetRolloutStrategy
+		objects         []client.Object
+		previous        *v1.DaemonSetRolloutStatus
+		expectState     v1.DaemonSetRolloutState
+		expectUpdated   int32
+		expectNodes     map[string]v1.NodeRolloutState
+		expectMessage   string
+		expectDeleted   []string
+		expectRemaining []string
+		expectNil       bool
+	}{
+		{
+			name:      "rolling update",
+			rollout:   &v1.DaemonSetRolloutStrategy{Type: v1.DaemonSetRolloutRollingUpdate},
+			objects:   []client.Object{ds, daemonPod("node-a", "1", true)},
+			expectNil: true,
+		},
+		{
+			name:      "DaemonSet not found",
+			rollout:   batched,
+			objects:   []client.Object{daemonPod("node-a", "1", true)},
+			expectNil: true,
+		},
+		{
+			name:          "all pods up to date",
+			rollout:       batched,
+			objects:       []client.Object{ds, daemonPod("node-a", "2", true), daemonPod("node-b", "2", true)},
+			expectState:   v1.DaemonSetRolloutComplete,
+			expectUpdated: 2,
+		},
+		{
+			name:    "starts first batch",
+			rollout: batched,
+			objects: []client.Object{
+				ds, daemonPod("node-c", "1", true), daemonPod("node-a", "1", true), daemonPod("node-b", "1", true),
+			},
+			expectState:     v1.DaemonSetRolloutInProgress,
+			expectNodes:     map[string]v1.NodeRolloutState{"node-a": v1.NodeRolloutUpdating, "node-b": v1.NodeRolloutUpdating},
+			expectDeleted:   []string{"ztunnel-node-a-1", "ztunnel-node-b-1"},
+			expectRemaining: []string{"ztunnel-node-c-1"},
+		},
+		{
+			name:    "starts next batch when previous batch is ready",
+			rollout: &v1.DaemonSetRolloutStrategy{Type: v1.DaemonSetRolloutBatched, BatchSize: 1},
+			objects: []client.Object{
+				ds, daemonPod("node-a", "2", true), daemonPod("node-b", "1", true), daemonPod("node-c", "1", true),
+				workloadPod("default", "app", "node-a", true, started.Add(time.Second)),
+			},
+			previous:        &v1.DaemonSetRolloutStatus{Nodes: []v1.NodeRolloutStatus{updating("node-a")}},
+			expectState:     v1.DaemonSetRolloutInProgress,
+			expectUpdated:   1,
+			expectNodes:     map[string]v1.NodeRolloutState{"node-b": v1.NodeRolloutUpdating},
+			expectDeleted:   []string{"ztunnel-node-b-1"},
+			expectRemaining: []string{"ztunnel-node-c-1"},
+		},
+		{
+			name:    "ignores pods it doesn't own and workloads on nodes outside the batch",
+			rollout: &v1.DaemonSetRolloutStrategy{Type: v1.DaemonSetRolloutBatched, BatchSize: 1},
+			objects: []client.Object{
+				ds, daemonPod("node-a", "2", true), daemonPod("node-b", "1", true),
+				withLabels(workloadPod(ns, "other", "node-c", true, started.Time), map[string]string{"app": "ztunnel"}),
+				workloadPod("default", "app", "node-b", false, started.Add(time.Second)),
+			},
+			previous:      &v1.DaemonSetRolloutStatus{Nodes: []v1.NodeRolloutStatus{updating("node-a")}},
+			expectState:   v1.DaemonSetRolloutInProgress,
+			expectUpdated: 1,
+			expectNodes:   map[string]v1.NodeRolloutState{"node-b": v1.NodeRolloutUpdating},
+			expectDeleted: []string{"ztunnel-node-b-1"},
+		},
+		{
+			name:            "waits for new pod to be ready",
+			rollout:         batched,
+			objects:         []client.Object{ds, daemonPod("node-a", "2", false), daemonPod("node-b", "1", true)},
+			previous:        &v1.DaemonSetRolloutStatus{Nodes: []v1.NodeRolloutStatus{updating("node-a")}},
+			expectState:     v1.DaemonSetRolloutInProgress,
+			expectUpdated:   1,
+			expectNodes:     map[string]v1.NodeRolloutState{"node-a": v1.NodeRolloutUpdating},
+			expectMessage:   "waiting for pod ztunnel-node-a-2 to be ready",
+			expectRemaining: []string{"ztunnel-node-b-1"},
+		},
+		{
+			name:    "waits for workloads that became unready",
+			rollout: batched,
+			objects: []client.Object{
+				ds, daemonPod("node-a", "2", true), daemonPod("node-b", "1", true),
+				workloadPod("default", "app", "node-a", false, started.Add(time.Second)),
+				workloadPod("default", "broken", "node-a", false, started.Add(-time.Second)),
+			},
+			previous:        &v1.DaemonSetRolloutStatus{Nodes: []v1.NodeRolloutStatus{updating("node-a")}},
+			expectState:     v1.DaemonSetRolloutInProgress,
+			expectUpdated:   1,
+			expectNodes:     map[string]v1.NodeRolloutState{"node-a": v1.NodeRolloutUpdating},
+			expectMessage:   "waiting for workload pods to be ready: default/app",
+			expectRemaining: []string{"ztunnel-node-b-1"},
+		},
+		{
+			name:    "pauses when new pod fails",
+			rollout: batched,
+			objects: []client.Object{
+				ds, withWaitingReason(daemonPod("node-a", "2", false), "CrashLoopBackOff"), daemonPod("node-b", "1", true),
+			},
+			previous:        &v1.DaemonSetRolloutStatus{Nodes: []v1.NodeRolloutStatus{updating("node-a")}},
+			expectState:     v1.DaemonSetRolloutPaused,
+			expectUpdated:   1,
+			expectNodes:     map[string]v1.NodeRolloutState{"node-a": v1.NodeRolloutFailed},
+			expectMessage:   "pod ztunnel-node-a-2 failed: CrashLoopBackOff",
+			expectRemaining: []string{"ztunnel-node-b-1"},
+		},
+		{
+			name:    "pauses when node times out",
+			rollout: batched,
+			objects: []client.Object{ds, daemonPod("node-a", "2", false), daemonPod("node-b", "1", true)},
+			previous: &v1.DaemonSetRolloutStatus{Nodes: []v1.NodeRolloutStatus{{
+				Name: "node-a", State: v1.NodeRolloutUpdating, StartTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+			}}},
+			expectState:     v1.DaemonSetRolloutPaused,
+			expectUpdated:   1,
+			expectNodes:     map[string]v1.NodeRolloutState{"node-a": v1.NodeRolloutFailed},
+			expectMessage:   "timed out after 5m0s: waiting for pod ztunnel-node-a-2 to be ready",
+			expectRemaining: []string{"ztunnel-node-b-1"},
+		},
+		{
+			name:    "resumes when failed node recovers",
+			rollout: &v1.DaemonSetRolloutStrategy{Type: v1.DaemonSetRolloutBatched, BatchSize: 1},
+			objects: []client.Object{ds, daemonPod("node-a", "2", true), daemonPod("node-b", "1", true)},
+			previous: &v1.DaemonSetRolloutStatus{Nodes: []v1.NodeRolloutStatus{{
+				Name: "node-a", State: v1.NodeRolloutFailed, StartTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+			}}},
+			expectState:   v1.DaemonSetRolloutInProgress,
+			expectUpdated: 1,
+			expectNodes:   map[string]v1.NodeRolloutState{"node-b": v1.NodeRolloutUpdating},
+			expectDeleted: []string{"ztunnel-node-b-1"},
+		},
+		{
+			name:    "drops removed node from batch",
+			rollout: &v1.DaemonSetRolloutStrategy{Type: v1.DaemonSetRolloutBatched, BatchSize: 1},
+			objects: []client.Object{ds, daemonPod("node-b", "1", true)},
+			previous: &v1.DaemonSetRolloutStatus{Nodes: []v1.NodeRolloutStatus{{
+				Name: "node-a", State: v1.NodeRolloutFailed, StartTime: started,
+			}}},
+			expectState:   v1.DaemonSetRolloutInProgress,
+			expectNodes:   map[string]v1.NodeRolloutState{"node-b": v1.NodeRolloutUpdating},
+			expectDeleted: []string{"ztunnel-node-b-1"},
+		},
+		{
+			name:            "waits for new pod to be created on existing node",
+			rollout:         batched,
+			objects:         []client.Object{ds, clusterNode("node-a"), daemonPod("node-b", "1", true)},
+			previous:        &v1.DaemonSetRolloutStatus{Nodes: []v1.NodeRolloutStatus{updating("node-a")}},
+			expectState:     v1.DaemonSetRolloutInProgress,
+			expectNodes:     map[string]v1.NodeRolloutState{"node-a": v1.NodeRolloutUpdating},
+			expectMessage:   "waiting for the new pod to be created",
+			expectRemaining: []string{"ztunnel-node-b-1"},
+		},
+		{
+			name:    "drops node that no longer runs the DaemonSet",
+			rollout: batched,
+			objects: []client.Object{ds, clusterNode("node-a"), daemonPod("node-b", "2", true)},
+			previous: &v1.DaemonSetRolloutStatus{Nodes: []v1.NodeRolloutStatus{{
+				Name: "node-a", State: v1.NodeRolloutUpdating, StartTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+			}}},
+			expectState:   v1.DaemonSetRolloutComplete,
+			expectUpdated: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cl := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(tt.objects...).
+				WithIndex(&corev1.Pod{}, podNodeNameField, podNodeName).
+				Build()
+
+			status, err := rolloutDaemonSet(ctx, cl, key, tt.rollout, tt.previous)
+			require.NoError(t, err)
+			if tt.expectNil {
+				assert.Nil(t, status)
+				return
+			}
+			require.NotNil(t, status)
+
+			assert.Equal(t, tt.expectState, status.State)
+			assert.Equal(t, tt.expectUpdated, status.UpdatedNodes)
+			nodes := map[string]v1.NodeRolloutState{}
+			for _, node := range status.Nodes {
+				nodes[node.Name] = node.State
+				if tt.expectMessage != "" {
+					assert.Equal(t, tt.expectMessage, node.Message)
+				}
+			}
+			if tt.expectNodes == nil {
+				tt.expectNodes = map[string]v1.NodeRolloutState{}
+			}
+			assert.Equal(t, tt.expectNodes, nodes)
+
+			for _, name := range tt.expectDeleted {
+				err := cl.Get(ctx, types.NamespacedName{Namespace: ns, Name: name}, &corev1.Pod{})
+				assert.True(t, apierrors.IsNotFound(err), "expected pod %s to be deleted", name)
+			}
+			for _, name := range tt.expectRemaining {
+				assert.NoError(t, cl.Get(ctx, types.NamespacedName{Namespace: ns, Name: name}, &corev1.Pod{}))
+			}
+		})
+	}
+}
+
+func workloadPod(namespace, name, node string, ready bool, readySince time.Time) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       corev1.PodSpec{NodeName: node},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{
+				Type: corev1.PodReady, Status: status, LastTransitionTime: metav1.NewTime(readySince),
+			}},
+		},
+	}
+}
+
+func clusterNode(name string) *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+func withLabels(pod *corev1.Pod, labels map[string]string) *corev1.Pod {
+	pod.Labels = labels
+	return pod
+}
+
+func withWaitingReason(pod *corev1.Pod, reason string) *corev1.Pod {
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "istio-proxy",
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}},
+	}}
+	return pod
+}
+
+func TestRolloutRequeueAfter(t *testing.T) {
+	assert.Zero(t, RolloutRequeueAfter(nil))
+	assert.Zero(t, RolloutRequeueAfter(&v1.DaemonSetRolloutStatus{State: v1.DaemonSetRolloutComplete}))
+	assert.Equal(t, RolloutCheckInterval, RolloutRequeueAfter(&v1.DaemonSetRolloutStatus{State: v1.DaemonSetRolloutPaused}))
+}
@@ -26,13 +26,15 @@ import (
 	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
 	"github.com/istio-ecosystem/sail-operator/pkg/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
-	ztunnelReleaseName = "ztunnel"
-	ztunnelChartName   = "ztunnel"
-	ztunnelProfile     = "ambient"
+	ztunnelReleaseName   = "ztunnel"
+	ztunnelChartName     = "ztunnel"
+	ztunnelProfile       = "ambient"
+	ztunnelDaemonSetName = "ztunnel"
 )
 
 // ZTunnelReconciler handles reconciliation of the ztunnel component.
@@ -67,10 +69,14 @@ func (r *ZTunnelReconciler) Validate(ctx context.Context, version, namespace str
 	return nil
 }
 
-// ComputeValues computes the final Helm values by applying digests, profiles, and user overrides.
+// ComputeValues computes the final Helm values by applying digests, profiles, user overrides, and registry mirrors.
 // If baseValues are provided (e.g. from a referenced IstioRevision), they are treated like an additional
 // profile layer: applied on top of profile defaults, with user values then applied on top.
-func (r *ZTunnelReconciler) ComputeValues(version string, userValues *v1.ZTunnelValues, baseValues ...helm.Values) (helm.Values, error) {
+// FIPS values are applied if fipsEnabled is true, in which case the image variant must support FIPS mode.
+// The TLS settings in the Config are applied to the mesh config, if it doesn't set them already.
+func (r *ZTunnelReconciler) ComputeValues(
+	version string, userValues *v1.ZTunnelValues, fipsEnabled bool, baseValues ...helm.Values,
+) (helm.Values, error) {
 	resolvedVersion, err := istioversion.Resolve(version)
 	if err != nil {
 		if istioversion.IsEOLVersion(version) {
@@ -87,7 +93,7 @@ func (r *ZTunnelReconciler) ComputeValues(version string, userValues *v1.ZTunnel
 	userValues = istiovalues.ApplyZTunnelImageDigests(resolvedVersion, userValues, config.Config)
 
 	// apply fips values
-	istiovalues.ApplyZTunnelFipsValues(userValues, resolvedVersion)
+	istiovalues.ApplyZTunnelFipsValues(userValues, resolvedVersion, fipsEnabled)
 
 	var mergedHelmValues helm.Values
 	if len(baseValues) > 0 && baseValues[0] != nil {
@@ -121,16 +127,45 @@ func (r *ZTunnelReconciler) ComputeValues(version string, userValues *v1.ZTunnel
 		return nil, fmt.Errorf("failed to apply user overrides: %w", err)
 	}
 
+	// Apply the TLS settings to the mesh config, unless they're already set by the IstioRevision or the user
+	if r.cfg.TLSConfig != nil {
+		tlsValues := &v1.Values{}
+		istiovalues.ApplyTLSConfig(r.cfg.TLSConfig, resolvedVersion, tlsValues)
+		finalHelmValues, err = istiovalues.ApplyUserValues(helm.FromValues(&v1.Values{MeshConfig: tlsValues.MeshConfig}), finalHelmValues)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply TLS config: %w", err)
+		}
+	}
+
+	// Rewrite the final images, including those set by the user and the chart's default hub, according to the registry mirrors
+	finalHelmValues, err = istiovalues.ApplyRegistryMirrors(finalHelmValues, config.Config, r.cfg.ResourceFS,
+		GetChartPath(resolvedVersion, ztunnelChartName), "hub", "image", "global.hub")
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply registry mirrors: %w", err)
+	}
+
+	// the image variant can be set in values.ztunnel.variant or in values.global.variant
+	for _, key := range []string{"variant", "global.variant"} {
+		if variant, found, _ := finalHelmValues.GetString(key); found {
+			if err := istiovalues.ValidateFipsImageVariant(fipsEnabled, &variant); err != nil {
+				return nil, reconciler.NewValidationError(err.Error())
+			}
+			break
+		}
+	}
+
 	return finalHelmValues, nil
 }
 
 // Install installs or upgrades the ztunnel Helm chart.
 // If baseValues are provided (e.g. from a referenced IstioRevision), they are passed to ComputeValues
 // to be merged early in the pipeline, before profiles and FIPS values are applied.
+// With a batched rollout strategy, the DaemonSet doesn't replace its pods itself; see Rollout.
 func (r *ZTunnelReconciler) Install(
-	ctx context.Context, version, namespace string, values *v1.ZTunnelValues, ownerRef *metav1.OwnerReference, baseValues ...helm.Values,
+	ctx context.Context, version, namespace string, values *v1.ZTunnelValues, rollout *v1.DaemonSetRolloutStrategy,
+	fipsEnabled bool, ownerRef *metav1.OwnerReference, baseValues ...helm.Values,
 ) error {
-	finalHelmValues, err := r.ComputeValues(version, values, baseValues...)
+	finalHelmValues, err := r.ComputeValues(version, values, fipsEnabled, baseValues...)
 	if err != nil {
 		return err
 	}
@@ -149,13 +184,22 @@ func (r *ZTunnelReconciler) Install(
 		namespace,
 		ztunnelReleaseName,
 		ownerRef,
+		append(instanceInstallOptions(ownerRef), rolloutInstallOptions(rollout)...)...,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to install/update Helm chart %q: %w", ztunnelChartName, err)
+		return installError(err, "failed to install/update Helm chart %q", ztunnelChartName)
 	}
 	return nil
 }
 
+// Rollout advances the batched rollout of the ztunnel DaemonSet and returns its status. It returns nil if the
+// rollout strategy isn't batched.
+func (r *ZTunnelReconciler) Rollout(
+	ctx context.Context, namespace string, rollout *v1.DaemonSetRolloutStrategy, previous *v1.DaemonSetRolloutStatus,
+) (*v1.DaemonSetRolloutStatus, error) {
+	return rolloutDaemonSet(ctx, r.client, types.NamespacedName{Namespace: namespace, Name: ztunnelDaemonSetName}, rollout, previous)
+}
+
 // Uninstall removes the ztunnel Helm chart.
 func (r *ZTunnelReconciler) Uninstall(ctx context.Context, namespace string) error {
 	_, err := r.cfg.ChartManager.UninstallChart(ctx, ztunnelReleaseName, namespace)
@@ -164,3 +208,8 @@ func (r *ZTunnelReconciler) Uninstall(ctx context.Context, namespace string) err
 	}
 	return nil
 }
+
+// DetectDrift returns the resources of the ztunnel Helm release that were modified outside of the operator.
+func (r *ZTunnelReconciler) DetectDrift(ctx context.Context, namespace string) ([]string, error) {
+	return detectDrift(ctx, r.cfg.ChartManager, namespace, ztunnelReleaseName)
+}
@@ -16,14 +16,23 @@ package reconcile
 
 import (
 	"context"
+	"crypto/tls"
 	"testing"
+	"testing/fstest"
 
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/helm"
+	"github.com/istio-ecosystem/sail-operator/pkg/istioversion"
 	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
 	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"istio.io/istio/pkg/ptr"
 )
 
 func TestZTunnelReconciler_Validate(t *testing.T) {
@@ -97,3 +106,130 @@ func TestZTunnelReconciler_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestZTunnelReconciler_ComputeValuesFips(t *testing.T) {
+	version, err := istioversion.Resolve(istioversion.Default)
+	assert.NoError(t, err)
+	profile := &fstest.MapFile{Data: []byte("apiVersion: sailoperator.io/v1\nkind: IstioRevision\nspec:\n")}
+	resourceFS := fstest.MapFS{
+		version + "/profiles/default.yaml": profile,
+		version + "/profiles/ambient.yaml": profile,
+	}
+
+	tests := []struct {
+		name        string
+		fipsEnabled bool
+		values      *v1.ZTunnelValues
+		wantErr     bool
+	}{
+		{
+			name:        "FIPS disabled with debug variant",
+			fipsEnabled: false,
+			values:      &v1.ZTunnelValues{Global: &v1.ZTunnelGlobalConfig{Variant: ptr.Of("debug")}},
+		},
+		{
+			name:        "FIPS enabled with default variant",
+			fipsEnabled: true,
+			values:      &v1.ZTunnelValues{},
+		},
+		{
+			name:        "FIPS enabled with distroless variant",
+			fipsEnabled: true,
+			values:      &v1.ZTunnelValues{ZTunnel: &v1.ZTunnelConfig{Variant: ptr.Of("distroless")}},
+		},
+		{
+			name:        "FIPS enabled with debug variant in values.global",
+			fipsEnabled: true,
+			values:      &v1.ZTunnelValues{Global: &v1.ZTunnelGlobalConfig{Variant: ptr.Of("debug")}},
+			wantErr:     true,
+		},
+		{
+			name:        "FIPS enabled with debug variant in values.ztunnel",
+			fipsEnabled: true,
+			values:      &v1.ZTunnelValues{ZTunnel: &v1.ZTunnelConfig{Variant: ptr.Of("debug")}},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewZTunnelReconciler(Config{ResourceFS: resourceFS, Platform: config.PlatformKubernetes}, nil)
+			_, err := r.ComputeValues(istioversion.Default, tt.values, tt.fipsEnabled)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.True(t, reconciler.IsValidationError(err), "expected validation error")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestZTunnelReconciler_ComputeValuesTLS(t *testing.T) {
+	version, err := istioversion.Resolve(istioversion.Default)
+	require.NoError(t, err)
+	profile := &fstest.MapFile{Data: []byte("apiVersion: sailoperator.io/v1\nkind: IstioRevision\nspec:\n")}
+	resourceFS := fstest.MapFS{
+		version + "/profiles/default.yaml": profile,
+		version + "/profiles/ambient.yaml": profile,
+	}
+	tlsConfig := &config.TLSConfig{CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, MinVersion: tls.VersionTLS12}
+
+	r := NewZTunnelReconciler(Config{ResourceFS: resourceFS, Platform: config.PlatformKubernetes, TLSConfig: tlsConfig}, nil)
+	values, err := r.ComputeValues(istioversion.Default, nil, false)
+	require.NoError(t, err)
+	minVersion, _, _ := values.GetString("meshConfig.tlsDefaults.minProtocolVersion")
+	assert.Equal(t, "TLSV1_2", minVersion)
+
+	// the TLS settings of the referenced IstioRevision take precedence
+	revisionValues := helm.FromValues(v1.Values{MeshConfig: &v1.MeshConfig{
+		TlsDefaults: &v1.MeshConfigTLSConfig{MinProtocolVersion: v1.MeshConfigTLSConfigTLSProtocolTlsv13},
+	}})
+	values, err = r.ComputeValues(istioversion.Default, nil, false, revisionValues)
+	require.NoError(t, err)
+	minVersion, _, _ = values.GetString("meshConfig.tlsDefaults.minProtocolVersion")
+	assert.Equal(t, "TLSV1_3", minVersion)
+
+	// without TLS settings, the mesh config isn't changed
+	r = NewZTunnelReconciler(Config{ResourceFS: resourceFS, Platform: config.PlatformKubernetes}, nil)
+	values, err = r.ComputeValues(istioversion.Default, nil, false)
+	require.NoError(t, err)
+	assert.NotContains(t, values, "meshConfig")
+}
+
+func TestZTunnelReconciler_ComputeValuesRegistryMirrors(t *testing.T) {
+	savedConfig := config.Config
+	t.Cleanup(func() { config.Config = savedConfig })
+	config.Config = config.OperatorConfig{
+		RegistryMirrors: map[string]config.RegistryMirror{
+			"release": {Source: "registry.istio.io/release", Mirror: "registry.example.com/istio"},
+		},
+	}
+
+	version, err := istioversion.Resolve(istioversion.Default)
+	require.NoError(t, err)
+	profile := &fstest.MapFile{Data: []byte("apiVersion: sailoperator.io/v1\nkind: IstioRevision\nspec:\n")}
+	resourceFS := fstest.MapFS{
+		version + "/profiles/default.yaml":      profile,
+		version + "/profiles/ambient.yaml":      profile,
+		version + "/charts/ztunnel/values.yaml": &fstest.MapFile{Data: []byte("_internal_defaults_do_not_set:\n  hub: registry.istio.io/release\n")},
+	}
+	r := NewZTunnelReconciler(Config{ResourceFS: resourceFS, Platform: config.PlatformKubernetes}, nil)
+
+	// the chart's default hub is rewritten
+	values, err := r.ComputeValues(istioversion.Default, nil, false)
+	require.NoError(t, err)
+	hub, _, _ := values.GetString("hub")
+	assert.Equal(t, "registry.example.com/istio", hub)
+
+	// the image set by the user and the hub of the referenced IstioRevision, which is rewritten already, are mirrored once
+	revisionValues := helm.FromValues(v1.Values{Global: &v1.GlobalConfig{Hub: ptr.Of("registry.example.com/istio")}})
+	userValues := &v1.ZTunnelValues{ZTunnel: &v1.ZTunnelConfig{Image: ptr.Of("registry.istio.io/release/ztunnel:1.30.3")}}
+	values, err = r.ComputeValues(istioversion.Default, userValues, false, revisionValues)
+	require.NoError(t, err)
+	image, _, _ := values.GetString("image")
+	assert.Equal(t, "registry.example.com/istio/ztunnel:1.30.3", image)
+	hub, _, _ = values.GetString("global.hub")
+	assert.Equal(t, "registry.example.com/istio", hub)
+	assert.Equal(t, "registry.istio.io/release/ztunnel:1.30.3", *userValues.ZTunnel.Image)
+}
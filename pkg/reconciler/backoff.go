@@ -0,0 +1,54 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconciler
+
+import (
+	"time"
+
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Retry describes when a failed reconciliation is retried. Controllers report it in the status of the
+// reconciled resource (status.retryCount and status.nextRetryTime).
+type Retry struct {
+	// Count is the number of consecutive failed reconciliations.
+	Count int32
+	// Time is the time of the next retry, or nil if no retry is scheduled.
+	Time *metav1.Time
+}
+
+// NextRetry determines the retry after a reconciliation that ended with the given error. Successful
+// reconciliations and terminal errors (see IsTerminalError) reset the retry count and schedule no retry,
+// because a terminal error only goes away when the spec changes. All other errors increment the count
+// and schedule the next retry according to the backoff policy.
+func NextRetry(policy config.BackoffPolicy, previousCount int32, err error) Retry {
+	if err == nil || IsTerminalError(err) {
+		return Retry{}
+	}
+	count := previousCount + 1
+	t := metav1.NewTime(time.Now().Add(policy.Delay(count))).Rfc3339Copy()
+	return Retry{Count: count, Time: &t}
+}
+
+// RateLimiter returns the rate limiter that a controller uses to requeue objects whose reconciliation failed.
+// Like NextRetry, it doubles the delay with each consecutive failure according to the backoff policy, so that
+// the retries happen at the time reported in the status. The failure count is reset when a reconciliation
+// succeeds.
+func RateLimiter(policy config.BackoffPolicy) workqueue.TypedRateLimiter[reconcile.Request] {
+	return workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](policy.InitialDelay, policy.MaxDelay)
+}
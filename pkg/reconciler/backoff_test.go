@@ -0,0 +1,96 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconciler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestNextRetry(t *testing.T) {
+	policy := config.BackoffPolicy{InitialDelay: time.Minute, MaxDelay: time.Hour}
+
+	tests := []struct {
+		name          string
+		previousCount int32
+		err           error
+		expectedCount int32
+		expectedDelay time.Duration
+	}{
+		{
+			name:          "success resets the retry count",
+			previousCount: 3,
+			err:           nil,
+			expectedCount: 0,
+		},
+		{
+			name:          "terminal error resets the retry count",
+			previousCount: 3,
+			err:           NewValidationError("simulated validation error"),
+			expectedCount: 0,
+		},
+		{
+			name:          "first failure",
+			previousCount: 0,
+			err:           errors.New("simulated error"),
+			expectedCount: 1,
+			expectedDelay: time.Minute,
+		},
+		{
+			name:          "consecutive transient error",
+			previousCount: 2,
+			err:           NewTransientError("simulated transient error"),
+			expectedCount: 3,
+			expectedDelay: 4 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			retry := NextRetry(policy, tt.previousCount, tt.err)
+			g.Expect(retry.Count).To(Equal(tt.expectedCount))
+			if tt.expectedDelay == 0 {
+				g.Expect(retry.Time).To(BeNil())
+				return
+			}
+			g.Expect(retry.Time).ToNot(BeNil())
+			g.Expect(retry.Time.Time).To(BeTemporally("~", time.Now().Add(tt.expectedDelay), 2*time.Second))
+		})
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	g := NewWithT(t)
+	policy := config.BackoffPolicy{InitialDelay: time.Minute, MaxDelay: 5 * time.Minute}
+	limiter := RateLimiter(policy)
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "default"}}
+
+	// the delays match the ones reported in the status by NextRetry
+	for count := int32(1); count <= 5; count++ {
+		g.Expect(limiter.When(req)).To(Equal(policy.Delay(count)))
+	}
+	g.Expect(limiter.NumRequeues(req)).To(Equal(5))
+
+	limiter.Forget(req)
+	g.Expect(limiter.When(req)).To(Equal(policy.InitialDelay))
+}
@@ -101,3 +101,168 @@ func IsReferenceNotFoundError(err error) bool {
 	var e ReferenceNotFoundError
 	return errors.As(err, &e)
 }
+
+// NotAllowedError indicates that the requested configuration is not permitted by a policy defined in another
+// resource, e.g. an allow-list. Retrying doesn't help; the resource must be reconciled again when the policy changes.
+type NotAllowedError struct {
+	Message       string
+	originalError error
+}
+
+func (err NotAllowedError) Error() string {
+	return err.Message
+}
+
+func (err NotAllowedError) Unwrap() error {
+	return err.originalError
+}
+
+func NewNotAllowedError(message string, originalError error) NotAllowedError {
+	return NotAllowedError{
+		Message:       message,
+		originalError: originalError,
+	}
+}
+
+func IsNotAllowedError(err error) bool {
+	var e NotAllowedError
+	return errors.As(err, &e)
+}
+
+// ChartRenderError indicates that a Helm chart could not be rendered, usually because of invalid values.
+// Retrying doesn't help; the values must be fixed.
+type ChartRenderError struct {
+	Message       string
+	originalError error
+}
+
+func (err ChartRenderError) Error() string {
+	return err.Message
+}
+
+func (err ChartRenderError) Unwrap() error {
+	return err.originalError
+}
+
+func NewChartRenderError(message string, originalError error) ChartRenderError {
+	return ChartRenderError{
+		Message:       message,
+		originalError: originalError,
+	}
+}
+
+func IsChartRenderError(err error) bool {
+	var e ChartRenderError
+	return errors.As(err, &e)
+}
+
+// ResourceConflictError indicates that a resource to be created already exists and is managed by something else,
+// e.g. by another Helm release.
+type ResourceConflictError struct {
+	Message       string
+	originalError error
+}
+
+func (err ResourceConflictError) Error() string {
+	return err.Message
+}
+
+func (err ResourceConflictError) Unwrap() error {
+	return err.originalError
+}
+
+func NewResourceConflictError(message string, originalError error) ResourceConflictError {
+	return ResourceConflictError{
+		Message:       message,
+		originalError: originalError,
+	}
+}
+
+func IsResourceConflictError(err error) bool {
+	var e ResourceConflictError
+	return errors.As(err, &e)
+}
+
+// QuotaExceededError indicates that a resource could not be created because a ResourceQuota was exceeded.
+type QuotaExceededError struct {
+	Message       string
+	originalError error
+}
+
+func (err QuotaExceededError) Error() string {
+	return err.Message
+}
+
+func (err QuotaExceededError) Unwrap() error {
+	return err.originalError
+}
+
+func NewQuotaExceededError(message string, originalError error) QuotaExceededError {
+	return QuotaExceededError{
+		Message:       message,
+		originalError: originalError,
+	}
+}
+
+func IsQuotaExceededError(err error) bool {
+	var e QuotaExceededError
+	return errors.As(err, &e)
+}
+
+// WebhookUnreachableError indicates that an admission webhook that must approve a change could not be reached.
+type WebhookUnreachableError struct {
+	Message       string
+	originalError error
+}
+
+func (err WebhookUnreachableError) Error() string {
+	return err.Message
+}
+
+func (err WebhookUnreachableError) Unwrap() error {
+	return err.originalError
+}
+
+func NewWebhookUnreachableError(message string, originalError error) WebhookUnreachableError {
+	return WebhookUnreachableError{
+		Message:       message,
+		originalError: originalError,
+	}
+}
+
+func IsWebhookUnreachableError(err error) bool {
+	var e WebhookUnreachableError
+	return errors.As(err, &e)
+}
+
+// PermissionDeniedError indicates that the operator lacks the RBAC permissions to manage a resource.
+type PermissionDeniedError struct {
+	Message       string
+	originalError error
+}
+
+func (err PermissionDeniedError) Error() string {
+	return err.Message
+}
+
+func (err PermissionDeniedError) Unwrap() error {
+	return err.originalError
+}
+
+func NewPermissionDeniedError(message string, originalError error) PermissionDeniedError {
+	return PermissionDeniedError{
+		Message:       message,
+		originalError: originalError,
+	}
+}
+
+func IsPermissionDeniedError(err error) bool {
+	var e PermissionDeniedError
+	return errors.As(err, &e)
+}
+
+// IsTerminalError returns true if retrying the reconciliation can't succeed until the resource's spec, or a
+// policy it is subject to, changes.
+func IsTerminalError(err error) bool {
+	return IsValidationError(err) || IsNotAllowedError(err) || IsChartRenderError(err)
+}
@@ -0,0 +1,84 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconciler
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Reasons reported in the Reconciled condition of all resource kinds when reconciliation fails with one of the
+// errors recognized by ClassifyError.
+const (
+	ReasonChartRenderFailed  v1.ConditionReason = "ChartRenderFailed"
+	ReasonResourceConflict   v1.ConditionReason = "ResourceConflict"
+	ReasonQuotaExceeded      v1.ConditionReason = "QuotaExceeded"
+	ReasonWebhookUnreachable v1.ConditionReason = "WebhookUnreachable"
+	ReasonPermissionDenied   v1.ConditionReason = "PermissionDenied"
+)
+
+var remediationHints = map[v1.ConditionReason]string{
+	ReasonChartRenderFailed:  "Check the values in the resource's spec; the Helm chart could not be rendered with them.",
+	ReasonResourceConflict:   "Delete the conflicting resource or remove it from the Helm release or tool that manages it.",
+	ReasonQuotaExceeded:      "Raise the ResourceQuota in the target namespace or lower the resource requests in the values.",
+	ReasonWebhookUnreachable: "Check that the service backing the admission webhook is running and reachable from the API server.",
+	ReasonPermissionDenied:   "Grant the operator's service account the RBAC permissions named in the error.",
+}
+
+// ClassifyError returns the reason and remediation hint for a reconciliation error, or false if the error
+// isn't one of the recognized kinds. Errors returned by Helm are converted to the typed errors in this package
+// when a chart is installed; errors returned directly by the API server are recognized by their status.
+func ClassifyError(err error) (v1.ConditionReason, string, bool) {
+	var reason v1.ConditionReason
+	switch {
+	case err == nil:
+		return "", "", false
+	case IsChartRenderError(err):
+		reason = ReasonChartRenderFailed
+	case IsWebhookUnreachableError(err):
+		reason = ReasonWebhookUnreachable
+	case IsQuotaExceededError(err), isQuotaForbiddenError(err):
+		reason = ReasonQuotaExceeded
+	case isStaleRESTMappingError(err):
+		return "", "", false
+	case IsPermissionDeniedError(err), apierrors.IsForbidden(err):
+		reason = ReasonPermissionDenied
+	case IsResourceConflictError(err), apierrors.IsAlreadyExists(err):
+		reason = ReasonResourceConflict
+	default:
+		return "", "", false
+	}
+	return reason, remediationHints[reason], true
+}
+
+// isQuotaForbiddenError returns true if the API server rejected a request because a ResourceQuota was exceeded.
+// The API server reports this as Forbidden, so it must be checked before the generic Forbidden case.
+func isQuotaForbiddenError(err error) bool {
+	return apierrors.IsForbidden(err) && strings.Contains(err.Error(), "exceeded quota")
+}
+
+// DescribeReconcileError returns the reason and message to report in the Reconciled condition when
+// reconciliation fails with the given error. Recognized errors get their own reason and a remediation hint;
+// all others get the specified default reason.
+func DescribeReconcileError(err error, defaultReason v1.ConditionReason) (v1.ConditionReason, string) {
+	message := fmt.Sprintf("error reconciling resource: %v", err)
+	if reason, hint, ok := ClassifyError(err); ok {
+		return reason, message + ". " + hint
+	}
+	return defaultReason, message
+}
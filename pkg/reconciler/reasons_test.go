@@ -0,0 +1,141 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconciler
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestClassifyError(t *testing.T) {
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedReason v1.ConditionReason
+		expectedOK     bool
+	}{
+		{
+			name:       "nil",
+			err:        nil,
+			expectedOK: false,
+		},
+		{
+			name:       "unrecognized error",
+			err:        errors.New("something went wrong"),
+			expectedOK: false,
+		},
+		{
+			name:           "chart render error",
+			err:            fmt.Errorf("failed to install: %w", NewChartRenderError("bad values", errors.New("execution error"))),
+			expectedReason: ReasonChartRenderFailed,
+			expectedOK:     true,
+		},
+		{
+			name:           "forbidden",
+			err:            apierrors.NewForbidden(deployments, "istiod", errors.New("no RBAC policy matched")),
+			expectedReason: ReasonPermissionDenied,
+			expectedOK:     true,
+		},
+		{
+			name:           "permission denied error",
+			err:            fmt.Errorf("failed to install: %w", NewPermissionDeniedError("forbidden", errors.New("forbidden"))),
+			expectedReason: ReasonPermissionDenied,
+			expectedOK:     true,
+		},
+		{
+			name:           "already exists",
+			err:            apierrors.NewAlreadyExists(deployments, "istiod"),
+			expectedReason: ReasonResourceConflict,
+			expectedOK:     true,
+		},
+		{
+			name:           "resource conflict error",
+			err:            fmt.Errorf("failed to install: %w", NewResourceConflictError("conflict", errors.New("conflict"))),
+			expectedReason: ReasonResourceConflict,
+			expectedOK:     true,
+		},
+		{
+			name:           "quota exceeded error",
+			err:            fmt.Errorf("failed to install: %w", NewQuotaExceededError("quota", errors.New("exceeded quota"))),
+			expectedReason: ReasonQuotaExceeded,
+			expectedOK:     true,
+		},
+		{
+			name: "forbidden because a quota was exceeded",
+			err: apierrors.NewForbidden(deployments, "istiod",
+				errors.New("exceeded quota: compute-resources, requested: limits.cpu=2, used: limits.cpu=1, limited: limits.cpu=2")),
+			expectedReason: ReasonQuotaExceeded,
+			expectedOK:     true,
+		},
+		{
+			name:           "webhook unreachable error",
+			err:            fmt.Errorf("failed to install: %w", NewWebhookUnreachableError("webhook", errors.New("failed calling webhook"))),
+			expectedReason: ReasonWebhookUnreachable,
+			expectedOK:     true,
+		},
+		{
+			name:       "untyped error with a recognizable message",
+			err:        errors.New(`Internal error occurred: failed calling webhook "validation.istio.io"`),
+			expectedOK: false,
+		},
+		{
+			name:       "RESTMapping error from the gc admission plugin",
+			err:        apierrors.NewForbidden(deployments, "istiod", errors.New("cannot set blockOwnerDeletion in this case because cannot find RESTMapping")),
+			expectedOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			reason, hint, ok := ClassifyError(tt.err)
+			g.Expect(ok).To(Equal(tt.expectedOK))
+			g.Expect(reason).To(Equal(tt.expectedReason))
+			if tt.expectedOK {
+				g.Expect(hint).ToNot(BeEmpty())
+			} else {
+				g.Expect(hint).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestDescribeReconcileError(t *testing.T) {
+	t.Run("unrecognized error", func(t *testing.T) {
+		g := NewWithT(t)
+
+		reason, message := DescribeReconcileError(errors.New("boom"), v1.IstioCNIReasonReconcileError)
+		g.Expect(reason).To(Equal(v1.IstioCNIReasonReconcileError))
+		g.Expect(message).To(Equal("error reconciling resource: boom"))
+	})
+
+	t.Run("recognized error", func(t *testing.T) {
+		g := NewWithT(t)
+
+		err := apierrors.NewAlreadyExists(schema.GroupResource{Resource: "services"}, "istiod")
+		reason, message := DescribeReconcileError(err, v1.IstioCNIReasonReconcileError)
+		g.Expect(reason).To(Equal(ReasonResourceConflict))
+		g.Expect(message).To(Equal("error reconciling resource: " + err.Error() + ". " + remediationHints[ReasonResourceConflict]))
+	})
+}
@@ -32,6 +32,10 @@ type ReconcileFunc[T client.Object] func(ctx context.Context, obj T) (ctrl.Resul
 // FinalizeFunc is a function that finalizes an object. It does not remove the finalizer.
 type FinalizeFunc[T client.Object] func(ctx context.Context, obj T) error
 
+// SuspendFunc is a function that is invoked instead of the ReconcileFunc while reconciliation of an object
+// is paused. It typically only updates the object's status.
+type SuspendFunc[T client.Object] func(ctx context.Context, obj T) error
+
 // StandardReconciler encapsulates common reconciler behavior, allowing you to
 // implement a reconciler simply by providing a ReconcileFunc and an optional
 // FinalizeFunc. These functions are invoked at the appropriate time and are
@@ -41,6 +45,7 @@ type StandardReconciler[T client.Object] struct {
 	reconcile ReconcileFunc[T]
 	finalizer string
 	finalize  FinalizeFunc[T]
+	suspend   SuspendFunc[T]
 }
 
 // NewStandardReconciler creates a new StandardReconciler for objects of the specified type.
@@ -61,10 +66,18 @@ func NewStandardReconcilerWithFinalizer[T client.Object](
 	}
 }
 
+// WithSuspendFunc sets the function that is invoked while reconciliation of an object is paused (see IsPaused).
+func (r *StandardReconciler[T]) WithSuspendFunc(suspendFunc SuspendFunc[T]) *StandardReconciler[T] {
+	r.suspend = suspendFunc
+	return r
+}
+
 // Reconcile reconciles the object. It first fetches the object from the client, then invokes the
 // configured ReconcileFunc. If a finalizer is configured in the reconciler, and the object is new,
 // this function adds the finalizer to the object. When the object is being deleted, this function
-// invokes the configured FinalizerFunc and removes the finalizer afterward.
+// invokes the configured FinalizerFunc and removes the finalizer afterward. While reconciliation of the object
+// is paused, the ReconcileFunc isn't invoked; the SuspendFunc is invoked instead, if configured. Finalization
+// isn't affected by the pause.
 func (r *StandardReconciler[T]) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
 
@@ -91,9 +104,17 @@ func (r *StandardReconciler[T]) Reconcile(ctx context.Context, req ctrl.Request)
 		return kube.AddFinalizer(ctx, r.client, obj, r.finalizer)
 	}
 
+	if IsPaused(obj) {
+		log.Info("Reconciliation is paused", "annotation", ReconcileAnnotation)
+		if r.suspend != nil {
+			return ctrl.Result{}, r.suspend(ctx, obj)
+		}
+		return ctrl.Result{}, nil
+	}
+
 	result, err := r.reconcile(ctx, obj)
 	switch {
-	case errors.IsForbidden(err) && strings.Contains(err.Error(), "RESTMapping"):
+	case isStaleRESTMappingError(err):
 		log.Info("APIServer seems to be not ready - RESTMapper of gc admission plugin is not up to date. Retrying...", "error", err)
 		return ctrl.Result{Requeue: true}, nil
 	case errors.IsConflict(err):
@@ -108,11 +129,26 @@ func (r *StandardReconciler[T]) Reconcile(ctx context.Context, req ctrl.Request)
 	case IsValidationError(err):
 		log.Info("Validation failed", "error", err)
 		return ctrl.Result{}, nil
+	case IsNotAllowedError(err):
+		log.Info("Configuration not allowed", "error", err)
+		return ctrl.Result{}, nil
+	case IsChartRenderError(err):
+		log.Info("Helm chart could not be rendered", "error", err)
+		return ctrl.Result{}, nil
+	case err != nil:
+		// the controller's rate limiter schedules the retry according to the backoff policy
+		return ctrl.Result{}, err
 	default:
-		return result, err
+		return result, nil
 	}
 }
 
+// isStaleRESTMappingError returns true if the API server rejected a request because the RESTMapper of its gc
+// admission plugin doesn't know about our resources yet. This resolves itself.
+func isStaleRESTMappingError(err error) bool {
+	return errors.IsForbidden(err) && strings.Contains(err.Error(), "RESTMapping")
+}
+
 func (r *StandardReconciler[T]) finalizationEnabled() bool {
 	return r.finalizer != ""
 }
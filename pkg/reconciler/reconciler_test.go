@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
@@ -40,6 +41,8 @@ const testFinalizer = "test-finalizer"
 type mockReconciler struct {
 	reconcileInvoked bool
 	finalizeInvoked  bool
+	suspendInvoked   bool
+	reconcileResult  ctrl.Result
 	reconcileError   error
 	finalizeError    error
 }
@@ -50,7 +53,7 @@ func (t *mockReconciler) Object() client.Object {
 
 func (t *mockReconciler) Reconcile(ctx context.Context, _ *v1.Istio) (ctrl.Result, error) {
 	t.reconcileInvoked = true
-	return ctrl.Result{}, t.reconcileError
+	return t.reconcileResult, t.reconcileError
 }
 
 func (t *mockReconciler) Finalize(ctx context.Context, _ *v1.Istio) error {
@@ -58,6 +61,11 @@ func (t *mockReconciler) Finalize(ctx context.Context, _ *v1.Istio) error {
 	return t.finalizeError
 }
 
+func (t *mockReconciler) Suspend(ctx context.Context, _ *v1.Istio) error {
+	t.suspendInvoked = true
+	return nil
+}
+
 var ctx = context.TODO()
 
 func TestReconcile(t *testing.T) {
@@ -157,6 +165,25 @@ func TestReconcile(t *testing.T) {
 				g.Expect(obj.GetFinalizers()).To(ContainElement(testFinalizer))
 			},
 		},
+		{
+			name: "finalizes resource when reconciliation is paused",
+			objects: []client.Object{
+				&v1.Istio{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              key.Name,
+						Annotations:       map[string]string{ReconcileAnnotation: ReconcilePaused},
+						DeletionTimestamp: testtime.OneMinuteAgo(),
+						Finalizers:        []string{testFinalizer},
+					},
+				},
+			},
+			assert: func(g *WithT, cl client.Client, result ctrl.Result, err error, mock *mockReconciler) {
+				g.Expect(result).To(BeZero())
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(mock.finalizeInvoked).To(BeTrue())
+				g.Expect(mock.suspendInvoked).To(BeFalse())
+			},
+		},
 		{
 			name: "adds finalizer when resource doesn't have it",
 			objects: []client.Object{
@@ -194,6 +221,24 @@ func TestReconcile(t *testing.T) {
 				g.Expect(mock.finalizeInvoked).To(BeFalse())
 			},
 		},
+		{
+			name: "suspends reconciliation when paused",
+			objects: []client.Object{
+				&v1.Istio{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        key.Name,
+						Annotations: map[string]string{ReconcileAnnotation: ReconcilePaused},
+						Finalizers:  []string{testFinalizer},
+					},
+				},
+			},
+			assert: func(g *WithT, cl client.Client, result ctrl.Result, err error, mock *mockReconciler) {
+				g.Expect(result).To(BeZero())
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(mock.reconcileInvoked).To(BeFalse())
+				g.Expect(mock.suspendInvoked).To(BeTrue())
+			},
+		},
 		{
 			name: "returns error when reconcile fails",
 			objects: []client.Object{
@@ -214,6 +259,46 @@ func TestReconcile(t *testing.T) {
 				g.Expect(mock.finalizeInvoked).To(BeFalse())
 			},
 		},
+		{
+			name: "returns error to the rate limiter when reconcile fails",
+			objects: []client.Object{
+				&v1.Istio{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       key.Name,
+						Finalizers: []string{testFinalizer},
+					},
+				},
+			},
+			setup: func(g *WithT, mock *mockReconciler) {
+				mock.reconcileResult = ctrl.Result{RequeueAfter: 10 * time.Second}
+				mock.reconcileError = errors.New("simulated error")
+			},
+			assert: func(g *WithT, cl client.Client, result ctrl.Result, err error, mock *mockReconciler) {
+				g.Expect(result).To(BeZero())
+				g.Expect(err).To(MatchError("simulated error"))
+				g.Expect(mock.reconcileInvoked).To(BeTrue())
+			},
+		},
+		{
+			name: "requeues through the rate limiter on TransientErrors",
+			objects: []client.Object{
+				&v1.Istio{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       key.Name,
+						Finalizers: []string{testFinalizer},
+					},
+				},
+			},
+			setup: func(g *WithT, mock *mockReconciler) {
+				mock.reconcileResult = ctrl.Result{RequeueAfter: 10 * time.Second}
+				mock.reconcileError = NewTransientError("simulated transient error")
+			},
+			assert: func(g *WithT, cl client.Client, result ctrl.Result, err error, mock *mockReconciler) {
+				g.Expect(result).To(Equal(reconcile.Result{Requeue: true}))
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(mock.reconcileInvoked).To(BeTrue())
+			},
+		},
 		{
 			name: "requeues on conflict",
 			objects: []client.Object{
@@ -254,6 +339,46 @@ func TestReconcile(t *testing.T) {
 				g.Expect(mock.finalizeInvoked).To(BeFalse())
 			},
 		},
+		{
+			name: "handles NotAllowedErrors",
+			objects: []client.Object{
+				&v1.Istio{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       key.Name,
+						Finalizers: []string{testFinalizer},
+					},
+				},
+			},
+			setup: func(g *WithT, mock *mockReconciler) {
+				mock.reconcileError = NewNotAllowedError("simulated policy violation", nil)
+			},
+			assert: func(g *WithT, cl client.Client, result ctrl.Result, err error, mock *mockReconciler) {
+				g.Expect(result).To(Equal(reconcile.Result{}))
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(mock.reconcileInvoked).To(BeTrue())
+				g.Expect(mock.finalizeInvoked).To(BeFalse())
+			},
+		},
+		{
+			name: "handles ChartRenderErrors",
+			objects: []client.Object{
+				&v1.Istio{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       key.Name,
+						Finalizers: []string{testFinalizer},
+					},
+				},
+			},
+			setup: func(g *WithT, mock *mockReconciler) {
+				mock.reconcileError = NewChartRenderError("simulated render error", nil)
+			},
+			assert: func(g *WithT, cl client.Client, result ctrl.Result, err error, mock *mockReconciler) {
+				g.Expect(result).To(Equal(reconcile.Result{}))
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(mock.reconcileInvoked).To(BeTrue())
+				g.Expect(mock.finalizeInvoked).To(BeFalse())
+			},
+		},
 		{
 			name: "requeues when gc admission plugin does not yet know about our resources",
 			objects: []client.Object{
@@ -291,7 +416,8 @@ func TestReconcile(t *testing.T) {
 				tt.setup(g, mock)
 			}
 
-			reconciler := NewStandardReconcilerWithFinalizer[*v1.Istio](cl, mock.Reconcile, mock.Finalize, testFinalizer)
+			reconciler := NewStandardReconcilerWithFinalizer[*v1.Istio](cl, mock.Reconcile, mock.Finalize, testFinalizer).
+				WithSuspendFunc(mock.Suspend)
 			result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
 
 			tt.assert(g, cl, result, err, mock)
@@ -0,0 +1,59 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconciler
+
+import (
+	"sort"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ReconcileAnnotation controls whether the operator reconciles a resource. When set to ReconcilePaused, the
+	// operator stops reconciling the resource and the resources it manages, so that these can be modified by hand.
+	ReconcileAnnotation = "sailoperator.io/reconcile"
+
+	// ReconcilePaused is the value of ReconcileAnnotation that pauses reconciliation.
+	ReconcilePaused = "paused"
+
+	suspendedMessage = "reconciliation is paused by the " + ReconcileAnnotation + " annotation"
+)
+
+// IsPaused returns true if reconciliation of the object is paused through the ReconcileAnnotation.
+func IsPaused(obj client.Object) bool {
+	return obj.GetAnnotations()[ReconcileAnnotation] == ReconcilePaused
+}
+
+// SuspendedCondition returns the Reconciled condition of an object whose reconciliation is paused.
+func SuspendedCondition(conditionType v1.ConditionType, reason v1.ConditionReason) v1.StatusCondition {
+	return v1.StatusCondition{
+		Type:    conditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: suspendedMessage,
+	}
+}
+
+// NewDriftSummary returns a DriftSummary listing the specified resources, sorted by name.
+func NewDriftSummary(resources []string) *v1.DriftSummary {
+	resources = append([]string(nil), resources...)
+	sort.Strings(resources)
+	return &v1.DriftSummary{
+		DetectedAt: metav1.Now().Rfc3339Copy(),
+		Resources:  resources,
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconciler
+
+import (
+	"testing"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsPaused(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    bool
+	}{
+		{
+			name:     "no annotations",
+			expected: false,
+		},
+		{
+			name:        "paused",
+			annotations: map[string]string{ReconcileAnnotation: ReconcilePaused},
+			expected:    true,
+		},
+		{
+			name:        "other value",
+			annotations: map[string]string{ReconcileAnnotation: "enabled"},
+			expected:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			obj := &v1.IstioCNI{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			g.Expect(IsPaused(obj)).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestNewDriftSummary(t *testing.T) {
+	g := NewWithT(t)
+
+	resources := []string{"Deployment istio-system/istiod", "ConfigMap istio-system/istio"}
+	summary := NewDriftSummary(resources)
+
+	g.Expect(summary.Resources).To(Equal([]string{"ConfigMap istio-system/istio", "Deployment istio-system/istiod"}))
+	g.Expect(summary.DetectedAt.IsZero()).To(BeFalse())
+	g.Expect(resources[0]).To(Equal("Deployment istio-system/istiod"), "input slice must not be modified")
+}
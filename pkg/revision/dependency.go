@@ -21,14 +21,14 @@ import (
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
 )
 
-type computeValuesFunc func(*v1.Values, string, string, config.Platform, string, string, fs.FS, string, *config.TLSConfig) (*v1.Values, error)
+type computeValuesFunc func(*v1.Values, string, string, config.Platform, string, string, fs.FS, string, *config.TLSConfig, bool) (*v1.Values, error)
 
 var defaultComputeValues computeValuesFunc = ComputeValues
 
 // DependsOnIstioCNI returns true if CNI is enabled in the revision
 func DependsOnIstioCNI(rev *v1.IstioRevision, cfg config.ReconcilerConfig) bool {
 	values, err := defaultComputeValues(rev.Spec.Values, rev.Spec.Namespace, rev.Spec.Version,
-		cfg.Platform, cfg.DefaultProfile, "", cfg.ResourceFS, rev.Name, nil)
+		cfg.Platform, cfg.DefaultProfile, "", cfg.ResourceFS, rev.Name, nil, false)
 	if err != nil || values == nil {
 		return false
 	}
@@ -50,7 +50,7 @@ func DependsOnIstioCNI(rev *v1.IstioRevision, cfg config.ReconcilerConfig) bool
 // DependsOnZTunnel returns true if the revision is configured for ambient mode and requires ZTunnel
 func DependsOnZTunnel(rev *v1.IstioRevision, cfg config.ReconcilerConfig) bool {
 	values, err := defaultComputeValues(rev.Spec.Values, rev.Spec.Namespace, rev.Spec.Version,
-		cfg.Platform, cfg.DefaultProfile, "", cfg.ResourceFS, rev.Name, nil)
+		cfg.Platform, cfg.DefaultProfile, "", cfg.ResourceFS, rev.Name, nil, false)
 	if err != nil || values == nil {
 		return false
 	}
@@ -32,7 +32,7 @@ func mockComputeValues(
 	_, _ string,
 	platform config.Platform,
 	defaultProfile, userProfile string, _ fs.FS, _ string,
-	_ *config.TLSConfig,
+	_ *config.TLSConfig, _ bool,
 ) (*v1.Values, error) {
 	if values == nil {
 		values = &v1.Values{}
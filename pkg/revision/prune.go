@@ -17,6 +17,8 @@ package revision
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
 	"time"
 
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
@@ -27,60 +29,163 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-// PruneInactive deletes IstioRevisions owned by the specified owner that are
-// not in use and whose grace period has expired.
-func PruneInactive(ctx context.Context, cl client.Client, ownerUID types.UID, activeRevisionName string, gracePeriod time.Duration) (ctrl.Result, error) {
+// PruneApprovedAnnotation approves the deletion of a non-active IstioRevision when the pruning policy
+// requires approval. The deletion is approved when the annotation is set to "true".
+const PruneApprovedAnnotation = "sailoperator.io/prune-approved"
+
+// PrunePolicy determines which non-active IstioRevisions PruneInactive deletes.
+type PrunePolicy struct {
+	// GracePeriod is how long a revision must not be in use before it's deleted.
+	GracePeriod time.Duration
+	// KeepLast is the number of most recently created non-active revisions that are never deleted.
+	KeepLast int
+	// Retained contains the names of the revisions that are never deleted.
+	Retained []string
+	// RequireApproval prevents revisions from being deleted until they are annotated with PruneApprovedAnnotation.
+	RequireApproval bool
+}
+
+// PruneInactive deletes IstioRevisions owned by the specified owner that are not in use, whose grace
+// period has expired and that aren't retained by the policy. It returns the pruning decision for
+// each non-active revision, sorted by name.
+func PruneInactive(ctx context.Context, cl client.Client, ownerUID types.UID, activeRevisionName string, policy PrunePolicy,
+) ([]v1.RevisionPruningStatus, ctrl.Result, error) {
 	log := logf.FromContext(ctx)
 	revisions, err := ListOwned(ctx, cl, ownerUID)
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to get revisions: %w", err)
+		return nil, ctrl.Result{}, fmt.Errorf("failed to get revisions: %w", err)
+	}
+	tags, err := getTaggedRevisions(ctx, cl)
+	if err != nil {
+		return nil, ctrl.Result{}, err
 	}
 
+	// newest revisions first, so that the first KeepLast non-active revisions are the ones to keep
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[j].CreationTimestamp.Before(&revisions[i].CreationTimestamp)
+	})
+
 	// the following code does two things:
 	// - prunes revisions whose grace period has expired
 	// - finds the time when the next revision is to be pruned
+	var decisions []v1.RevisionPruningStatus
 	var nextPruneTimestamp *time.Time
-	for _, rev := range revisions {
+	now := time.Now()
+	for i, rev := range revisions {
 		if rev.Name == activeRevisionName {
 			log.V(2).Info("IstioRevision is the active revision", "IstioRevision", rev.Name)
 			continue
 		}
-		inUseCondition := rev.Status.GetCondition(v1.IstioRevisionConditionInUse)
-
-		// Only prune revisions that are confirmed to be not in use (i.e., ConditionFalse).
-		// Skip revisions that are in use (ConditionTrue) or whose usage status is unknown (ConditionUnknown).
-		if inUseCondition.Status == metav1.ConditionTrue {
-			log.V(2).Info("IstioRevision is in use", "IstioRevision", rev.Name)
-			continue
-		}
-		if inUseCondition.Status != metav1.ConditionFalse {
-			log.V(2).Info("IstioRevision usage status is unknown, skipping pruning", "IstioRevision", rev.Name)
-			continue
-		}
+		recent := len(decisions) < policy.KeepLast
+		decision := decide(&revisions[i], tags[rev.Name], recent, policy, now)
+		log.V(2).Info("Evaluated IstioRevision for pruning", "IstioRevision", rev.Name, "Decision", decision.Decision, "Reason", decision.Reason)
 
-		pruneTimestamp := inUseCondition.LastTransitionTime.Time.Add(gracePeriod)
-		expired := pruneTimestamp.Before(time.Now())
-		if expired {
+		switch decision.Decision {
+		case v1.RevisionPruningDecisionPruned:
 			log.Info("Deleting expired IstioRevision", "IstioRevision", rev.Name)
-			err = cl.Delete(ctx, &rev)
-			if err != nil {
-				return ctrl.Result{}, fmt.Errorf("delete failed: %w", err)
+			if err := cl.Delete(ctx, &revisions[i]); err != nil {
+				return nil, ctrl.Result{}, fmt.Errorf("delete failed: %w", err)
 			}
-		} else {
-			log.V(2).Info("IstioRevision is not in use, but hasn't yet expired", "IstioRevision", rev.Name, "InUseLastTransitionTime", inUseCondition.LastTransitionTime)
+		case v1.RevisionPruningDecisionScheduled:
+			pruneTimestamp := decision.PruneTime.Time
 			if nextPruneTimestamp == nil || nextPruneTimestamp.After(pruneTimestamp) {
 				nextPruneTimestamp = &pruneTimestamp
 			}
 		}
+		decisions = append(decisions, decision)
 	}
+	sort.Slice(decisions, func(i, j int) bool {
+		return decisions[i].Name < decisions[j].Name
+	})
+
 	if nextPruneTimestamp == nil {
 		log.V(2).Info("No IstioRevisions to prune")
-		return ctrl.Result{}, nil
+		return decisions, ctrl.Result{}, nil
 	}
 
 	requeueAfter := time.Until(*nextPruneTimestamp)
 	log.Info("Requeueing resource for cleanup of expired IstioRevision", "RequeueAfter", requeueAfter)
 	// requeue so that we prune the next revision at the right time (if we didn't, we would prune it when
 	// something else triggers another reconciliation)
-	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	return decisions, ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// decide determines whether the specified non-active revision is deleted. The tag is the name of an
+// IstioRevisionTag that references the revision, and recent is true if the revision is one of the
+// policy.KeepLast most recently created non-active revisions.
+func decide(rev *v1.IstioRevision, tag string, recent bool, policy PrunePolicy, now time.Time) v1.RevisionPruningStatus {
+	retained := func(reason v1.RevisionPruningReason, message string) v1.RevisionPruningStatus {
+		return v1.RevisionPruningStatus{
+			Name:     rev.Name,
+			Decision: v1.RevisionPruningDecisionRetained,
+			Reason:   reason,
+			Message:  message,
+		}
+	}
+
+	if tag != "" {
+		return retained(v1.RevisionPruningReasonReferencedByTag, fmt.Sprintf("revision is referenced by IstioRevisionTag %s", tag))
+	}
+
+	// Only prune revisions that are confirmed to be not in use (i.e., ConditionFalse).
+	// Skip revisions that are in use (ConditionTrue) or whose usage status is unknown (ConditionUnknown).
+	inUseCondition := rev.Status.GetCondition(v1.IstioRevisionConditionInUse)
+	if inUseCondition.Status == metav1.ConditionTrue {
+		return retained(v1.RevisionPruningReasonInUse, "revision is in use")
+	}
+	if inUseCondition.Status != metav1.ConditionFalse {
+		return retained(v1.RevisionPruningReasonUsageUnknown, "cannot determine whether the revision is in use")
+	}
+
+	if slices.Contains(policy.Retained, rev.Name) {
+		return retained(v1.RevisionPruningReasonRetainedByName, "revision is listed in the retained revisions of the pruning policy")
+	}
+	if recent {
+		return retained(v1.RevisionPruningReasonKeepLast,
+			fmt.Sprintf("revision is one of the %d most recent revisions kept by the pruning policy", policy.KeepLast))
+	}
+
+	pruneTimestamp := inUseCondition.LastTransitionTime.Add(policy.GracePeriod)
+	if !pruneTimestamp.Before(now) {
+		return v1.RevisionPruningStatus{
+			Name:      rev.Name,
+			Decision:  v1.RevisionPruningDecisionScheduled,
+			Reason:    v1.RevisionPruningReasonGracePeriod,
+			Message:   "revision is not in use, but its grace period hasn't expired yet",
+			PruneTime: &metav1.Time{Time: pruneTimestamp},
+		}
+	}
+	if policy.RequireApproval && rev.Annotations[PruneApprovedAnnotation] != "true" {
+		return v1.RevisionPruningStatus{
+			Name:     rev.Name,
+			Decision: v1.RevisionPruningDecisionAwaitingApproval,
+			Reason:   v1.RevisionPruningReasonApprovalRequired,
+			Message:  fmt.Sprintf("revision is not in use; set the %s annotation to \"true\" to approve its deletion", PruneApprovedAnnotation),
+		}
+	}
+	return v1.RevisionPruningStatus{
+		Name:     rev.Name,
+		Decision: v1.RevisionPruningDecisionPruned,
+		Reason:   v1.RevisionPruningReasonExpired,
+		Message:  "revision is not in use and its grace period has expired",
+	}
+}
+
+// getTaggedRevisions returns the names of the IstioRevisions referenced by IstioRevisionTags, mapped to the
+// name of the referencing tag.
+func getTaggedRevisions(ctx context.Context, cl client.Client) (map[string]string, error) {
+	tagList := v1.IstioRevisionTagList{}
+	if err := cl.List(ctx, &tagList); err != nil {
+		return nil, fmt.Errorf("failed to list IstioRevisionTags: %w", err)
+	}
+	tags := map[string]string{}
+	for _, tag := range tagList.Items {
+		if tag.Status.IstioRevision != "" {
+			tags[tag.Status.IstioRevision] = tag.Name
+		}
+		if tag.Spec.TargetRef.Kind == v1.IstioRevisionKind {
+			tags[tag.Spec.TargetRef.Name] = tag.Name
+		}
+	}
+	return tags, nil
 }
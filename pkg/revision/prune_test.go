@@ -21,6 +21,8 @@ import (
 
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -205,7 +207,7 @@ func TestPruneInactive(t *testing.T) {
 
 			cl := newFakeClientBuilder().WithObjects(initObjs...).Build()
 
-			result, err := PruneInactive(ctx, cl, istio.UID, istioName, gracePeriod)
+			_, result, err := PruneInactive(ctx, cl, istio.UID, istioName, PrunePolicy{GracePeriod: gracePeriod})
 			if err != nil {
 				t.Errorf("Expected no error, but got: %v", err)
 			}
@@ -236,6 +238,117 @@ func TestPruneInactive(t *testing.T) {
 	}
 }
 
+func TestPruneInactiveWithPolicy(t *testing.T) {
+	const (
+		istioName = "my-istio"
+		istioUID  = "my-uid"
+	)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	newRevision := func(name string, age time.Duration, inUse metav1.ConditionStatus, annotations map[string]string) *v1.IstioRevision {
+		return &v1.IstioRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.Time{Time: now.Add(-age)},
+				Annotations:       annotations,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: v1.GroupVersion.String(),
+					Kind:       v1.IstioKind,
+					Name:       istioName,
+					UID:        istioUID,
+					Controller: ptr.Of(true),
+				}},
+			},
+			Status: v1.IstioRevisionStatus{
+				Conditions: []v1.StatusCondition{{
+					Type:               v1.IstioRevisionConditionInUse,
+					Status:             inUse,
+					LastTransitionTime: metav1.Time{Time: now.Add(-time.Hour)},
+				}},
+			},
+		}
+	}
+
+	approved := map[string]string{PruneApprovedAnnotation: "true"}
+	initObjs := []client.Object{
+		newRevision("active", time.Minute, metav1.ConditionTrue, nil),
+		newRevision("in-use", 2*time.Hour, metav1.ConditionTrue, nil),
+		newRevision("recent", 3*time.Hour, metav1.ConditionFalse, nil),
+		newRevision("pinned", 10*time.Hour, metav1.ConditionFalse, nil),
+		newRevision("tagged", 11*time.Hour, metav1.ConditionFalse, nil),
+		newRevision("unapproved", 12*time.Hour, metav1.ConditionFalse, nil),
+		newRevision("approved", 13*time.Hour, metav1.ConditionFalse, approved),
+		&v1.IstioRevisionTag{
+			ObjectMeta: metav1.ObjectMeta{Name: "canary"},
+			Spec: v1.IstioRevisionTagSpec{
+				TargetRef: v1.TargetReference{Kind: v1.IstioRevisionKind, Name: "tagged"},
+			},
+		},
+	}
+	cl := newFakeClientBuilder().WithObjects(initObjs...).Build()
+
+	policy := PrunePolicy{
+		GracePeriod:     time.Minute,
+		KeepLast:        2,
+		Retained:        []string{"pinned"},
+		RequireApproval: true,
+	}
+	decisions, result, err := PruneInactive(ctx, cl, istioUID, "active", policy)
+	require.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+
+	type decision struct {
+		Decision v1.RevisionPruningDecision
+		Reason   v1.RevisionPruningReason
+	}
+	actual := map[string]decision{}
+	var names []string
+	for _, d := range decisions {
+		actual[d.Name] = decision{d.Decision, d.Reason}
+		names = append(names, d.Name)
+	}
+	assert.Equal(t, map[string]decision{
+		"in-use":     {v1.RevisionPruningDecisionRetained, v1.RevisionPruningReasonInUse},
+		"recent":     {v1.RevisionPruningDecisionRetained, v1.RevisionPruningReasonKeepLast},
+		"pinned":     {v1.RevisionPruningDecisionRetained, v1.RevisionPruningReasonRetainedByName},
+		"tagged":     {v1.RevisionPruningDecisionRetained, v1.RevisionPruningReasonReferencedByTag},
+		"unapproved": {v1.RevisionPruningDecisionAwaitingApproval, v1.RevisionPruningReasonApprovalRequired},
+		"approved":   {v1.RevisionPruningDecisionPruned, v1.RevisionPruningReasonExpired},
+	}, actual)
+	assert.IsIncreasing(t, names, "decisions must be sorted by name")
+
+	revList := v1.IstioRevisionList{}
+	require.NoError(t, cl.List(ctx, &revList))
+	var remaining []string
+	for _, rev := range revList.Items {
+		remaining = append(remaining, rev.Name)
+	}
+	assert.ElementsMatch(t, []string{"active", "in-use", "recent", "pinned", "tagged", "unapproved"}, remaining)
+}
+
+func TestDecideSchedulesRevisionInGracePeriod(t *testing.T) {
+	now := time.Now()
+	transitionTime := metav1.Time{Time: now.Add(-10 * time.Second)}
+	rev := &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-istio-old"},
+		Status: v1.IstioRevisionStatus{
+			Conditions: []v1.StatusCondition{{
+				Type:               v1.IstioRevisionConditionInUse,
+				Status:             metav1.ConditionFalse,
+				LastTransitionTime: transitionTime,
+			}},
+		},
+	}
+
+	d := decide(rev, "", false, PrunePolicy{GracePeriod: time.Minute, RequireApproval: true}, now)
+	assert.Equal(t, v1.RevisionPruningDecisionScheduled, d.Decision)
+	assert.Equal(t, v1.RevisionPruningReasonGracePeriod, d.Reason)
+	require.NotNil(t, d.PruneTime)
+	assert.Equal(t, transitionTime.Add(time.Minute), d.PruneTime.Time)
+}
+
 func abs(duration time.Duration) time.Duration {
 	if duration < 0 {
 		return -duration
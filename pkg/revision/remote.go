@@ -14,7 +14,20 @@
 
 package revision
 
-import v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+import (
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// ExternalKubeconfigSecretName is the name of the Secret in the namespace of an external control plane that
+	// contains the kubeconfig for the remote cluster. It is the Secret that istiod itself uses to access the remote
+	// cluster, and is created with `istioctl create-remote-secret --type=config`.
+	ExternalKubeconfigSecretName = "istio-kubeconfig"
+
+	// ExternalKubeconfigSecretKey is the key of the kubeconfig in the ExternalKubeconfigSecretName Secret.
+	ExternalKubeconfigSecretKey = "config"
+)
 
 // IsUsingRemoteControlPlane returns true if the IstioRevision is configured to
 // connect to a remote rather than deploy a local control plane.
@@ -23,3 +36,15 @@ func IsUsingRemoteControlPlane(rev *v1.IstioRevision) bool {
 	values := rev.Spec.Values
 	return values != nil && values.Profile != nil && *values.Profile == "remote"
 }
+
+// IsExternalControlPlane returns true if the IstioRevision deploys an external control plane, i.e. an istiod
+// that manages the workloads in a remote cluster.
+func IsExternalControlPlane(values *v1.Values) bool {
+	return values != nil && values.Pilot != nil && values.Pilot.Env["EXTERNAL_ISTIOD"] == "true"
+}
+
+// ExternalKubeconfigSecret returns the namespace and name of the Secret that contains the kubeconfig for the remote
+// cluster of an external control plane.
+func ExternalKubeconfigSecret(rev *v1.IstioRevision) types.NamespacedName {
+	return types.NamespacedName{Namespace: rev.Spec.Namespace, Name: ExternalKubeconfigSecretName}
+}
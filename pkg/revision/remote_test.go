@@ -0,0 +1,57 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revision
+
+import (
+	"testing"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsExternalControlPlane(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   *v1.Values
+		expected bool
+	}{
+		{
+			name:     "nil values",
+			values:   nil,
+			expected: false,
+		},
+		{
+			name:     "empty values.pilot",
+			values:   &v1.Values{},
+			expected: false,
+		},
+		{
+			name:     "empty values.pilot.env",
+			values:   &v1.Values{Pilot: &v1.PilotConfig{}},
+			expected: false,
+		},
+		{
+			name:     "EXTERNAL_ISTIOD=true",
+			values:   &v1.Values{Pilot: &v1.PilotConfig{Env: map[string]string{"EXTERNAL_ISTIOD": "true"}}},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsExternalControlPlane(tt.values))
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revision
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/istioversion"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CheckComponentSkew returns a message for each IstioRevision that depends on the component and whose version
+// doesn't support the specified component version. IstioRevisions that are being deleted are ignored.
+func CheckComponentSkew(ctx context.Context, cl client.Client, cfg config.ReconcilerConfig,
+	component istioversion.Component, componentVersion string,
+) ([]string, error) {
+	revList := v1.IstioRevisionList{}
+	if err := cl.List(ctx, &revList); err != nil {
+		return nil, fmt.Errorf("failed to list IstioRevisions: %w", err)
+	}
+
+	return checkSkew(revList.Items, cfg, component, componentVersion, func(*v1.IstioRevision) bool { return true }), nil
+}
+
+// ValidateComponentVersion returns a ValidationError if the version of the component changed from the installed
+// version to one that isn't supported by an IstioRevision that depends on the component. An unchanged version is
+// never rejected, so that an installed component keeps being reconciled, e.g. when an IstioRevision with an older
+// version is created. IstioRevisions that are no longer in use, like the previous revision of an Istio with the
+// RevisionBased update strategy, are ignored, since they're about to be pruned.
+func ValidateComponentVersion(ctx context.Context, cl client.Client, cfg config.ReconcilerConfig,
+	component istioversion.Component, componentVersion, installedVersion string,
+) error {
+	if componentVersion == installedVersion {
+		return nil
+	}
+
+	revList := v1.IstioRevisionList{}
+	if err := cl.List(ctx, &revList); err != nil {
+		return fmt.Errorf("failed to list IstioRevisions: %w", err)
+	}
+
+	skewed := checkSkew(revList.Items, cfg, component, componentVersion, func(rev *v1.IstioRevision) bool {
+		return rev.Status.GetCondition(v1.IstioRevisionConditionInUse).Status != metav1.ConditionFalse
+	})
+	if len(skewed) > 0 {
+		return reconciler.NewValidationError("unsupported version skew: " + strings.Join(skewed, "; "))
+	}
+	return nil
+}
+
+func checkSkew(revs []v1.IstioRevision, cfg config.ReconcilerConfig, component istioversion.Component, componentVersion string,
+	include func(*v1.IstioRevision) bool,
+) []string {
+	var skewed []string
+	for i := range revs {
+		rev := &revs[i]
+		if !rev.DeletionTimestamp.IsZero() || !dependsOn(component, rev, cfg) || !include(rev) {
+			continue
+		}
+		if err := istioversion.CheckSkew(component, rev.Spec.Version, componentVersion); err != nil {
+			skewed = append(skewed, fmt.Sprintf("IstioRevision %s: %v", rev.Name, err))
+		}
+	}
+	return skewed
+}
+
+func dependsOn(component istioversion.Component, rev *v1.IstioRevision, cfg config.ReconcilerConfig) bool {
+	switch component {
+	case istioversion.ComponentCNI:
+		return DependsOnIstioCNI(rev, cfg)
+	case istioversion.ComponentZTunnel:
+		return DependsOnZTunnel(rev, cfg)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revision
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/istioversion"
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"istio.io/istio/pkg/ptr"
+)
+
+func TestCheckComponentSkew(t *testing.T) {
+	defaultComputeValues = mockComputeValues
+	cfg := config.ReconcilerConfig{
+		Platform:       config.PlatformKubernetes,
+		DefaultProfile: "default",
+	}
+
+	versions := istioversion.GetLatestPatchVersions()
+	require.GreaterOrEqual(t, len(versions), 3, "test requires at least three minor versions")
+	newest, previous, oldest := versions[0].Name, versions[1].Name, versions[2].Name
+
+	newRevision := func(name, version string, cniEnabled bool) *v1.IstioRevision {
+		return &v1.IstioRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1.IstioRevisionSpec{
+				Version:   version,
+				Namespace: "istio-system",
+				Values: &v1.Values{
+					Pilot: &v1.PilotConfig{Cni: &v1.CNIUsageConfig{Enabled: ptr.Of(cniEnabled)}},
+				},
+			},
+		}
+	}
+	deleting := newRevision("deleting", newest, true)
+	deleting.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	deleting.Finalizers = []string{"test"}
+
+	cl := newFakeClientBuilder().WithObjects([]client.Object{
+		newRevision("compatible", previous, true),
+		newRevision("skewed", newest, true),
+		newRevision("without-cni", newest, false),
+		deleting,
+	}...).Build()
+
+	skewed, err := CheckComponentSkew(context.Background(), cl, cfg, istioversion.ComponentCNI, oldest)
+	require.NoError(t, err)
+	require.Len(t, skewed, 1)
+	assert.Equal(t, "IstioRevision skewed: "+istioversion.CheckSkew(istioversion.ComponentCNI, newest, oldest).Error(), skewed[0])
+
+	skewed, err = CheckComponentSkew(context.Background(), cl, cfg, istioversion.ComponentCNI, previous)
+	require.NoError(t, err)
+	assert.Empty(t, skewed)
+}
+
+func TestValidateComponentVersion(t *testing.T) {
+	defaultComputeValues = mockComputeValues
+	cfg := config.ReconcilerConfig{
+		Platform:       config.PlatformKubernetes,
+		DefaultProfile: "default",
+	}
+
+	versions := istioversion.GetLatestPatchVersions()
+	require.GreaterOrEqual(t, len(versions), 3, "test requires at least three minor versions")
+	newest, previous, oldest := versions[0].Name, versions[1].Name, versions[2].Name
+
+	newRevision := func(name, version string, inUse metav1.ConditionStatus) *v1.IstioRevision {
+		return &v1.IstioRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1.IstioRevisionSpec{
+				Version:   version,
+				Namespace: "istio-system",
+				Values: &v1.Values{
+					Pilot: &v1.PilotConfig{Cni: &v1.CNIUsageConfig{Enabled: ptr.Of(true)}},
+				},
+			},
+			Status: v1.IstioRevisionStatus{
+				Conditions: []v1.StatusCondition{{Type: v1.IstioRevisionConditionInUse, Status: inUse}},
+			},
+		}
+	}
+
+	t.Run("rejects a change to an unsupported version", func(t *testing.T) {
+		cl := newFakeClientBuilder().WithObjects(newRevision("skewed", newest, metav1.ConditionTrue)).Build()
+
+		err := ValidateComponentVersion(context.Background(), cl, cfg, istioversion.ComponentCNI, oldest, previous)
+		require.Error(t, err)
+		assert.True(t, reconciler.IsValidationError(err))
+		assert.Contains(t, err.Error(), "IstioRevision skewed")
+	})
+
+	t.Run("accepts the installed version", func(t *testing.T) {
+		cl := newFakeClientBuilder().WithObjects(newRevision("skewed", newest, metav1.ConditionTrue)).Build()
+
+		assert.NoError(t, ValidateComponentVersion(context.Background(), cl, cfg, istioversion.ComponentCNI, oldest, oldest))
+	})
+
+	t.Run("ignores revisions that are no longer in use", func(t *testing.T) {
+		cl := newFakeClientBuilder().WithObjects(
+			newRevision("pruned", oldest, metav1.ConditionFalse),
+			newRevision("active", newest, metav1.ConditionTrue),
+		).Build()
+
+		assert.NoError(t, ValidateComponentVersion(context.Background(), cl, cfg, istioversion.ComponentCNI, newest, previous))
+
+		cl = newFakeClientBuilder().WithObjects(newRevision("lingering", oldest, metav1.ConditionTrue)).Build()
+		assert.Error(t, ValidateComponentVersion(context.Background(), cl, cfg, istioversion.ComponentCNI, newest, previous))
+	})
+}
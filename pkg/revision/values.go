@@ -17,26 +17,32 @@ package revision
 import (
 	"fmt"
 	"io/fs"
+	"path"
 
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
 	"github.com/istio-ecosystem/sail-operator/pkg/helm"
 	"github.com/istio-ecosystem/sail-operator/pkg/istiovalues"
 )
 
+// registryMirrorKeys are the keys of the images and hubs in the istiod chart values
+var registryMirrorKeys = []string{"global.hub", "global.proxy.image", "global.proxy_init.image", "pilot.hub", "pilot.image"}
+
 // ComputeValues computes the Istio Helm values for an IstioRevision as follows:
 // - applies image digests from the operator configuration
 // - applies vendor-specific default values
 // - applies the user-provided values on top of the default values from the default and user-selected profiles
+// - rewrites the images according to the registry mirrors in the operator configuration
 // - applies OpenShift TLS settings from the APIServer (if provided)
-// - applies FIPS values (if FIPS mode is enabled)
+// - applies FIPS values (if fipsEnabled is true)
 // - applies overrides that are not configurable by the user
 //
 // The resourceFS parameter accepts any fs.FS implementation (embed.FS, os.DirFS, etc.).
 func ComputeValues(
 	userValues *v1.Values, namespace string, version string,
 	platform config.Platform, defaultProfile, userProfile string, resourceFS fs.FS,
-	activeRevisionName string, tlsConfig *config.TLSConfig,
+	activeRevisionName string, tlsConfig *config.TLSConfig, fipsEnabled bool,
 ) (*v1.Values, error) {
 	// apply image digests from configuration, if not already set by user
 	userValues = istiovalues.ApplyDigests(version, userValues, config.Config)
@@ -53,6 +59,14 @@ func ComputeValues(
 		return nil, fmt.Errorf("failed to apply profile: %w", err)
 	}
 
+	// rewrite the final images, including those set by the user and the chart's default hub, according to the
+	// registry mirrors. Since the sidecar injector uses the proxy image, this also applies to the injected sidecars.
+	mergedHelmValues, err = istiovalues.ApplyRegistryMirrors(mergedHelmValues, config.Config, resourceFS,
+		path.Join(version, "charts", constants.IstiodChartName), registryMirrorKeys...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply registry mirrors: %w", err)
+	}
+
 	values, err := helm.ToValues(mergedHelmValues, &v1.Values{})
 	if err != nil {
 		return nil, fmt.Errorf("conversion to Helm values failed: %w", err)
@@ -62,7 +76,7 @@ func ComputeValues(
 	istiovalues.ApplyTLSConfig(tlsConfig, version, values)
 
 	// apply FipsValues on top of merged values from profile
-	istiovalues.ApplyFipsValues(values)
+	istiovalues.ApplyFipsValues(values, fipsEnabled)
 
 	// override values that are not configurable by the user
 	istiovalues.ApplyOverrides(activeRevisionName, namespace, values)
@@ -22,7 +22,6 @@ import (
 
 	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	"github.com/istio-ecosystem/sail-operator/pkg/config"
-	"github.com/istio-ecosystem/sail-operator/pkg/istiovalues"
 
 	"istio.io/istio/pkg/ptr"
 )
@@ -68,7 +67,7 @@ spec:
 		},
 	}
 
-	result, err := ComputeValues(values, namespace, version, config.PlatformOpenShift, "default", "my-profile", os.DirFS(resourceDir), revisionName, nil)
+	result, err := ComputeValues(values, namespace, version, config.PlatformOpenShift, "default", "my-profile", os.DirFS(resourceDir), revisionName, nil, false)
 	if err != nil {
 		t.Errorf("Expected no error, but got an error: %v", err)
 	}
@@ -108,12 +107,9 @@ apiVersion: sailoperator.io/v1
 kind: IstioRevision
 spec:`)), 0o644))
 
-	originalFipsEnabled := istiovalues.FipsEnabled
-	t.Cleanup(func() { istiovalues.FipsEnabled = originalFipsEnabled })
-	istiovalues.FipsEnabled = true
 	values := &v1.Values{}
 	result, err := ComputeValues(values, namespace, version, config.PlatformOpenShift, "default", "",
-		os.DirFS(resourceDir), revisionName, nil)
+		os.DirFS(resourceDir), revisionName, nil, true)
 	if err != nil {
 		t.Errorf("Expected no error, but got an error: %v", err)
 	}
@@ -130,6 +126,78 @@ spec:`)), 0o644))
 		DefaultRevision: nil,
 	}
 
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Result does not match the expected Values.\nExpected: %v\nActual: %v", expected, result)
+	}
+}
+
+// TestRegistryMirrorsComputeValues tests that the image digests from the operator configuration, the hub set in the
+// profile and the chart's default hub are rewritten according to the registry mirrors
+func TestRegistryMirrorsComputeValues(t *testing.T) {
+	const (
+		namespace    = "istio-system"
+		version      = "my-version"
+		revisionName = "my-revision"
+	)
+	resourceDir := t.TempDir()
+	profilesDir := path.Join(resourceDir, version, "profiles")
+	Must(t, os.MkdirAll(profilesDir, 0o755))
+
+	Must(t, os.WriteFile(path.Join(profilesDir, "default.yaml"), []byte((`
+apiVersion: sailoperator.io/v1
+kind: IstioRevision
+spec:
+  values:
+    pilot:
+      hub: registry.istio.io/release`)), 0o644))
+
+	chartDir := path.Join(resourceDir, version, "charts", "istiod")
+	Must(t, os.MkdirAll(chartDir, 0o755))
+	Must(t, os.WriteFile(path.Join(chartDir, "values.yaml"), []byte((`
+_internal_defaults_do_not_set:
+  global:
+    hub: registry.istio.io/release`)), 0o644))
+
+	savedConfig := config.Config
+	t.Cleanup(func() { config.Config = savedConfig })
+	config.Config = config.OperatorConfig{
+		ImageDigests: map[string]config.IstioImageConfig{
+			version: {
+				IstiodImage: "registry.istio.io/release/pilot@sha256:abc123",
+				ProxyImage:  "registry.istio.io/release/proxyv2@sha256:def456",
+			},
+		},
+		RegistryMirrors: map[string]config.RegistryMirror{
+			"release": {Source: "registry.istio.io/release", Mirror: "registry.example.com/istio"},
+		},
+	}
+
+	result, err := ComputeValues(nil, namespace, version, config.PlatformOpenShift, "default", "",
+		os.DirFS(resourceDir), revisionName, nil, false)
+	if err != nil {
+		t.Errorf("Expected no error, but got an error: %v", err)
+	}
+
+	expected := &v1.Values{
+		Pilot: &v1.PilotConfig{
+			Hub:   ptr.Of("registry.example.com/istio"),
+			Image: ptr.Of("registry.example.com/istio/pilot@sha256:abc123"),
+		},
+		Global: &v1.GlobalConfig{
+			Hub:            ptr.Of("registry.example.com/istio"),
+			Platform:       ptr.Of("openshift"),
+			IstioNamespace: ptr.Of(namespace),
+			Proxy: &v1.ProxyConfig{
+				Image: ptr.Of("registry.example.com/istio/proxyv2@sha256:def456"),
+			},
+			ProxyInit: &v1.ProxyInitConfig{
+				Image: ptr.Of("registry.example.com/istio/proxyv2@sha256:def456"),
+			},
+		},
+		Revision:        ptr.Of(revisionName),
+		DefaultRevision: nil,
+	}
+
 	if !reflect.DeepEqual(result, expected) {
 		t.Errorf("Result does not match the expected Values.\nExpected: %v\nActual: %v", expected, result)
 	}
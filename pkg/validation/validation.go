@@ -51,3 +51,42 @@ func ResourceTakesPrecedence(object1 *metav1.ObjectMeta, object2 *metav1.ObjectM
 	return object1.CreationTimestamp.Before(&object2.CreationTimestamp) ||
 		(object1.CreationTimestamp.Equal(&object2.CreationTimestamp) && strings.Compare(string(object1.UID), string(object2.UID)) < 0)
 }
+
+// NodePool describes an IstioCNI or ZTunnel instance: the namespace it's installed in and the nodes it runs on.
+type NodePool struct {
+	Meta         *metav1.ObjectMeta
+	Namespace    string
+	NodeSelector map[string]string
+}
+
+// ValidateNodePool checks that the pool doesn't share its namespace or any of its nodes with the other pools of
+// the same kind. Of two conflicting pools, the one that takes precedence according to ResourceTakesPrecedence is
+// considered valid, so that creating a new instance never breaks an existing one.
+func ValidateNodePool(kind string, pool NodePool, others []NodePool) error {
+	for _, other := range others {
+		if other.Meta.UID == pool.Meta.UID || other.Meta.DeletionTimestamp != nil ||
+			!ResourceTakesPrecedence(other.Meta, pool.Meta) {
+			continue
+		}
+		if other.Namespace == pool.Namespace {
+			return reconciler.NewValidationError(fmt.Sprintf("namespace %q is already used by %s %q",
+				pool.Namespace, kind, other.Meta.Name))
+		}
+		if NodeSelectorsOverlap(pool.NodeSelector, other.NodeSelector) {
+			return reconciler.NewValidationError(fmt.Sprintf("nodeSelector overlaps with the nodeSelector of %s %q",
+				kind, other.Meta.Name))
+		}
+	}
+	return nil
+}
+
+// NodeSelectorsOverlap returns true if a node can match both selectors, i.e. if the selectors don't require
+// different values for the same label. An empty selector matches all nodes and thus overlaps with any selector.
+func NodeSelectorsOverlap(selector1, selector2 map[string]string) bool {
+	for key, value := range selector1 {
+		if otherValue, found := selector2[key]; found && otherValue != value {
+			return false
+		}
+	}
+	return true
+}
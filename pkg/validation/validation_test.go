@@ -20,11 +20,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/istio-ecosystem/sail-operator/pkg/reconciler"
 	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
 	"github.com/istio-ecosystem/sail-operator/pkg/test/testtime"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
@@ -159,3 +161,89 @@ func TestResourceTakesPrecedence(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateNodePool(t *testing.T) {
+	earlyTimestamp := metav1.Now()
+	lateTimestamp := metav1.NewTime(earlyTimestamp.Add(time.Hour))
+
+	newPool := func(name, namespace string, created metav1.Time, selector map[string]string) NodePool {
+		return NodePool{
+			Meta:         &metav1.ObjectMeta{Name: name, UID: types.UID(name), CreationTimestamp: created},
+			Namespace:    namespace,
+			NodeSelector: selector,
+		}
+	}
+	gpu := newPool("gpu", "istio-cni-gpu", earlyTimestamp, map[string]string{"pool": "gpu"})
+	deleted := newPool("deleted", "istio-cni-deleted", earlyTimestamp, nil)
+	deleted.Meta.DeletionTimestamp = testtime.OneMinuteAgo()
+
+	testCases := []struct {
+		name      string
+		pool      NodePool
+		expectErr string
+	}{
+		{
+			name: "disjoint selector",
+			pool: newPool("canary", "istio-cni-canary", lateTimestamp, map[string]string{"pool": "canary"}),
+		},
+		{
+			name:      "overlapping selector",
+			pool:      newPool("canary", "istio-cni-canary", lateTimestamp, map[string]string{"canary": "true"}),
+			expectErr: `nodeSelector overlaps with the nodeSelector of IstioCNI "gpu"`,
+		},
+		{
+			name:      "no selector",
+			pool:      newPool("default", "istio-cni", lateTimestamp, nil),
+			expectErr: `nodeSelector overlaps with the nodeSelector of IstioCNI "gpu"`,
+		},
+		{
+			name:      "same namespace",
+			pool:      newPool("canary", "istio-cni-gpu", lateTimestamp, map[string]string{"pool": "canary"}),
+			expectErr: `namespace "istio-cni-gpu" is already used by IstioCNI "gpu"`,
+		},
+		{
+			name: "older pool takes precedence",
+			pool: newPool("canary", "istio-cni-gpu", metav1.NewTime(earlyTimestamp.Add(-time.Hour)), nil),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := ValidateNodePool("IstioCNI", tc.pool, []NodePool{gpu, deleted, tc.pool})
+			if tc.expectErr == "" {
+				g.Expect(err).ToNot(HaveOccurred())
+			} else {
+				g.Expect(reconciler.IsValidationError(err)).To(BeTrue())
+				g.Expect(err.Error()).To(ContainSubstring(tc.expectErr))
+			}
+		})
+	}
+}
+
+func TestNodeSelectorsOverlap(t *testing.T) {
+	testCases := []struct {
+		name      string
+		selector1 map[string]string
+		selector2 map[string]string
+		expected  bool
+	}{
+		{name: "both empty", expected: true},
+		{name: "one empty", selector1: map[string]string{"pool": "gpu"}, expected: true},
+		{name: "same value", selector1: map[string]string{"pool": "gpu"}, selector2: map[string]string{"pool": "gpu"}, expected: true},
+		{name: "different keys", selector1: map[string]string{"pool": "gpu"}, selector2: map[string]string{"zone": "a"}, expected: true},
+		{name: "different values", selector1: map[string]string{"pool": "gpu"}, selector2: map[string]string{"pool": "cpu"}, expected: false},
+		{
+			name:      "different values for one of several keys",
+			selector1: map[string]string{"pool": "gpu", "zone": "a"},
+			selector2: map[string]string{"pool": "gpu", "zone": "b"},
+			expected:  false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(NodeSelectorsOverlap(tc.selector1, tc.selector2)).To(Equal(tc.expected))
+			g.Expect(NodeSelectorsOverlap(tc.selector2, tc.selector1)).To(Equal(tc.expected))
+		})
+	}
+}
@@ -19,6 +19,8 @@ import (
 
 	admissionv1 "k8s.io/api/admissionregistration/v1"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -55,6 +57,35 @@ func IgnoreStatusChanges() ShouldReconcileFunc {
 	}
 }
 
+// IgnoreRetryStatusChanges returns a filter that ignores updates where only status.retryCount and
+// status.nextRetryTime changed. Controllers record these fields after each failed reconciliation and their
+// rate limiter schedules the retry, so updating them must not trigger another reconciliation right away.
+func IgnoreRetryStatusChanges() ShouldReconcileFunc {
+	return func(oldObj, newObj client.Object) bool {
+		oldFields, err := withoutRetryStatus(oldObj)
+		if err != nil {
+			return true
+		}
+		newFields, err := withoutRetryStatus(newObj)
+		if err != nil {
+			return true
+		}
+		return !reflect.DeepEqual(oldFields, newFields)
+	}
+}
+
+func withoutRetryStatus(obj client.Object) (map[string]any, error) {
+	fields, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(fields, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(fields, "metadata", "managedFields")
+	unstructured.RemoveNestedField(fields, "status", "retryCount")
+	unstructured.RemoveNestedField(fields, "status", "nextRetryTime")
+	return fields, nil
+}
+
 // WebhookFilter returns a filter that ignores webhook config updates caused by istiod (caBundle, failurePolicy).
 func WebhookFilter() ShouldReconcileFunc {
 	return func(oldObj, newObj client.Object) bool {
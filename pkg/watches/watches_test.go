@@ -16,7 +16,9 @@ package watches
 
 import (
 	"testing"
+	"time"
 
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -172,3 +174,70 @@ func TestWebhookFilter(t *testing.T) {
 	newObj2.Generation = 2
 	g.Expect(shouldReconcile(oldObj2, newObj2)).To(BeFalse(), "generation-only change should not trigger reconcile (cleared by filter)")
 }
+
+func TestIgnoreRetryStatusChanges(t *testing.T) {
+	shouldReconcile := IgnoreRetryStatusChanges()
+	retryTime := metav1.NewTime(time.Now().Add(time.Minute).Truncate(time.Second))
+
+	oldObj := &v1.IstioCNI{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", ResourceVersion: "1", Generation: 1},
+		Spec:       v1.IstioCNISpec{Namespace: "istio-cni"},
+		Status:     v1.IstioCNIStatus{State: v1.IstioCNIReasonReconcileError, RetryCount: 1},
+	}
+
+	tests := []struct {
+		name     string
+		update   func(cni *v1.IstioCNI)
+		expected bool
+	}{
+		{
+			name:     "no changes",
+			update:   func(cni *v1.IstioCNI) {},
+			expected: false,
+		},
+		{
+			name: "retry status changed",
+			update: func(cni *v1.IstioCNI) {
+				cni.ResourceVersion = "2"
+				cni.Status.RetryCount = 2
+				cni.Status.NextRetryTime = &retryTime
+			},
+			expected: false,
+		},
+		{
+			name: "other status field changed",
+			update: func(cni *v1.IstioCNI) {
+				cni.ResourceVersion = "2"
+				cni.Status.RetryCount = 2
+				cni.Status.State = v1.IstioCNIReasonHealthy
+			},
+			expected: true,
+		},
+		{
+			name: "spec changed",
+			update: func(cni *v1.IstioCNI) {
+				cni.ResourceVersion = "2"
+				cni.Generation = 2
+				cni.Spec.Namespace = "kube-system"
+			},
+			expected: true,
+		},
+		{
+			name: "annotation changed",
+			update: func(cni *v1.IstioCNI) {
+				cni.ResourceVersion = "2"
+				cni.Annotations = map[string]string{"sailoperator.io/reconcile": "paused"}
+			},
+			expected: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			newObj := oldObj.DeepCopy()
+			tc.update(newObj)
+			g.Expect(shouldReconcile(oldObj, newObj)).To(Equal(tc.expected))
+		})
+	}
+}
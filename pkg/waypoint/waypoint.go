@@ -0,0 +1,137 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package waypoint
+
+import (
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// GatewayClassName is the class of the Gateways that Istio deploys as waypoint proxies.
+	GatewayClassName = "istio-waypoint"
+
+	// listenerPort is the port on which the waypoint accepts HBONE traffic.
+	listenerPort = 15008
+
+	// conditionProgrammed is the Gateway condition that is True when the waypoint has been deployed.
+	conditionProgrammed = "Programmed"
+
+	// proxyContainerName is the name of the container in the waypoint Deployment that runs the proxy.
+	proxyContainerName = "istio-proxy"
+
+	// deploymentParametersKey is the ConfigMap key that holds the overlay that istiod applies to the Deployment of
+	// a Gateway that references the ConfigMap in spec.infrastructure.parametersRef.
+	deploymentParametersKey = "deployment"
+)
+
+// GatewayGVK is the GroupVersionKind of the Gateway API Gateway. The Gateway API types aren't a dependency of the
+// operator, so waypoints are managed as unstructured objects.
+var GatewayGVK = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "Gateway"}
+
+// NewGateway returns an empty Gateway object with the given namespace and name.
+func NewGateway(namespace, name string) *unstructured.Unstructured {
+	gw := &unstructured.Unstructured{}
+	gw.SetGroupVersionKind(GatewayGVK)
+	gw.SetNamespace(namespace)
+	gw.SetName(name)
+	return gw
+}
+
+// Configure sets the labels and spec of a waypoint Gateway according to the requirement. The revisionName is set
+// in the istio.io/rev label, so that the waypoint is deployed by that control plane revision.
+func Configure(gw *unstructured.Unstructured, requirement v1.WaypointRequirement, revisionName string) error {
+	labels := gw.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[constants.ManagedByLabelKey] = constants.ManagedByLabelValue
+	labels[constants.WaypointForLabel] = string(requirement.TrafficType)
+	if revisionName != "" {
+		labels[constants.IstioRevLabel] = revisionName
+	} else {
+		delete(labels, constants.IstioRevLabel)
+	}
+	gw.SetLabels(labels)
+	if err := unstructured.SetNestedField(gw.Object, GatewayClassName, "spec", "gatewayClassName"); err != nil {
+		return err
+	}
+	listeners := []any{map[string]any{"name": "mesh", "port": int64(listenerPort), "protocol": "HBONE"}}
+	return unstructured.SetNestedSlice(gw.Object, listeners, "spec", "listeners")
+}
+
+// ParametersConfigMapName returns the name of the ConfigMap that holds the deployment parameters of the waypoint.
+func ParametersConfigMapName(gatewayName string) string {
+	return gatewayName + "-parameters"
+}
+
+// ParametersData returns the data of the ConfigMap that sets the resources of the waypoint proxy container.
+func ParametersData(resources *corev1.ResourceRequirements) (map[string]string, error) {
+	overlay := map[string]any{
+		"spec": map[string]any{
+			"template": map[string]any{
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{"name": proxyContainerName, "resources": resources},
+					},
+				},
+			},
+		},
+	}
+	data, err := yaml.Marshal(overlay)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{deploymentParametersKey: string(data)}, nil
+}
+
+// SetParametersRef references the ConfigMap in spec.infrastructure.parametersRef of the Gateway, or removes the
+// reference if configMapName is empty.
+func SetParametersRef(gw *unstructured.Unstructured, configMapName string) error {
+	if configMapName == "" {
+		unstructured.RemoveNestedField(gw.Object, "spec", "infrastructure", "parametersRef")
+		if infrastructure, _, _ := unstructured.NestedMap(gw.Object, "spec", "infrastructure"); len(infrastructure) == 0 {
+			unstructured.RemoveNestedField(gw.Object, "spec", "infrastructure")
+		}
+		return nil
+	}
+	ref := map[string]any{"group": "", "kind": "ConfigMap", "name": configMapName}
+	return unstructured.SetNestedMap(gw.Object, ref, "spec", "infrastructure", "parametersRef")
+}
+
+// IsProgrammed returns true if the Gateway's Programmed condition is True.
+func IsProgrammed(gw *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(gw.Object, "status", "conditions")
+	for _, c := range conditions {
+		if condition, ok := c.(map[string]any); ok && condition["type"] == conditionProgrammed {
+			return condition["status"] == string(metav1.ConditionTrue)
+		}
+	}
+	return false
+}
+
+// RevisionName returns the value of the istio.io/rev label that selects the revision, or an empty string
+// for the revision that has no revision name.
+func RevisionName(rev *v1.IstioRevision) string {
+	if rev.Spec.Values != nil && rev.Spec.Values.Revision != nil {
+		return *rev.Spec.Values.Revision
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package waypoint
+
+import (
+	"testing"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"istio.io/istio/pkg/ptr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestConfigure(t *testing.T) {
+	gw := NewGateway("ns1", "waypoint")
+	gw.SetLabels(map[string]string{"foo": "bar", constants.IstioRevLabel: "old"})
+
+	require.NoError(t, Configure(gw, v1.WaypointRequirement{Name: "waypoint", TrafficType: v1.WaypointTrafficTypeAll}, ""))
+	assert.Equal(t, map[string]string{
+		"foo":                       "bar",
+		constants.ManagedByLabelKey: constants.ManagedByLabelValue,
+		constants.WaypointForLabel:  "all",
+	}, gw.GetLabels())
+	className, _, _ := unstructured.NestedString(gw.Object, "spec", "gatewayClassName")
+	assert.Equal(t, GatewayClassName, className)
+	listeners, _, _ := unstructured.NestedSlice(gw.Object, "spec", "listeners")
+	assert.Equal(t, []any{map[string]any{"name": "mesh", "port": int64(15008), "protocol": "HBONE"}}, listeners)
+
+	require.NoError(t, Configure(gw, v1.WaypointRequirement{Name: "waypoint", TrafficType: v1.WaypointTrafficTypeService}, "canary"))
+	assert.Equal(t, "canary", gw.GetLabels()[constants.IstioRevLabel])
+	assert.Equal(t, "service", gw.GetLabels()[constants.WaypointForLabel])
+}
+
+func TestParametersData(t *testing.T) {
+	data, err := ParametersData(&corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"deployment": `spec:
+  template:
+    spec:
+      containers:
+      - name: istio-proxy
+        resources:
+          limits:
+            memory: 1Gi
+`,
+	}, data)
+}
+
+func TestSetParametersRef(t *testing.T) {
+	gw := NewGateway("ns", "waypoint")
+	require.NoError(t, SetParametersRef(gw, ParametersConfigMapName("waypoint")))
+	ref, _, _ := unstructured.NestedStringMap(gw.Object, "spec", "infrastructure", "parametersRef")
+	assert.Equal(t, map[string]string{"group": "", "kind": "ConfigMap", "name": "waypoint-parameters"}, ref)
+
+	require.NoError(t, SetParametersRef(gw, ""))
+	_, found, _ := unstructured.NestedFieldNoCopy(gw.Object, "spec", "infrastructure")
+	assert.False(t, found)
+}
+
+func TestIsProgrammed(t *testing.T) {
+	gw := NewGateway("ns1", "waypoint")
+	assert.False(t, IsProgrammed(gw))
+
+	setCondition := func(status string) {
+		conditions := []any{
+			map[string]any{"type": "Accepted", "status": "True"},
+			map[string]any{"type": "Programmed", "status": status},
+		}
+		require.NoError(t, unstructured.SetNestedSlice(gw.Object, conditions, "status", "conditions"))
+	}
+	setCondition("False")
+	assert.False(t, IsProgrammed(gw))
+	setCondition("True")
+	assert.True(t, IsProgrammed(gw))
+}
+
+func TestRevisionName(t *testing.T) {
+	assert.Equal(t, "", RevisionName(&v1.IstioRevision{}))
+	assert.Equal(t, "canary", RevisionName(&v1.IstioRevision{Spec: v1.IstioRevisionSpec{Values: &v1.Values{Revision: ptr.Of("canary")}}}))
+}
@@ -0,0 +1,107 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// Package waypointtest provides the fixtures shared by the tests of the controllers that deploy waypoints.
+package waypointtest
+
+import (
+	"context"
+
+	v1 "github.com/istio-ecosystem/sail-operator/api/v1"
+	"github.com/istio-ecosystem/sail-operator/pkg/scheme"
+	"github.com/istio-ecosystem/sail-operator/pkg/waypoint"
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"istio.io/istio/pkg/ptr"
+)
+
+// NewFakeClient returns a fake client with the status subresources of the operator's resources. If withGatewayAPI
+// is false, the client returns a NoKindMatchError for Gateways, which mimics a cluster without the Gateway API CRDs.
+func NewFakeClient(withGatewayAPI bool, objs ...client.Object) client.Client {
+	builder := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&v1.Waypoint{}, &v1.AmbientEnrollment{}, &v1.IstioRevision{})
+	if !withGatewayAPI {
+		noMatch := func(obj client.Object) error {
+			if obj.GetObjectKind().GroupVersionKind() == waypoint.GatewayGVK {
+				return &meta.NoKindMatchError{GroupKind: waypoint.GatewayGVK.GroupKind(), SearchedVersions: []string{waypoint.GatewayGVK.Version}}
+			}
+			return nil
+		}
+		builder = builder.WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, cl client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if err := noMatch(obj); err != nil {
+					return err
+				}
+				return cl.Get(ctx, key, obj, opts...)
+			},
+			Create: func(ctx context.Context, cl client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if err := noMatch(obj); err != nil {
+					return err
+				}
+				return cl.Create(ctx, obj, opts...)
+			},
+		})
+	}
+	return builder.Build()
+}
+
+// NewIstio returns an Istio with the given active revision.
+func NewIstio(name, activeRevision string) *v1.Istio {
+	return &v1.Istio{
+		ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name + "-uid")},
+		Status:     v1.IstioStatus{ActiveRevisionName: activeRevision},
+	}
+}
+
+// NewOwnedRevision returns an IstioRevision owned by the Istio returned by NewIstio. If revisionName isn't empty,
+// it's set as the revision in the values.
+func NewOwnedRevision(istioName, name, revisionName string, ready bool) *v1.IstioRevision {
+	rev := &v1.IstioRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: v1.GroupVersion.String(),
+					Kind:       v1.IstioKind,
+					Name:       istioName,
+					UID:        types.UID(istioName + "-uid"),
+					Controller: ptr.Of(true),
+				},
+			},
+		},
+		Spec: v1.IstioRevisionSpec{Values: &v1.Values{}},
+	}
+	if revisionName != "" {
+		rev.Spec.Values.Revision = ptr.Of(revisionName)
+	}
+	if ready {
+		rev.Status.SetCondition(v1.StatusCondition{Type: v1.IstioRevisionConditionReady, Status: metav1.ConditionTrue})
+	}
+	return rev
+}
+
+// GetGateway returns the Gateway with the given name and fails the test if it doesn't exist.
+func GetGateway(g gomega.Gomega, cl client.Client, namespace, name string) *unstructured.Unstructured {
+	gw := waypoint.NewGateway("", "")
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, gw)).To(gomega.Succeed())
+	return gw
+}
@@ -429,6 +429,9 @@ spec:
 				g.Expect(rev.Spec.Values.Pilot.ExtraContainerArgs).To(
 					ContainElement(ContainSubstring("--tls-cipher-suites=")),
 					"IstioRevision should have --tls-cipher-suites in pilot.extraContainerArgs")
+
+				g.Expect(rev.Status.TLSProfile).NotTo(BeNil(), "IstioRevision should report the TLS settings in effect")
+				g.Expect(rev.Status.TLSProfile.Ciphers).To(Equal(ciphers))
 			}).WithTimeout(5*time.Minute).WithPolling(5*time.Second).Should(Succeed(),
 				"IstioRevision is not syncing TLS settings but should be")
 
@@ -606,25 +609,20 @@ func ensureTLSAdherence(ctx context.Context, cl client.Client, policy configv1.T
 	apiServer.Spec.TLSAdherence = policy
 	Expect(cl.Update(ctx, apiServer)).To(Succeed(), "Failed to update APIServer TLSAdherence")
 
-	Step("Waiting for operator to restart and be ready after TLSAdherence change")
-	Eventually(func(g Gomega) {
+	Step("Verifying the operator reloads the TLS config without restarting after TLSAdherence change")
+	Consistently(func(g Gomega) {
 		pods := &corev1.PodList{}
 		g.Expect(cl.List(ctx, pods, client.InNamespace(namespace),
 			client.MatchingLabels{"control-plane": deploymentName})).To(Succeed())
 
-		restarted := false
 		for _, pod := range pods.Items {
-			if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning || !podReady(pod) {
-				continue
-			}
-			if prev, seen := oldRestarts[string(pod.UID)]; !seen || totalRestarts(pod) > prev {
-				restarted = true
-				break
-			}
+			prev, seen := oldRestarts[string(pod.UID)]
+			g.Expect(seen).To(BeTrue(), "Operator pod %s was replaced after TLSAdherence change to %s", pod.Name, policy)
+			g.Expect(totalRestarts(pod)).To(Equal(prev), "Operator pod %s restarted after TLSAdherence change to %s", pod.Name, policy)
+			g.Expect(podReady(pod)).To(BeTrue(), "Operator pod %s is not ready after TLSAdherence change to %s", pod.Name, policy)
 		}
-		g.Expect(restarted).To(BeTrue(), "Operator did not restart (in place or via a new pod) after TLSAdherence change to %s", policy)
-	}).WithTimeout(5*time.Minute).WithPolling(5*time.Second).Should(Succeed(),
-		"Operator should restart and be ready after TLSAdherence change")
+	}).WithTimeout(30*time.Second).WithPolling(5*time.Second).Should(Succeed(),
+		"Operator should not restart after TLSAdherence change")
 }
 
 func applyCustomTLSProfile(ctx context.Context, cl client.Client, ciphers []string) {
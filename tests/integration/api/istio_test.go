@@ -101,6 +101,37 @@ var _ = Describe("Istio resource", Ordered, func() {
 			}
 			Expect(k8sClient.Create(ctx, istio)).To(Not(Succeed()))
 		})
+
+		It("rejects an Istio with duplicate gateway names", func() {
+			istio = &v1.Istio{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: istioName,
+				},
+				Spec: v1.IstioSpec{
+					Version:   istioversion.Default,
+					Namespace: istioNamespace,
+					Gateways: []v1.IstioGateway{
+						{Name: "ingress"},
+						{Name: "ingress", Namespace: "other"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, istio)).To(Not(Succeed()))
+		})
+
+		It("rejects a gateway name that is too long for the Helm release name", func() {
+			istio = &v1.Istio{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: istioName,
+				},
+				Spec: v1.IstioSpec{
+					Version:   istioversion.Default,
+					Namespace: istioNamespace,
+					Gateways:  []v1.IstioGateway{{Name: strings.Repeat("a", 46)}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, istio)).To(Not(Succeed()))
+		})
 	})
 
 	Describe("basic operation", func() {
@@ -554,9 +585,9 @@ var _ = Describe("Istio resource", Ordered, func() {
 		It("applies TLS cipher suites to the IstioRevision", func() {
 			cipherID := tls.CipherSuites()[0].ID
 			expectedCipherName := tls.CipherSuiteName(cipherID)
-			istioReconciler.Config.TLSConfig = &config.TLSConfig{
+			istioReconciler.Config.TLSConfig = config.NewTLSConfigSource(&config.TLSConfig{
 				CipherSuites: []uint16{cipherID},
-			}
+			})
 			DeferCleanup(func() {
 				istioReconciler.Config.TLSConfig = nil
 			})
@@ -600,9 +631,9 @@ var _ = Describe("Istio resource", Ordered, func() {
 			userExtraArg := "--tls-cipher-suites=" + userCipherSuite
 			tlsConfigCipherID := tls.TLS_AES_256_GCM_SHA384
 			tlsConfigCipherName := tls.CipherSuiteName(tlsConfigCipherID)
-			istioReconciler.Config.TLSConfig = &config.TLSConfig{
+			istioReconciler.Config.TLSConfig = config.NewTLSConfigSource(&config.TLSConfig{
 				CipherSuites: []uint16{tlsConfigCipherID},
-			}
+			})
 			DeferCleanup(func() {
 				istioReconciler.Config.TLSConfig = nil
 			})
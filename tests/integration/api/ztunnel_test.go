@@ -203,20 +203,15 @@ var _ = Describe("ZTunnel FIPS", Label("ztunnel", "fips"), Ordered, func() {
 	})
 
 	// TODO: Remove this test when Istio 1.29 goes out of support
-	It("sets TLS12_ENABLED on the ztunnel DaemonSet when FipsEnabled is true and version < 1.30", func() {
-		originalFipsEnabled := istiovalues.FipsEnabled
-		DeferCleanup(func() {
-			istiovalues.FipsEnabled = originalFipsEnabled
-		})
-		istiovalues.FipsEnabled = true
-
+	It("sets TLS12_ENABLED on the ztunnel DaemonSet when FIPS mode is enabled and version < 1.30", func() {
 		ztunnel := &v1.ZTunnel{
 			ObjectMeta: metav1.ObjectMeta{
 				Name: ztunnelName,
 			},
 			Spec: v1.ZTunnelSpec{
-				Version:   "v1.29.3",
-				Namespace: fipsZTunnelNamespace,
+				Version:    "v1.29.3",
+				Namespace:  fipsZTunnelNamespace,
+				Compliance: &v1.Compliance{FIPS: v1.FIPSModeEnabled},
 			},
 		}
 		Expect(k8sClient.Create(ctx, ztunnel)).To(Succeed())
@@ -231,6 +226,11 @@ var _ = Describe("ZTunnel FIPS", Label("ztunnel", "fips"), Ordered, func() {
 		Expect(ds).To(HaveContainersThat(ContainElement(WithTransform(getEnvVars,
 			ContainElement(corev1.EnvVar{Name: "TLS12_ENABLED", Value: "true"})))),
 			"Expected TLS12_ENABLED to be set to true on ztunnel DaemonSet when FIPS is enabled")
+
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, fipsZTunnelKey, ztunnel)).To(Succeed())
+			g.Expect(ztunnel.Status.Compliance).To(Equal(&v1.ComplianceStatus{FIPS: v1.FIPSModeEnabled}))
+		}).Should(Succeed())
 	})
 
 	It("removes TLS12_ENABLED from the ztunnel DaemonSet when version > 1.30", func() {